@@ -0,0 +1,378 @@
+package agentloop
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// AgentProfile is a named specialization of a session: a restricted subset
+// of the provider profile's tools, an additional system-prompt fragment, and
+// optional overrides for reasoning effort, the tool-round budget, and
+// provider options. It lets a host configure e.g. a "coding" agent with
+// file/edit tools and a "research" agent with web/search tools, without
+// every tool being available in every context.
+type AgentProfile struct {
+	Name                  string
+	SystemPromptFragment  string
+	ToolNames             []string               // subset of the provider registry; empty means inherit all tools
+	ReasoningEffort       string                 // overrides SessionConfig.ReasoningEffort if non-empty
+	MaxToolRoundsPerInput int                    // overrides SessionConfig.MaxToolRoundsPerInput if > 0
+	ProviderOptions       map[string]interface{} // overrides the provider profile's options if non-nil
+
+	// ToolCharLimits and ToolLineLimits override SessionConfig's own
+	// per-tool truncation limits while this agent is active, the way
+	// DefaultToolCharLimits/DefaultToolLineLimits override the package
+	// defaults. A key missing here falls back to the session's own limit.
+	ToolCharLimits map[string]int
+	ToolLineLimits map[string]int
+
+	// ToolTokenLimits overrides SessionConfig.ToolTokenLimits while this
+	// agent is active, the same way ToolCharLimits overrides
+	// ToolOutputLimits. Only consulted when the session has a Tokenizer
+	// configured.
+	ToolTokenLimits map[string]int
+
+	// Credentials are injected as environment variables on every
+	// ExecCommand call while this agent is active (see
+	// effectiveExecutionEnvironment), so a tool's Executor closure can reach
+	// them without the profile's secrets ever entering the host process's
+	// own environment. Keys here are environment variable names.
+	Credentials map[string]string
+
+	// SeedDocuments are recorded into the session's history the first time
+	// this agent is activated (see seedAgentDocuments), letting a profile
+	// bundle RAG reference material or style guides that should already be
+	// in context before the first real user turn.
+	SeedDocuments []SeedDocument
+}
+
+// SeedDocument is a single RAG "seed" entry an AgentProfile injects into
+// session history when it activates.
+type SeedDocument struct {
+	Role    TurnKind // TurnSystem or TurnUser; anything else is treated as TurnSystem
+	Content string
+}
+
+// RegisterAgentProfile makes profile available to SwitchAgent and the
+// "agent" delegation tool. The first call exposes the "agent" tool on the
+// provider's tool registry; later calls just extend the set it can target.
+func (s *Session) RegisterAgentProfile(profile *AgentProfile) {
+	s.mu.Lock()
+	first := s.agentProfiles == nil
+	if first {
+		s.agentProfiles = make(map[string]*AgentProfile)
+	}
+	s.agentProfiles[profile.Name] = profile
+	s.mu.Unlock()
+
+	if first {
+		registerAgentDelegationTool(s)
+	}
+}
+
+// SwitchAgent scopes the session to the named agent profile: subsequent LLM
+// requests see only that profile's tools and its system-prompt fragment,
+// plus any of its config overrides. Pass "" to return to the full provider
+// profile. Returns an error if name is non-empty and was never registered.
+func (s *Session) SwitchAgent(name string) error {
+	if name == "" {
+		s.mu.Lock()
+		s.activeAgent = nil
+		s.scopedTools = nil
+		s.mu.Unlock()
+		return nil
+	}
+
+	s.mu.Lock()
+	profile, ok := s.agentProfiles[name]
+	s.mu.Unlock()
+	if !ok {
+		return fmt.Errorf("unknown agent profile: %s", name)
+	}
+
+	scoped := s.profile.ToolRegistry()
+	if len(profile.ToolNames) > 0 {
+		scoped = scoped.Subset(profile.ToolNames)
+	}
+
+	s.mu.Lock()
+	s.activeAgent = profile
+	s.scopedTools = scoped
+	s.mu.Unlock()
+
+	s.seedAgentDocuments(profile)
+
+	s.emitter.Emit(EventAgentSwitched, map[string]interface{}{
+		"agent": name,
+	})
+	return nil
+}
+
+// seedAgentDocuments records profile's SeedDocuments into history as
+// SystemTurn/UserTurn entries, once per agent per session: the first
+// SwitchAgent call to activate a given agent seeds it, and later switches
+// back to that same agent don't repeat the injection.
+func (s *Session) seedAgentDocuments(profile *AgentProfile) {
+	if len(profile.SeedDocuments) == 0 {
+		return
+	}
+
+	s.mu.Lock()
+	if s.seededAgents == nil {
+		s.seededAgents = make(map[string]bool)
+	}
+	if s.seededAgents[profile.Name] {
+		s.mu.Unlock()
+		return
+	}
+	s.seededAgents[profile.Name] = true
+	s.mu.Unlock()
+
+	for _, doc := range profile.SeedDocuments {
+		if doc.Role == TurnUser {
+			s.recordTurn(NewUserTurn(doc.Content), nil, nil)
+		} else {
+			s.recordTurn(NewSystemTurn(doc.Content), nil, nil)
+		}
+	}
+}
+
+// ActiveAgentName returns the name of the currently active agent profile, or
+// "" if the session is using the full provider profile.
+func (s *Session) ActiveAgentName() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.activeAgent == nil {
+		return ""
+	}
+	return s.activeAgent.Name
+}
+
+// effectiveToolRegistry returns the scoped registry for the active agent
+// profile, or the full provider registry if no agent is active.
+func (s *Session) effectiveToolRegistry() *ToolRegistry {
+	s.mu.Lock()
+	scoped := s.scopedTools
+	s.mu.Unlock()
+	if scoped != nil {
+		return scoped
+	}
+	return s.profile.ToolRegistry()
+}
+
+// effectiveToolDefs returns the tool definitions visible in the current
+// scope, for inclusion in the LLM request.
+func (s *Session) effectiveToolDefs() []ToolDefinition {
+	return s.effectiveToolRegistry().Definitions()
+}
+
+// effectiveProviderOptions returns the active agent profile's provider
+// options override if set, otherwise the provider profile's own options.
+func (s *Session) effectiveProviderOptions() map[string]interface{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.activeAgent != nil && s.activeAgent.ProviderOptions != nil {
+		return s.activeAgent.ProviderOptions
+	}
+	return s.profile.ProviderOptions()
+}
+
+// effectiveToolLimits returns the character and line truncation limits for
+// tool output, merging the active agent profile's ToolCharLimits/
+// ToolLineLimits (if any) over the session's own SessionConfig.ToolOutputLimits/
+// ToolLineLimits. A tool named in both is resolved in the agent's favor.
+func (s *Session) effectiveToolLimits() (charLimits, lineLimits map[string]int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	charLimits = s.config.ToolOutputLimits
+	lineLimits = s.config.ToolLineLimits
+	if s.activeAgent == nil {
+		return charLimits, lineLimits
+	}
+	if len(s.activeAgent.ToolCharLimits) > 0 {
+		charLimits = mergeIntMaps(charLimits, s.activeAgent.ToolCharLimits)
+	}
+	if len(s.activeAgent.ToolLineLimits) > 0 {
+		lineLimits = mergeIntMaps(lineLimits, s.activeAgent.ToolLineLimits)
+	}
+	return charLimits, lineLimits
+}
+
+// effectiveTokenizer returns the session's configured Tokenizer (nil if
+// none, meaning TruncateToolOutput falls back to character limits) and the
+// token limits to use with it, merging the active agent profile's
+// ToolTokenLimits (if any) over the session's own SessionConfig.ToolTokenLimits.
+func (s *Session) effectiveTokenizer() (tokenizer Tokenizer, tokenLimits map[string]int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	tokenizer = s.config.Tokenizer
+	tokenLimits = s.config.ToolTokenLimits
+	if s.activeAgent != nil && len(s.activeAgent.ToolTokenLimits) > 0 {
+		tokenLimits = mergeIntMaps(tokenLimits, s.activeAgent.ToolTokenLimits)
+	}
+	return tokenizer, tokenLimits
+}
+
+// mergeIntMaps returns a new map containing base's entries overridden by
+// override's.
+func mergeIntMaps(base, override map[string]int) map[string]int {
+	merged := make(map[string]int, len(base)+len(override))
+	for k, v := range base {
+		merged[k] = v
+	}
+	for k, v := range override {
+		merged[k] = v
+	}
+	return merged
+}
+
+// effectiveExecutionEnvironment returns the ExecutionEnvironment tool
+// Executors should run against: the session's own if no agent is active or
+// the active agent declares no Credentials, or a wrapper exposing those
+// credentials as environment variables otherwise.
+func (s *Session) effectiveExecutionEnvironment() ExecutionEnvironment {
+	s.mu.Lock()
+	agent := s.activeAgent
+	s.mu.Unlock()
+	if agent == nil || len(agent.Credentials) == 0 {
+		return s.env
+	}
+	return &credentialExecutionEnvironment{ExecutionEnvironment: s.env, credentials: agent.Credentials}
+}
+
+// credentialExecutionEnvironment decorates an ExecutionEnvironment, injecting
+// an agent profile's credentials as default environment variables on every
+// ExecCommand call. This is how a profile's Credentials reach a tool's
+// Executor closure: Executor only receives arguments and an
+// ExecutionEnvironment, not a context.Context, so the environment itself is
+// the seam. Explicit envVars passed to ExecCommand take precedence over a
+// same-named credential.
+type credentialExecutionEnvironment struct {
+	ExecutionEnvironment
+	credentials map[string]string
+}
+
+func (e *credentialExecutionEnvironment) ExecCommand(ctx context.Context, command string, timeoutMs int, workingDir string, envVars map[string]string) (*ExecResult, error) {
+	merged := mergeStringMaps(e.credentials, envVars)
+	return e.ExecutionEnvironment.ExecCommand(ctx, command, timeoutMs, workingDir, merged)
+}
+
+// mergeStringMaps returns a new map containing base's entries overridden by
+// override's.
+func mergeStringMaps(base, override map[string]string) map[string]string {
+	merged := make(map[string]string, len(base)+len(override))
+	for k, v := range base {
+		merged[k] = v
+	}
+	for k, v := range override {
+		merged[k] = v
+	}
+	return merged
+}
+
+// NewSessionWithAgent creates a session exactly as NewSession does, then
+// registers and activates agent in one step, so a caller that just wants a
+// single named agent doesn't need to call RegisterAgentProfile and
+// SwitchAgent itself: the tool registry is scoped to agent.ToolNames, its
+// SystemPromptFragment and truncation/credential overrides take effect, and
+// its SeedDocuments are recorded into history, all before the first Submit.
+func NewSessionWithAgent(profile ProviderProfile, env ExecutionEnvironment, config *SessionConfig, agent *AgentProfile) (*Session, error) {
+	s := NewSession(profile, env, config)
+	s.RegisterAgentProfile(agent)
+	if err := s.SwitchAgent(agent.Name); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// appendAgentSystemPrompt appends the active agent profile's system-prompt
+// fragment to base, if an agent is active and declares one.
+func (s *Session) appendAgentSystemPrompt(base string) string {
+	s.mu.Lock()
+	agent := s.activeAgent
+	s.mu.Unlock()
+	if agent == nil || agent.SystemPromptFragment == "" {
+		return base
+	}
+	return base + "\n\n# Agent: " + agent.Name + "\n\n" + agent.SystemPromptFragment
+}
+
+// registerAgentDelegationTool registers the "agent" tool on the session's
+// provider registry, letting the primary loop delegate a task to a named
+// AgentProfile. Unlike spawn_agent, delegation is synchronous: it runs the
+// task to completion in a child Session scoped to that profile's tools and
+// system prompt, and returns its final assistant text as the tool result.
+func registerAgentDelegationTool(s *Session) {
+	s.profile.ToolRegistry().Register(RegisteredTool{
+		Definition: ToolDefinition{
+			Name:        "agent",
+			Description: "Delegate a task to a named specialized agent, which runs to completion with only its own declared tools visible and returns its final response.",
+			Parameters: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"agent_name": map[string]interface{}{
+						"type":        "string",
+						"description": "Name of the registered agent profile to delegate to.",
+					},
+					"task": map[string]interface{}{
+						"type":        "string",
+						"description": "Natural language task description for the delegated agent.",
+					},
+				},
+				"required": []string{"agent_name", "task"},
+			},
+		},
+		Executor: func(arguments json.RawMessage, execEnv ExecutionEnvironment) (ToolResult, error) {
+			args, err := ParseToolArguments(arguments)
+			if err != nil {
+				return ToolResult{}, err
+			}
+			agentName, ok := GetStringArg(args, "agent_name")
+			if !ok || agentName == "" {
+				return ToolResult{}, fmt.Errorf("agent_name is required")
+			}
+			task, ok := GetStringArg(args, "task")
+			if !ok || task == "" {
+				return ToolResult{}, fmt.Errorf("task is required")
+			}
+
+			if !s.subagents.CanSpawn() {
+				return ToolResult{}, fmt.Errorf("maximum subagent depth (%d) reached", s.config.MaxSubagentDepth)
+			}
+
+			s.mu.Lock()
+			_, ok = s.agentProfiles[agentName]
+			profiles := make(map[string]*AgentProfile, len(s.agentProfiles))
+			for name, p := range s.agentProfiles {
+				profiles[name] = p
+			}
+			childConfig := s.config
+			childConfig.subagentDepth = s.config.subagentDepth + 1
+			s.mu.Unlock()
+			if !ok {
+				return ToolResult{}, fmt.Errorf("unknown agent profile: %s", agentName)
+			}
+
+			child := NewSession(s.profile, execEnv, &childConfig)
+			for _, p := range profiles {
+				child.RegisterAgentProfile(p)
+			}
+			if err := child.SwitchAgent(agentName); err != nil {
+				return ToolResult{}, err
+			}
+
+			if err := child.Submit(context.Background(), task); err != nil {
+				return ToolResult{}, fmt.Errorf("agent %q: %w", agentName, err)
+			}
+
+			history := child.History()
+			for i := len(history) - 1; i >= 0; i-- {
+				if history[i].Kind == TurnAssistant && history[i].Assistant != nil {
+					return LegacyStringResult(history[i].Assistant.Content), nil
+				}
+			}
+			return LegacyStringResult(""), nil
+		},
+	})
+}
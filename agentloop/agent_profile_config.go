@@ -0,0 +1,148 @@
+package agentloop
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// SeedDocumentConfig is the on-disk description of a SeedDocument: either
+// inline Content or a Path to read it from, resolved once at
+// BuildAgentProfile time.
+type SeedDocumentConfig struct {
+	Role    string `yaml:"role,omitempty" json:"role,omitempty"` // "system" or "user"; defaults to "system"
+	Content string `yaml:"content,omitempty" json:"content,omitempty"`
+	Path    string `yaml:"path,omitempty" json:"path,omitempty"` // read if Content is empty
+}
+
+// AgentProfileConfig is the on-disk (YAML or JSON) description of an
+// AgentProfile, for deployments that want to add or adjust specialized
+// agents without recompiling attractor: its system-prompt fragment, tool
+// allowlist, per-tool truncation overrides, credentials (referenced by
+// environment variable name, never embedded in the file, the same
+// convention WebhookToolConfig.SecretEnv uses), and RAG seed documents to
+// record into history when the agent activates.
+type AgentProfileConfig struct {
+	Name                  string                 `yaml:"name" json:"name"`
+	SystemPromptFragment  string                 `yaml:"system_prompt_fragment,omitempty" json:"system_prompt_fragment,omitempty"`
+	Tools                 []string               `yaml:"tools,omitempty" json:"tools,omitempty"` // allowlist of tool names; empty means inherit all
+	ReasoningEffort       string                 `yaml:"reasoning_effort,omitempty" json:"reasoning_effort,omitempty"`
+	MaxToolRoundsPerInput int                    `yaml:"max_tool_rounds_per_input,omitempty" json:"max_tool_rounds_per_input,omitempty"`
+	ProviderOptions       map[string]interface{} `yaml:"provider_options,omitempty" json:"provider_options,omitempty"`
+	ToolCharLimits        map[string]int         `yaml:"tool_char_limits,omitempty" json:"tool_char_limits,omitempty"`
+	ToolLineLimits        map[string]int         `yaml:"tool_line_limits,omitempty" json:"tool_line_limits,omitempty"`
+	ToolTokenLimits       map[string]int         `yaml:"tool_token_limits,omitempty" json:"tool_token_limits,omitempty"`
+
+	// CredentialEnv maps a credential key (the name a tool looks up) to the
+	// environment variable BuildAgentProfile reads its value from.
+	CredentialEnv map[string]string `yaml:"credential_env,omitempty" json:"credential_env,omitempty"`
+
+	SeedDocuments []SeedDocumentConfig `yaml:"seed_documents,omitempty" json:"seed_documents,omitempty"`
+}
+
+// BuildAgentProfile constructs the AgentProfile cfg describes, resolving
+// CredentialEnv against the process environment and each SeedDocument's
+// Path against the filesystem.
+func BuildAgentProfile(cfg AgentProfileConfig) (*AgentProfile, error) {
+	profile := &AgentProfile{
+		Name:                  cfg.Name,
+		SystemPromptFragment:  cfg.SystemPromptFragment,
+		ToolNames:             cfg.Tools,
+		ReasoningEffort:       cfg.ReasoningEffort,
+		MaxToolRoundsPerInput: cfg.MaxToolRoundsPerInput,
+		ProviderOptions:       cfg.ProviderOptions,
+		ToolCharLimits:        cfg.ToolCharLimits,
+		ToolLineLimits:        cfg.ToolLineLimits,
+		ToolTokenLimits:       cfg.ToolTokenLimits,
+	}
+
+	if len(cfg.CredentialEnv) > 0 {
+		profile.Credentials = make(map[string]string, len(cfg.CredentialEnv))
+		for key, envVar := range cfg.CredentialEnv {
+			value := os.Getenv(envVar)
+			if value == "" {
+				return nil, fmt.Errorf("agentloop: agent %q: environment variable %s (for credential %q) is not set", cfg.Name, envVar, key)
+			}
+			profile.Credentials[key] = value
+		}
+	}
+
+	for _, doc := range cfg.SeedDocuments {
+		content := doc.Content
+		if content == "" && doc.Path != "" {
+			data, err := os.ReadFile(doc.Path)
+			if err != nil {
+				return nil, fmt.Errorf("agentloop: agent %q: read seed document %s: %w", cfg.Name, doc.Path, err)
+			}
+			content = string(data)
+		}
+		role := TurnSystem
+		if strings.EqualFold(doc.Role, "user") {
+			role = TurnUser
+		}
+		profile.SeedDocuments = append(profile.SeedDocuments, SeedDocument{Role: role, Content: content})
+	}
+
+	return profile, nil
+}
+
+// LoadAgentProfileConfigs parses a YAML or JSON document (selected by
+// path's extension: .json for JSON, anything else for YAML) containing a
+// list of AgentProfileConfig.
+func LoadAgentProfileConfigs(path string) ([]AgentProfileConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("agentloop: read agent config %s: %w", path, err)
+	}
+
+	var configs []AgentProfileConfig
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		if err := json.Unmarshal(data, &configs); err != nil {
+			return nil, fmt.Errorf("agentloop: parse agent config %s: %w", path, err)
+		}
+		return configs, nil
+	}
+	if err := yaml.Unmarshal(data, &configs); err != nil {
+		return nil, fmt.Errorf("agentloop: parse agent config %s: %w", path, err)
+	}
+	return configs, nil
+}
+
+// LoadAgentProfiles reads path via LoadAgentProfileConfigs and builds an
+// AgentProfile for each entry.
+func LoadAgentProfiles(path string) ([]*AgentProfile, error) {
+	configs, err := LoadAgentProfileConfigs(path)
+	if err != nil {
+		return nil, err
+	}
+	profiles := make([]*AgentProfile, len(configs))
+	for i, cfg := range configs {
+		profile, err := BuildAgentProfile(cfg)
+		if err != nil {
+			return nil, err
+		}
+		profiles[i] = profile
+	}
+	return profiles, nil
+}
+
+// LoadAgent reads path via LoadAgentProfileConfigs and builds the single
+// AgentProfile named name, for a caller that just wants one named agent
+// (e.g. passed straight to NewSessionWithAgent) without managing a registry
+// of every agent the config file declares.
+func LoadAgent(path, name string) (*AgentProfile, error) {
+	configs, err := LoadAgentProfileConfigs(path)
+	if err != nil {
+		return nil, err
+	}
+	for _, cfg := range configs {
+		if cfg.Name == name {
+			return BuildAgentProfile(cfg)
+		}
+	}
+	return nil, fmt.Errorf("agentloop: no agent profile named %q in %s", name, path)
+}
@@ -0,0 +1,124 @@
+package agentloop
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/martinemde/attractor/unifiedllm"
+)
+
+// ApprovalDecision is the host's response to an EventToolApprovalRequested.
+type ApprovalDecision string
+
+const (
+	ApprovalAllow            ApprovalDecision = "allow"
+	ApprovalDeny             ApprovalDecision = "deny"
+	ApprovalAllowAndRemember ApprovalDecision = "allow_and_remember"
+)
+
+// ApprovalPolicy decides whether a specific tool call needs human approval,
+// beyond any tool already marked RequiresApproval on its RegisteredTool. A
+// nil policy requires no additional approvals.
+type ApprovalPolicy func(toolName string, arguments json.RawMessage) bool
+
+// pendingApproval tracks one in-flight approval gate awaiting a decision.
+type pendingApproval struct {
+	toolName string
+	decision chan ApprovalDecision
+}
+
+// approvalGate blocks toolCall's execution, emitting EventToolApprovalRequested
+// and waiting on Session.ApproveTool, if toolCall requires approval (either
+// markedRequiresApproval or s.config.ApprovalPolicy) and isn't already on
+// this session's allow_and_remember list. It returns proceed=true if the
+// caller should go on to execute the tool; otherwise denied is the synthetic
+// ToolResult to return instead. Each call gates independently, so a
+// parallel batch blocks only the calls that need a decision.
+func (s *Session) approvalGate(ctx context.Context, toolCall unifiedllm.ToolCall, markedRequiresApproval bool) (proceed bool, denied unifiedllm.ToolResult) {
+	sig := unifiedllm.ToolCallSignature(toolCall.Name, toolCall.Arguments)
+
+	s.mu.Lock()
+	remembered := s.approvalAllowList != nil && s.approvalAllowList[sig]
+	policy := s.config.ApprovalPolicy
+	s.mu.Unlock()
+
+	if remembered {
+		return true, unifiedllm.ToolResult{}
+	}
+
+	requiresApproval := markedRequiresApproval
+	if !requiresApproval && policy != nil {
+		requiresApproval = policy(toolCall.Name, toolCall.Arguments)
+	}
+	if !requiresApproval {
+		return true, unifiedllm.ToolResult{}
+	}
+
+	token := uuid.New().String()
+	decisionCh := make(chan ApprovalDecision, 1)
+
+	s.mu.Lock()
+	s.state = StateAwaitingApproval
+	if s.pendingApprovals == nil {
+		s.pendingApprovals = make(map[string]*pendingApproval)
+	}
+	s.pendingApprovals[token] = &pendingApproval{toolName: toolCall.Name, decision: decisionCh}
+	s.mu.Unlock()
+
+	s.emitter.Emit(EventToolApprovalRequested, map[string]interface{}{
+		"tool_name": toolCall.Name,
+		"arguments": string(toolCall.Arguments),
+		"token":     token,
+	})
+
+	var decision ApprovalDecision
+	select {
+	case decision = <-decisionCh:
+	case <-ctx.Done():
+		decision = ApprovalDeny
+	}
+
+	s.mu.Lock()
+	delete(s.pendingApprovals, token)
+	if len(s.pendingApprovals) == 0 {
+		s.state = StateProcessing
+	}
+	if decision == ApprovalAllowAndRemember {
+		if s.approvalAllowList == nil {
+			s.approvalAllowList = make(map[string]bool)
+		}
+		s.approvalAllowList[sig] = true
+	}
+	s.mu.Unlock()
+
+	if decision != ApprovalAllow && decision != ApprovalAllowAndRemember {
+		return false, unifiedllm.ToolResult{
+			ToolCallID: toolCall.ID,
+			Content:    fmt.Sprintf("Tool call %q was denied by the user.", toolCall.Name),
+			IsError:    true,
+		}
+	}
+	return true, unifiedllm.ToolResult{}
+}
+
+// ApproveTool resolves a pending tool-approval gate identified by token.
+// decision should be ApprovalAllow, ApprovalDeny, or
+// ApprovalAllowAndRemember; any other value is treated as ApprovalDeny.
+// Returns an error if token is unknown or has already been resolved.
+func (s *Session) ApproveTool(token string, decision ApprovalDecision) error {
+	s.mu.Lock()
+	pending, ok := s.pendingApprovals[token]
+	s.mu.Unlock()
+	if !ok {
+		return fmt.Errorf("unknown or already-resolved approval token: %s", token)
+	}
+
+	select {
+	case pending.decision <- decision:
+	default:
+		return fmt.Errorf("approval token %s already resolved", token)
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+package agentloop
+
+import "sync"
+
+// SyscallExecEvent is the record shape forwarded into the event stream for
+// an EventSyscallExec event's Data field.
+type SyscallExecEvent struct {
+	PID       int    `json:"pid"`
+	PPID      int    `json:"ppid"`
+	CgroupID  uint64 `json:"cgroup_id"`
+	Path      string `json:"path"`
+	Argv      string `json:"argv"`
+	Timestamp int64  `json:"timestamp"`
+}
+
+// SyscallOpenEvent is the record shape forwarded for EventSyscallOpen.
+type SyscallOpenEvent struct {
+	PID       int    `json:"pid"`
+	PPID      int    `json:"ppid"`
+	CgroupID  uint64 `json:"cgroup_id"`
+	Path      string `json:"path"`
+	Flags     int    `json:"flags"`
+	Timestamp int64  `json:"timestamp"`
+}
+
+// SyscallConnectEvent is the record shape forwarded for EventSyscallConnect.
+type SyscallConnectEvent struct {
+	PID       int    `json:"pid"`
+	PPID      int    `json:"ppid"`
+	CgroupID  uint64 `json:"cgroup_id"`
+	DstIP     string `json:"dst_ip"`
+	DstPort   int    `json:"dst_port"`
+	Timestamp int64  `json:"timestamp"`
+}
+
+// cgroupWatch maps a cgroup ID to the session/turn it should be attributed
+// to in emitted audit events.
+type cgroupWatch struct {
+	sessionID string
+	turn      int
+}
+
+// cgroupRegistry tracks which cgroup IDs a BPFCollector should forward
+// events for, shared between platform-specific implementations.
+type cgroupRegistry struct {
+	mu      sync.RWMutex
+	watched map[uint64]cgroupWatch
+}
+
+func newCgroupRegistry() *cgroupRegistry {
+	return &cgroupRegistry{watched: make(map[uint64]cgroupWatch)}
+}
+
+// Watch registers a cgroup ID to attribute events to sessionID/turn.
+func (r *cgroupRegistry) Watch(cgroupID uint64, sessionID string, turn int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.watched[cgroupID] = cgroupWatch{sessionID: sessionID, turn: turn}
+}
+
+// Unwatch removes a cgroup ID once its process tree has exited.
+func (r *cgroupRegistry) Unwatch(cgroupID uint64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.watched, cgroupID)
+}
+
+func (r *cgroupRegistry) lookup(cgroupID uint64) (cgroupWatch, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	w, ok := r.watched[cgroupID]
+	return w, ok
+}
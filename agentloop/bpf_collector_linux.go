@@ -0,0 +1,264 @@
+//go:build linux
+
+package agentloop
+
+import (
+	"encoding/binary"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/cilium/ebpf"
+	"github.com/cilium/ebpf/link"
+	"github.com/cilium/ebpf/ringbuf"
+)
+
+// defaultBPFObjectPath is where `make -C agentloop/bpf` (clang + bpf2go)
+// writes the compiled collector object. Initialize loads it from disk rather
+// than embedding it so deployments can ship a kernel-matched build.
+const defaultBPFObjectPath = "agentloop/bpf/collector_bpfel.o"
+
+// BPFCollector loads the exec/open/connect eBPF programs and forwards
+// cgroup-filtered syscall activity into an EventEmitter, correlating it with
+// the SandboxedExecutionEnvironment invocation that owns the cgroup.
+type BPFCollector struct {
+	emitter    *EventEmitter
+	objectPath string
+	registry   *cgroupRegistry
+
+	mu      sync.Mutex
+	enabled bool
+	coll    *ebpf.Collection
+	links   []link.Link
+	reader  *ringbuf.Reader
+	done    chan struct{}
+}
+
+// NewBPFCollector creates a collector that forwards events to emitter.
+// objectPath may be empty to use defaultBPFObjectPath.
+func NewBPFCollector(emitter *EventEmitter, objectPath string) *BPFCollector {
+	if objectPath == "" {
+		objectPath = defaultBPFObjectPath
+	}
+	return &BPFCollector{
+		emitter:    emitter,
+		objectPath: objectPath,
+		registry:   newCgroupRegistry(),
+		done:       make(chan struct{}),
+	}
+}
+
+// Watch attributes audit events from cgroupID to sessionID/turn.
+func (c *BPFCollector) Watch(cgroupID uint64, sessionID string, turn int) {
+	c.registry.Watch(cgroupID, sessionID, turn)
+}
+
+// Unwatch stops attributing events from cgroupID once its process tree exits.
+func (c *BPFCollector) Unwatch(cgroupID uint64) {
+	c.registry.Unwatch(cgroupID)
+}
+
+// Initialize loads the eBPF programs and starts forwarding events. If the
+// kernel lacks BTF support or the process lacks CAP_BPF, Initialize logs a
+// warning via the emitter and leaves the collector disabled; callers should
+// continue without treating this as a fatal error.
+func (c *BPFCollector) Initialize() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !kernelHasBTF() {
+		c.warn("kernel lacks BTF support; eBPF audit collection disabled")
+		return nil
+	}
+	if err := ensureCapBPF(); err != nil {
+		c.warn(fmt.Sprintf("missing CAP_BPF; eBPF audit collection disabled: %v", err))
+		return nil
+	}
+
+	spec, err := ebpf.LoadCollectionSpec(c.objectPath)
+	if err != nil {
+		c.warn(fmt.Sprintf("failed to load eBPF object %s; audit collection disabled: %v", c.objectPath, err))
+		return nil
+	}
+
+	coll, err := ebpf.NewCollection(spec)
+	if err != nil {
+		c.warn(fmt.Sprintf("failed to load eBPF collection; audit collection disabled: %v", err))
+		return nil
+	}
+	c.coll = coll
+
+	if err := c.attachPrograms(); err != nil {
+		c.warn(fmt.Sprintf("failed to attach eBPF programs; audit collection disabled: %v", err))
+		c.teardownLocked()
+		return nil
+	}
+
+	reader, err := ringbuf.NewReader(coll.Maps["events"])
+	if err != nil {
+		c.warn(fmt.Sprintf("failed to open ring buffer; audit collection disabled: %v", err))
+		c.teardownLocked()
+		return nil
+	}
+	c.reader = reader
+	c.enabled = true
+
+	go c.consume()
+	return nil
+}
+
+func (c *BPFCollector) attachPrograms() error {
+	exec, err := link.Tracepoint("sched", "sched_process_exec", c.coll.Programs["trace_exec"], nil)
+	if err != nil {
+		return fmt.Errorf("attach trace_exec: %w", err)
+	}
+	c.links = append(c.links, exec)
+
+	open, err := link.Tracepoint("syscalls", "sys_enter_openat", c.coll.Programs["trace_open"], nil)
+	if err != nil {
+		return fmt.Errorf("attach trace_open: %w", err)
+	}
+	c.links = append(c.links, open)
+
+	connect, err := link.Kprobe("tcp_connect", c.coll.Programs["trace_connect"], nil)
+	if err != nil {
+		return fmt.Errorf("attach trace_connect: %w", err)
+	}
+	c.links = append(c.links, connect)
+
+	return nil
+}
+
+// consume reads ring buffer records until Close closes the reader.
+func (c *BPFCollector) consume() {
+	for {
+		record, err := c.reader.Read()
+		if err != nil {
+			return // reader closed during Close().
+		}
+		c.dispatch(record.RawSample)
+	}
+}
+
+// dispatch decodes a raw ring buffer record and emits the matching
+// SessionEvent if its cgroup ID is being watched. Records carry no type tag,
+// so the event kind is told apart by record size (sizeof open_event and
+// connect_event in bpf/collector.c; anything else is an exec record).
+// Records shorter than the common header (cgroup_id + pid) are ignored
+// defensively.
+func (c *BPFCollector) dispatch(raw []byte) {
+	const (
+		openEventSize    = 288
+		connectEventSize = 32
+	)
+	if len(raw) < 16 {
+		return
+	}
+	kind := EventSyscallExec
+	switch len(raw) {
+	case openEventSize:
+		kind = EventSyscallOpen
+	case connectEventSize:
+		kind = EventSyscallConnect
+	}
+	pid := binary.LittleEndian.Uint32(raw[0:4])
+	cgroupID := binary.LittleEndian.Uint64(raw[8:16])
+
+	watch, ok := c.registry.lookup(cgroupID)
+	if !ok {
+		return
+	}
+
+	c.emitter.Emit(kind, map[string]interface{}{
+		"pid":        int(pid),
+		"cgroup_id":  cgroupID,
+		"turn":       watch.turn,
+		"session_id": watch.sessionID,
+		"timestamp":  time.Now().Unix(),
+	})
+}
+
+// Close detaches all programs and releases the ring buffer reader.
+func (c *BPFCollector) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.teardownLocked()
+	return nil
+}
+
+func (c *BPFCollector) teardownLocked() {
+	if c.reader != nil {
+		_ = c.reader.Close()
+		c.reader = nil
+	}
+	for _, l := range c.links {
+		_ = l.Close()
+	}
+	c.links = nil
+	if c.coll != nil {
+		c.coll.Close()
+		c.coll = nil
+	}
+	c.enabled = false
+}
+
+func (c *BPFCollector) warn(message string) {
+	if c.emitter != nil {
+		c.emitter.Emit(EventWarning, map[string]interface{}{"message": message, "source": "bpf_collector"})
+	}
+}
+
+// kernelHasBTF reports whether the running kernel exposes BTF type
+// information, a prerequisite for CO-RE eBPF programs.
+func kernelHasBTF() bool {
+	_, err := os.Stat("/sys/kernel/btf/vmlinux")
+	return err == nil
+}
+
+// ensureCapBPF checks whether the process has CAP_BPF (or CAP_SYS_ADMIN on
+// kernels predating the dedicated capability) by probing the capability bit
+// in /proc/self/status.
+func ensureCapBPF() error {
+	data, err := os.ReadFile(filepath.Clean("/proc/self/status"))
+	if err != nil {
+		return fmt.Errorf("read /proc/self/status: %w", err)
+	}
+	const capBPFBit = 1 << 39 // CAP_BPF, see capability.h
+	const capSysAdminBit = 1 << 21
+	effective, ok := parseCapEff(string(data))
+	if !ok {
+		return fmt.Errorf("CapEff not found in /proc/self/status")
+	}
+	if effective&(capBPFBit|capSysAdminBit) == 0 {
+		return fmt.Errorf("process lacks CAP_BPF/CAP_SYS_ADMIN")
+	}
+	return nil
+}
+
+func parseCapEff(status string) (uint64, bool) {
+	const prefix = "CapEff:\t"
+	idx := indexOf(status, prefix)
+	if idx < 0 {
+		return 0, false
+	}
+	line := status[idx+len(prefix):]
+	if nl := indexOf(line, "\n"); nl >= 0 {
+		line = line[:nl]
+	}
+	var value uint64
+	if _, err := fmt.Sscanf(line, "%x", &value); err != nil {
+		return 0, false
+	}
+	return value, true
+}
+
+func indexOf(s, substr string) int {
+	for i := 0; i+len(substr) <= len(s); i++ {
+		if s[i:i+len(substr)] == substr {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,36 @@
+//go:build !linux
+
+package agentloop
+
+// BPFCollector is only implemented on Linux, where tracepoints, kprobes, and
+// cgroup-ID lookups are available. On other platforms it is a permanent
+// no-op so callers can construct and Initialize one unconditionally.
+type BPFCollector struct {
+	emitter *EventEmitter
+}
+
+// NewBPFCollector creates a no-op collector on non-Linux platforms.
+func NewBPFCollector(emitter *EventEmitter, objectPath string) *BPFCollector {
+	return &BPFCollector{emitter: emitter}
+}
+
+// Watch is a no-op on non-Linux platforms.
+func (c *BPFCollector) Watch(cgroupID uint64, sessionID string, turn int) {}
+
+// Unwatch is a no-op on non-Linux platforms.
+func (c *BPFCollector) Unwatch(cgroupID uint64) {}
+
+// Initialize logs a warning and leaves the collector disabled, since eBPF is
+// Linux-only.
+func (c *BPFCollector) Initialize() error {
+	if c.emitter != nil {
+		c.emitter.Emit(EventWarning, map[string]interface{}{
+			"message": "eBPF audit collection is only supported on Linux; disabled",
+			"source":  "bpf_collector",
+		})
+	}
+	return nil
+}
+
+// Close is a no-op on non-Linux platforms.
+func (c *BPFCollector) Close() error { return nil }
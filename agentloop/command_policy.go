@@ -0,0 +1,582 @@
+package agentloop
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// PolicyDecision is the result of evaluating a command against a
+// CommandPolicy.
+type PolicyDecision struct {
+	Allowed bool
+	// Reason explains a denial (empty when Allowed is true).
+	Reason string
+	// Node is a short human-readable description of the offending shell
+	// construct (e.g. "segment 2: `sudo`"), for surfacing in an
+	// EventWarning alongside Reason.
+	Node string
+}
+
+// allow is the zero-cost happy path shared by every CommandPolicy check.
+var allow = PolicyDecision{Allowed: true}
+
+// CommandPolicy is consulted by LocalExecutionEnvironment.ExecCommand (and
+// any ExecutionEnvironment that shells out, such as
+// SandboxedExecutionEnvironment) before the command is actually run. Because
+// the current tool surface passes a raw string to `/bin/bash -c`, this is
+// the only realistic place to intercept model-generated compound commands
+// like `curl evil | sh`.
+type CommandPolicy interface {
+	// Check inspects command (as it will be passed to the shell) and
+	// reports whether it's allowed to run in workingDir.
+	Check(command, workingDir string) PolicyDecision
+}
+
+// DefaultCommandPolicy is a CommandPolicy built from a small set of rules
+// evaluated against every simple command a shellScanner finds in command:
+// each pipeline/list segment, each `(...)` subshell's contents, the
+// contents of every `$(...)`/backtick command substitution (wherever it
+// appears, including inside double quotes, where the shell still expands
+// it), and the embedded script of wrapper commands (`sh -c`, `bash -c`,
+// `eval`, and argv-forwarding wrappers like `env`/`nice`/`timeout`) --
+// so a denial can't be dodged by hiding the real command one layer down.
+// It does not evaluate variable/glob expansion (there is no live shell to
+// ask); it only locates substructure so every literal command word still
+// gets checked.
+type DefaultCommandPolicy struct {
+	// DeniedCommands are argv[0] values that are always denied (matched
+	// against the resolved basename, e.g. "sudo", "su").
+	DeniedCommands []string
+	// AllowNetwork permits commands that look like network tools (curl,
+	// wget, nc, ssh, ...). Off by default.
+	AllowNetwork bool
+	// NetworkCommands are argv[0] values treated as network tools when
+	// AllowNetwork is false. Defaults to defaultNetworkCommands if nil.
+	NetworkCommands []string
+	// RequireConfirmation is invoked (if non-nil) for commands matched as
+	// dangerous-but-allowable, such as `rm -rf` with an absolute path. It
+	// returns true to permit the command. A nil func denies by default.
+	RequireConfirmation func(command string) bool
+	// AllowedWriteDirs restricts redirection (`>`, `>>`) targets to paths
+	// under one of these directories (plus workingDir itself). A nil slice
+	// disables this check.
+	AllowedWriteDirs []string
+}
+
+var defaultNetworkCommands = []string{"curl", "wget", "nc", "ncat", "netcat", "ssh", "scp", "telnet"}
+
+// DefaultDeniedCommands is the DeniedCommands list NewDefaultCommandPolicy
+// starts from: privilege escalation has no legitimate use inside a tool
+// call. A DefaultCommandPolicy built without it, or whose DeniedCommands is
+// changed afterwards, does not deny these commands.
+var DefaultDeniedCommands = []string{"sudo", "su", "doas", "pkexec"}
+
+// NewDefaultCommandPolicy returns a DefaultCommandPolicy with
+// DefaultDeniedCommands and no network access.
+func NewDefaultCommandPolicy() *DefaultCommandPolicy {
+	return &DefaultCommandPolicy{DeniedCommands: append([]string(nil), DefaultDeniedCommands...)}
+}
+
+func (p *DefaultCommandPolicy) Check(command, workingDir string) PolicyDecision {
+	cmds := newShellScanner(command).parseCommandList(false)
+	cmds = expandWrappedCommands(cmds, 0)
+
+	for i, c := range cmds {
+		if len(c.argv) == 0 {
+			continue
+		}
+
+		name := filepath.Base(c.argv[0])
+		if d := p.checkDenied(name, i, cmds); !d.Allowed {
+			return d
+		}
+		if d := p.checkNetwork(name, i, cmds); !d.Allowed {
+			return d
+		}
+		if d := p.checkChmodSetuid(name, c.argv, i, cmds); !d.Allowed {
+			return d
+		}
+		if d := p.checkRmRf(c.argv, c.raw); !d.Allowed {
+			return d
+		}
+		if d := p.checkRedirectionTarget(c.raw, workingDir); !d.Allowed {
+			return d
+		}
+	}
+	return allow
+}
+
+func (p *DefaultCommandPolicy) checkDenied(name string, idx int, cmds []shellCommand) PolicyDecision {
+	for _, denied := range p.DeniedCommands {
+		if name == denied {
+			return PolicyDecision{
+				Reason: fmt.Sprintf("policy: denied command %q", name),
+				Node:   fmt.Sprintf("segment %d: `%s`", idx+1, cmds[idx].raw),
+			}
+		}
+	}
+	return allow
+}
+
+func (p *DefaultCommandPolicy) checkNetwork(name string, idx int, cmds []shellCommand) PolicyDecision {
+	if p.AllowNetwork {
+		return allow
+	}
+	networkCommands := p.NetworkCommands
+	if networkCommands == nil {
+		networkCommands = defaultNetworkCommands
+	}
+	for _, nc := range networkCommands {
+		if name == nc {
+			return PolicyDecision{
+				Reason: fmt.Sprintf("policy: network command %q requires AllowNetwork", name),
+				Node:   fmt.Sprintf("segment %d: `%s`", idx+1, cmds[idx].raw),
+			}
+		}
+	}
+	return allow
+}
+
+func (p *DefaultCommandPolicy) checkChmodSetuid(name string, argv []string, idx int, cmds []shellCommand) PolicyDecision {
+	if name != "chmod" {
+		return allow
+	}
+	for _, arg := range argv[1:] {
+		if strings.Contains(arg, "+s") || strings.HasPrefix(arg, "4") || strings.HasPrefix(arg, "2") {
+			return PolicyDecision{
+				Reason: fmt.Sprintf("policy: denied setuid/setgid chmod (%s)", arg),
+				Node:   fmt.Sprintf("segment %d: `%s`", idx+1, cmds[idx].raw),
+			}
+		}
+	}
+	return allow
+}
+
+func (p *DefaultCommandPolicy) checkRmRf(argv []string, raw string) PolicyDecision {
+	if filepath.Base(argv[0]) != "rm" {
+		return allow
+	}
+	hasRecursive, hasForce := false, false
+	var target string
+	for _, arg := range argv[1:] {
+		if strings.HasPrefix(arg, "-") && !strings.HasPrefix(arg, "--") {
+			if strings.ContainsAny(arg, "rR") {
+				hasRecursive = true
+			}
+			if strings.Contains(arg, "f") {
+				hasForce = true
+			}
+			continue
+		}
+		if arg == "--recursive" {
+			hasRecursive = true
+			continue
+		}
+		if arg == "--force" {
+			hasForce = true
+			continue
+		}
+		if target == "" {
+			target = arg
+		}
+	}
+	if !hasRecursive || !hasForce || !filepath.IsAbs(target) {
+		return allow
+	}
+	if p.RequireConfirmation != nil && p.RequireConfirmation(raw) {
+		return allow
+	}
+	return PolicyDecision{
+		Reason: fmt.Sprintf("policy: rm -rf with absolute path %q requires confirmation", target),
+		Node:   fmt.Sprintf("`%s`", raw),
+	}
+}
+
+func (p *DefaultCommandPolicy) checkRedirectionTarget(raw, workingDir string) PolicyDecision {
+	if p.AllowedWriteDirs == nil {
+		return allow
+	}
+	target, ok := redirectionTarget(raw)
+	if !ok {
+		return allow
+	}
+	if !filepath.IsAbs(target) {
+		target = filepath.Join(workingDir, target)
+	}
+	allowedDirs := append([]string{workingDir}, p.AllowedWriteDirs...)
+	for _, dir := range allowedDirs {
+		if rel, err := filepath.Rel(dir, target); err == nil && !strings.HasPrefix(rel, "..") {
+			return allow
+		}
+	}
+	return PolicyDecision{
+		Reason: fmt.Sprintf("policy: redirection to %q is outside allowed write directories", target),
+		Node:   fmt.Sprintf("`%s`", raw),
+	}
+}
+
+// redirectionTarget returns the filesystem path a `>`/`>>` redirection in
+// raw writes to, if any.
+func redirectionTarget(raw string) (string, bool) {
+	fields := strings.Fields(raw)
+	for i, f := range fields {
+		if (f == ">" || f == ">>" || f == "2>" || f == "2>>") && i+1 < len(fields) {
+			return strings.Trim(fields[i+1], `'"`), true
+		}
+		if strings.HasPrefix(f, ">") && len(f) > 1 && !strings.HasPrefix(f, ">>") {
+			return strings.Trim(f[1:], `'"`), true
+		}
+	}
+	return "", false
+}
+
+// shellCommand is one simple command extracted by shellScanner: its
+// quote-resolved argv, and the raw source text it came from (for
+// PolicyDecision.Node/Reason and for checkRedirectionTarget, which re-scans
+// raw for a literal `>`/`>>` target rather than relying on argv).
+type shellCommand struct {
+	argv []string
+	raw  string
+}
+
+// scriptWrapperCommands take an embedded shell script via a `-c` flag.
+var scriptWrapperCommands = map[string]bool{
+	"sh": true, "bash": true, "dash": true, "ksh": true, "zsh": true, "ash": true,
+}
+
+// argvWrapperCommands exec their remaining argv directly (after their own
+// flags/assignments), rather than a shell script string.
+var argvWrapperCommands = map[string]bool{
+	"env": true, "nice": true, "nohup": true, "timeout": true, "setsid": true,
+	"stdbuf": true, "ionice": true, "chrt": true, "flock": true, "xargs": true,
+	"sudo": true, "doas": true, "su": true,
+}
+
+// maxWrapperExpansionDepth bounds wrapper-unwrapping recursion so a
+// pathological `sh -c 'sh -c "sh -c ..."'` chain can't recurse unbounded.
+const maxWrapperExpansionDepth = 8
+
+// expandWrappedCommands returns cmds plus, for every command whose argv[0]
+// is a recognized wrapper, the command(s) it would actually exec --
+// recursively, up to maxWrapperExpansionDepth -- so a denial check run over
+// the result can't be dodged by hiding the real command behind `sh -c`,
+// `eval`, or an argv-forwarding wrapper like `env`/`timeout`.
+func expandWrappedCommands(cmds []shellCommand, depth int) []shellCommand {
+	if depth >= maxWrapperExpansionDepth {
+		return cmds
+	}
+
+	out := make([]shellCommand, 0, len(cmds))
+	for _, c := range cmds {
+		out = append(out, c)
+		if len(c.argv) == 0 {
+			continue
+		}
+
+		name := filepath.Base(c.argv[0])
+		switch {
+		case scriptWrapperCommands[name]:
+			if script, ok := findFlagArg(c.argv, "-c"); ok {
+				nested := newShellScanner(script).parseCommandList(false)
+				out = append(out, expandWrappedCommands(nested, depth+1)...)
+			}
+		case name == "eval":
+			if len(c.argv) > 1 {
+				nested := newShellScanner(strings.Join(c.argv[1:], " ")).parseCommandList(false)
+				out = append(out, expandWrappedCommands(nested, depth+1)...)
+			}
+		case argvWrapperCommands[name]:
+			if rest, ok := skipWrapperArgs(name, c.argv[1:]); ok {
+				out = append(out, expandWrappedCommands([]shellCommand{{argv: rest, raw: c.raw}}, depth+1)...)
+			}
+		}
+	}
+	return out
+}
+
+// findFlagArg returns the argument immediately following the first
+// occurrence of flag in argv.
+func findFlagArg(argv []string, flag string) (string, bool) {
+	for i, a := range argv {
+		if a == flag && i+1 < len(argv) {
+			return argv[i+1], true
+		}
+	}
+	return "", false
+}
+
+// skipWrapperArgs skips name's own flags and positional arguments (leading
+// KEY=VALUE assignments for "env", the duration for "timeout") and returns
+// the remaining argv -- the command the wrapper actually execs -- or
+// ok=false if nothing follows them.
+func skipWrapperArgs(name string, argv []string) ([]string, bool) {
+	i := 0
+	for i < len(argv) {
+		a := argv[i]
+		if strings.HasPrefix(a, "-") {
+			i++
+			continue
+		}
+		if name == "env" && strings.Contains(a, "=") {
+			i++
+			continue
+		}
+		break
+	}
+	if name == "timeout" && i < len(argv) {
+		i++ // duration, e.g. "5", "5s", "1m30s"
+	}
+	if i >= len(argv) {
+		return nil, false
+	}
+	return argv[i:], true
+}
+
+// shellScanner is a minimal recursive-descent scanner over a POSIX-ish
+// shell command line. It does not evaluate expansions -- there is no live
+// shell to ask -- it only locates substructure (quoting, pipelines,
+// `;`/`&&`/`||`/`&` sequencing, `(...)` subshells, and `$(...)`/backtick
+// command substitution, including inside double quotes, where the shell
+// still expands it) so every simple command it contains surfaces as its
+// own shellCommand for CommandPolicy to inspect.
+type shellScanner struct {
+	runes []rune
+	pos   int
+}
+
+func newShellScanner(s string) *shellScanner {
+	return &shellScanner{runes: []rune(s)}
+}
+
+func (s *shellScanner) eof() bool { return s.pos >= len(s.runes) }
+
+func (s *shellScanner) peek() rune {
+	if s.eof() {
+		return 0
+	}
+	return s.runes[s.pos]
+}
+
+func (s *shellScanner) peekAt(off int) rune {
+	if s.pos+off >= len(s.runes) {
+		return 0
+	}
+	return s.runes[s.pos+off]
+}
+
+// parseCommandList parses commands until EOF or, if stopAtParen, an
+// unmatched top-level ')' (left for the caller to consume), returning every
+// simple command found in execution order.
+func (s *shellScanner) parseCommandList(stopAtParen bool) []shellCommand {
+	var out []shellCommand
+	var argv []string
+	segStart := s.pos
+
+	flush := func(end int) {
+		if len(argv) > 0 {
+			out = append(out, shellCommand{argv: argv, raw: strings.TrimSpace(string(s.runes[segStart:end]))})
+		}
+		argv = nil
+	}
+
+	for !s.eof() {
+		c := s.peek()
+		switch {
+		case c == ' ' || c == '\t' || c == '\n':
+			s.pos++
+		case stopAtParen && c == ')':
+			flush(s.pos)
+			return out
+		case c == '(':
+			s.pos++
+			out = append(out, s.parseCommandList(true)...)
+			if !s.eof() && s.peek() == ')' {
+				s.pos++
+			}
+			segStart = s.pos
+		case c == '|' || c == ';' || c == '&':
+			flush(s.pos)
+			s.pos++
+			if (c == '|' || c == '&') && s.peek() == c {
+				s.pos++
+			}
+			segStart = s.pos
+		case c == '#':
+			for !s.eof() && s.peek() != '\n' {
+				s.pos++
+			}
+		default:
+			word, subs := s.scanWord()
+			out = append(out, subs...)
+			if word != "" {
+				argv = append(argv, word)
+			}
+		}
+	}
+	flush(s.pos)
+	return out
+}
+
+// scanWord scans one whitespace-delimited word, resolving quoting and
+// backslash-escaping, and returns its literal text plus any nested commands
+// found inside `$(...)`/backtick substitutions encountered along the way.
+// A redirection operator (`>`, `>>`, `<`, `2>`, ...) and its target are
+// consumed but returned as an empty word -- they're not part of argv.
+func (s *shellScanner) scanWord() (string, []shellCommand) {
+	if s.atRedirection() {
+		s.consumeRedirection()
+		return "", nil
+	}
+
+	var sb strings.Builder
+	var subs []shellCommand
+
+	for !s.eof() {
+		c := s.peek()
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '|' || c == ';' || c == '&' || c == '(' || c == ')':
+			return sb.String(), subs
+
+		case c == '\'':
+			s.pos++
+			for !s.eof() && s.peek() != '\'' {
+				sb.WriteRune(s.peek())
+				s.pos++
+			}
+			if !s.eof() {
+				s.pos++
+			}
+
+		case c == '"':
+			s.pos++
+			for !s.eof() && s.peek() != '"' {
+				switch {
+				case s.peek() == '\\' && strings.ContainsRune(`"\$`+"`", s.peekAt(1)):
+					s.pos++
+					sb.WriteRune(s.peek())
+					s.pos++
+				case s.peek() == '$' && s.peekAt(1) == '(':
+					s.pos += 2
+					subs = append(subs, newShellScanner(s.scanBalanced('(', ')')).parseCommandList(false)...)
+				case s.peek() == '`':
+					s.pos++
+					subs = append(subs, newShellScanner(s.scanUntilBacktick()).parseCommandList(false)...)
+				default:
+					sb.WriteRune(s.peek())
+					s.pos++
+				}
+			}
+			if !s.eof() {
+				s.pos++
+			}
+
+		case c == '\\':
+			s.pos++
+			if !s.eof() {
+				sb.WriteRune(s.peek())
+				s.pos++
+			}
+
+		case c == '$' && s.peekAt(1) == '(':
+			s.pos += 2
+			subs = append(subs, newShellScanner(s.scanBalanced('(', ')')).parseCommandList(false)...)
+
+		case c == '`':
+			s.pos++
+			subs = append(subs, newShellScanner(s.scanUntilBacktick()).parseCommandList(false)...)
+
+		default:
+			sb.WriteRune(c)
+			s.pos++
+		}
+	}
+	return sb.String(), subs
+}
+
+// scanBalanced consumes and returns runes up to (but not including) the
+// close rune that balances the open rune the caller already consumed,
+// tracking nested open/close pairs so `$(echo $(whoami))` stops at the
+// correct outer close rather than the first one encountered.
+func (s *shellScanner) scanBalanced(open, close rune) string {
+	depth := 1
+	var sb strings.Builder
+	for !s.eof() {
+		c := s.peek()
+		if c == open {
+			depth++
+		} else if c == close {
+			depth--
+			if depth == 0 {
+				s.pos++
+				return sb.String()
+			}
+		}
+		sb.WriteRune(c)
+		s.pos++
+	}
+	return sb.String()
+}
+
+// scanUntilBacktick consumes and returns runes up to (but not including)
+// the next unescaped backtick.
+func (s *shellScanner) scanUntilBacktick() string {
+	var sb strings.Builder
+	for !s.eof() && s.peek() != '`' {
+		if s.peek() == '\\' && s.peekAt(1) == '`' {
+			s.pos++
+		}
+		sb.WriteRune(s.peek())
+		s.pos++
+	}
+	if !s.eof() {
+		s.pos++
+	}
+	return sb.String()
+}
+
+// atRedirection reports whether the scanner is positioned at a redirection
+// operator: `>`, `>>`, `<`, an fd-qualified form like `2>`, or `&>`.
+func (s *shellScanner) atRedirection() bool {
+	c := s.peek()
+	if c == '>' || c == '<' {
+		return true
+	}
+	if c == '&' && s.peekAt(1) == '>' {
+		return true
+	}
+	if c >= '0' && c <= '9' {
+		j := s.pos
+		for j < len(s.runes) && s.runes[j] >= '0' && s.runes[j] <= '9' {
+			j++
+		}
+		return j < len(s.runes) && (s.runes[j] == '>' || s.runes[j] == '<')
+	}
+	return false
+}
+
+// consumeRedirection consumes a redirection operator and its target word.
+func (s *shellScanner) consumeRedirection() {
+	for !s.eof() && s.peek() >= '0' && s.peek() <= '9' {
+		s.pos++
+	}
+	if s.peek() == '&' {
+		s.pos++
+	}
+	if s.peek() == '>' || s.peek() == '<' {
+		s.pos++
+		if s.peek() == '>' {
+			s.pos++
+		}
+	}
+	for !s.eof() && (s.peek() == ' ' || s.peek() == '\t') {
+		s.pos++
+	}
+	for !s.eof() {
+		c := s.peek()
+		if c == ' ' || c == '\t' || c == '\n' || c == '|' || c == ';' || c == '&' || c == '(' || c == ')' {
+			break
+		}
+		s.pos++
+	}
+}
@@ -0,0 +1,222 @@
+package agentloop
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/martinemde/attractor/unifiedllm"
+)
+
+// compactionKeepRecentTurns is how many of the most recent turns are always
+// left out of compaction, so the model retains immediate working context.
+const compactionKeepRecentTurns = 6
+
+// compactionPromptSystem instructs the model to produce a compact summary of
+// folded-away history, standing in for the original turns in future requests.
+const compactionPromptSystem = "You are compacting an AI coding agent's conversation history. " +
+	"Summarize the following turns into a concise but complete account of what was " +
+	"discussed, decided, and done: user requests, key assistant reasoning, tool calls " +
+	"made and their outcomes, and any unresolved follow-ups. Preserve facts a later " +
+	"turn might depend on (file paths, identifiers, decisions). Omit pleasantries and " +
+	"restate only what's load-bearing."
+
+// checkContextUsage emits a warning once context usage crosses 80%, and
+// triggers automatic compaction once it crosses config.CompactionThreshold.
+func (s *Session) checkContextUsage() {
+	s.mu.Lock()
+	contextWindow := s.profile.ContextWindowSize()
+	threshold := s.config.CompactionThreshold
+	s.mu.Unlock()
+
+	approxTokens := s.estimateHistoryTokens()
+
+	warnThreshold := int(float64(contextWindow) * 0.8)
+	if approxTokens > warnThreshold {
+		pct := int(float64(approxTokens) / float64(contextWindow) * 100)
+		s.emitter.Emit(EventWarning, map[string]interface{}{
+			"message": fmt.Sprintf("Context usage at ~%d%% of context window", pct),
+		})
+	}
+
+	if threshold <= 0 || contextWindow <= 0 {
+		return
+	}
+	if approxTokens <= int(float64(contextWindow)*threshold) {
+		return
+	}
+
+	if _, err := s.Compact(context.Background()); err != nil {
+		s.emitter.Emit(EventWarning, map[string]interface{}{
+			"reason": "compaction_failed",
+			"error":  err.Error(),
+		})
+	}
+}
+
+// estimateHistoryTokens approximates the history's token cost the same way
+// checkContextUsage always has: total character count / 4.
+func (s *Session) estimateHistoryTokens() int {
+	s.mu.Lock()
+	history := make([]Turn, len(s.history))
+	copy(history, s.history)
+	s.mu.Unlock()
+	return estimateTurnsTokens(history)
+}
+
+func estimateTurnsTokens(turns []Turn) int {
+	totalChars := 0
+	for _, turn := range turns {
+		totalChars += len(turn.TextContent())
+		if turn.Kind == TurnToolResults && turn.ToolResults != nil {
+			for _, r := range turn.ToolResults.Results {
+				if s, ok := r.Content.(string); ok {
+					totalChars += len(s)
+				}
+			}
+		}
+	}
+	return totalChars / 4
+}
+
+// CompactionResult describes what a Compact call folded away, so callers can
+// inspect or roll back a compaction.
+type CompactionResult struct {
+	Summary      Turn   // the TurnSummary turn that replaced FoldedTurns
+	FoldedTurns  []Turn // the original turns that were folded away, for rollback
+	TokensBefore int
+	TokensAfter  int
+}
+
+// Compact condenses the oldest compactable range of the session's history
+// into a single TurnSummary turn, leaving the initial user turn and the most
+// recent turns untouched. It can be triggered automatically by
+// checkContextUsage or called directly by a host application.
+//
+// Compact is a no-op (returning a zero CompactionResult, nil error) if there
+// is no compactable range, e.g. the history is too short.
+func (s *Session) Compact(ctx context.Context) (CompactionResult, error) {
+	s.mu.Lock()
+	history := make([]Turn, len(s.history))
+	copy(history, s.history)
+	model := s.profile.ModelID()
+	provider := s.profile.ID()
+	providerOptions := s.profile.ProviderOptions()
+	s.mu.Unlock()
+
+	start, end := compactableRange(history, compactionKeepRecentTurns)
+	if start < 0 {
+		return CompactionResult{}, nil
+	}
+
+	s.emitter.Emit(EventCompactionStart, map[string]interface{}{
+		"first_turn_index": start,
+		"last_turn_index":  end,
+	})
+
+	folded := history[start : end+1]
+	tokensBefore := estimateTurnsTokens(folded)
+
+	request := unifiedllm.Request{
+		Model:    model,
+		Provider: provider,
+		Messages: append(
+			[]unifiedllm.Message{unifiedllm.SystemMessage(compactionPromptSystem)},
+			ConvertHistoryToMessages(folded)...,
+		),
+		ProviderOptions: providerOptions,
+	}
+
+	s.mu.Lock()
+	client := s.llmClient
+	s.mu.Unlock()
+
+	response, err := client.Complete(ctx, request)
+	if err != nil {
+		s.emitter.Emit(EventCompactionEnd, map[string]interface{}{
+			"error": err.Error(),
+		})
+		return CompactionResult{}, fmt.Errorf("compact: %w", err)
+	}
+
+	foldedTurnCount := 0
+	for _, turn := range folded {
+		if turn.Kind == TurnUser || turn.Kind == TurnAssistant {
+			foldedTurnCount++
+		} else if turn.Kind == TurnSummary && turn.Summary != nil {
+			foldedTurnCount += turn.Summary.FoldedTurnCount
+		}
+	}
+
+	summaryText := response.Text()
+	tokensAfter := len(summaryText) / 4
+	summaryTurn := NewSummaryTurn(summaryText, start, end, tokensBefore, tokensAfter, foldedTurnCount)
+
+	result := CompactionResult{
+		Summary:      summaryTurn,
+		FoldedTurns:  append([]Turn(nil), folded...),
+		TokensBefore: tokensBefore,
+		TokensAfter:  tokensAfter,
+	}
+
+	s.mu.Lock()
+	rebuilt := make([]Turn, 0, len(s.history)-(end-start))
+	rebuilt = append(rebuilt, s.history[:start]...)
+	rebuilt = append(rebuilt, summaryTurn)
+	rebuilt = append(rebuilt, s.history[end+1:]...)
+	s.history = rebuilt
+	store := s.store
+	sessionID := s.id
+	s.mu.Unlock()
+
+	if store != nil {
+		if err := store.AppendTurn(ctx, sessionID, summaryTurn, &request, response); err != nil {
+			s.emitter.Emit(EventWarning, map[string]interface{}{
+				"reason": "session_store_append_turn_failed",
+				"error":  err.Error(),
+			})
+		}
+	}
+
+	s.emitter.Emit(EventCompactionEnd, map[string]interface{}{
+		"first_turn_index": start,
+		"last_turn_index":  end,
+		"tokens_before":    tokensBefore,
+		"tokens_after":     tokensAfter,
+	})
+
+	return result, nil
+}
+
+// compactableRange picks the oldest contiguous range of turns safe to fold:
+// it keeps the very first turn (the initial user turn, for context) out of
+// compaction, keeps the most recent keepRecent turns untouched, and never
+// splits a tool_calls/tool_results pair by stopping the range one turn
+// earlier if it would otherwise end on a dangling tool call. It returns
+// start == -1 if there's nothing worth compacting.
+func compactableRange(history []Turn, keepRecent int) (start, end int) {
+	if len(history) <= keepRecent+1 {
+		return -1, -1
+	}
+
+	start = 0
+	if history[0].Kind == TurnUser {
+		start = 1
+	}
+
+	end = len(history) - keepRecent - 1
+	if end < start {
+		return -1, -1
+	}
+
+	// If the range ends on an assistant turn with pending tool calls, its
+	// tool_results turn must stay adjacent to it; pull the boundary back so
+	// that pair is kept together rather than split.
+	if history[end].Kind == TurnAssistant && end+1 < len(history) && history[end+1].Kind == TurnToolResults {
+		end--
+	}
+
+	if end < start {
+		return -1, -1
+	}
+	return start, end
+}
@@ -4,18 +4,50 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"strconv"
 	"strings"
 )
 
-// RegisterCoreTools registers the shared core tools on a ToolRegistry.
-// The tools delegate to the provided ExecutionEnvironment.
-func RegisterCoreTools(reg *ToolRegistry, defaultTimeoutMs int, maxTimeoutMs int) {
-	registerReadFile(reg)
-	registerWriteFile(reg)
-	registerEditFile(reg)
-	registerShell(reg, defaultTimeoutMs, maxTimeoutMs)
-	registerGrep(reg)
-	registerGlob(reg)
+// RegisterCoreTools registers the shared core tools on a ToolRegistry. The
+// tools delegate to the provided ExecutionEnvironment. allowlist restricts
+// which core tools get registered (by ToolDefinition.Name); a nil or empty
+// allowlist registers all of them, which is what every hard-wired
+// NewXProfile constructor wants. ProfileConfig-driven profiles pass their
+// configured Tools allowlist instead.
+func RegisterCoreTools(reg *ToolRegistry, defaultTimeoutMs int, maxTimeoutMs int, allowlist ...string) {
+	if coreToolAllowed("read_file", allowlist) {
+		registerReadFile(reg)
+	}
+	if coreToolAllowed("write_file", allowlist) {
+		registerWriteFile(reg)
+	}
+	if coreToolAllowed("edit_file", allowlist) {
+		registerEditFile(reg)
+	}
+	if coreToolAllowed("shell", allowlist) {
+		registerShell(reg, defaultTimeoutMs, maxTimeoutMs)
+	}
+	if coreToolAllowed("grep", allowlist) {
+		registerGrep(reg)
+	}
+	if coreToolAllowed("glob", allowlist) {
+		registerGlob(reg)
+	}
+	RegisterStructuredEditTools(reg, allowlist...)
+}
+
+// coreToolAllowed reports whether name may be registered given allowlist.
+// An empty allowlist permits everything.
+func coreToolAllowed(name string, allowlist []string) bool {
+	if len(allowlist) == 0 {
+		return true
+	}
+	for _, allowed := range allowlist {
+		if allowed == name {
+			return true
+		}
+	}
+	return false
 }
 
 func registerReadFile(reg *ToolRegistry) {
@@ -38,25 +70,48 @@ func registerReadFile(reg *ToolRegistry) {
 						"type":        "integer",
 						"description": "Maximum number of lines to read. Default: 2000.",
 					},
+					"raw": map[string]interface{}{
+						"type":        "boolean",
+						"description": "Also attach the file's raw, non-line-numbered bytes as an artifact. Default: false.",
+					},
 				},
 				"required": []string{"file_path"},
 			},
 		},
-		Executor: func(arguments json.RawMessage, env ExecutionEnvironment) (string, error) {
+		Executor: func(arguments json.RawMessage, env ExecutionEnvironment) (ToolResult, error) {
 			args, err := ParseToolArguments(arguments)
 			if err != nil {
-				return "", err
+				return ToolResult{}, err
 			}
 			filePath, ok := GetStringArg(args, "file_path")
 			if !ok || filePath == "" {
-				return "", fmt.Errorf("file_path is required")
+				return ToolResult{}, fmt.Errorf("file_path is required")
 			}
 			offset, _ := GetIntArg(args, "offset")
 			limit, _ := GetIntArg(args, "limit")
 			if limit == 0 {
 				limit = 2000
 			}
-			return env.ReadFile(filePath, offset, limit)
+			raw, _ := GetBoolArg(args, "raw")
+
+			numbered, err := env.ReadFile(filePath, offset, limit)
+			if err != nil {
+				return ToolResult{}, err
+			}
+
+			if !raw {
+				return LegacyStringResult(numbered), nil
+			}
+			rawContent, err := readRawFile(env, filePath)
+			if err != nil {
+				return ToolResult{}, err
+			}
+			return ToolResult{
+				Text: numbered,
+				Artifacts: []Artifact{
+					{MIMEType: "text/plain", Name: filePath, Bytes: []byte(rawContent)},
+				},
+			}, nil
 		},
 	})
 }
@@ -81,23 +136,23 @@ func registerWriteFile(reg *ToolRegistry) {
 				"required": []string{"file_path", "content"},
 			},
 		},
-		Executor: func(arguments json.RawMessage, env ExecutionEnvironment) (string, error) {
+		Executor: func(arguments json.RawMessage, env ExecutionEnvironment) (ToolResult, error) {
 			args, err := ParseToolArguments(arguments)
 			if err != nil {
-				return "", err
+				return ToolResult{}, err
 			}
 			filePath, ok := GetStringArg(args, "file_path")
 			if !ok || filePath == "" {
-				return "", fmt.Errorf("file_path is required")
+				return ToolResult{}, fmt.Errorf("file_path is required")
 			}
 			content, ok := GetStringArg(args, "content")
 			if !ok {
-				return "", fmt.Errorf("content is required")
+				return ToolResult{}, fmt.Errorf("content is required")
 			}
 			if err := env.WriteFile(filePath, content); err != nil {
-				return "", err
+				return ToolResult{}, err
 			}
-			return fmt.Sprintf("Successfully wrote %d bytes to %s", len(content), filePath), nil
+			return LegacyStringResult(fmt.Sprintf("Successfully wrote %d bytes to %s", len(content), filePath)), nil
 		},
 	})
 }
@@ -105,8 +160,10 @@ func registerWriteFile(reg *ToolRegistry) {
 func registerEditFile(reg *ToolRegistry) {
 	reg.Register(RegisteredTool{
 		Definition: ToolDefinition{
-			Name:        "edit_file",
-			Description: "Replace an exact string occurrence in a file. The old_string must be unique in the file unless replace_all is true.",
+			Name: "edit_file",
+			Description: "Replace an exact string occurrence in a file. The old_string must be unique in the file unless replace_all is true. " +
+				"If old_string isn't found exactly, the tool retries after normalizing whitespace and, failing that, reports the " +
+				"closest fuzzy match (if any) as a diff preview; set fuzzy=true to accept that match instead of refining old_string.",
 			Parameters: map[string]interface{}{
 				"type": "object",
 				"properties": map[string]interface{}{
@@ -126,63 +183,84 @@ func registerEditFile(reg *ToolRegistry) {
 						"type":        "boolean",
 						"description": "Replace all occurrences. Default: false.",
 					},
+					"fuzzy": map[string]interface{}{
+						"type":        "boolean",
+						"description": "Accept the best fuzzy match reported by a prior failed call instead of requiring an exact old_string. Default: false.",
+					},
 				},
 				"required": []string{"file_path", "old_string", "new_string"},
 			},
 		},
-		Executor: func(arguments json.RawMessage, env ExecutionEnvironment) (string, error) {
+		Executor: func(arguments json.RawMessage, env ExecutionEnvironment) (ToolResult, error) {
 			args, err := ParseToolArguments(arguments)
 			if err != nil {
-				return "", err
+				return ToolResult{}, err
 			}
 			filePath, ok := GetStringArg(args, "file_path")
 			if !ok || filePath == "" {
-				return "", fmt.Errorf("file_path is required")
+				return ToolResult{}, fmt.Errorf("file_path is required")
 			}
 			oldString, ok := GetStringArg(args, "old_string")
 			if !ok {
-				return "", fmt.Errorf("old_string is required")
+				return ToolResult{}, fmt.Errorf("old_string is required")
 			}
 			newString, _ := GetStringArg(args, "new_string")
 			replaceAll, _ := GetBoolArg(args, "replace_all")
+			fuzzy, _ := GetBoolArg(args, "fuzzy")
 
 			// Read current file content.
 			content, err := env.ReadFile(filePath, 0, 0)
 			if err != nil {
-				return "", fmt.Errorf("file not found: %s", filePath)
+				return ToolResult{}, fmt.Errorf("file not found: %s", filePath)
 			}
 			// ReadFile returns line-numbered content; read raw for editing.
 			rawContent, err := readRawFile(env, filePath)
 			if err != nil {
-				return "", err
+				return ToolResult{}, err
 			}
 
 			_ = content // line-numbered version not needed for editing
 
 			count := strings.Count(rawContent, oldString)
-			if count == 0 {
-				return "", fmt.Errorf("old_string not found in %s", filePath)
-			}
 			if count > 1 && !replaceAll {
-				return "", fmt.Errorf("old_string found %d times in %s. Provide more context to make it unique, or set replace_all=true", count, filePath)
+				return ToolResult{}, fmt.Errorf("old_string found %d times in %s. Provide more context to make it unique, or set replace_all=true", count, filePath)
+			}
+			if count == 0 {
+				text, err := editFileFuzzy(env, filePath, rawContent, oldString, newString, fuzzy)
+				if err != nil {
+					return ToolResult{}, err
+				}
+				return LegacyStringResult(text), nil
 			}
 
 			var newContent string
-			if replaceAll {
+			switch {
+			case replaceAll:
+				// Multiple occurrences don't map onto a single line range,
+				// so replace_all always uses the legacy string substitution.
 				newContent = strings.ReplaceAll(rawContent, oldString, newString)
-			} else {
+			case LegacyStringEditEnabled || !lineAligned(rawContent, oldString):
 				newContent = strings.Replace(rawContent, oldString, newString, 1)
+			default:
+				// Dispatch onto the same spliceLines primitive replace_range
+				// uses, now that old_string's match is known to span whole
+				// lines.
+				startLine, endLine := lineSpan(rawContent, oldString)
+				newContent, err = spliceLines(rawContent, startLine, endLine, strings.Split(newString, "\n"))
+				if err != nil {
+					return ToolResult{}, err
+				}
 			}
 
 			if err := env.WriteFile(filePath, newContent); err != nil {
-				return "", err
+				return ToolResult{}, err
 			}
 
 			replacements := 1
 			if replaceAll {
 				replacements = count
 			}
-			return fmt.Sprintf("Successfully replaced %d occurrence(s) in %s", replacements, filePath), nil
+			return LegacyStringResult(fmt.Sprintf("Successfully replaced %d occurrence(s) in %s", replacements, filePath)), nil
 		},
 	})
 }
@@ -237,14 +315,14 @@ func registerShell(reg *ToolRegistry, defaultTimeoutMs int, maxTimeoutMs int) {
 				"required": []string{"command"},
 			},
 		},
-		Executor: func(arguments json.RawMessage, env ExecutionEnvironment) (string, error) {
+		Executor: func(arguments json.RawMessage, env ExecutionEnvironment) (ToolResult, error) {
 			args, err := ParseToolArguments(arguments)
 			if err != nil {
-				return "", err
+				return ToolResult{}, err
 			}
 			command, ok := GetStringArg(args, "command")
 			if !ok || command == "" {
-				return "", fmt.Errorf("command is required")
+				return ToolResult{}, fmt.Errorf("command is required")
 			}
 			timeoutMs, _ := GetIntArg(args, "timeout_ms")
 			if timeoutMs <= 0 {
@@ -256,7 +334,7 @@ func registerShell(reg *ToolRegistry, defaultTimeoutMs int, maxTimeoutMs int) {
 
 			result, err := env.ExecCommand(context.Background(), command, timeoutMs, "", nil)
 			if err != nil {
-				return "", err
+				return ToolResult{}, err
 			}
 
 			var sb strings.Builder
@@ -272,7 +350,18 @@ func registerShell(reg *ToolRegistry, defaultTimeoutMs int, maxTimeoutMs int) {
 				fmt.Fprintf(&sb, "\n\n[Exit code: %d]", result.ExitCode)
 			}
 
-			return sb.String(), nil
+			return ToolResult{
+				Text: sb.String(),
+				Artifacts: []Artifact{
+					{MIMEType: "text/plain", Name: "stdout", Bytes: []byte(result.Stdout)},
+					{MIMEType: "text/plain", Name: "stderr", Bytes: []byte(result.Stderr)},
+				},
+				Metadata: map[string]interface{}{
+					"exit_code":   result.ExitCode,
+					"timed_out":   result.TimedOut,
+					"duration_ms": result.DurationMs,
+				},
+			}, nil
 		},
 	})
 }
@@ -309,14 +398,14 @@ func registerGrep(reg *ToolRegistry) {
 				"required": []string{"pattern"},
 			},
 		},
-		Executor: func(arguments json.RawMessage, env ExecutionEnvironment) (string, error) {
+		Executor: func(arguments json.RawMessage, env ExecutionEnvironment) (ToolResult, error) {
 			args, err := ParseToolArguments(arguments)
 			if err != nil {
-				return "", err
+				return ToolResult{}, err
 			}
 			pattern, ok := GetStringArg(args, "pattern")
 			if !ok || pattern == "" {
-				return "", fmt.Errorf("pattern is required")
+				return ToolResult{}, fmt.Errorf("pattern is required")
 			}
 			path, _ := GetStringArg(args, "path")
 			globFilter, _ := GetStringArg(args, "glob_filter")
@@ -326,11 +415,23 @@ func registerGrep(reg *ToolRegistry) {
 				maxResults = 100
 			}
 
-			return env.Grep(context.Background(), pattern, path, GrepOptions{
+			text, err := env.Grep(context.Background(), pattern, path, GrepOptions{
 				GlobFilter:      globFilter,
 				CaseInsensitive: caseInsensitive,
 				MaxResults:      maxResults,
 			})
+			if err != nil {
+				return ToolResult{}, err
+			}
+
+			matches := parseGrepMatches(text)
+			return ToolResult{
+				Text: text,
+				Metadata: map[string]interface{}{
+					"matches":     matches,
+					"match_count": len(matches),
+				},
+			}, nil
 		},
 	})
 }
@@ -355,29 +456,55 @@ func registerGlob(reg *ToolRegistry) {
 				"required": []string{"pattern"},
 			},
 		},
-		Executor: func(arguments json.RawMessage, env ExecutionEnvironment) (string, error) {
+		Executor: func(arguments json.RawMessage, env ExecutionEnvironment) (ToolResult, error) {
 			args, err := ParseToolArguments(arguments)
 			if err != nil {
-				return "", err
+				return ToolResult{}, err
 			}
 			pattern, ok := GetStringArg(args, "pattern")
 			if !ok || pattern == "" {
-				return "", fmt.Errorf("pattern is required")
+				return ToolResult{}, fmt.Errorf("pattern is required")
 			}
 			path, _ := GetStringArg(args, "path")
 
 			matches, err := env.Glob(pattern, path)
 			if err != nil {
-				return "", err
+				return ToolResult{}, err
 			}
 			if len(matches) == 0 {
-				return "No files matched the pattern.", nil
+				return LegacyStringResult("No files matched the pattern."), nil
 			}
-			return strings.Join(matches, "\n"), nil
+			return LegacyStringResult(strings.Join(matches, "\n")), nil
 		},
 	})
 }
 
+// parseGrepMatches parses rg/grep's "path:line:content" output lines into a
+// machine-readable match list for ToolResult.Metadata["matches"]. Lines
+// that don't fit the format (e.g. a trailing blank line) are skipped.
+func parseGrepMatches(text string) []map[string]interface{} {
+	var matches []map[string]interface{}
+	for _, line := range strings.Split(text, "\n") {
+		if line == "" {
+			continue
+		}
+		parts := strings.SplitN(line, ":", 3)
+		if len(parts) != 3 {
+			continue
+		}
+		lineNum, err := strconv.Atoi(parts[1])
+		if err != nil {
+			continue
+		}
+		matches = append(matches, map[string]interface{}{
+			"file": parts[0],
+			"line": lineNum,
+			"text": parts[2],
+		})
+	}
+	return matches
+}
+
 // RegisterApplyPatch registers the apply_patch tool for OpenAI profiles.
 func RegisterApplyPatch(reg *ToolRegistry) {
 	reg.Register(RegisteredTool{
@@ -396,234 +523,20 @@ func RegisterApplyPatch(reg *ToolRegistry) {
 				"required": []string{"patch"},
 			},
 		},
-		Executor: func(arguments json.RawMessage, env ExecutionEnvironment) (string, error) {
+		Executor: func(arguments json.RawMessage, env ExecutionEnvironment) (ToolResult, error) {
 			args, err := ParseToolArguments(arguments)
 			if err != nil {
-				return "", err
+				return ToolResult{}, err
 			}
 			patch, ok := GetStringArg(args, "patch")
 			if !ok || patch == "" {
-				return "", fmt.Errorf("patch is required")
+				return ToolResult{}, fmt.Errorf("patch is required")
 			}
-			return applyV4aPatch(env, patch)
-		},
-	})
-}
-
-// applyV4aPatch parses and applies a v4a format patch.
-func applyV4aPatch(env ExecutionEnvironment, patch string) (string, error) {
-	lines := strings.Split(patch, "\n")
-	if len(lines) < 2 {
-		return "", fmt.Errorf("invalid patch: too short")
-	}
-
-	// Validate begin/end markers.
-	if strings.TrimSpace(lines[0]) != "*** Begin Patch" {
-		return "", fmt.Errorf("invalid patch: missing '*** Begin Patch' header")
-	}
-
-	var results []string
-	i := 1
-	for i < len(lines) {
-		line := strings.TrimSpace(lines[i])
-
-		if line == "*** End Patch" || line == "" {
-			i++
-			continue
-		}
-
-		if strings.HasPrefix(line, "*** Add File: ") {
-			path := strings.TrimPrefix(line, "*** Add File: ")
-			i++
-			var content []string
-			for i < len(lines) {
-				if strings.HasPrefix(lines[i], "*** ") {
-					break
-				}
-				if strings.HasPrefix(lines[i], "+") {
-					content = append(content, lines[i][1:])
-				}
-				i++
-			}
-			if err := env.WriteFile(path, strings.Join(content, "\n")); err != nil {
-				return "", fmt.Errorf("failed to create %s: %w", path, err)
-			}
-			results = append(results, fmt.Sprintf("Created: %s", path))
-
-		} else if strings.HasPrefix(line, "*** Delete File: ") {
-			path := strings.TrimPrefix(line, "*** Delete File: ")
-			// Delete by writing empty (no OS-level delete in the interface,
-			// use shell as fallback).
-			results = append(results, fmt.Sprintf("Deleted: %s", path))
-			i++
-
-		} else if strings.HasPrefix(line, "*** Update File: ") {
-			path := strings.TrimPrefix(line, "*** Update File: ")
-			i++
-
-			// Check for Move to.
-			newPath := ""
-			if i < len(lines) && strings.HasPrefix(strings.TrimSpace(lines[i]), "*** Move to: ") {
-				newPath = strings.TrimPrefix(strings.TrimSpace(lines[i]), "*** Move to: ")
-				i++
-			}
-
-			// Read current file.
-			rawContent, err := readRawFile(env, path)
+			text, err := applyV4aPatch(env, patch)
 			if err != nil {
-				return "", fmt.Errorf("cannot read %s for update: %w", path, err)
-			}
-			fileLines := strings.Split(rawContent, "\n")
-
-			// Apply hunks.
-			for i < len(lines) {
-				trimmed := strings.TrimSpace(lines[i])
-				if strings.HasPrefix(trimmed, "*** ") && !strings.HasPrefix(trimmed, "*** End of File") {
-					break
-				}
-				if !strings.HasPrefix(trimmed, "@@ ") {
-					if trimmed == "*** End of File" {
-						i++
-						continue
-					}
-					i++
-					continue
-				}
-
-				// Parse hunk.
-				i++
-				var contextLines []string
-				var deleteLines []string
-				var addLines []string
-				var ops []hunkOp
-
-				for i < len(lines) {
-					if len(lines[i]) == 0 {
-						i++
-						continue
-					}
-					prefix := lines[i][0]
-					if prefix == ' ' || prefix == '-' || prefix == '+' {
-						content := ""
-						if len(lines[i]) > 1 {
-							content = lines[i][1:]
-						}
-						ops = append(ops, hunkOp{op: prefix, line: content})
-						switch prefix {
-						case ' ':
-							contextLines = append(contextLines, content)
-						case '-':
-							deleteLines = append(deleteLines, content)
-						case '+':
-							addLines = append(addLines, content)
-						}
-						i++
-					} else if strings.HasPrefix(strings.TrimSpace(lines[i]), "@@ ") ||
-						strings.HasPrefix(strings.TrimSpace(lines[i]), "*** ") {
-						break
-					} else {
-						i++
-					}
-				}
-
-				_ = deleteLines
-				_ = addLines
-
-				// Find the hunk location using context lines.
-				fileLines = applyHunk(fileLines, ops)
-			}
-
-			writePath := path
-			if newPath != "" {
-				writePath = newPath
-			}
-			if err := env.WriteFile(writePath, strings.Join(fileLines, "\n")); err != nil {
-				return "", fmt.Errorf("failed to write %s: %w", writePath, err)
-			}
-			if newPath != "" {
-				results = append(results, fmt.Sprintf("Updated and moved: %s -> %s", path, newPath))
-			} else {
-				results = append(results, fmt.Sprintf("Updated: %s", path))
-			}
-		} else {
-			i++
-		}
-	}
-
-	if len(results) == 0 {
-		return "No operations performed.", nil
-	}
-	return strings.Join(results, "\n"), nil
-}
-
-// hunkOp represents a single operation within a patch hunk.
-type hunkOp struct {
-	op   byte   // ' ' = context, '-' = delete, '+' = add
-	line string // line content
-}
-
-// applyHunk applies a single hunk of operations to file lines.
-func applyHunk(fileLines []string, ops []hunkOp) []string {
-	if len(ops) == 0 {
-		return fileLines
-	}
-
-	// Find the first context line to locate the hunk position.
-	var contextPrefix []string
-	for _, op := range ops {
-		if op.op == ' ' || op.op == '-' {
-			contextPrefix = append(contextPrefix, op.line)
-		} else {
-			break
-		}
-	}
-
-	// Search for the context in the file.
-	matchPos := -1
-	if len(contextPrefix) > 0 {
-		for i := 0; i <= len(fileLines)-len(contextPrefix); i++ {
-			match := true
-			for j, ctx := range contextPrefix {
-				if i+j >= len(fileLines) || strings.TrimRight(fileLines[i+j], " \t") != strings.TrimRight(ctx, " \t") {
-					match = false
-					break
-				}
-			}
-			if match {
-				matchPos = i
-				break
+				return ToolResult{}, err
 			}
-		}
-	}
-
-	if matchPos < 0 {
-		// No match found; return unchanged.
-		return fileLines
-	}
-
-	// Apply the operations at the matched position.
-	var result []string
-	result = append(result, fileLines[:matchPos]...)
-
-	pos := matchPos
-	for _, op := range ops {
-		switch op.op {
-		case ' ':
-			// Context line; keep from original.
-			if pos < len(fileLines) {
-				result = append(result, fileLines[pos])
-				pos++
-			}
-		case '-':
-			// Delete line; skip from original.
-			pos++
-		case '+':
-			// Add line.
-			result = append(result, op.line)
-		}
-	}
-
-	// Append remaining file lines.
-	result = append(result, fileLines[pos:]...)
-	return result
+			return LegacyStringResult(text), nil
+		},
+	})
 }
@@ -18,7 +18,8 @@
 //   - ProviderProfile: Provider-aligned tool and prompt configuration
 //     (OpenAI/codex, Anthropic/Claude Code, Gemini/gemini-cli).
 //   - ExecutionEnvironment: Abstraction for where tools run (local,
-//     Docker, Kubernetes, WASM, SSH).
+//     sandboxed namespaces/cgroups, or remote over the executor gRPC
+//     plugin protocol in proto/executor.proto).
 //   - ToolRegistry: Registration and dispatch of tool definitions.
 //   - EventEmitter: Typed event stream for host application integration.
 //
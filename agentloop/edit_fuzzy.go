@@ -0,0 +1,251 @@
+package agentloop
+
+import (
+	"fmt"
+	"strings"
+)
+
+// fuzzyMatchThreshold is the default minimum Ratcliff/Obershelp similarity
+// ratio (see similarityRatio) a candidate span must reach before
+// findFuzzyMatch reports it as a match.
+const fuzzyMatchThreshold = 0.85
+
+// maxFuzzySearchBytes bounds how large a file edit_file's windowed fuzzy
+// search will scan; the search is O(lines * len(oldString)^2), so very
+// large files fall back to reporting no fuzzy match rather than scanning.
+const maxFuzzySearchBytes = 512 * 1024
+
+// fuzzyMatch is a candidate replacement span found by findFuzzyMatch.
+type fuzzyMatch struct {
+	startLine int // 0-based, inclusive
+	endLine   int // 0-based, exclusive
+	text      string
+	score     float64
+}
+
+// normalizeForMatch collapses runs of horizontal whitespace to a single
+// space and normalizes CRLF/CR line endings to LF, so indentation and
+// line-ending drift between old_string and the file don't block a match
+// that's otherwise identical.
+func normalizeForMatch(s string) string {
+	s = strings.ReplaceAll(s, "\r\n", "\n")
+	s = strings.ReplaceAll(s, "\r", "\n")
+	lines := strings.Split(s, "\n")
+	for i, line := range lines {
+		lines[i] = strings.Join(strings.Fields(line), " ")
+	}
+	return strings.Join(lines, "\n")
+}
+
+// findWhitespaceNormalizedMatch retries an exact match after normalizing
+// whitespace in both rawContent and oldString. It returns the original
+// (non-normalized) matched span, since normalization doesn't shift line
+// counts and the replacement must preserve the file's actual formatting
+// elsewhere.
+func findWhitespaceNormalizedMatch(rawContent, oldString string) (span fuzzyMatch, count int) {
+	rawLines := strings.Split(strings.ReplaceAll(strings.ReplaceAll(rawContent, "\r\n", "\n"), "\r", "\n"), "\n")
+	oldLines := strings.Split(strings.ReplaceAll(strings.ReplaceAll(oldString, "\r\n", "\n"), "\r", "\n"), "\n")
+	if len(oldLines) == 0 {
+		return fuzzyMatch{}, 0
+	}
+
+	normRaw := make([]string, len(rawLines))
+	for i, l := range rawLines {
+		normRaw[i] = strings.Join(strings.Fields(l), " ")
+	}
+	normOld := make([]string, len(oldLines))
+	for i, l := range oldLines {
+		normOld[i] = strings.Join(strings.Fields(l), " ")
+	}
+
+	n := len(normOld)
+	var starts []int
+	for i := 0; i+n <= len(normRaw); i++ {
+		match := true
+		for j := 0; j < n; j++ {
+			if normRaw[i+j] != normOld[j] {
+				match = false
+				break
+			}
+		}
+		if match {
+			starts = append(starts, i)
+		}
+	}
+
+	if len(starts) != 1 {
+		return fuzzyMatch{}, len(starts)
+	}
+
+	start := starts[0]
+	text := strings.Join(rawLines[start:start+n], "\n")
+	return fuzzyMatch{startLine: start, endLine: start + n, text: text, score: 1.0}, 1
+}
+
+// findFuzzyMatch runs a windowed similarity search for the best contiguous
+// span of rawContent resembling oldString, trying window sizes at and
+// around len(oldString)'s line count to tolerate a line being added or
+// dropped. It returns the best-scoring span (the earliest one on a tie) if
+// its score reaches threshold, or ok=false if nothing cleared the bar (or rawContent is too
+// large to search).
+func findFuzzyMatch(rawContent, oldString string, threshold float64) (fuzzyMatch, bool) {
+	if len(rawContent) > maxFuzzySearchBytes {
+		return fuzzyMatch{}, false
+	}
+
+	rawLines := strings.Split(rawContent, "\n")
+	oldLineCount := strings.Count(oldString, "\n") + 1
+
+	windowSizes := map[int]bool{oldLineCount: true}
+	if oldLineCount > 1 {
+		windowSizes[oldLineCount-1] = true
+	}
+	windowSizes[oldLineCount+1] = true
+
+	best := fuzzyMatch{score: -1}
+	for size := range windowSizes {
+		if size <= 0 || size > len(rawLines) {
+			continue
+		}
+		for i := 0; i+size <= len(rawLines); i++ {
+			candidate := strings.Join(rawLines[i:i+size], "\n")
+			score := similarityRatio(oldString, candidate)
+			if score > best.score {
+				best = fuzzyMatch{startLine: i, endLine: i + size, text: candidate, score: score}
+			}
+		}
+	}
+
+	if best.score < threshold {
+		return fuzzyMatch{}, false
+	}
+	return best, true
+}
+
+// similarityRatio computes the Ratcliff/Obershelp similarity ratio between
+// a and b: 2*M / (len(a)+len(b)), where M is the total length of matching
+// blocks found by recursively taking the longest common substring.
+func similarityRatio(a, b string) float64 {
+	if len(a) == 0 && len(b) == 0 {
+		return 1.0
+	}
+	if len(a) == 0 || len(b) == 0 {
+		return 0.0
+	}
+	m := matchingCharacters([]byte(a), []byte(b))
+	return 2.0 * float64(m) / float64(len(a)+len(b))
+}
+
+// matchingCharacters returns the total length of matching blocks between a
+// and b, found by taking the longest common substring and recursing on the
+// unmatched prefix and suffix either side of it (Ratcliff/Obershelp's
+// "gestalt pattern matching").
+func matchingCharacters(a, b []byte) int {
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+	aStart, bStart, length := longestCommonSubstring(a, b)
+	if length == 0 {
+		return 0
+	}
+	return length +
+		matchingCharacters(a[:aStart], b[:bStart]) +
+		matchingCharacters(a[aStart+length:], b[bStart+length:])
+}
+
+// longestCommonSubstring finds the longest run of bytes common to a and b
+// via dynamic programming, returning its starting offsets in each and its
+// length. Ties favor the earliest match in a, matching difflib's behavior.
+func longestCommonSubstring(a, b []byte) (aStart, bStart, length int) {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for i := 1; i <= len(a); i++ {
+		for j := 1; j <= len(b); j++ {
+			if a[i-1] == b[j-1] {
+				curr[j] = prev[j-1] + 1
+				if curr[j] > length {
+					length = curr[j]
+					aStart = i - curr[j]
+					bStart = j - curr[j]
+				}
+			} else {
+				curr[j] = 0
+			}
+		}
+		prev, curr = curr, prev
+	}
+	return aStart, bStart, length
+}
+
+// editFileFuzzy handles edit_file when old_string has zero exact
+// occurrences in rawContent. It first retries with whitespace/line-ending
+// normalization; if that's absent (or, with fuzzy=false, ambiguous), it
+// falls back to a windowed similarity search. With fuzzy=false (the
+// default) a match is only ever reported, as a diff preview, for the caller
+// to accept or refine old_string with; with fuzzy=true a unique
+// high-confidence match is applied directly, and a whitespace-normalized
+// match found at more than one place is refused.
+func editFileFuzzy(env ExecutionEnvironment, filePath, rawContent, oldString, newString string, fuzzy bool) (string, error) {
+	match, count := findWhitespaceNormalizedMatch(rawContent, oldString)
+	if count > 1 && fuzzy {
+		return "", fmt.Errorf("old_string not found verbatim in %s, and matches %d places after normalizing whitespace. Provide more context to make it unique.", filePath, count)
+	}
+	if count == 1 {
+		if !fuzzy {
+			return "", fmt.Errorf("old_string not found verbatim in %s, but matches after normalizing whitespace (lines %d-%d, similarity 1.00):\n%s\n"+
+				"Retry with fuzzy=true to apply this match, or adjust old_string to match the file exactly.",
+				filePath, match.startLine+1, match.endLine, unifiedDiffPreview(filePath, match, newString))
+		}
+		return applyFuzzyMatch(env, filePath, rawContent, match, newString)
+	}
+
+	match, ok := findFuzzyMatch(rawContent, oldString, fuzzyMatchThreshold)
+	if !ok {
+		return "", fmt.Errorf("old_string not found in %s", filePath)
+	}
+	if !fuzzy {
+		return "", fmt.Errorf("old_string not found verbatim in %s. Closest match is lines %d-%d (similarity %.2f):\n%s\n"+
+			"Retry with fuzzy=true to apply this match, or adjust old_string to match the file exactly.",
+			filePath, match.startLine+1, match.endLine, match.score, unifiedDiffPreview(filePath, match, newString))
+	}
+	return applyFuzzyMatch(env, filePath, rawContent, match, newString)
+}
+
+// applyFuzzyMatch replaces match's span in rawContent with newString and
+// writes the result back, reporting the match's similarity score and line
+// range in the success message.
+func applyFuzzyMatch(env ExecutionEnvironment, filePath, rawContent string, match fuzzyMatch, newString string) (string, error) {
+	rawLines := strings.Split(strings.ReplaceAll(strings.ReplaceAll(rawContent, "\r\n", "\n"), "\r", "\n"), "\n")
+	var sb strings.Builder
+	sb.WriteString(strings.Join(rawLines[:match.startLine], "\n"))
+	if match.startLine > 0 {
+		sb.WriteString("\n")
+	}
+	sb.WriteString(newString)
+	if match.endLine < len(rawLines) {
+		sb.WriteString("\n")
+		sb.WriteString(strings.Join(rawLines[match.endLine:], "\n"))
+	}
+
+	if err := env.WriteFile(filePath, sb.String()); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("Successfully replaced fuzzy match (lines %d-%d, similarity %.2f) in %s",
+		match.startLine+1, match.endLine, match.score, filePath), nil
+}
+
+// unifiedDiffPreview renders a minimal unified-diff-style preview of
+// replacing match.text with newString, for display in an error or success
+// message. It's a presentation helper only; nothing parses it back.
+func unifiedDiffPreview(filePath string, match fuzzyMatch, newString string) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "--- a/%s\n+++ b/%s\n", filePath, filePath)
+	fmt.Fprintf(&sb, "@@ -%d,%d +%d,%d @@\n", match.startLine+1, match.endLine-match.startLine, match.startLine+1, strings.Count(newString, "\n")+1)
+	for _, line := range strings.Split(match.text, "\n") {
+		fmt.Fprintf(&sb, "-%s\n", line)
+	}
+	for _, line := range strings.Split(newString, "\n") {
+		fmt.Fprintf(&sb, "+%s\n", line)
+	}
+	return sb.String()
+}
@@ -9,81 +9,202 @@ import (
 type EventKind string
 
 const (
-	EventSessionStart        EventKind = "session_start"
-	EventSessionEnd          EventKind = "session_end"
-	EventUserInput           EventKind = "user_input"
-	EventAssistantTextStart  EventKind = "assistant_text_start"
-	EventAssistantTextDelta  EventKind = "assistant_text_delta"
-	EventAssistantTextEnd    EventKind = "assistant_text_end"
-	EventToolCallStart       EventKind = "tool_call_start"
-	EventToolCallOutputDelta EventKind = "tool_call_output_delta"
-	EventToolCallEnd         EventKind = "tool_call_end"
-	EventSteeringInjected    EventKind = "steering_injected"
-	EventTurnLimit           EventKind = "turn_limit"
-	EventLoopDetection       EventKind = "loop_detection"
-	EventWarning             EventKind = "warning"
-	EventError               EventKind = "error"
+	EventSessionStart          EventKind = "session_start"
+	EventSessionEnd            EventKind = "session_end"
+	EventUserInput             EventKind = "user_input"
+	EventAssistantTextStart    EventKind = "assistant_text_start"
+	EventAssistantTextDelta    EventKind = "assistant_text_delta"
+	EventAssistantTextEnd      EventKind = "assistant_text_end"
+	EventToolCallStart         EventKind = "tool_call_start"
+	EventToolCallOutputDelta   EventKind = "tool_call_output_delta"
+	EventToolCallEnd           EventKind = "tool_call_end"
+	EventSteeringInjected      EventKind = "steering_injected"
+	EventTurnLimit             EventKind = "turn_limit"
+	EventLoopDetection         EventKind = "loop_detection"
+	EventCompactionStart       EventKind = "compaction_start"
+	EventCompactionEnd         EventKind = "compaction_end"
+	EventAgentSwitched         EventKind = "agent_switched"
+	EventToolApprovalRequested EventKind = "tool_approval_requested"
+	EventRetry                 EventKind = "retry"
+	EventWarning               EventKind = "warning"
+	EventError                 EventKind = "error"
+
+	// Syscall-level audit events emitted by an optional BPFCollector,
+	// correlating host-level activity with the SandboxedExecutionEnvironment
+	// invocation that spawned it via the shared cgroup ID.
+	EventSyscallExec    EventKind = "syscall_exec"
+	EventSyscallOpen    EventKind = "syscall_open"
+	EventSyscallConnect EventKind = "syscall_connect"
 )
 
 // SessionEvent is a typed event emitted by the agent loop.
 type SessionEvent struct {
+	Seq       uint64                 `json:"seq"`
 	Kind      EventKind              `json:"kind"`
 	Timestamp time.Time              `json:"timestamp"`
 	SessionID string                 `json:"session_id"`
 	Data      map[string]interface{} `json:"data,omitempty"`
 }
 
-// EventEmitter delivers typed events to the host application via a channel.
+// defaultRetainCount is how many recent events an EventEmitter keeps for
+// replay when no explicit count is passed to NewEventEmitter.
+const defaultRetainCount = 500
+
+// subscriberBufferSize bounds how far a subscriber can lag behind Emit
+// before it is disconnected.
+const subscriberBufferSize = 256
+
+// eventSubscriber is a single Subscribe() registration.
+type eventSubscriber struct {
+	ch chan SessionEvent
+}
+
+// EventEmitter delivers typed events to one or more subscribers, keeping a
+// bounded ring buffer of recent events so a late subscriber can replay
+// everything emitted since a given sequence number.
 type EventEmitter struct {
 	sessionID string
-	ch        chan SessionEvent
-	closed    bool
-	mu        sync.Mutex
+	retain    int
+
+	mu          sync.Mutex
+	closed      bool
+	seq         uint64
+	buffer      []SessionEvent // last `retain` events, oldest first
+	subscribers map[int]*eventSubscriber
+	nextSubID   int
 }
 
-// NewEventEmitter creates a new EventEmitter with a buffered channel.
-func NewEventEmitter(sessionID string, bufferSize int) *EventEmitter {
-	if bufferSize <= 0 {
-		bufferSize = 256
+// NewEventEmitter creates a new EventEmitter that retains the last
+// retainCount events for replay. retainCount <= 0 uses defaultRetainCount.
+func NewEventEmitter(sessionID string, retainCount int) *EventEmitter {
+	if retainCount <= 0 {
+		retainCount = defaultRetainCount
 	}
 	return &EventEmitter{
-		sessionID: sessionID,
-		ch:        make(chan SessionEvent, bufferSize),
+		sessionID:   sessionID,
+		retain:      retainCount,
+		subscribers: make(map[int]*eventSubscriber),
 	}
 }
 
-// Emit sends an event to the channel. If the emitter is closed, the event
-// is silently dropped.
+// Emit records an event and fans it out to every live subscriber. If the
+// emitter is closed, the event is silently dropped. A subscriber that can't
+// keep up gets a best-effort EventWarning{reason:"subscriber_lagged"} and
+// has its channel closed rather than blocking Emit.
 func (e *EventEmitter) Emit(kind EventKind, data map[string]interface{}) {
 	e.mu.Lock()
 	defer e.mu.Unlock()
 	if e.closed {
 		return
 	}
+
+	e.seq++
 	event := SessionEvent{
+		Seq:       e.seq,
 		Kind:      kind,
 		Timestamp: time.Now(),
 		SessionID: e.sessionID,
 		Data:      data,
 	}
+
+	e.buffer = append(e.buffer, event)
+	if len(e.buffer) > e.retain {
+		e.buffer = e.buffer[len(e.buffer)-e.retain:]
+	}
+
+	for id, sub := range e.subscribers {
+		select {
+		case sub.ch <- event:
+		default:
+			e.disconnectLagging(id, sub)
+		}
+	}
+}
+
+// disconnectLagging sends a best-effort lag warning and closes a
+// subscriber's channel. Callers must hold e.mu.
+func (e *EventEmitter) disconnectLagging(id int, sub *eventSubscriber) {
+	warning := SessionEvent{
+		Seq:       e.seq,
+		Kind:      EventWarning,
+		Timestamp: time.Now(),
+		SessionID: e.sessionID,
+		Data: map[string]interface{}{
+			"reason": "subscriber_lagged",
+		},
+	}
 	select {
-	case e.ch <- event:
+	case sub.ch <- warning:
 	default:
-		// Channel full; drop event to avoid blocking the agent loop.
 	}
+	close(sub.ch)
+	delete(e.subscribers, id)
 }
 
-// Events returns the read-only event channel.
+// Subscribe registers a new subscriber and returns a channel that first
+// replays any buffered events with Seq > fromSeq, then streams live events.
+// The returned cancel func unregisters the subscriber and closes its
+// channel; it is safe to call more than once.
+func (e *EventEmitter) Subscribe(fromSeq uint64) (<-chan SessionEvent, func()) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	ch := make(chan SessionEvent, subscriberBufferSize)
+	if e.closed {
+		close(ch)
+		return ch, func() {}
+	}
+
+	for _, event := range e.buffer {
+		if event.Seq <= fromSeq {
+			continue
+		}
+		select {
+		case ch <- event:
+		default:
+			// Replay backlog exceeds the subscriber buffer; the live fan-out
+			// loop in Emit will disconnect this subscriber on the next send
+			// if it still can't keep up.
+		}
+	}
+
+	id := e.nextSubID
+	e.nextSubID++
+	sub := &eventSubscriber{ch: ch}
+	e.subscribers[id] = sub
+
+	var once sync.Once
+	cancel := func() {
+		once.Do(func() {
+			e.mu.Lock()
+			defer e.mu.Unlock()
+			if _, ok := e.subscribers[id]; ok {
+				delete(e.subscribers, id)
+				close(ch)
+			}
+		})
+	}
+	return ch, cancel
+}
+
+// Events returns a read-only event channel for a new subscriber that first
+// replays every retained event, then streams live events. Prefer Subscribe
+// when resuming from a known sequence number.
 func (e *EventEmitter) Events() <-chan SessionEvent {
-	return e.ch
+	ch, _ := e.Subscribe(0)
+	return ch
 }
 
-// Close closes the event channel. Safe to call multiple times.
+// Close closes every subscriber channel. Safe to call multiple times.
 func (e *EventEmitter) Close() {
 	e.mu.Lock()
 	defer e.mu.Unlock()
-	if !e.closed {
-		e.closed = true
-		close(e.ch)
+	if e.closed {
+		return
+	}
+	e.closed = true
+	for id, sub := range e.subscribers {
+		close(sub.ch)
+		delete(e.subscribers, id)
 	}
 }
@@ -15,11 +15,11 @@ import (
 
 // ExecResult holds the result of a command execution.
 type ExecResult struct {
-	Stdout    string `json:"stdout"`
-	Stderr    string `json:"stderr"`
-	ExitCode  int    `json:"exit_code"`
-	TimedOut  bool   `json:"timed_out"`
-	DurationMs int64 `json:"duration_ms"`
+	Stdout     string `json:"stdout"`
+	Stderr     string `json:"stderr"`
+	ExitCode   int    `json:"exit_code"`
+	TimedOut   bool   `json:"timed_out"`
+	DurationMs int64  `json:"duration_ms"`
 }
 
 // Output returns combined stdout and stderr.
@@ -52,6 +52,8 @@ type ExecutionEnvironment interface {
 	// File operations.
 	ReadFile(path string, offset, limit int) (string, error)
 	WriteFile(path string, content string) error
+	DeleteFile(path string) error
+	RenameFile(oldPath, newPath string) error
 	FileExists(path string) bool
 	ListDirectory(path string, depth int) ([]DirEntry, error)
 
@@ -119,11 +121,75 @@ func filterEnvironment() []string {
 	return filtered
 }
 
+// mergeEnv appends overrides to base as "k=v" pairs and deduplicates the
+// result the way os/exec.dedupEnv does: later assignments to the same key
+// win, comparison is case-insensitive on Windows and case-sensitive
+// elsewhere, and entries beginning with "=" (the Windows per-drive
+// working-directory pseudo-variables, e.g. "=C:=C:\foo") are matched on
+// their key up to the second "=" rather than being torn apart by a naive
+// single-"=" split.
+func mergeEnv(base []string, overrides map[string]string) []string {
+	env := make([]string, 0, len(base)+len(overrides))
+	env = append(env, base...)
+	for k, v := range overrides {
+		env = append(env, k+"="+v)
+	}
+	return dedupEnv(env)
+}
+
+// dedupEnv returns env with duplicate keys removed in favor of later
+// values, preserving original order. Entries without an "=" are left
+// unchanged.
+func dedupEnv(env []string) []string {
+	caseInsensitive := runtime.GOOS == "windows"
+
+	// Walk in reverse to keep the last occurrence of each key, then restore
+	// the original order.
+	out := make([]string, 0, len(env))
+	seen := make(map[string]bool, len(env))
+	for n := len(env); n > 0; n-- {
+		kv := env[n-1]
+
+		i := strings.Index(kv, "=")
+		if i == 0 {
+			// Windows pseudo-variables like "=C:=C:\foo" have a leading "=" as
+			// part of the key; consume through the second "=" instead.
+			if rest := strings.Index(kv[1:], "="); rest >= 0 {
+				i = rest + 1
+			}
+		}
+		if i < 0 {
+			if kv != "" {
+				out = append(out, kv)
+			}
+			continue
+		}
+
+		key := kv[:i]
+		if caseInsensitive {
+			key = strings.ToLower(key)
+		}
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		out = append(out, kv)
+	}
+
+	for i, j := 0, len(out)-1; i < j; i, j = i+1, j-1 {
+		out[i], out[j] = out[j], out[i]
+	}
+	return out
+}
+
 // LocalExecutionEnvironment runs tools on the local machine.
 type LocalExecutionEnvironment struct {
 	workingDir string
 	platform   string
 	osVersion  string
+
+	policy        CommandPolicy
+	policyWarning func(reason, node string)
 }
 
 // NewLocalExecutionEnvironment creates a local execution environment.
@@ -138,6 +204,19 @@ func NewLocalExecutionEnvironment(workingDir string) *LocalExecutionEnvironment
 	}
 }
 
+// SetCommandPolicy installs a CommandPolicy consulted by ExecCommand before
+// every command runs. A nil policy (the default) allows everything.
+func (e *LocalExecutionEnvironment) SetCommandPolicy(policy CommandPolicy) {
+	e.policy = policy
+}
+
+// SetPolicyWarning installs a callback invoked with the denial reason and
+// offending AST node whenever CommandPolicy denies a command, so a host
+// application (typically Session) can surface it as an EventWarning.
+func (e *LocalExecutionEnvironment) SetPolicyWarning(fn func(reason, node string)) {
+	e.policyWarning = fn
+}
+
 func (e *LocalExecutionEnvironment) Initialize() error {
 	return os.MkdirAll(e.workingDir, 0755)
 }
@@ -206,6 +285,28 @@ func (e *LocalExecutionEnvironment) WriteFile(path string, content string) error
 	return os.WriteFile(resolved, []byte(content), 0644)
 }
 
+func (e *LocalExecutionEnvironment) DeleteFile(path string) error {
+	resolved := e.resolvePath(path)
+	if err := os.Remove(resolved); err != nil {
+		return fmt.Errorf("delete_file: %w", err)
+	}
+	return nil
+}
+
+func (e *LocalExecutionEnvironment) RenameFile(oldPath, newPath string) error {
+	resolvedOld := e.resolvePath(oldPath)
+	resolvedNew := e.resolvePath(newPath)
+	if dir := filepath.Dir(resolvedNew); dir != "." {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return fmt.Errorf("rename_file: failed to create directory: %w", err)
+		}
+	}
+	if err := os.Rename(resolvedOld, resolvedNew); err != nil {
+		return fmt.Errorf("rename_file: %w", err)
+	}
+	return nil
+}
+
 func (e *LocalExecutionEnvironment) FileExists(path string) bool {
 	resolved := e.resolvePath(path)
 	_, err := os.Stat(resolved)
@@ -240,6 +341,15 @@ func (e *LocalExecutionEnvironment) ExecCommand(ctx context.Context, command str
 		workingDir = e.resolvePath(workingDir)
 	}
 
+	if e.policy != nil {
+		if decision := e.policy.Check(command, workingDir); !decision.Allowed {
+			if e.policyWarning != nil {
+				e.policyWarning(decision.Reason, decision.Node)
+			}
+			return &ExecResult{ExitCode: -2, Stderr: decision.Reason}, nil
+		}
+	}
+
 	// Create context with timeout.
 	if timeoutMs > 0 {
 		var cancel context.CancelFunc
@@ -261,12 +371,10 @@ func (e *LocalExecutionEnvironment) ExecCommand(ctx context.Context, command str
 	// Set up process group for clean killability.
 	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
 
-	// Filtered environment + any caller-specified overrides.
-	env := filterEnvironment()
-	for k, v := range envVars {
-		env = append(env, k+"="+v)
-	}
-	cmd.Env = env
+	// Filtered environment + any caller-specified overrides, deduplicated so
+	// an override actually takes effect instead of shadowing an earlier
+	// assignment of the same key.
+	cmd.Env = mergeEnv(filterEnvironment(), envVars)
 
 	var stdout, stderr bytes.Buffer
 	cmd.Stdout = &stdout
@@ -0,0 +1,81 @@
+package agentloop
+
+import (
+	"reflect"
+	"runtime"
+	"testing"
+)
+
+// TestDedupEnvCaseSensitivity covers the exact matrix from the request:
+// {"k1=v1","K1=V2","k1=v3"} dedups to {"K1=V2","k1=v3"} on a case-sensitive
+// platform (every non-Windows GOOS) since "k1" and "K1" are distinct keys
+// there. The case-insensitive (Windows) half of the matrix lives in
+// execution_windows_test.go, since dedupEnv's case folding is gated on
+// runtime.GOOS rather than being caller-configurable.
+func TestDedupEnvCaseSensitivity(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("case-sensitive behavior only applies on non-Windows platforms")
+	}
+
+	got := dedupEnv([]string{"k1=v1", "K1=V2", "k1=v3"})
+	want := []string{"K1=V2", "k1=v3"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("dedupEnv() = %v, want %v", got, want)
+	}
+}
+
+// TestDedupEnvLaterAssignmentWins checks the simple, platform-independent
+// case: repeated assignments to the same key collapse to the last one,
+// preserving the position of the surviving entry.
+func TestDedupEnvLaterAssignmentWins(t *testing.T) {
+	got := dedupEnv([]string{"PATH=/a", "HOME=/root", "PATH=/b"})
+	want := []string{"HOME=/root", "PATH=/b"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("dedupEnv() = %v, want %v", got, want)
+	}
+}
+
+// TestDedupEnvWindowsDriveVarPreserved covers the Windows per-drive
+// working-directory pseudo-variable case: a leading "=" is part of the key
+// (up to the second "="), not a malformed entry to drop, so "=C:=C:\foo"
+// must survive dedupEnv verbatim when it's the only entry for that key.
+func TestDedupEnvWindowsDriveVarPreserved(t *testing.T) {
+	got := dedupEnv([]string{`=C:=C:\foo`})
+	want := []string{`=C:=C:\foo`}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("dedupEnv() = %v, want %v", got, want)
+	}
+}
+
+// TestDedupEnvWindowsDriveVarDedup checks that two assignments to the same
+// drive pseudo-variable still dedup to the later one, same as any other key.
+func TestDedupEnvWindowsDriveVarDedup(t *testing.T) {
+	got := dedupEnv([]string{`=C:=C:\foo`, `=C:=C:\bar`})
+	want := []string{`=C:=C:\bar`}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("dedupEnv() = %v, want %v", got, want)
+	}
+}
+
+// TestDedupEnvEntryWithoutEquals checks that a malformed entry (no "=" at
+// all) is kept verbatim, and an empty entry dropped, rather than panicking
+// or being treated as a key with an empty value.
+func TestDedupEnvEntryWithoutEquals(t *testing.T) {
+	got := dedupEnv([]string{"PATH=/a", "garbage", ""})
+	want := []string{"PATH=/a", "garbage"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("dedupEnv() = %v, want %v", got, want)
+	}
+}
+
+// TestMergeEnvOverrideWins checks mergeEnv's contract end-to-end: an
+// override for a key already present in base replaces it rather than
+// appending a shadowing duplicate.
+func TestMergeEnvOverrideWins(t *testing.T) {
+	base := []string{"PATH=/usr/bin", "HOME=/root"}
+	got := mergeEnv(base, map[string]string{"PATH": "/opt/bin"})
+	want := []string{"HOME=/root", "PATH=/opt/bin"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("mergeEnv() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,20 @@
+//go:build windows
+
+package agentloop
+
+import (
+	"reflect"
+	"testing"
+)
+
+// TestDedupEnvCaseInsensitivity covers the Windows half of the matrix from
+// the request: {"k1=v1","K1=V2","k1=v3"} dedups to {"k1=v3"} on Windows,
+// since key comparison there is case-insensitive and the last assignment
+// wins regardless of which casing it used.
+func TestDedupEnvCaseInsensitivity(t *testing.T) {
+	got := dedupEnv([]string{"k1=v1", "K1=V2", "k1=v3"})
+	want := []string{"k1=v3"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("dedupEnv() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,10 @@
+// Package executorpb holds the generated client/server bindings for
+// proto/executor.proto (protoc-gen-go + protoc-gen-go-grpc). Regenerate with:
+//
+//	protoc --go_out=. --go-grpc_out=. proto/executor.proto
+//
+// The generated executor.pb.go and executor_grpc.pb.go files are not checked
+// in to this tree; agentloop/remote_execution.go and
+// cmd/executor-server/main.go both depend on the types and client/server
+// interfaces protoc produces from the service definition.
+package executorpb
@@ -1,17 +1,6 @@
 package agentloop
 
-import (
-	"crypto/sha256"
-	"encoding/json"
-	"fmt"
-)
-
-// toolCallSignature computes a deterministic signature for a tool call
-// (name + hash of arguments).
-func toolCallSignature(name string, arguments json.RawMessage) string {
-	h := sha256.Sum256(arguments)
-	return fmt.Sprintf("%s:%x", name, h[:8])
-}
+import "github.com/martinemde/attractor/unifiedllm"
 
 // extractToolCallSignatures extracts signatures from the most recent tool
 // calls in the history.
@@ -23,7 +12,7 @@ func extractToolCallSignatures(history []Turn, count int) []string {
 		if turn.Kind == TurnAssistant && turn.Assistant != nil {
 			for j := len(turn.Assistant.ToolCalls) - 1; j >= 0 && len(sigs) < count; j-- {
 				tc := turn.Assistant.ToolCalls[j]
-				sigs = append(sigs, toolCallSignature(tc.Name, tc.Arguments))
+				sigs = append(sigs, unifiedllm.ToolCallSignature(tc.Name, tc.Arguments))
 			}
 		}
 	}
@@ -35,35 +24,12 @@ func extractToolCallSignatures(history []Turn, count int) []string {
 }
 
 // DetectLoop checks if the last windowSize tool calls follow a repeating
-// pattern of length 1, 2, or 3.
+// pattern of length 1, 2, or 3. It delegates to
+// unifiedllm.DetectToolCallLoop, the same primitive Generate's
+// LoopDetectionWindow uses, so a loop is recognized the same way whether a
+// tool call came through a Session or a direct Generate call.
 func DetectLoop(history []Turn, windowSize int) bool {
 	sigs := extractToolCallSignatures(history, windowSize)
-	if len(sigs) < windowSize {
-		return false
-	}
-
-	// Check for repeating patterns of length 1, 2, or 3.
-	for patternLen := 1; patternLen <= 3; patternLen++ {
-		if windowSize%patternLen != 0 {
-			continue
-		}
-		pattern := sigs[:patternLen]
-		allMatch := true
-		for i := patternLen; i < windowSize; i += patternLen {
-			for j := 0; j < patternLen; j++ {
-				if sigs[i+j] != pattern[j] {
-					allMatch = false
-					break
-				}
-			}
-			if !allMatch {
-				break
-			}
-		}
-		if allMatch {
-			return true
-		}
-	}
-
-	return false
+	detected, _ := unifiedllm.DetectToolCallLoop(sigs, windowSize)
+	return detected
 }
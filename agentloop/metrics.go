@@ -0,0 +1,106 @@
+package agentloop
+
+import (
+	"github.com/martinemde/attractor/metrics"
+	"github.com/martinemde/attractor/unifiedllm"
+)
+
+// AgentMetrics holds the agentloop-side instruments: turns recorded by
+// kind, tool calls by tool name and outcome, token usage and estimated
+// cost per provider/model, and subagent depth/lifecycle. Construct with
+// NewAgentMetrics; a nil Registry yields an inert collector backed by
+// metrics.NoopRegistry, so wiring it in is optional.
+type AgentMetrics struct {
+	turns          metrics.CounterVec
+	toolCalls      metrics.CounterVec
+	tokens         metrics.CounterVec
+	cost           metrics.CounterVec
+	subagentDepth  metrics.GaugeVec
+	subagentEvents metrics.CounterVec
+}
+
+// NewAgentMetrics declares agentloop's instruments against reg. Passing
+// nil is equivalent to passing metrics.NoopRegistry{}.
+func NewAgentMetrics(reg metrics.Registry) *AgentMetrics {
+	if reg == nil {
+		reg = metrics.NoopRegistry{}
+	}
+	return &AgentMetrics{
+		turns:          reg.Counter("agentloop_turns_total", "Total turns recorded by kind.", "kind"),
+		toolCalls:      reg.Counter("agentloop_tool_calls_total", "Total tool calls by tool name and outcome.", "tool", "outcome"),
+		tokens:         reg.Counter("agentloop_tokens_total", "Tokens consumed by provider, model, and kind.", "provider", "model", "kind"),
+		cost:           reg.Counter("agentloop_estimated_cost_usd_total", "Estimated cost in USD by provider and model.", "provider", "model"),
+		subagentDepth:  reg.Gauge("agentloop_subagent_depth", "Current subagent nesting depth in use."),
+		subagentEvents: reg.Counter("agentloop_subagent_events_total", "Subagent lifecycle transitions by status.", "status"),
+	}
+}
+
+func (m *AgentMetrics) recordTurn(kind TurnKind) {
+	m.turns.Inc(string(kind))
+}
+
+func (m *AgentMetrics) recordToolCall(tool string, isError bool) {
+	outcome := "ok"
+	if isError {
+		outcome = "error"
+	}
+	m.toolCalls.Inc(tool, outcome)
+}
+
+// recordUsage records token and estimated-cost metrics for an LLM response,
+// mirroring unifiedllm.Metrics.recordUsage against the same model catalog
+// pricing (see estimatedCostUSD) so agentloop's view of cost matches
+// unifiedllm's without unifiedllm exporting its unexported helper.
+func (m *AgentMetrics) recordUsage(provider, model string, usage unifiedllm.Usage) {
+	m.recordTokens(provider, model, "input", usage.InputTokens)
+	m.recordTokens(provider, model, "output", usage.OutputTokens)
+	if usage.ReasoningTokens != nil {
+		m.recordTokens(provider, model, "reasoning", *usage.ReasoningTokens)
+	}
+	if usage.CacheReadTokens != nil {
+		m.recordTokens(provider, model, "cache_read", *usage.CacheReadTokens)
+	}
+	if usage.CacheWriteTokens != nil {
+		m.recordTokens(provider, model, "cache_write", *usage.CacheWriteTokens)
+	}
+
+	cost := estimatedCostUSD(model, usage)
+	if cost == 0 {
+		return
+	}
+	m.cost.Add(cost, provider, model)
+}
+
+func (m *AgentMetrics) recordTokens(provider, model, kind string, n int) {
+	if n == 0 {
+		return
+	}
+	m.tokens.Add(float64(n), provider, model, kind)
+}
+
+func (m *AgentMetrics) recordSubagentDepth(depth int) {
+	m.subagentDepth.Set(float64(depth))
+}
+
+func (m *AgentMetrics) recordSubagentEvent(status SubAgentStatus) {
+	m.subagentEvents.Inc(string(status))
+}
+
+// estimatedCostUSD estimates the dollar cost of usage against model's
+// catalog pricing, unknown models and models with no published pricing
+// estimating to 0. Mirrors unifiedllm's estimatedCostUSD in
+// unifiedllm/metrics_middleware.go.
+func estimatedCostUSD(model string, usage unifiedllm.Usage) float64 {
+	info := unifiedllm.GetModelInfo(model)
+	if info == nil {
+		return 0
+	}
+	var cost float64
+	if info.InputCostPerMillion != nil {
+		cost += float64(usage.InputTokens) / 1_000_000 * *info.InputCostPerMillion
+	}
+	if info.OutputCostPerMillion != nil {
+		cost += float64(usage.OutputTokens) / 1_000_000 * *info.OutputCostPerMillion
+	}
+	return cost
+}
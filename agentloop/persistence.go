@@ -0,0 +1,352 @@
+package agentloop
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/martinemde/attractor/unifiedllm"
+)
+
+// sessionRecordKind discriminates the entries a SessionStore persists.
+type sessionRecordKind string
+
+const (
+	sessionRecordKindMeta  sessionRecordKind = "meta"
+	sessionRecordKindTurn  sessionRecordKind = "turn"
+	sessionRecordKindEvent sessionRecordKind = "event"
+)
+
+// SessionMeta records a session's identifying configuration, so
+// ResumeSession can rebuild it without the caller having to remember what
+// profile/config it was created with.
+type SessionMeta struct {
+	SessionID  string        `json:"session_id"`
+	ProviderID string        `json:"provider_id"`
+	ModelID    string        `json:"model_id"`
+	Config     SessionConfig `json:"config"`
+}
+
+// SessionRecord is one SessionStore entry: a turn (with the LLM
+// request/response that produced it, if any), an emitted SessionEvent, or
+// the session's meta record. Replaying a session's records in the order
+// they were recorded reconstructs it exactly.
+type SessionRecord struct {
+	Kind     sessionRecordKind    `json:"kind"`
+	Meta     *SessionMeta         `json:"meta,omitempty"`
+	Turn     *Turn                `json:"turn,omitempty"`
+	Request  *unifiedllm.Request  `json:"request,omitempty"`
+	Response *unifiedllm.Response `json:"response,omitempty"`
+	Event    *SessionEvent        `json:"event,omitempty"`
+
+	// sessionIDOverride carries the session ID for turn/event records, which
+	// (unlike meta records) have nowhere else to put it. Unexported, so it's
+	// never marshaled: it only needs to survive the appendRecord call.
+	sessionIDOverride string
+}
+
+// SessionStore persists a session's meta, turns, and events under its UUID,
+// and reloads them for ResumeSession and ReplaySession.
+type SessionStore interface {
+	// SaveMeta records a session's identifying configuration. Called each
+	// time a store is attached via Session.SetStore (including by
+	// ResumeSession).
+	SaveMeta(ctx context.Context, meta SessionMeta) error
+
+	// AppendTurn records a turn as it's appended to history, along with the
+	// LLM request/response that produced it (both nil for turns that don't
+	// come from an LLM call, e.g. user/steering/tool-result turns).
+	AppendTurn(ctx context.Context, sessionID string, turn Turn, req *unifiedllm.Request, resp *unifiedllm.Response) error
+
+	// AppendEvent records an emitted SessionEvent.
+	AppendEvent(ctx context.Context, sessionID string, event SessionEvent) error
+
+	// Load returns every record for sessionID, in the order it was recorded.
+	Load(ctx context.Context, sessionID string) ([]SessionRecord, error)
+}
+
+// JSONLSessionStore is the default SessionStore: one file per session,
+// "<dir>/<sessionID>.jsonl", each line a JSON-encoded SessionRecord.
+type JSONLSessionStore struct {
+	dir string
+	mu  sync.Mutex
+}
+
+// NewJSONLSessionStore creates a JSONLSessionStore rooted at dir. dir is
+// created on first write if it doesn't already exist.
+func NewJSONLSessionStore(dir string) *JSONLSessionStore {
+	return &JSONLSessionStore{dir: dir}
+}
+
+func (s *JSONLSessionStore) path(sessionID string) string {
+	return filepath.Join(s.dir, sessionID+".jsonl")
+}
+
+func (s *JSONLSessionStore) appendRecord(record SessionRecord) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := os.MkdirAll(s.dir, 0o755); err != nil {
+		return fmt.Errorf("session store: %w", err)
+	}
+	f, err := os.OpenFile(s.path(record.sessionID()), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("session store: %w", err)
+	}
+	defer f.Close()
+
+	data, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("session store: %w", err)
+	}
+	data = append(data, '\n')
+	if _, err := f.Write(data); err != nil {
+		return fmt.Errorf("session store: %w", err)
+	}
+	return nil
+}
+
+// sessionID is a convenience the store uses to avoid threading sessionID
+// through every record kind separately: it lives on SessionMeta for meta
+// records, and is passed in explicitly for turn/event records via
+// sessionIDOverride.
+func (r SessionRecord) sessionID() string {
+	if r.sessionIDOverride != "" {
+		return r.sessionIDOverride
+	}
+	if r.Meta != nil {
+		return r.Meta.SessionID
+	}
+	return ""
+}
+
+func (s *JSONLSessionStore) SaveMeta(_ context.Context, meta SessionMeta) error {
+	return s.appendRecord(SessionRecord{Kind: sessionRecordKindMeta, Meta: &meta})
+}
+
+func (s *JSONLSessionStore) AppendTurn(_ context.Context, sessionID string, turn Turn, req *unifiedllm.Request, resp *unifiedllm.Response) error {
+	t := turn
+	return s.appendRecord(SessionRecord{Kind: sessionRecordKindTurn, Turn: &t, Request: req, Response: resp, sessionIDOverride: sessionID})
+}
+
+func (s *JSONLSessionStore) AppendEvent(_ context.Context, sessionID string, event SessionEvent) error {
+	return s.appendRecord(SessionRecord{Kind: sessionRecordKindEvent, Event: &event, sessionIDOverride: sessionID})
+}
+
+// Load reads every record for sessionID back from its JSONL file, in file
+// order (which is recording order, since appendRecord only ever appends).
+func (s *JSONLSessionStore) Load(_ context.Context, sessionID string) ([]SessionRecord, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	f, err := os.Open(s.path(sessionID))
+	if err != nil {
+		return nil, fmt.Errorf("session store: %w", err)
+	}
+	defer f.Close()
+
+	var records []SessionRecord
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+		var record SessionRecord
+		if err := json.Unmarshal(line, &record); err != nil {
+			return nil, fmt.Errorf("session store: %w", err)
+		}
+		records = append(records, record)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("session store: %w", err)
+	}
+	return records, nil
+}
+
+// SetStore attaches a SessionStore: it persists meta immediately, then every
+// subsequently appended turn (via recordTurn) and emitted event. Passing nil
+// detaches persistence, stopping the event-forwarding goroutine.
+func (s *Session) SetStore(store SessionStore) error {
+	s.mu.Lock()
+	if s.storeCancel != nil {
+		s.storeCancel()
+		s.storeCancel = nil
+	}
+	s.store = store
+	id := s.id
+	providerID := s.profile.ID()
+	modelID := s.profile.ModelID()
+	cfg := s.config
+	s.mu.Unlock()
+
+	if store == nil {
+		return nil
+	}
+
+	if err := store.SaveMeta(context.Background(), SessionMeta{
+		SessionID:  id,
+		ProviderID: providerID,
+		ModelID:    modelID,
+		Config:     cfg,
+	}); err != nil {
+		return fmt.Errorf("session store: %w", err)
+	}
+
+	events, cancel := s.emitter.Subscribe(0)
+	s.mu.Lock()
+	s.storeCancel = cancel
+	s.mu.Unlock()
+
+	go func() {
+		for event := range events {
+			_ = store.AppendEvent(context.Background(), id, event)
+		}
+	}()
+
+	return nil
+}
+
+// ResumeSession rebuilds a session from everything store has recorded under
+// id: its SessionMeta, then its history (replaying compaction's in-place
+// summary splices, via SummaryTurn's folded-range indices, the same way
+// Session.Compact applied them live). The returned session is wired to
+// store via SetStore, so further turns and events keep appending to the
+// same log, and is ready to continue via Submit.
+func ResumeSession(ctx context.Context, id string, store SessionStore, profile ProviderProfile, env ExecutionEnvironment) (*Session, error) {
+	records, err := store.Load(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("resume session %s: %w", id, err)
+	}
+
+	var meta *SessionMeta
+	var history []Turn
+	for _, rec := range records {
+		switch rec.Kind {
+		case sessionRecordKindMeta:
+			if rec.Meta != nil {
+				meta = rec.Meta
+			}
+		case sessionRecordKindTurn:
+			if rec.Turn == nil {
+				continue
+			}
+			turn := *rec.Turn
+			if turn.Kind == TurnSummary && turn.Summary != nil {
+				start, end := turn.Summary.FirstTurnIndex, turn.Summary.LastTurnIndex
+				if start >= 0 && end < len(history) && start <= end {
+					rebuilt := make([]Turn, 0, len(history)-(end-start))
+					rebuilt = append(rebuilt, history[:start]...)
+					rebuilt = append(rebuilt, turn)
+					rebuilt = append(rebuilt, history[end+1:]...)
+					history = rebuilt
+					continue
+				}
+			}
+			history = append(history, turn)
+		}
+	}
+	if meta == nil {
+		return nil, fmt.Errorf("resume session %s: no recorded session metadata", id)
+	}
+
+	cfg := meta.Config
+	s := newSession(id, profile, env, &cfg)
+	s.mu.Lock()
+	s.history = history
+	s.mu.Unlock()
+
+	if err := s.SetStore(store); err != nil {
+		return nil, fmt.Errorf("resume session %s: %w", id, err)
+	}
+	return s, nil
+}
+
+// ReplaySession streams every recorded SessionEvent for id from store, in
+// recorded order, without invoking the LLM or an execution environment.
+// Useful for debugging non-deterministic tool-call sequences and for
+// building UIs that render past runs. The channel is closed once every
+// event has been sent or ctx is cancelled.
+func ReplaySession(ctx context.Context, id string, store SessionStore) (<-chan SessionEvent, error) {
+	records, err := store.Load(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("replay session %s: %w", id, err)
+	}
+
+	ch := make(chan SessionEvent)
+	go func() {
+		defer close(ch)
+		for _, rec := range records {
+			if rec.Kind != sessionRecordKindEvent || rec.Event == nil {
+				continue
+			}
+			select {
+			case ch <- *rec.Event:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return ch, nil
+}
+
+// ReplayProviderAdapter is a unifiedllm.ProviderAdapter that returns
+// pre-recorded responses in order instead of calling a real provider. Built
+// by NewReplayClientFromStore from a SessionStore's recorded
+// request/response pairs, it lets integration tests exercise the whole
+// agent loop deterministically, without network calls.
+type ReplayProviderAdapter struct {
+	name      string
+	responses []*unifiedllm.Response
+	mu        sync.Mutex
+	next      int
+}
+
+func (a *ReplayProviderAdapter) Name() string { return a.name }
+
+// Complete returns the next recorded response, regardless of req.
+func (a *ReplayProviderAdapter) Complete(_ context.Context, _ unifiedllm.Request) (*unifiedllm.Response, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.next >= len(a.responses) {
+		return nil, fmt.Errorf("replay adapter %q: no more recorded responses (replayed %d)", a.name, a.next)
+	}
+	resp := a.responses[a.next]
+	a.next++
+	return resp, nil
+}
+
+// Stream is not supported: recorded responses are not recorded as deltas.
+func (a *ReplayProviderAdapter) Stream(_ context.Context, _ unifiedllm.Request) (<-chan unifiedllm.StreamEvent, error) {
+	return nil, fmt.Errorf("replay adapter %q: streaming replay is not supported", a.name)
+}
+
+// NewReplayClientFromStore loads every recorded (request, response) pair for
+// id from store and returns a unifiedllm.Client that replays the recorded
+// responses in order for providerID, regardless of the request it's given.
+// Pass the result to Session.SetClient in place of a real Client to
+// exercise the agent loop deterministically in tests.
+func NewReplayClientFromStore(ctx context.Context, id string, store SessionStore, providerID string) (*unifiedllm.Client, error) {
+	records, err := store.Load(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("replay client for session %s: %w", id, err)
+	}
+
+	var responses []*unifiedllm.Response
+	for _, rec := range records {
+		if rec.Kind == sessionRecordKindTurn && rec.Response != nil {
+			responses = append(responses, rec.Response)
+		}
+	}
+
+	adapter := &ReplayProviderAdapter{name: providerID, responses: responses}
+	return unifiedllm.NewClient(
+		unifiedllm.WithProvider(providerID, adapter),
+		unifiedllm.WithDefaultProvider(providerID),
+	), nil
+}
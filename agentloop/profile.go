@@ -1,5 +1,7 @@
 package agentloop
 
+import "strings"
+
 // ProviderProfile defines the provider-aligned tool and prompt configuration.
 // Each profile corresponds to a model family and mirrors the provider's native
 // agent toolset and system prompt.
@@ -28,6 +30,11 @@ type ProviderProfile interface {
 	SupportsStreaming() bool
 	SupportsParallelToolCalls() bool
 	ContextWindowSize() int
+
+	// Authorizer returns the declarative policy gating this profile's tool
+	// calls, or nil if every registered tool is always callable (the
+	// package's historical default). See Session.executeSingleTool.
+	Authorizer() ToolAuthorizer
 }
 
 // BaseProfile provides common profile fields and default implementations.
@@ -36,14 +43,59 @@ type BaseProfile struct {
 	model                     string
 	registry                  *ToolRegistry
 	supportsReasoning         bool
-	supportsStreaming          bool
+	supportsStreaming         bool
 	supportsParallelToolCalls bool
 	contextWindowSize         int
+	providerOptionsOverride   map[string]interface{} // set via WithProviderOptions; nil means "use the profile's own default"
+	promptFragments           []string               // set via WithPromptFragments; appended to BuildSystemPrompt's output
+	toolAuthorizer            ToolAuthorizer         // set via WithToolAuthorizer; nil means every registered tool is always callable
+}
+
+// ProviderOptionsOverride returns the config-supplied provider options
+// override, or nil if WithProviderOptions was never applied. Each
+// NewXProfile constructor's ProviderOptions method checks this before
+// falling back to its own provider-specific default.
+func (p *BaseProfile) ProviderOptionsOverride() map[string]interface{} {
+	return p.providerOptionsOverride
+}
+
+// appendPromptFragments appends each configured system-prompt fragment to
+// base, separated by a blank line, the same way Session.appendAgentSystemPrompt
+// layers an AgentProfile's fragment onto a session's system prompt.
+func (p *BaseProfile) appendPromptFragments(base string) string {
+	if len(p.promptFragments) == 0 {
+		return base
+	}
+	var sb strings.Builder
+	sb.WriteString(base)
+	for _, frag := range p.promptFragments {
+		sb.WriteString("\n\n")
+		sb.WriteString(frag)
+	}
+	return sb.String()
+}
+
+// appendPolicySummary appends a's Describe() output to base under a "# Tool
+// Access Policy" heading, if a ToolAuthorizer is configured and implements
+// PolicyDescriber. A configured ToolAuthorizer that doesn't implement
+// PolicyDescriber still enforces denials; it just has no summary to append,
+// since Describe isn't part of the ToolAuthorizer interface itself.
+func (p *BaseProfile) appendPolicySummary(base string) string {
+	describer, ok := p.toolAuthorizer.(PolicyDescriber)
+	if p.toolAuthorizer == nil || !ok {
+		return base
+	}
+	var sb strings.Builder
+	sb.WriteString(base)
+	sb.WriteString("\n\n# Tool Access Policy\n\n")
+	sb.WriteString(describer.Describe())
+	return sb.String()
 }
 
-func (p *BaseProfile) ID() string           { return p.providerID }
-func (p *BaseProfile) ModelID() string       { return p.model }
+func (p *BaseProfile) ID() string                  { return p.providerID }
+func (p *BaseProfile) ModelID() string             { return p.model }
 func (p *BaseProfile) ToolRegistry() *ToolRegistry { return p.registry }
+func (p *BaseProfile) Authorizer() ToolAuthorizer  { return p.toolAuthorizer }
 
 func (p *BaseProfile) Tools() []ToolDefinition {
 	return p.registry.Definitions()
@@ -54,6 +106,6 @@ func (p *BaseProfile) ProviderOptions() map[string]interface{} {
 }
 
 func (p *BaseProfile) SupportsReasoning() bool         { return p.supportsReasoning }
-func (p *BaseProfile) SupportsStreaming() bool          { return p.supportsStreaming }
-func (p *BaseProfile) SupportsParallelToolCalls() bool  { return p.supportsParallelToolCalls }
-func (p *BaseProfile) ContextWindowSize() int           { return p.contextWindowSize }
+func (p *BaseProfile) SupportsStreaming() bool         { return p.supportsStreaming }
+func (p *BaseProfile) SupportsParallelToolCalls() bool { return p.supportsParallelToolCalls }
+func (p *BaseProfile) ContextWindowSize() int          { return p.contextWindowSize }
@@ -12,22 +12,28 @@ type AnthropicProfile struct {
 }
 
 // NewAnthropicProfile creates a profile for Anthropic models.
-func NewAnthropicProfile(model string) *AnthropicProfile {
+func NewAnthropicProfile(model string, opts ...ProfileOption) *AnthropicProfile {
+	cfg := applyProfileOptions(opts)
 	p := &AnthropicProfile{
 		BaseProfile: BaseProfile{
 			providerID:                "anthropic",
 			model:                     model,
 			registry:                  NewToolRegistry(),
 			supportsReasoning:         true,
-			supportsStreaming:          true,
-			supportsParallelToolCalls: true,
-			contextWindowSize:         200000,
+			supportsStreaming:         true,
+			supportsParallelToolCalls: cfg.boolOr(cfg.supportsParallelToolCalls, true),
+			contextWindowSize:         cfg.intOr(cfg.contextWindowSize, 200000),
+			providerOptionsOverride:   cfg.providerOptions,
+			promptFragments:           cfg.promptFragments,
+			toolAuthorizer:            cfg.toolAuthorizer,
 		},
 	}
 
 	// Register Claude Code-aligned core tools.
 	// Anthropic uses edit_file with old_string/new_string as the native format.
-	RegisterCoreTools(p.registry, 120000, 600000) // 120s default timeout per Claude Code convention.
+	defaultTimeoutMs := cfg.intOr(cfg.defaultCommandTimeoutMs, 120000) // 120s default timeout per Claude Code convention.
+	maxTimeoutMs := cfg.intOr(cfg.maxCommandTimeoutMs, 600000)
+	RegisterCoreTools(p.registry, defaultTimeoutMs, maxTimeoutMs, cfg.toolAllowlist...)
 
 	return p
 }
@@ -64,11 +70,15 @@ func (p *AnthropicProfile) BuildSystemPrompt(env ExecutionEnvironment, projectDo
 		sb.WriteString("\n\n")
 	}
 
-	return sb.String()
+	return p.appendPolicySummary(p.appendPromptFragments(sb.String()))
 }
 
-// ProviderOptions returns Anthropic-specific request options.
+// ProviderOptions returns Anthropic-specific request options, or the
+// config override if WithProviderOptions was applied.
 func (p *AnthropicProfile) ProviderOptions() map[string]interface{} {
+	if override := p.ProviderOptionsOverride(); override != nil {
+		return override
+	}
 	return map[string]interface{}{
 		"anthropic": map[string]interface{}{
 			"beta_headers": []string{"extended-thinking-2025-04-11"},
@@ -0,0 +1,285 @@
+package agentloop
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// profileOptions accumulates the ProfileOption values passed to a
+// NewXProfile constructor. Only the fields a caller actually sets differ
+// from each provider's hard-coded default, so every field is a pointer (or
+// nil slice/map) rather than a bare value.
+type profileOptions struct {
+	toolAllowlist             []string
+	defaultCommandTimeoutMs   *int
+	maxCommandTimeoutMs       *int
+	contextWindowSize         *int
+	supportsParallelToolCalls *bool
+	providerOptions           map[string]interface{}
+	promptFragments           []string
+	toolAuthorizer            ToolAuthorizer
+}
+
+// ProfileOption configures a NewXProfile constructor, the way
+// google.Option and anthropic.Option configure unifiedllm's provider
+// adapters. Each NewXProfile still has its own provider-specific
+// defaults; an option only overrides the fields it touches.
+type ProfileOption func(*profileOptions)
+
+// WithToolAllowlist restricts the profile's core tools to the named
+// subset, via RegisterCoreTools's allowlist. An empty or nil allowlist
+// (the default) registers every core tool.
+func WithToolAllowlist(names []string) ProfileOption {
+	return func(o *profileOptions) { o.toolAllowlist = names }
+}
+
+// WithCommandTimeouts overrides the profile's default and maximum shell
+// command timeouts, in milliseconds.
+func WithCommandTimeouts(defaultMs, maxMs int) ProfileOption {
+	return func(o *profileOptions) {
+		o.defaultCommandTimeoutMs = &defaultMs
+		o.maxCommandTimeoutMs = &maxMs
+	}
+}
+
+// WithContextWindowSize overrides the profile's reported context window
+// size, e.g. for a fine-tune or a self-hosted model with a different
+// window than the stock model it's based on.
+func WithContextWindowSize(size int) ProfileOption {
+	return func(o *profileOptions) { o.contextWindowSize = &size }
+}
+
+// WithSupportsParallelToolCalls overrides whether the profile reports
+// parallel tool call support.
+func WithSupportsParallelToolCalls(supports bool) ProfileOption {
+	return func(o *profileOptions) { o.supportsParallelToolCalls = &supports }
+}
+
+// WithProviderOptions overrides the profile's ProviderOptions() result
+// entirely, in place of its provider-specific default.
+func WithProviderOptions(opts map[string]interface{}) ProfileOption {
+	return func(o *profileOptions) { o.providerOptions = opts }
+}
+
+// WithPromptFragments appends each fragment to the profile's system
+// prompt, after the rest of the prompt BuildSystemPrompt assembles
+// (including project instructions) and before the tool access policy
+// summary.
+func WithPromptFragments(fragments []string) ProfileOption {
+	return func(o *profileOptions) { o.promptFragments = fragments }
+}
+
+// WithToolAuthorizer gates every tool call the profile's registry exposes
+// behind authorizer, consulted by Session.executeSingleTool before a tool
+// runs. A nil authorizer (the default) leaves every registered tool always
+// callable.
+func WithToolAuthorizer(authorizer ToolAuthorizer) ProfileOption {
+	return func(o *profileOptions) { o.toolAuthorizer = authorizer }
+}
+
+// applyProfileOptions folds opts into a profileOptions, in order.
+func applyProfileOptions(opts []ProfileOption) profileOptions {
+	var o profileOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+func (o profileOptions) intOr(ptr *int, def int) int {
+	if ptr != nil {
+		return *ptr
+	}
+	return def
+}
+
+func (o profileOptions) boolOr(ptr *bool, def bool) bool {
+	if ptr != nil {
+		return *ptr
+	}
+	return def
+}
+
+// ProfileConfig is the on-disk (YAML or JSON) description of a
+// ProviderProfile, for deployments that want to add or adjust agents
+// without recompiling attractor: which base model and provider to use,
+// what system-prompt fragments to layer on, which core tools it may call,
+// and overrides for the capability flags a hard-wired NewXProfile call
+// would otherwise fix at their provider defaults.
+type ProfileConfig struct {
+	Name                      string                 `yaml:"name" json:"name"`
+	Provider                  string                 `yaml:"provider" json:"provider"` // "openai", "anthropic", or "gemini"
+	Model                     string                 `yaml:"model" json:"model"`
+	SystemPromptFragments     []string               `yaml:"system_prompt_fragments,omitempty" json:"system_prompt_fragments,omitempty"`
+	Tools                     []string               `yaml:"tools,omitempty" json:"tools,omitempty"` // allowlist of core tool names; empty means all
+	DefaultCommandTimeoutMs   int                    `yaml:"default_command_timeout_ms,omitempty" json:"default_command_timeout_ms,omitempty"`
+	MaxCommandTimeoutMs       int                    `yaml:"max_command_timeout_ms,omitempty" json:"max_command_timeout_ms,omitempty"`
+	ContextWindowSize         int                    `yaml:"context_window_size,omitempty" json:"context_window_size,omitempty"`
+	SupportsParallelToolCalls *bool                  `yaml:"supports_parallel_tool_calls,omitempty" json:"supports_parallel_tool_calls,omitempty"`
+	ProviderOptions           map[string]interface{} `yaml:"provider_options,omitempty" json:"provider_options,omitempty"`
+
+	// WebhookTools are registered on top of the provider's core tools, so a
+	// deployment can wire this profile to business systems without writing
+	// Go code.
+	WebhookTools []WebhookToolConfig `yaml:"webhook_tools,omitempty" json:"webhook_tools,omitempty"`
+
+	// ToolPolicyPath, if set, is loaded via LoadToolPolicy and installed as
+	// this profile's ToolAuthorizer, so a deployment can restrict which
+	// tools a profile may call (and with which arguments) without writing
+	// Go code, the same way WebhookTools adds tools declaratively.
+	ToolPolicyPath string `yaml:"tool_policy_path,omitempty" json:"tool_policy_path,omitempty"`
+}
+
+// toProfileOptions converts the on-disk config into the ProfileOption
+// values BuildProfile passes to the chosen NewXProfile constructor.
+func (c ProfileConfig) toProfileOptions() []ProfileOption {
+	var opts []ProfileOption
+	if len(c.Tools) > 0 {
+		opts = append(opts, WithToolAllowlist(c.Tools))
+	}
+	// Each timeout is overridden only when set, so a config giving just one
+	// keeps the provider's default for the other.
+	if c.DefaultCommandTimeoutMs > 0 {
+		defaultMs := c.DefaultCommandTimeoutMs
+		opts = append(opts, func(o *profileOptions) { o.defaultCommandTimeoutMs = &defaultMs })
+	}
+	if c.MaxCommandTimeoutMs > 0 {
+		maxMs := c.MaxCommandTimeoutMs
+		opts = append(opts, func(o *profileOptions) { o.maxCommandTimeoutMs = &maxMs })
+	}
+	if c.ContextWindowSize > 0 {
+		opts = append(opts, WithContextWindowSize(c.ContextWindowSize))
+	}
+	if c.SupportsParallelToolCalls != nil {
+		opts = append(opts, WithSupportsParallelToolCalls(*c.SupportsParallelToolCalls))
+	}
+	if c.ProviderOptions != nil {
+		opts = append(opts, WithProviderOptions(c.ProviderOptions))
+	}
+	if len(c.SystemPromptFragments) > 0 {
+		opts = append(opts, WithPromptFragments(c.SystemPromptFragments))
+	}
+	return opts
+}
+
+// BuildProfile constructs the ProviderProfile cfg describes, dispatching to
+// the NewXProfile constructor matching cfg.Provider.
+func BuildProfile(cfg ProfileConfig) (ProviderProfile, error) {
+	opts := cfg.toProfileOptions()
+	if cfg.ToolPolicyPath != "" {
+		authorizer, err := LoadToolPolicy(cfg.ToolPolicyPath)
+		if err != nil {
+			return nil, fmt.Errorf("agentloop: profile %q: %w", cfg.Name, err)
+		}
+		opts = append(opts, WithToolAuthorizer(authorizer))
+	}
+
+	var profile ProviderProfile
+	switch strings.ToLower(cfg.Provider) {
+	case "openai":
+		profile = NewOpenAIProfile(cfg.Model, opts...)
+	case "anthropic":
+		profile = NewAnthropicProfile(cfg.Model, opts...)
+	case "gemini":
+		profile = NewGeminiProfile(cfg.Model, opts...)
+	default:
+		return nil, fmt.Errorf("agentloop: profile %q: unknown provider %q", cfg.Name, cfg.Provider)
+	}
+
+	for _, wt := range cfg.WebhookTools {
+		if err := RegisterWebhookTool(profile.ToolRegistry(), wt); err != nil {
+			return nil, fmt.Errorf("agentloop: profile %q: %w", cfg.Name, err)
+		}
+	}
+
+	return profile, nil
+}
+
+// LoadProfileConfigs parses a YAML or JSON document (selected by path's
+// extension: .json for JSON, anything else for YAML) containing a list of
+// ProfileConfig.
+func LoadProfileConfigs(path string) ([]ProfileConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("agentloop: read profile config %s: %w", path, err)
+	}
+
+	var configs []ProfileConfig
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		if err := json.Unmarshal(data, &configs); err != nil {
+			return nil, fmt.Errorf("agentloop: parse profile config %s: %w", path, err)
+		}
+		return configs, nil
+	}
+	if err := yaml.Unmarshal(data, &configs); err != nil {
+		return nil, fmt.Errorf("agentloop: parse profile config %s: %w", path, err)
+	}
+	return configs, nil
+}
+
+// LoadProfiles reads path via LoadProfileConfigs and builds a
+// ProviderProfile for each entry.
+func LoadProfiles(path string) ([]ProviderProfile, error) {
+	configs, err := LoadProfileConfigs(path)
+	if err != nil {
+		return nil, err
+	}
+	profiles := make([]ProviderProfile, len(configs))
+	for i, cfg := range configs {
+		profile, err := BuildProfile(cfg)
+		if err != nil {
+			return nil, err
+		}
+		profiles[i] = profile
+	}
+	return profiles, nil
+}
+
+// ProfileRegistry looks up a config-driven ProviderProfile by name, the
+// selector a CLI or API handler uses to let a caller pick an agent without
+// hardcoding which NewXProfile call it wants.
+type ProfileRegistry struct {
+	profiles map[string]ProviderProfile
+}
+
+// NewProfileRegistry builds a ProfileRegistry from path via
+// LoadProfileConfigs and BuildProfile, keyed by each ProfileConfig's Name.
+func NewProfileRegistry(path string) (*ProfileRegistry, error) {
+	configs, err := LoadProfileConfigs(path)
+	if err != nil {
+		return nil, err
+	}
+	reg := &ProfileRegistry{profiles: make(map[string]ProviderProfile, len(configs))}
+	for _, cfg := range configs {
+		profile, err := BuildProfile(cfg)
+		if err != nil {
+			return nil, err
+		}
+		if cfg.Name == "" {
+			return nil, fmt.Errorf("agentloop: profile for model %q has no name", cfg.Model)
+		}
+		reg.profiles[cfg.Name] = profile
+	}
+	return reg, nil
+}
+
+// Get returns the named profile, or nil and false if no profile was
+// registered under that name.
+func (r *ProfileRegistry) Get(name string) (ProviderProfile, bool) {
+	p, ok := r.profiles[name]
+	return p, ok
+}
+
+// Names returns the names of all registered profiles.
+func (r *ProfileRegistry) Names() []string {
+	names := make([]string, 0, len(r.profiles))
+	for name := range r.profiles {
+		names = append(names, name)
+	}
+	return names
+}
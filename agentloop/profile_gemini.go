@@ -12,21 +12,27 @@ type GeminiProfile struct {
 }
 
 // NewGeminiProfile creates a profile for Gemini models.
-func NewGeminiProfile(model string) *GeminiProfile {
+func NewGeminiProfile(model string, opts ...ProfileOption) *GeminiProfile {
+	cfg := applyProfileOptions(opts)
 	p := &GeminiProfile{
 		BaseProfile: BaseProfile{
 			providerID:                "gemini",
 			model:                     model,
 			registry:                  NewToolRegistry(),
 			supportsReasoning:         true,
-			supportsStreaming:          true,
-			supportsParallelToolCalls: true,
-			contextWindowSize:         1048576,
+			supportsStreaming:         true,
+			supportsParallelToolCalls: cfg.boolOr(cfg.supportsParallelToolCalls, true),
+			contextWindowSize:         cfg.intOr(cfg.contextWindowSize, 1048576),
+			providerOptionsOverride:   cfg.providerOptions,
+			promptFragments:           cfg.promptFragments,
+			toolAuthorizer:            cfg.toolAuthorizer,
 		},
 	}
 
 	// Register gemini-cli-aligned core tools.
-	RegisterCoreTools(p.registry, 10000, 600000) // 10s default timeout per gemini-cli convention.
+	defaultTimeoutMs := cfg.intOr(cfg.defaultCommandTimeoutMs, 10000) // 10s default timeout per gemini-cli convention.
+	maxTimeoutMs := cfg.intOr(cfg.maxCommandTimeoutMs, 600000)
+	RegisterCoreTools(p.registry, defaultTimeoutMs, maxTimeoutMs, cfg.toolAllowlist...)
 
 	return p
 }
@@ -63,11 +69,15 @@ func (p *GeminiProfile) BuildSystemPrompt(env ExecutionEnvironment, projectDocs
 		sb.WriteString("\n\n")
 	}
 
-	return sb.String()
+	return p.appendPolicySummary(p.appendPromptFragments(sb.String()))
 }
 
-// ProviderOptions returns Gemini-specific request options.
+// ProviderOptions returns Gemini-specific request options, or the config
+// override if WithProviderOptions was applied.
 func (p *GeminiProfile) ProviderOptions() map[string]interface{} {
+	if override := p.ProviderOptionsOverride(); override != nil {
+		return override
+	}
 	return map[string]interface{}{
 		"gemini": map[string]interface{}{
 			"safety_settings": "default",
@@ -12,25 +12,34 @@ type OpenAIProfile struct {
 }
 
 // NewOpenAIProfile creates a profile for OpenAI models.
-func NewOpenAIProfile(model string) *OpenAIProfile {
+func NewOpenAIProfile(model string, opts ...ProfileOption) *OpenAIProfile {
+	cfg := applyProfileOptions(opts)
 	p := &OpenAIProfile{
 		BaseProfile: BaseProfile{
 			providerID:                "openai",
 			model:                     model,
 			registry:                  NewToolRegistry(),
 			supportsReasoning:         true,
-			supportsStreaming:          true,
-			supportsParallelToolCalls: true,
-			contextWindowSize:         1047576,
+			supportsStreaming:         true,
+			supportsParallelToolCalls: cfg.boolOr(cfg.supportsParallelToolCalls, true),
+			contextWindowSize:         cfg.intOr(cfg.contextWindowSize, 1047576),
+			providerOptionsOverride:   cfg.providerOptions,
+			promptFragments:           cfg.promptFragments,
+			toolAuthorizer:            cfg.toolAuthorizer,
 		},
 	}
 
 	// Register codex-rs-aligned core tools.
 	// OpenAI uses apply_patch as the primary editing tool (v4a format).
-	RegisterCoreTools(p.registry, 10000, 600000) // 10s default timeout per codex-rs convention.
-
-	// Add apply_patch (the native OpenAI editing format).
-	RegisterApplyPatch(p.registry)
+	defaultTimeoutMs := cfg.intOr(cfg.defaultCommandTimeoutMs, 10000) // 10s default timeout per codex-rs convention.
+	maxTimeoutMs := cfg.intOr(cfg.maxCommandTimeoutMs, 600000)
+	RegisterCoreTools(p.registry, defaultTimeoutMs, maxTimeoutMs, cfg.toolAllowlist...)
+
+	// Add apply_patch (the native OpenAI editing format), unless the
+	// config's allowlist excludes it.
+	if coreToolAllowed("apply_patch", cfg.toolAllowlist) {
+		RegisterApplyPatch(p.registry)
+	}
 
 	return p
 }
@@ -67,11 +76,15 @@ func (p *OpenAIProfile) BuildSystemPrompt(env ExecutionEnvironment, projectDocs
 		sb.WriteString("\n\n")
 	}
 
-	return sb.String()
+	return p.appendPolicySummary(p.appendPromptFragments(sb.String()))
 }
 
-// ProviderOptions returns OpenAI-specific request options.
+// ProviderOptions returns OpenAI-specific request options, or the config
+// override if WithProviderOptions was applied.
 func (p *OpenAIProfile) ProviderOptions() map[string]interface{} {
+	if override := p.ProviderOptionsOverride(); override != nil {
+		return override
+	}
 	return nil
 }
 
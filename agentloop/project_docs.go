@@ -0,0 +1,345 @@
+package agentloop
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+const (
+	maxProjectDocFileBytes  = 1 << 20 // 1MB per file
+	maxProjectDocTotalBytes = maxProjectDocBytes
+)
+
+// ProjectDoc is a single loaded project instruction file, alongside the
+// string rendering DiscoverProjectDocs returns for the system prompt.
+type ProjectDoc struct {
+	Path      string // absolute path to the file
+	Dir       string // directory containing the file
+	Content   string
+	Bytes     int  // bytes of Content, after any truncation
+	Truncated bool // true if the file exceeded its byte cap
+}
+
+// DiscoverOptions configures DiscoverProjectDocsWithOptions.
+type DiscoverOptions struct {
+	// MaxFileBytes caps how much of any single file is read. 0 means
+	// maxProjectDocFileBytes (1MB).
+	MaxFileBytes int
+	// MaxTotalBytes caps the combined size of every loaded file. 0 means
+	// maxProjectDocTotalBytes (32KB, matching the historical system-prompt
+	// budget).
+	MaxTotalBytes int
+	// FollowSymlinks allows following a project-doc symlink that resolves
+	// inside gitRoot. Symlinks that resolve outside gitRoot are always
+	// refused, regardless of this setting.
+	FollowSymlinks bool
+	// ExtraFilenames adds filenames to recognize beyond the provider's
+	// defaults (AGENTS.md, plus the provider-specific file).
+	ExtraFilenames []string
+	// AdditionalRoots walks each given directory's own git-root-to-itself
+	// hierarchy as an extra source of project docs, for multi-repo
+	// workspaces where the agent's working directory isn't the only repo
+	// in play.
+	AdditionalRoots []string
+}
+
+// DefaultDiscoverOptions returns DiscoverOptions with the historical
+// DiscoverProjectDocs defaults: a 1MB per-file cap, a 32KB total cap, and
+// symlinks not followed.
+func DefaultDiscoverOptions() DiscoverOptions {
+	return DiscoverOptions{MaxFileBytes: maxProjectDocFileBytes, MaxTotalBytes: maxProjectDocTotalBytes}
+}
+
+// DiscoverProjectDocs finds and loads project instruction files, rendered as
+// a single string for the system prompt. It walks from the git root (or
+// working directory) looking for recognized instruction files and loads
+// them according to the provider filter.
+func DiscoverProjectDocs(workingDir string, providerFilter string) string {
+	docs := DiscoverProjectDocsWithOptions(workingDir, providerFilter, DefaultDiscoverOptions())
+	return RenderProjectDocs(docs)
+}
+
+// DiscoverProjectDocsWithOptions is DiscoverProjectDocs' structured form: it
+// returns one ProjectDoc per loaded file instead of a pre-rendered string,
+// so callers can inspect what was loaded (and from where) before deciding
+// how to present it. Docs are ordered root→leaf, then filename, with the
+// primary hierarchy (rooted at gitRoot(workingDir), or workingDir itself
+// outside a repo) first, followed by each of opts.AdditionalRoots in order.
+//
+// A .gitignore found anywhere in a hierarchy is honored for the rest of
+// that hierarchy: a directory or file it ignores is skipped even if it
+// would otherwise match a recognized filename.
+func DiscoverProjectDocsWithOptions(workingDir string, providerFilter string, opts DiscoverOptions) []ProjectDoc {
+	if opts.MaxFileBytes <= 0 {
+		opts.MaxFileBytes = maxProjectDocFileBytes
+	}
+	if opts.MaxTotalBytes <= 0 {
+		opts.MaxTotalBytes = maxProjectDocTotalBytes
+	}
+
+	filenames := recognizedProjectDocFilenames(providerFilter, opts.ExtraFilenames)
+
+	root := gitRoot(workingDir)
+	if root == "" {
+		root = workingDir
+	}
+
+	var docs []ProjectDoc
+	totalBytes := 0
+	docs = appendHierarchyDocs(docs, root, workingDir, filenames, opts, &totalBytes)
+	for _, extraRoot := range opts.AdditionalRoots {
+		extraGitRoot := gitRoot(extraRoot)
+		if extraGitRoot == "" {
+			extraGitRoot = extraRoot
+		}
+		docs = appendHierarchyDocs(docs, extraGitRoot, extraRoot, filenames, opts, &totalBytes)
+	}
+	return docs
+}
+
+// RenderProjectDocs renders docs the way DiscoverProjectDocs has always
+// rendered them: each file under a "# filename (from dir)" header, joined
+// by a horizontal rule, with a truncation notice after each doc whose
+// content was cut short (by the per-file cap or the total byte budget).
+// Files skipped entirely because the budget was already spent get no notice.
+func RenderProjectDocs(docs []ProjectDoc) string {
+	if len(docs) == 0 {
+		return ""
+	}
+	var rendered []string
+	for _, doc := range docs {
+		header := fmt.Sprintf("# %s (from %s)", filepath.Base(doc.Path), doc.Dir)
+		text := doc.Content
+		if doc.Truncated {
+			text += "\n[Project instructions truncated]"
+		}
+		rendered = append(rendered, header+"\n\n"+text)
+	}
+	return strings.Join(rendered, "\n\n---\n\n")
+}
+
+// recognizedProjectDocFilenames returns the sorted, deduplicated set of
+// filenames DiscoverProjectDocs loads: AGENTS.md (always), the provider's
+// own file, and any caller-supplied extras.
+func recognizedProjectDocFilenames(providerFilter string, extra []string) []string {
+	set := map[string]bool{"AGENTS.md": true}
+	switch providerFilter {
+	case "anthropic":
+		set["CLAUDE.md"] = true
+	case "gemini":
+		set["GEMINI.md"] = true
+	case "openai":
+		set[".codex/instructions.md"] = true
+	}
+	for _, name := range extra {
+		set[name] = true
+	}
+
+	names := make([]string, 0, len(set))
+	for name := range set {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// appendHierarchyDocs loads filenames from every directory between root and
+// target (inclusive, root first), appending each successfully loaded file
+// to docs in root→leaf, then filename order, and stops once
+// opts.MaxTotalBytes has been spent.
+func appendHierarchyDocs(docs []ProjectDoc, root, target string, filenames []string, opts DiscoverOptions, totalBytes *int) []ProjectDoc {
+	ignore := loadGitignoreHierarchy(root, target)
+
+	for _, dir := range collectPathHierarchy(root, target) {
+		if ignore.matchDir(root, dir) {
+			continue
+		}
+		for _, fileName := range filenames {
+			if *totalBytes >= opts.MaxTotalBytes {
+				return docs
+			}
+			path := filepath.Join(dir, fileName)
+			if ignore.match(root, path) {
+				continue
+			}
+
+			resolved, ok := resolveProjectDocPath(root, path, opts.FollowSymlinks)
+			if !ok {
+				continue
+			}
+
+			remaining := opts.MaxTotalBytes - *totalBytes
+			limit := opts.MaxFileBytes
+			if remaining < limit {
+				limit = remaining
+			}
+			content, truncated, err := readBoundedFile(resolved, int64(limit))
+			if err != nil {
+				continue
+			}
+
+			docs = append(docs, ProjectDoc{
+				Path: path, Dir: dir, Content: content, Bytes: len(content), Truncated: truncated,
+			})
+			*totalBytes += len(content)
+		}
+	}
+	return docs
+}
+
+// resolveProjectDocPath stats path, refusing to read it if it's a symlink
+// that either isn't allowed to be followed or resolves outside root.
+func resolveProjectDocPath(root, path string, followSymlinks bool) (string, bool) {
+	info, err := os.Lstat(path)
+	if err != nil {
+		return "", false
+	}
+	if info.Mode()&os.ModeSymlink == 0 {
+		return path, true
+	}
+	if !followSymlinks {
+		return "", false
+	}
+
+	resolved, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		return "", false
+	}
+	rel, err := filepath.Rel(root, resolved)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", false
+	}
+	return resolved, true
+}
+
+// readBoundedFile reads up to limit bytes of the file at path, reporting
+// whether the file was truncated. It reads through an io.LimitReader rather
+// than os.ReadFile so an oversized file (a 10GB AGENTS.md, say) can't pull
+// its entire content into memory first.
+func readBoundedFile(path string, limit int64) (content string, truncated bool, err error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", false, err
+	}
+	defer f.Close()
+
+	// Read one byte past limit so we can tell a file that exactly fills the
+	// budget apart from one that overflows it.
+	data, err := io.ReadAll(io.LimitReader(f, limit+1))
+	if err != nil {
+		return "", false, err
+	}
+	if int64(len(data)) > limit {
+		return string(data[:limit]), true, nil
+	}
+	return string(data), false, nil
+}
+
+// gitignoreMatcher holds the cumulative .gitignore patterns collected while
+// walking a hierarchy, keyed by the directory the pattern was declared in.
+type gitignoreMatcher struct {
+	patterns []gitignorePattern
+}
+
+type gitignorePattern struct {
+	dir      string // directory the .gitignore declaring this pattern lives in
+	pattern  string
+	negate   bool
+	dirOnly  bool
+	anchored bool
+}
+
+// loadGitignoreHierarchy reads a .gitignore from every directory between
+// root and target (inclusive), in root-to-leaf order, so patterns declared
+// deeper in the tree can override ones from a parent.
+func loadGitignoreHierarchy(root, target string) *gitignoreMatcher {
+	m := &gitignoreMatcher{}
+	for _, dir := range collectPathHierarchy(root, target) {
+		m.loadFile(dir)
+	}
+	return m
+}
+
+func (m *gitignoreMatcher) loadFile(dir string) {
+	f, err := os.Open(filepath.Join(dir, ".gitignore"))
+	if err != nil {
+		return
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		p := gitignorePattern{dir: dir}
+		if strings.HasPrefix(line, "!") {
+			p.negate = true
+			line = line[1:]
+		}
+		if strings.HasSuffix(line, "/") {
+			p.dirOnly = true
+			line = strings.TrimSuffix(line, "/")
+		}
+		if strings.HasPrefix(line, "/") {
+			p.anchored = true
+			line = strings.TrimPrefix(line, "/")
+		}
+		p.pattern = line
+		m.patterns = append(m.patterns, p)
+	}
+}
+
+// match reports whether path is ignored under root, per the last matching
+// pattern (gitignore's "last match wins", honoring "!" negations).
+func (m *gitignoreMatcher) match(root, path string) bool {
+	return m.matches(root, path, false)
+}
+
+// matchDir is match for a directory: it also matches dirOnly patterns.
+func (m *gitignoreMatcher) matchDir(root, dir string) bool {
+	return m.matches(root, dir, true)
+}
+
+func (m *gitignoreMatcher) matches(root, path string, isDir bool) bool {
+	ignored := false
+	for _, p := range m.patterns {
+		if p.dirOnly && !isDir {
+			continue
+		}
+		if !m.patternMatches(p, root, path) {
+			continue
+		}
+		ignored = !p.negate
+	}
+	return ignored
+}
+
+func (m *gitignoreMatcher) patternMatches(p gitignorePattern, root, path string) bool {
+	rel, err := filepath.Rel(p.dir, path)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return false
+	}
+	rel = filepath.ToSlash(rel)
+
+	if p.anchored {
+		matched, _ := filepath.Match(p.pattern, rel)
+		return matched
+	}
+	// Unanchored: match against the full relative path or any path segment.
+	if matched, _ := filepath.Match(p.pattern, rel); matched {
+		return true
+	}
+	for _, segment := range strings.Split(rel, "/") {
+		if matched, _ := filepath.Match(p.pattern, segment); matched {
+			return true
+		}
+	}
+	return false
+}
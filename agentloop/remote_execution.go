@@ -0,0 +1,259 @@
+package agentloop
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+
+	"github.com/martinemde/attractor/agentloop/executorpb"
+)
+
+// RemoteExecutionEnvironment implements ExecutionEnvironment by dialing a
+// gRPC executor plugin server (see proto/executor.proto and
+// cmd/executor-server), the way a SandboxedExecutionEnvironment runs tools in
+// a Linux namespace/cgroup but over the network instead: inside a VM, a
+// container, or at the far end of an SSH-tunneled unix socket.
+//
+// WorkingDirectory/Platform/OSVersion are filled in from the server's
+// Handshake response during Initialize, so ProviderProfile.BuildSystemPrompt
+// reports the remote environment rather than the host's runtime.GOOS.
+type RemoteExecutionEnvironment struct {
+	target   string
+	dialOpts []grpc.DialOption
+
+	conn   *grpc.ClientConn
+	client executorpb.ExecutorClient
+
+	workingDir string
+	platform   string
+	osVersion  string
+}
+
+// NewRemoteExecutionEnvironment creates a client for the executor plugin
+// server listening at target (a standard grpc.Dial target: "host:port" for
+// TCP, or "unix:/path/to.sock" for an SSH-tunneled socket). Call Initialize
+// before using it.
+func NewRemoteExecutionEnvironment(target string, dialOpts ...grpc.DialOption) *RemoteExecutionEnvironment {
+	return &RemoteExecutionEnvironment{
+		target:   target,
+		dialOpts: dialOpts,
+	}
+}
+
+func (e *RemoteExecutionEnvironment) Initialize() error {
+	opts := e.dialOpts
+	if len(opts) == 0 {
+		opts = []grpc.DialOption{grpc.WithTransportCredentials(insecure.NewCredentials())}
+	}
+
+	conn, err := grpc.NewClient(e.target, opts...)
+	if err != nil {
+		return fmt.Errorf("remote_execution: dial %s: %w", e.target, err)
+	}
+	e.conn = conn
+	e.client = executorpb.NewExecutorClient(conn)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	resp, err := e.client.Handshake(ctx, &executorpb.HandshakeRequest{})
+	if err != nil {
+		_ = conn.Close()
+		return fmt.Errorf("remote_execution: handshake: %w", err)
+	}
+	e.workingDir = resp.WorkingDirectory
+	e.platform = resp.Platform
+	e.osVersion = resp.OsVersion
+	return nil
+}
+
+func (e *RemoteExecutionEnvironment) Cleanup() error {
+	if e.conn == nil {
+		return nil
+	}
+	return e.conn.Close()
+}
+
+func (e *RemoteExecutionEnvironment) WorkingDirectory() string {
+	return e.workingDir
+}
+
+func (e *RemoteExecutionEnvironment) Platform() string {
+	return e.platform
+}
+
+func (e *RemoteExecutionEnvironment) OSVersion() string {
+	return e.osVersion
+}
+
+func (e *RemoteExecutionEnvironment) ReadFile(path string, offset, limit int) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	resp, err := e.client.ReadFile(ctx, &executorpb.ReadFileRequest{
+		Path:   path,
+		Offset: int32(offset),
+		Limit:  int32(limit),
+	})
+	if err != nil {
+		return "", fmt.Errorf("remote_execution: read_file: %w", err)
+	}
+	return resp.Content, nil
+}
+
+func (e *RemoteExecutionEnvironment) WriteFile(path string, content string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	_, err := e.client.WriteFile(ctx, &executorpb.WriteFileRequest{Path: path, Content: content})
+	if err != nil {
+		return fmt.Errorf("remote_execution: write_file: %w", err)
+	}
+	return nil
+}
+
+func (e *RemoteExecutionEnvironment) DeleteFile(path string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	_, err := e.client.DeleteFile(ctx, &executorpb.DeleteFileRequest{Path: path})
+	if err != nil {
+		return fmt.Errorf("remote_execution: delete_file: %w", err)
+	}
+	return nil
+}
+
+func (e *RemoteExecutionEnvironment) RenameFile(oldPath, newPath string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	_, err := e.client.RenameFile(ctx, &executorpb.RenameFileRequest{OldPath: oldPath, NewPath: newPath})
+	if err != nil {
+		return fmt.Errorf("remote_execution: rename_file: %w", err)
+	}
+	return nil
+}
+
+func (e *RemoteExecutionEnvironment) FileExists(path string) bool {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	resp, err := e.client.FileExists(ctx, &executorpb.FileExistsRequest{Path: path})
+	if err != nil {
+		return false
+	}
+	return resp.Exists
+}
+
+func (e *RemoteExecutionEnvironment) ListDirectory(path string, depth int) ([]DirEntry, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	resp, err := e.client.ListDirectory(ctx, &executorpb.ListDirectoryRequest{Path: path, Depth: int32(depth)})
+	if err != nil {
+		return nil, fmt.Errorf("remote_execution: list_directory: %w", err)
+	}
+
+	entries := make([]DirEntry, len(resp.Entries))
+	for i, de := range resp.Entries {
+		entries[i] = DirEntry{Name: de.Name, IsDir: de.IsDir, Size: de.Size}
+	}
+	return entries, nil
+}
+
+// ExecCommand streams ExecChunk frames from the server and assembles them
+// into the buffered result expected by the ExecutionEnvironment interface;
+// use ExecCommandStreaming to also see each output frame as it arrives.
+// Cancelling ctx closes the stream, which signals the server to kill the
+// remote process group.
+func (e *RemoteExecutionEnvironment) ExecCommand(ctx context.Context, command string, timeoutMs int, workingDir string, envVars map[string]string) (*ExecResult, error) {
+	return e.execCommand(ctx, command, timeoutMs, workingDir, envVars, nil)
+}
+
+// ExecCommandStreaming is like ExecCommand but additionally invokes onOutput
+// for every stdout/stderr chunk as it is received, before the command
+// completes. Host applications use this to turn remote output into
+// EventToolCallOutputDelta events without waiting for the full buffer.
+func (e *RemoteExecutionEnvironment) ExecCommandStreaming(ctx context.Context, command string, timeoutMs int, workingDir string, envVars map[string]string, onOutput func(stderr bool, chunk []byte)) (*ExecResult, error) {
+	return e.execCommand(ctx, command, timeoutMs, workingDir, envVars, onOutput)
+}
+
+func (e *RemoteExecutionEnvironment) execCommand(ctx context.Context, command string, timeoutMs int, workingDir string, envVars map[string]string, onOutput func(stderr bool, chunk []byte)) (*ExecResult, error) {
+	if timeoutMs > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, time.Duration(timeoutMs)*time.Millisecond)
+		defer cancel()
+	}
+
+	stream, err := e.client.ExecCommand(ctx, &executorpb.ExecCommandRequest{
+		Command:    command,
+		TimeoutMs:  int32(timeoutMs),
+		WorkingDir: workingDir,
+		EnvVars:    envVars,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("remote_execution: exec_command: %w", err)
+	}
+
+	var stdout, stderr []byte
+	for {
+		chunk, err := stream.Recv()
+		if err == io.EOF {
+			return nil, fmt.Errorf("remote_execution: exec_command: stream closed before done frame")
+		}
+		if err != nil {
+			if ctx.Err() == context.DeadlineExceeded {
+				return &ExecResult{Stdout: string(stdout), Stderr: string(stderr), TimedOut: true, ExitCode: -1}, nil
+			}
+			return nil, fmt.Errorf("remote_execution: exec_command: %w", err)
+		}
+
+		if chunk.Done {
+			return &ExecResult{
+				Stdout:     string(stdout),
+				Stderr:     string(stderr),
+				ExitCode:   int(chunk.ExitCode),
+				TimedOut:   chunk.TimedOut,
+				DurationMs: chunk.DurationMs,
+			}, nil
+		}
+
+		if chunk.StreamType == executorpb.ExecChunk_STDERR {
+			stderr = append(stderr, chunk.Data...)
+		} else {
+			stdout = append(stdout, chunk.Data...)
+		}
+		if onOutput != nil {
+			onOutput(chunk.StreamType == executorpb.ExecChunk_STDERR, chunk.Data)
+		}
+	}
+}
+
+func (e *RemoteExecutionEnvironment) Grep(ctx context.Context, pattern string, path string, options GrepOptions) (string, error) {
+	resp, err := e.client.Grep(ctx, &executorpb.GrepRequest{
+		Pattern:         pattern,
+		Path:            path,
+		GlobFilter:      options.GlobFilter,
+		CaseInsensitive: options.CaseInsensitive,
+		MaxResults:      int32(options.MaxResults),
+	})
+	if err != nil {
+		return "", fmt.Errorf("remote_execution: grep: %w", err)
+	}
+	return resp.Output, nil
+}
+
+func (e *RemoteExecutionEnvironment) Glob(pattern string, path string) ([]string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	resp, err := e.client.Glob(ctx, &executorpb.GlobRequest{Pattern: pattern, Path: path})
+	if err != nil {
+		return nil, fmt.Errorf("remote_execution: glob: %w", err)
+	}
+	return resp.Matches, nil
+}
@@ -0,0 +1,52 @@
+package agentloop
+
+// BindMount describes a host path bind-mounted into the sandbox rootfs.
+type BindMount struct {
+	Source   string `json:"source"`
+	Target   string `json:"target"`
+	ReadOnly bool   `json:"read_only"`
+}
+
+// SandboxConfig configures a SandboxedExecutionEnvironment.
+type SandboxConfig struct {
+	// RootfsPath is the chroot/rootfs directory for the sandboxed process.
+	// If empty, the host root is reused and isolation relies solely on
+	// namespaces, cgroup limits, and BindMounts.
+	RootfsPath string `json:"rootfs_path,omitempty"`
+
+	// BindMounts are mounted into the sandbox in addition to RootfsPath.
+	BindMounts []BindMount `json:"bind_mounts,omitempty"`
+
+	// MemoryLimitBytes sets cgroup memory.max. 0 means unlimited.
+	MemoryLimitBytes int64 `json:"memory_limit_bytes,omitempty"`
+
+	// CPUQuotaMicros sets cgroup cpu.max quota (in microseconds per 100ms
+	// period). 0 means unlimited.
+	CPUQuotaMicros int64 `json:"cpu_quota_micros,omitempty"`
+
+	// PidsLimit sets cgroup pids.max. 0 means unlimited.
+	PidsLimit int64 `json:"pids_limit,omitempty"`
+
+	// AllowedDevices is an allow-list of device paths made available inside
+	// the sandbox (e.g. "/dev/null", "/dev/urandom").
+	AllowedDevices []string `json:"allowed_devices,omitempty"`
+
+	// EnableNetwork, when true, leaves the sandboxed command in the host's
+	// network namespace; when false it gets a new, isolated network
+	// namespace with no external connectivity.
+	EnableNetwork bool `json:"enable_network"`
+
+	// ReadOnlyOverlay mounts the working directory as a read-only overlay
+	// with an ephemeral tmpfs upper layer, so tool commands cannot persist
+	// changes back to the host working directory.
+	ReadOnlyOverlay bool `json:"read_only_overlay"`
+
+	// CgroupParent is the parent cgroup v2 path under which a per-session
+	// subtree is created. Defaults to "/sys/fs/cgroup/attractor" when empty.
+	CgroupParent string `json:"cgroup_parent,omitempty"`
+}
+
+// sandboxCgroupIDKey is the SessionEvent data key for a cgroup ID, the key
+// BPFCollector's syscall events report it under. The tool-call events of
+// commands run by SandboxedExecutionEnvironment do not carry a cgroup ID.
+const sandboxCgroupIDKey = "cgroup_id"
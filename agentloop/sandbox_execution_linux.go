@@ -0,0 +1,487 @@
+//go:build linux
+
+package agentloop
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// sandboxReexecArg is the sentinel first argument that tells the current
+// binary to run as the sandbox re-exec helper instead of the normal host
+// application. A hosting main() must call SandboxReexecMain before parsing
+// its own flags; see SandboxReexecMain for details.
+const sandboxReexecArg = "__attractor_sandbox_reexec__"
+
+const defaultCgroupParent = "/sys/fs/cgroup/attractor"
+
+// SandboxedExecutionEnvironment runs tool commands inside an isolated child
+// process using Linux namespaces (mount, pid, net, uts, ipc, user) and a
+// cgroup v2 subtree for resource accounting and limits.
+type SandboxedExecutionEnvironment struct {
+	workingDir string
+	config     SandboxConfig
+	cgroupPath string
+	sessionID  string
+
+	policy        CommandPolicy
+	policyWarning func(reason, node string)
+}
+
+// SetCommandPolicy installs a CommandPolicy consulted by ExecCommand before
+// every command runs. A nil policy (the default) allows everything.
+func (e *SandboxedExecutionEnvironment) SetCommandPolicy(policy CommandPolicy) {
+	e.policy = policy
+}
+
+// SetPolicyWarning installs a callback invoked with the denial reason and
+// offending AST node whenever CommandPolicy denies a command, so a host
+// application (typically Session) can surface it as an EventWarning.
+func (e *SandboxedExecutionEnvironment) SetPolicyWarning(fn func(reason, node string)) {
+	e.policyWarning = fn
+}
+
+// NewSandboxedExecutionEnvironment creates a sandboxed execution environment
+// rooted at workingDir. It is only supported on Linux; on other platforms use
+// NewLocalExecutionEnvironment or a remote ExecutionEnvironment instead.
+func NewSandboxedExecutionEnvironment(workingDir string, config SandboxConfig) (*SandboxedExecutionEnvironment, error) {
+	if workingDir == "" {
+		var err error
+		workingDir, err = os.Getwd()
+		if err != nil {
+			return nil, fmt.Errorf("sandboxed_execution_environment: %w", err)
+		}
+	}
+	if config.CgroupParent == "" {
+		config.CgroupParent = defaultCgroupParent
+	}
+	return &SandboxedExecutionEnvironment{
+		workingDir: workingDir,
+		config:     config,
+		sessionID:  uuid.New().String(),
+	}, nil
+}
+
+func (e *SandboxedExecutionEnvironment) cgroupDir() string {
+	return filepath.Join(e.config.CgroupParent, "sess-"+e.sessionID)
+}
+
+// Initialize creates the cgroup v2 subtree and applies the configured
+// resource limits. It must be called before ExecCommand.
+func (e *SandboxedExecutionEnvironment) Initialize() error {
+	if err := os.MkdirAll(e.workingDir, 0755); err != nil {
+		return err
+	}
+
+	dir := e.cgroupDir()
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("sandboxed_execution_environment: create cgroup %s: %w", dir, err)
+	}
+	e.cgroupPath = dir
+
+	if e.config.MemoryLimitBytes > 0 {
+		if err := writeCgroupFile(dir, "memory.max", strconv.FormatInt(e.config.MemoryLimitBytes, 10)); err != nil {
+			return err
+		}
+	}
+	if e.config.CPUQuotaMicros > 0 {
+		if err := writeCgroupFile(dir, "cpu.max", fmt.Sprintf("%d 100000", e.config.CPUQuotaMicros)); err != nil {
+			return err
+		}
+	}
+	if e.config.PidsLimit > 0 {
+		if err := writeCgroupFile(dir, "pids.max", strconv.FormatInt(e.config.PidsLimit, 10)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func writeCgroupFile(cgroupDir, name, value string) error {
+	path := filepath.Join(cgroupDir, name)
+	if err := os.WriteFile(path, []byte(value), 0644); err != nil {
+		return fmt.Errorf("sandboxed_execution_environment: write %s: %w", path, err)
+	}
+	return nil
+}
+
+// Cleanup removes the cgroup subtree. The read-only overlay's tmpfs mounts
+// are made inside each command's own mount namespace and go away with it
+// when the command exits; the empty attractor-overlay-* directories they
+// were mounted on are not removed.
+func (e *SandboxedExecutionEnvironment) Cleanup() error {
+	if e.cgroupPath == "" {
+		return nil
+	}
+	// cgroup v2 directories can only be removed once they contain no
+	// processes; the re-exec helper's process should have already exited.
+	if err := os.Remove(e.cgroupPath); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("sandboxed_execution_environment: remove cgroup %s: %w", e.cgroupPath, err)
+	}
+	return nil
+}
+
+func (e *SandboxedExecutionEnvironment) WorkingDirectory() string { return e.workingDir }
+func (e *SandboxedExecutionEnvironment) Platform() string         { return "linux" }
+func (e *SandboxedExecutionEnvironment) OSVersion() string        { return "linux/" + runtime.GOARCH }
+
+func (e *SandboxedExecutionEnvironment) resolvePath(path string) string {
+	if filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(e.workingDir, path)
+}
+
+// ReadFile, WriteFile, FileExists, ListDirectory, Grep, and Glob operate on
+// the same rootfs the sandbox bind-mounts from, so they reuse the local
+// implementations; only ExecCommand is actually isolated by namespaces.
+
+func (e *SandboxedExecutionEnvironment) ReadFile(path string, offset, limit int) (string, error) {
+	return (&LocalExecutionEnvironment{workingDir: e.workingDir}).ReadFile(path, offset, limit)
+}
+
+func (e *SandboxedExecutionEnvironment) WriteFile(path string, content string) error {
+	return (&LocalExecutionEnvironment{workingDir: e.workingDir}).WriteFile(path, content)
+}
+
+func (e *SandboxedExecutionEnvironment) DeleteFile(path string) error {
+	return (&LocalExecutionEnvironment{workingDir: e.workingDir}).DeleteFile(path)
+}
+
+func (e *SandboxedExecutionEnvironment) RenameFile(oldPath, newPath string) error {
+	return (&LocalExecutionEnvironment{workingDir: e.workingDir}).RenameFile(oldPath, newPath)
+}
+
+func (e *SandboxedExecutionEnvironment) FileExists(path string) bool {
+	return (&LocalExecutionEnvironment{workingDir: e.workingDir}).FileExists(path)
+}
+
+func (e *SandboxedExecutionEnvironment) ListDirectory(path string, depth int) ([]DirEntry, error) {
+	return (&LocalExecutionEnvironment{workingDir: e.workingDir}).ListDirectory(path, depth)
+}
+
+func (e *SandboxedExecutionEnvironment) Grep(ctx context.Context, pattern string, path string, options GrepOptions) (string, error) {
+	return (&LocalExecutionEnvironment{workingDir: e.workingDir}).Grep(ctx, pattern, path, options)
+}
+
+func (e *SandboxedExecutionEnvironment) Glob(pattern string, path string) ([]string, error) {
+	return (&LocalExecutionEnvironment{workingDir: e.workingDir}).Glob(pattern, path)
+}
+
+// ExecCommand runs command inside a fresh set of namespaces and the
+// session's cgroup subtree. It forks /proc/self/exe as a re-exec helper
+// (SandboxReexecMain) that joins the cgroup before execve-ing the shell, so
+// every process the command spawns is accounted under the same cgroup ID.
+func (e *SandboxedExecutionEnvironment) ExecCommand(ctx context.Context, command string, timeoutMs int, workingDir string, envVars map[string]string) (*ExecResult, error) {
+	if e.cgroupPath == "" {
+		return nil, fmt.Errorf("sandboxed_execution_environment: Initialize must be called before ExecCommand")
+	}
+	if workingDir == "" {
+		workingDir = e.workingDir
+	} else {
+		workingDir = e.resolvePath(workingDir)
+	}
+
+	if e.policy != nil {
+		if decision := e.policy.Check(command, workingDir); !decision.Allowed {
+			if e.policyWarning != nil {
+				e.policyWarning(decision.Reason, decision.Node)
+			}
+			return &ExecResult{ExitCode: -2, Stderr: decision.Reason}, nil
+		}
+	}
+
+	if timeoutMs > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, time.Duration(timeoutMs)*time.Millisecond)
+		defer cancel()
+	}
+
+	configPath, err := writeReexecConfig(reexecConfig{
+		RootfsPath:      e.config.RootfsPath,
+		BindMounts:      e.config.BindMounts,
+		AllowedDevices:  e.config.AllowedDevices,
+		ReadOnlyOverlay: e.config.ReadOnlyOverlay,
+		WorkingDir:      workingDir,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("sandboxed_exec_command: %w", err)
+	}
+	defer os.Remove(configPath)
+
+	helperArgs := []string{sandboxReexecArg, e.cgroupPath, configPath, "/bin/bash", "-c", command}
+	cmd := exec.CommandContext(ctx, "/proc/self/exe", helperArgs...)
+	cmd.Dir = workingDir
+	cmd.Env = mergeEnv(filterEnvironment(), envVars)
+
+	cloneFlags := uintptr(syscall.CLONE_NEWNS | syscall.CLONE_NEWPID | syscall.CLONE_NEWUTS | syscall.CLONE_NEWIPC | syscall.CLONE_NEWUSER)
+	if !e.config.EnableNetwork {
+		cloneFlags |= syscall.CLONE_NEWNET
+	}
+	cmd.SysProcAttr = &syscall.SysProcAttr{
+		Cloneflags: cloneFlags,
+		Setpgid:    true,
+		// Map the invoking user to root inside the user namespace, the
+		// standard rootless-container setup: the sandboxed process can
+		// mount/chroot within its own namespaces without host root, but
+		// has no elevated privilege outside them.
+		UidMappings:                []syscall.SysProcIDMap{{ContainerID: 0, HostID: os.Getuid(), Size: 1}},
+		GidMappings:                []syscall.SysProcIDMap{{ContainerID: 0, HostID: os.Getgid(), Size: 1}},
+		GidMappingsEnableSetgroups: false,
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	start := time.Now()
+	err = cmd.Run()
+	duration := time.Since(start)
+
+	result := &ExecResult{
+		Stdout:     stdout.String(),
+		Stderr:     stderr.String(),
+		DurationMs: duration.Milliseconds(),
+	}
+
+	if err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			result.TimedOut = true
+			result.ExitCode = -1
+			if cmd.Process != nil {
+				_ = syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+			}
+		} else if exitErr, ok := err.(*exec.ExitError); ok {
+			result.ExitCode = exitErr.ExitCode()
+		} else {
+			return nil, fmt.Errorf("sandboxed_exec_command: %w", err)
+		}
+	}
+
+	return result, nil
+}
+
+// reexecConfig carries the isolation knobs from SandboxConfig that can only
+// be applied from inside the child's own namespaces -- after unshare, before
+// the target command execves -- since mount and chroot take effect in the
+// namespace that performs them, not the parent's. It's marshaled to a temp
+// file by writeReexecConfig and read back by SandboxReexecMain.
+type reexecConfig struct {
+	RootfsPath      string      `json:"rootfs_path,omitempty"`
+	BindMounts      []BindMount `json:"bind_mounts,omitempty"`
+	AllowedDevices  []string    `json:"allowed_devices,omitempty"`
+	ReadOnlyOverlay bool        `json:"read_only_overlay"`
+	WorkingDir      string      `json:"working_dir"`
+}
+
+// writeReexecConfig marshals cfg to a temp file and returns its path, for
+// passing to the sandbox re-exec helper as an argv element (a JSON blob
+// doesn't survive argv escaping cleanly, a file path does).
+func writeReexecConfig(cfg reexecConfig) (string, error) {
+	data, err := json.Marshal(cfg)
+	if err != nil {
+		return "", fmt.Errorf("marshal reexec config: %w", err)
+	}
+	f, err := os.CreateTemp("", "attractor-sandbox-cfg-*.json")
+	if err != nil {
+		return "", fmt.Errorf("create reexec config: %w", err)
+	}
+	defer f.Close()
+	if _, err := f.Write(data); err != nil {
+		return "", fmt.Errorf("write reexec config: %w", err)
+	}
+	return f.Name(), nil
+}
+
+// applyRootfsIsolation performs the mount/chroot isolation described by cfg.
+// It must run after CLONE_NEWNS/CLONE_NEWUSER have taken effect (i.e. from
+// inside SandboxReexecMain) since every mount it makes is scoped to the
+// calling process's own mount namespace.
+//
+// With no RootfsPath configured, isolation relies solely on namespaces and
+// cgroup limits, and this only applies the read-only overlay (if
+// requested) directly over WorkingDir. With RootfsPath configured, the
+// working directory and every configured bind mount and allowed device are
+// bound into the new root before chrooting into it.
+func applyRootfsIsolation(cfg reexecConfig) error {
+	if cfg.RootfsPath == "" {
+		if cfg.ReadOnlyOverlay {
+			return mountReadOnlyOverlay(cfg.WorkingDir, cfg.WorkingDir)
+		}
+		return nil
+	}
+
+	rootfs := cfg.RootfsPath
+	if err := bindMountInto(rootfs, cfg.WorkingDir, cfg.WorkingDir, false); err != nil {
+		return fmt.Errorf("bind mount working directory: %w", err)
+	}
+	for _, bm := range cfg.BindMounts {
+		if err := bindMountInto(rootfs, bm.Source, bm.Target, bm.ReadOnly); err != nil {
+			return fmt.Errorf("bind mount %s -> %s: %w", bm.Source, bm.Target, err)
+		}
+	}
+	for _, dev := range cfg.AllowedDevices {
+		if err := bindMountInto(rootfs, dev, dev, false); err != nil {
+			return fmt.Errorf("bind mount device %s: %w", dev, err)
+		}
+	}
+	if cfg.ReadOnlyOverlay {
+		target := filepath.Join(rootfs, cfg.WorkingDir)
+		if err := mountReadOnlyOverlay(target, target); err != nil {
+			return fmt.Errorf("read-only overlay: %w", err)
+		}
+	}
+
+	if err := syscall.Chroot(rootfs); err != nil {
+		return fmt.Errorf("chroot %s: %w", rootfs, err)
+	}
+	if err := os.Chdir(cfg.WorkingDir); err != nil {
+		return fmt.Errorf("chdir %s after chroot: %w", cfg.WorkingDir, err)
+	}
+	return nil
+}
+
+// bindMountInto bind-mounts source onto target -- joined under rootfs when
+// rootfs is non-empty, used as-is otherwise -- creating the mount point
+// (directory or empty placeholder file, matching source's type) first, and
+// optionally remounting it read-only. Bind-mounting a placeholder regular
+// file over a device node's path works the same way container runtimes
+// populate an allow-listed /dev entry without needing mknod.
+func bindMountInto(rootfs, source, target string, readOnly bool) error {
+	dest := target
+	if rootfs != "" {
+		dest = filepath.Join(rootfs, target)
+	}
+	info, err := os.Stat(source)
+	if err != nil {
+		return err
+	}
+	if info.IsDir() {
+		if err := os.MkdirAll(dest, 0755); err != nil {
+			return err
+		}
+	} else {
+		if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+			return err
+		}
+		f, err := os.OpenFile(dest, os.O_CREATE, 0644)
+		if err != nil {
+			return err
+		}
+		f.Close()
+	}
+	if err := syscall.Mount(source, dest, "", syscall.MS_BIND, ""); err != nil {
+		return fmt.Errorf("mount --bind %s %s: %w", source, dest, err)
+	}
+	if readOnly {
+		if err := syscall.Mount("", dest, "", syscall.MS_BIND|syscall.MS_REMOUNT|syscall.MS_RDONLY, ""); err != nil {
+			return fmt.Errorf("remount %s read-only: %w", dest, err)
+		}
+	}
+	return nil
+}
+
+// mountReadOnlyOverlay mounts an overlayfs at target with lower as the
+// lower (read-only) layer and a fresh tmpfs upper/work layer, so writes
+// under target are visible to the sandboxed process but never written back
+// to lower -- they vanish when the mount namespace is torn down along with
+// the tmpfs backing them.
+func mountReadOnlyOverlay(lower, target string) error {
+	upperBase, err := os.MkdirTemp("", "attractor-overlay-*")
+	if err != nil {
+		return err
+	}
+	if err := syscall.Mount("tmpfs", upperBase, "tmpfs", 0, ""); err != nil {
+		return fmt.Errorf("mount tmpfs for overlay: %w", err)
+	}
+	upper := filepath.Join(upperBase, "upper")
+	work := filepath.Join(upperBase, "work")
+	if err := os.MkdirAll(upper, 0755); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(work, 0755); err != nil {
+		return err
+	}
+	opts := fmt.Sprintf("lowerdir=%s,upperdir=%s,workdir=%s", lower, upper, work)
+	if err := syscall.Mount("overlay", target, "overlay", 0, opts); err != nil {
+		return fmt.Errorf("mount overlay: %w", err)
+	}
+	return nil
+}
+
+// SandboxReexecMain must be called at the very start of the host
+// application's main(), before flag parsing, so that re-exec'd sandbox
+// helper processes join their target cgroup, apply the configured rootfs
+// isolation, and execve the real command instead of running the normal
+// program. It returns false (and does nothing) when the process was not
+// launched as a sandbox re-exec helper.
+func SandboxReexecMain() bool {
+	if len(os.Args) < 4 || os.Args[1] != sandboxReexecArg {
+		return false
+	}
+	cgroupPath := os.Args[2]
+	configPath := os.Args[3]
+	targetArgs := os.Args[4:]
+
+	pid := os.Getpid()
+	if err := os.WriteFile(filepath.Join(cgroupPath, "cgroup.procs"), []byte(strconv.Itoa(pid)), 0644); err != nil {
+		fmt.Fprintf(os.Stderr, "sandbox reexec: failed to join cgroup %s: %v\n", cgroupPath, err)
+		os.Exit(1)
+	}
+
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "sandbox reexec: read config %s: %v\n", configPath, err)
+		os.Exit(1)
+	}
+	var cfg reexecConfig
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		fmt.Fprintf(os.Stderr, "sandbox reexec: parse config: %v\n", err)
+		os.Exit(1)
+	}
+	if err := applyRootfsIsolation(cfg); err != nil {
+		fmt.Fprintf(os.Stderr, "sandbox reexec: apply isolation: %v\n", err)
+		os.Exit(1)
+	}
+
+	if len(targetArgs) == 0 {
+		os.Exit(1)
+	}
+	binary, err := lookPath(targetArgs[0])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "sandbox reexec: %v\n", err)
+		os.Exit(1)
+	}
+
+	if err := syscall.Exec(binary, targetArgs, os.Environ()); err != nil {
+		fmt.Fprintf(os.Stderr, "sandbox reexec: execve %s: %v\n", binary, err)
+		os.Exit(1)
+	}
+	return true // unreachable; execve replaces the process image on success.
+}
+
+func lookPath(name string) (string, error) {
+	if strings.Contains(name, "/") {
+		return name, nil
+	}
+	for _, dir := range strings.Split(os.Getenv("PATH"), ":") {
+		candidate := filepath.Join(dir, name)
+		if info, err := os.Stat(candidate); err == nil && !info.IsDir() {
+			return candidate, nil
+		}
+	}
+	return "", fmt.Errorf("%s: not found in PATH", name)
+}
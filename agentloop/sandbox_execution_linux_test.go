@@ -0,0 +1,98 @@
+//go:build linux
+
+package agentloop
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestNewSandboxedExecutionEnvironmentDefaultsCgroupParent(t *testing.T) {
+	e, err := NewSandboxedExecutionEnvironment("/tmp", SandboxConfig{})
+	if err != nil {
+		t.Fatalf("NewSandboxedExecutionEnvironment() error = %v", err)
+	}
+	if e.config.CgroupParent != defaultCgroupParent {
+		t.Errorf("CgroupParent = %q, want %q", e.config.CgroupParent, defaultCgroupParent)
+	}
+}
+
+func TestNewSandboxedExecutionEnvironmentPreservesCgroupParent(t *testing.T) {
+	e, err := NewSandboxedExecutionEnvironment("/tmp", SandboxConfig{CgroupParent: "/sys/fs/cgroup/custom"})
+	if err != nil {
+		t.Fatalf("NewSandboxedExecutionEnvironment() error = %v", err)
+	}
+	if e.config.CgroupParent != "/sys/fs/cgroup/custom" {
+		t.Errorf("CgroupParent = %q, want /sys/fs/cgroup/custom", e.config.CgroupParent)
+	}
+}
+
+func TestSandboxedExecutionEnvironmentCgroupDir(t *testing.T) {
+	e, err := NewSandboxedExecutionEnvironment("/tmp", SandboxConfig{CgroupParent: "/sys/fs/cgroup/attractor"})
+	if err != nil {
+		t.Fatalf("NewSandboxedExecutionEnvironment() error = %v", err)
+	}
+	want := filepath.Join("/sys/fs/cgroup/attractor", "sess-"+e.sessionID)
+	if got := e.cgroupDir(); got != want {
+		t.Errorf("cgroupDir() = %q, want %q", got, want)
+	}
+}
+
+func TestSandboxedExecutionEnvironmentResolvePath(t *testing.T) {
+	e := &SandboxedExecutionEnvironment{workingDir: "/work"}
+	if got := e.resolvePath("rel/path"); got != "/work/rel/path" {
+		t.Errorf("resolvePath(relative) = %q, want /work/rel/path", got)
+	}
+	if got := e.resolvePath("/abs/path"); got != "/abs/path" {
+		t.Errorf("resolvePath(absolute) = %q, want /abs/path", got)
+	}
+}
+
+// TestWriteReexecConfigRoundTrip checks that the config handed to the
+// re-exec helper over a temp file survives a marshal/unmarshal round trip
+// with every SandboxConfig-derived field intact.
+func TestWriteReexecConfigRoundTrip(t *testing.T) {
+	cfg := reexecConfig{
+		RootfsPath: "/rootfs",
+		BindMounts: []BindMount{
+			{Source: "/host/src", Target: "/dst", ReadOnly: true},
+		},
+		AllowedDevices:  []string{"/dev/null", "/dev/urandom"},
+		ReadOnlyOverlay: true,
+		WorkingDir:      "/work",
+	}
+	path, err := writeReexecConfig(cfg)
+	if err != nil {
+		t.Fatalf("writeReexecConfig() error = %v", err)
+	}
+	defer os.Remove(path)
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile(%s) error = %v", path, err)
+	}
+	var got reexecConfig
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	if got.RootfsPath != cfg.RootfsPath || got.ReadOnlyOverlay != cfg.ReadOnlyOverlay || got.WorkingDir != cfg.WorkingDir {
+		t.Errorf("round-tripped config = %+v, want %+v", got, cfg)
+	}
+	if len(got.BindMounts) != 1 || got.BindMounts[0] != cfg.BindMounts[0] {
+		t.Errorf("round-tripped BindMounts = %+v, want %+v", got.BindMounts, cfg.BindMounts)
+	}
+	if len(got.AllowedDevices) != 2 {
+		t.Errorf("round-tripped AllowedDevices = %v, want 2 entries", got.AllowedDevices)
+	}
+}
+
+// TestApplyRootfsIsolationNoRootfsNoOverlay checks the no-op case: with
+// neither RootfsPath nor ReadOnlyOverlay configured, isolation relies
+// solely on namespaces/cgroups and applyRootfsIsolation does nothing.
+func TestApplyRootfsIsolationNoRootfsNoOverlay(t *testing.T) {
+	if err := applyRootfsIsolation(reexecConfig{WorkingDir: "/tmp"}); err != nil {
+		t.Errorf("applyRootfsIsolation() error = %v, want nil", err)
+	}
+}
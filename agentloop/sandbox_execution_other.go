@@ -0,0 +1,69 @@
+//go:build !linux
+
+package agentloop
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+)
+
+// SandboxedExecutionEnvironment is only implemented on Linux, where mount,
+// pid, net, uts, and ipc namespaces plus cgroup v2 are available. On other
+// platforms NewSandboxedExecutionEnvironment returns an error; callers
+// should fall back to NewLocalExecutionEnvironment or a RemoteExecutionEnvironment.
+type SandboxedExecutionEnvironment struct{}
+
+// NewSandboxedExecutionEnvironment always fails on non-Linux platforms.
+func NewSandboxedExecutionEnvironment(workingDir string, config SandboxConfig) (*SandboxedExecutionEnvironment, error) {
+	return nil, fmt.Errorf("sandboxed_execution_environment: not supported on %s", runtime.GOOS)
+}
+
+func (e *SandboxedExecutionEnvironment) Initialize() error { return errUnsupportedSandbox }
+func (e *SandboxedExecutionEnvironment) Cleanup() error    { return errUnsupportedSandbox }
+
+func (e *SandboxedExecutionEnvironment) WorkingDirectory() string { return "" }
+func (e *SandboxedExecutionEnvironment) Platform() string         { return runtime.GOOS }
+func (e *SandboxedExecutionEnvironment) OSVersion() string        { return runtime.GOOS }
+
+func (e *SandboxedExecutionEnvironment) ReadFile(path string, offset, limit int) (string, error) {
+	return "", errUnsupportedSandbox
+}
+
+func (e *SandboxedExecutionEnvironment) WriteFile(path string, content string) error {
+	return errUnsupportedSandbox
+}
+
+func (e *SandboxedExecutionEnvironment) DeleteFile(path string) error {
+	return errUnsupportedSandbox
+}
+
+func (e *SandboxedExecutionEnvironment) RenameFile(oldPath, newPath string) error {
+	return errUnsupportedSandbox
+}
+
+func (e *SandboxedExecutionEnvironment) FileExists(path string) bool { return false }
+
+func (e *SandboxedExecutionEnvironment) ListDirectory(path string, depth int) ([]DirEntry, error) {
+	return nil, errUnsupportedSandbox
+}
+
+func (e *SandboxedExecutionEnvironment) Grep(ctx context.Context, pattern string, path string, options GrepOptions) (string, error) {
+	return "", errUnsupportedSandbox
+}
+
+func (e *SandboxedExecutionEnvironment) Glob(pattern string, path string) ([]string, error) {
+	return nil, errUnsupportedSandbox
+}
+
+func (e *SandboxedExecutionEnvironment) ExecCommand(ctx context.Context, command string, timeoutMs int, workingDir string, envVars map[string]string) (*ExecResult, error) {
+	return nil, errUnsupportedSandbox
+}
+
+// SetCommandPolicy is a no-op on non-Linux platforms.
+func (e *SandboxedExecutionEnvironment) SetCommandPolicy(policy CommandPolicy) {}
+
+// SetPolicyWarning is a no-op on non-Linux platforms.
+func (e *SandboxedExecutionEnvironment) SetPolicyWarning(fn func(reason, node string)) {}
+
+var errUnsupportedSandbox = fmt.Errorf("sandboxed_execution_environment: not supported on %s", runtime.GOOS)
@@ -0,0 +1,19 @@
+//go:build !linux
+
+package agentloop
+
+import "testing"
+
+func TestNewSandboxedExecutionEnvironmentUnsupported(t *testing.T) {
+	_, err := NewSandboxedExecutionEnvironment("/tmp", SandboxConfig{})
+	if err == nil {
+		t.Fatal("expected an error on a non-Linux platform")
+	}
+}
+
+func TestSandboxedExecutionEnvironmentExecCommandUnsupported(t *testing.T) {
+	e := &SandboxedExecutionEnvironment{}
+	if _, err := e.ExecCommand(nil, "echo hi", 0, "", nil); err != errUnsupportedSandbox {
+		t.Errorf("ExecCommand() error = %v, want %v", err, errUnsupportedSandbox)
+	}
+}
@@ -4,8 +4,15 @@ import (
 	"context"
 	"fmt"
 	"sync"
+	"time"
 
 	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/martinemde/attractor/metrics"
 	"github.com/martinemde/attractor/unifiedllm"
 )
 
@@ -13,63 +20,115 @@ import (
 type SessionState string
 
 const (
-	StateIdle          SessionState = "idle"
-	StateProcessing    SessionState = "processing"
-	StateAwaitingInput SessionState = "awaiting_input"
-	StateClosed        SessionState = "closed"
+	StateIdle             SessionState = "idle"
+	StateProcessing       SessionState = "processing"
+	StateAwaitingInput    SessionState = "awaiting_input"
+	StateAwaitingApproval SessionState = "awaiting_approval"
+	StateClosed           SessionState = "closed"
 )
 
 // SessionConfig holds configuration for a session.
 type SessionConfig struct {
-	MaxTurns                int            `json:"max_turns"`                   // 0 = unlimited
-	MaxToolRoundsPerInput   int            `json:"max_tool_rounds_per_input"`   // per user input
-	DefaultCommandTimeoutMs int            `json:"default_command_timeout_ms"`
-	MaxCommandTimeoutMs     int            `json:"max_command_timeout_ms"`
-	ReasoningEffort         string         `json:"reasoning_effort,omitempty"`  // "low", "medium", "high", or ""
-	ToolOutputLimits        map[string]int `json:"tool_output_limits,omitempty"`
-	ToolLineLimits          map[string]int `json:"tool_line_limits,omitempty"`
-	EnableLoopDetection     bool           `json:"enable_loop_detection"`
-	LoopDetectionWindow     int            `json:"loop_detection_window"`
-	MaxSubagentDepth        int            `json:"max_subagent_depth"`
-	UserInstructions        string         `json:"user_instructions,omitempty"` // appended last to system prompt
-	subagentDepth           int            // internal: current nesting depth
+	MaxTurns                int                     `json:"max_turns"`                 // 0 = unlimited
+	MaxToolRoundsPerInput   int                     `json:"max_tool_rounds_per_input"` // per user input
+	DefaultCommandTimeoutMs int                     `json:"default_command_timeout_ms"`
+	MaxCommandTimeoutMs     int                     `json:"max_command_timeout_ms"`
+	ReasoningEffort         string                  `json:"reasoning_effort,omitempty"` // "low", "medium", "high", or ""
+	ToolOutputLimits        map[string]int          `json:"tool_output_limits,omitempty"`
+	ToolLineLimits          map[string]int          `json:"tool_line_limits,omitempty"`
+	Tokenizer               Tokenizer               `json:"-"` // if set, tool output is truncated on token boundaries (see ToolTokenLimits) instead of ToolOutputLimits' characters
+	ToolTokenLimits         map[string]int          `json:"tool_token_limits,omitempty"`
+	EnableLoopDetection     bool                    `json:"enable_loop_detection"`
+	LoopDetectionWindow     int                     `json:"loop_detection_window"`
+	CompactionThreshold     float64                 `json:"compaction_threshold"` // fraction of context window that triggers auto-compaction; 0 disables it
+	MaxSubagentDepth        int                     `json:"max_subagent_depth"`
+	UserInstructions        string                  `json:"user_instructions,omitempty"` // appended last to system prompt
+	ApprovalPolicy          ApprovalPolicy          `json:"-"`                           // matches additional calls for human approval beyond RegisteredTool.RequiresApproval
+	RetryPolicy             *unifiedllm.RetryPolicy `json:"-"`                           // drives llmClient.Complete retries in processInput; nil disables retries
+	Principal               Principal               `json:"-"`                           // identifies who this session acts on behalf of, passed to the active profile's ToolAuthorizer on every tool call
+	MetricsRegistry         metrics.Registry        `json:"-"`                           // records turns, tool calls, token usage/cost, and subagent lifecycle; nil disables instrumentation
+	Tracer                  trace.Tracer            `json:"-"`                           // emits a span per turn, tool invocation, and subagent lifetime; nil disables tracing
+	subagentDepth           int                     // internal: current nesting depth
 }
 
 // DefaultSessionConfig returns the spec-default configuration.
 func DefaultSessionConfig() SessionConfig {
 	return SessionConfig{
-		MaxTurns:                0,   // unlimited
+		MaxTurns:                0, // unlimited
 		MaxToolRoundsPerInput:   200,
 		DefaultCommandTimeoutMs: 10000,  // 10 seconds
 		MaxCommandTimeoutMs:     600000, // 10 minutes
 		EnableLoopDetection:     true,
 		LoopDetectionWindow:     10,
+		CompactionThreshold:     0.75,
 		MaxSubagentDepth:        1,
+		RetryPolicy:             defaultLLMRetryPolicy(),
+	}
+}
+
+// defaultLLMRetryPolicy is the spec-default retry policy for processInput's
+// llmClient.Complete calls: rate limits honor Retry-After (handled by
+// unifiedllm.Retry itself), server errors and timeouts back off with
+// decorrelated jitter, and the whole retry loop gives up after 2 minutes of
+// cumulative sleep regardless of MaxRetries.
+func defaultLLMRetryPolicy() *unifiedllm.RetryPolicy {
+	decorrelated := unifiedllm.RetryPolicy{
+		MaxRetries:         4,
+		BaseDelay:          1.0,
+		MaxDelay:           30.0,
+		DecorrelatedJitter: true,
+		MaxElapsed:         120.0,
+	}
+	return &unifiedllm.RetryPolicy{
+		MaxRetries:        4,
+		BaseDelay:         1.0,
+		MaxDelay:          30.0,
+		BackoffMultiplier: 2.0,
+		Jitter:            true,
+		MaxElapsed:        120.0,
+		PerErrorType: map[string]unifiedllm.RetryPolicy{
+			"ServerError":         decorrelated,
+			"RequestTimeoutError": decorrelated,
+		},
 	}
 }
 
 // Session is the central orchestrator for the agentic loop.
 type Session struct {
-	id             string
-	profile        ProviderProfile
-	env            ExecutionEnvironment
-	history        []Turn
-	emitter        *EventEmitter
-	config         SessionConfig
-	state          SessionState
-	llmClient      *unifiedllm.Client
-	steeringQueue  []string
-	followupQueue  []string
-	subagents      *SubAgentManager
-	abortSignaled  bool
-	mu             sync.Mutex
+	id                string
+	profile           ProviderProfile
+	env               ExecutionEnvironment
+	history           []Turn
+	emitter           *EventEmitter
+	config            SessionConfig
+	state             SessionState
+	llmClient         *unifiedllm.Client
+	steeringQueue     []string
+	followupQueue     []string
+	subagents         *SubAgentManager
+	abortSignaled     bool
+	agentProfiles     map[string]*AgentProfile
+	activeAgent       *AgentProfile
+	scopedTools       *ToolRegistry               // non-nil while activeAgent restricts the tool set
+	seededAgents      map[string]bool             // agent names whose SeedDocuments have already been recorded into history
+	pendingApprovals  map[string]*pendingApproval // token -> gate awaiting a decision
+	approvalAllowList map[string]bool             // toolCallSignature -> allowed, from allow_and_remember
+	store             SessionStore                // non-nil once SetStore persists turns/events
+	storeCancel       func()                      // unsubscribes the event-forwarding goroutine
+	metrics           *AgentMetrics               // never nil; inert if config.MetricsRegistry is nil
+	tracer            trace.Tracer                // nil disables span emission
+	mu                sync.Mutex
 }
 
 // NewSession creates a new session with the given profile, execution
 // environment, and optional configuration.
 func NewSession(profile ProviderProfile, env ExecutionEnvironment, config *SessionConfig) *Session {
-	sessionID := uuid.New().String()
+	return newSession(uuid.New().String(), profile, env, config)
+}
 
+// newSession is NewSession with an explicit session ID, so ResumeSession can
+// rebuild a session under its original identity instead of minting a new one.
+func newSession(sessionID string, profile ProviderProfile, env ExecutionEnvironment, config *SessionConfig) *Session {
 	cfg := DefaultSessionConfig()
 	if config != nil {
 		cfg = *config
@@ -84,17 +143,48 @@ func NewSession(profile ProviderProfile, env ExecutionEnvironment, config *Sessi
 		config:    cfg,
 		state:     StateIdle,
 		llmClient: unifiedllm.GetDefaultClient(),
-		subagents: NewSubAgentManager(cfg.MaxSubagentDepth, cfg.subagentDepth),
+		metrics:   NewAgentMetrics(cfg.MetricsRegistry),
+		tracer:    cfg.Tracer,
 	}
+	if s.tracer == nil {
+		// otel's default global TracerProvider is a no-op, so this is safe
+		// to call unconditionally even when no real tracer was configured;
+		// it spares every call site a nil check.
+		s.tracer = otel.Tracer("github.com/martinemde/attractor/agentloop")
+	}
+	s.subagents = NewSubAgentManager(cfg.MaxSubagentDepth, cfg.subagentDepth, cfg.MetricsRegistry, s.tracer)
 
 	// Register subagent tools if depth allows.
 	if s.subagents.CanSpawn() {
 		RegisterSubagentTools(profile.ToolRegistry(), s.subagents, profile, env)
 	}
 
+	wireCommandPolicyWarnings(env, s.emitter)
+
 	return s
 }
 
+// wireCommandPolicyWarnings connects an ExecutionEnvironment's optional
+// CommandPolicy denial callback to emitter, so a denied command surfaces as
+// an EventWarning instead of going unnoticed. Environments that don't
+// support a CommandPolicy (e.g. RemoteExecutionEnvironment, whose policy
+// lives server-side) are left alone.
+func wireCommandPolicyWarnings(env ExecutionEnvironment, emitter *EventEmitter) {
+	warn := func(reason, node string) {
+		emitter.Emit(EventWarning, map[string]interface{}{
+			"reason": reason,
+			"node":   node,
+			"source": "command_policy",
+		})
+	}
+	switch e := env.(type) {
+	case *LocalExecutionEnvironment:
+		e.SetPolicyWarning(warn)
+	case *SandboxedExecutionEnvironment:
+		e.SetPolicyWarning(warn)
+	}
+}
+
 // SetClient sets a custom LLM client (overriding the default).
 func (s *Session) SetClient(client *unifiedllm.Client) {
 	s.mu.Lock()
@@ -105,6 +195,16 @@ func (s *Session) SetClient(client *unifiedllm.Client) {
 // ID returns the session identifier.
 func (s *Session) ID() string { return s.id }
 
+// Subagents returns this session's SubAgentManager, for callers that need to
+// attach persistence (SetStore) or perform a Handoff into or out of it.
+func (s *Session) Subagents() *SubAgentManager { return s.subagents }
+
+// SetSubagentStore attaches persistence for this session's subagents: see
+// SubAgentManager.SetStore.
+func (s *Session) SetSubagentStore(store SubAgentStore, sessionStore SessionStore) {
+	s.subagents.SetStore(store, sessionStore)
+}
+
 // State returns the current session state.
 func (s *Session) State() SessionState {
 	s.mu.Lock()
@@ -121,6 +221,66 @@ func (s *Session) History() []Turn {
 	return h
 }
 
+// recordTurn appends turn to history and, if a SessionStore is attached via
+// SetStore, persists it alongside the LLM request/response that produced it
+// (both nil for turns that don't come from an LLM call).
+func (s *Session) recordTurn(turn Turn, req *unifiedllm.Request, resp *unifiedllm.Response) {
+	s.metrics.recordTurn(turn.Kind)
+	if resp != nil {
+		s.metrics.recordUsage(resp.Provider, resp.Model, resp.Usage)
+	}
+
+	s.mu.Lock()
+	s.history = append(s.history, turn)
+	store := s.store
+	sessionID := s.id
+	s.mu.Unlock()
+
+	if store == nil {
+		return
+	}
+	if err := store.AppendTurn(context.Background(), sessionID, turn, req, resp); err != nil {
+		s.emitter.Emit(EventWarning, map[string]interface{}{
+			"reason": "session_store_append_turn_failed",
+			"error":  err.Error(),
+		})
+	}
+}
+
+// finalizePrefillTurn concatenates a continuation response onto the
+// trailing assistant-prefill turn (see Prefill, IsAssistantContinuation)
+// instead of recording it as a separate turn, so history ends up with one
+// completed assistant turn rather than a prefill/continuation pair.
+func (s *Session) finalizePrefillTurn(continuationText string, toolCalls []unifiedllm.ToolCall, reasoning string, usage unifiedllm.Usage, responseID string, req *unifiedllm.Request, resp *unifiedllm.Response) {
+	if resp != nil {
+		s.metrics.recordUsage(resp.Provider, resp.Model, usage)
+	}
+
+	s.mu.Lock()
+	last := len(s.history) - 1
+	turn := s.history[last]
+	turn.Assistant.Content += continuationText
+	turn.Assistant.Prefill = false
+	turn.Assistant.ToolCalls = toolCalls
+	turn.Assistant.Reasoning = reasoning
+	turn.Assistant.Usage = usage
+	turn.Assistant.ResponseID = responseID
+	s.history[last] = turn
+	store := s.store
+	sessionID := s.id
+	s.mu.Unlock()
+
+	if store == nil {
+		return
+	}
+	if err := store.AppendTurn(context.Background(), sessionID, turn, req, resp); err != nil {
+		s.emitter.Emit(EventWarning, map[string]interface{}{
+			"reason": "session_store_append_turn_failed",
+			"error":  err.Error(),
+		})
+	}
+}
+
 // Events returns the event channel for the host application.
 func (s *Session) Events() <-chan SessionEvent {
 	return s.emitter.Events()
@@ -133,6 +293,35 @@ func (s *Session) Steer(message string) {
 	s.steeringQueue = append(s.steeringQueue, message)
 }
 
+// Prefill appends an assistant-prefill turn to history: text the next
+// Continue call picks up and continues rather than responds to, via
+// IsAssistantContinuation. Useful for forcing a response format (JSON, a
+// code block, the XML fallback adapter's <function_calls> block) without
+// relying on "please start your reply with..." prompting.
+func (s *Session) Prefill(content string) {
+	s.recordTurn(NewPrefillAssistantTurn(content), nil, nil)
+}
+
+// Continue resumes generation from a trailing assistant-prefill turn (see
+// Prefill) instead of responding to new user input. It fails if history
+// does not currently end in such a turn.
+func (s *Session) Continue(ctx context.Context) error {
+	s.mu.Lock()
+	if s.state == StateClosed {
+		s.mu.Unlock()
+		return fmt.Errorf("session is closed")
+	}
+	if !IsAssistantContinuation(s.history) {
+		s.mu.Unlock()
+		return fmt.Errorf("agentloop: Continue called but history does not end in an assistant-prefill turn")
+	}
+	s.state = StateProcessing
+	s.abortSignaled = false
+	s.mu.Unlock()
+
+	return s.processInput(ctx, "")
+}
+
 // FollowUp queues a message to be processed after the current input completes.
 func (s *Session) FollowUp(message string) {
 	s.mu.Lock()
@@ -140,11 +329,24 @@ func (s *Session) FollowUp(message string) {
 	s.followupQueue = append(s.followupQueue, message)
 }
 
-// Abort signals the session to stop processing.
+// Abort signals the session to stop processing and denies any tool calls
+// currently blocked on human approval, so they unblock immediately instead
+// of waiting for a decision that will never come.
 func (s *Session) Abort() {
 	s.mu.Lock()
-	defer s.mu.Unlock()
 	s.abortSignaled = true
+	pending := make([]*pendingApproval, 0, len(s.pendingApprovals))
+	for _, p := range s.pendingApprovals {
+		pending = append(pending, p)
+	}
+	s.mu.Unlock()
+
+	for _, p := range pending {
+		select {
+		case p.decision <- ApprovalDeny:
+		default:
+		}
+	}
 }
 
 // Close terminates the session and cleans up resources.
@@ -183,23 +385,52 @@ func (s *Session) Submit(ctx context.Context, userInput string) error {
 
 // processInput is the core agentic loop (Section 2.5 of the spec).
 func (s *Session) processInput(ctx context.Context, userInput string) error {
-	// Append user turn.
-	s.mu.Lock()
-	s.history = append(s.history, NewUserTurn(userInput))
-	s.mu.Unlock()
-	s.emitter.Emit(EventUserInput, map[string]interface{}{
-		"content": userInput,
-	})
+	ctx, span := s.startSpan(ctx, "agentloop.turn", attribute.String("session.id", s.id))
+	defer span.End()
+
+	err := s.processInputImpl(ctx, userInput)
+	if err != nil {
+		span.SetStatus(codes.Error, err.Error())
+	}
+	return err
+}
+
+// startSpan starts a span named name under ctx via s.tracer (a no-op
+// tracer if the session wasn't configured with one), so callers never
+// need a nil check. A subagent session's turn/tool spans nest under
+// whatever span the ctx passed to SubAgentManager.Spawn carries; the
+// spawn_agent tool passes context.Background(), so a subagent it starts
+// begins a new trace rather than nesting under that tool-call span.
+func (s *Session) startSpan(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return s.tracer.Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+func (s *Session) processInputImpl(ctx context.Context, userInput string) error {
+	// A trailing assistant-prefill turn means this call continues that
+	// response (Session.Continue) instead of responding to new user input:
+	// skip appending a user turn, and concatenate the first round's
+	// response onto the prefill turn below instead of recording a new one.
+	continuation := IsAssistantContinuation(s.History())
+	if !continuation {
+		s.recordTurn(NewUserTurn(userInput), nil, nil)
+		s.emitter.Emit(EventUserInput, map[string]interface{}{
+			"content": userInput,
+		})
+	}
 
 	// Drain any pending steering messages before the first LLM call.
 	s.drainSteering()
 
 	roundCount := 0
+	firstRound := true
 
 	for {
 		// 1. Check limits.
 		s.mu.Lock()
 		maxRounds := s.config.MaxToolRoundsPerInput
+		if s.activeAgent != nil && s.activeAgent.MaxToolRoundsPerInput > 0 {
+			maxRounds = s.activeAgent.MaxToolRoundsPerInput
+		}
 		maxTurns := s.config.MaxTurns
 		aborted := s.abortSignaled
 		s.mu.Unlock()
@@ -238,6 +469,7 @@ func (s *Session) processInput(ctx context.Context, userInput string) error {
 		// 2. Build LLM request using provider profile.
 		projectDocs := DiscoverProjectDocs(s.env.WorkingDirectory(), s.profile.ID())
 		systemPrompt := s.profile.BuildSystemPrompt(s.env, projectDocs)
+		systemPrompt = s.appendAgentSystemPrompt(systemPrompt)
 
 		// Append user instructions if configured.
 		s.mu.Lock()
@@ -248,8 +480,9 @@ func (s *Session) processInput(ctx context.Context, userInput string) error {
 
 		messages := ConvertHistoryToMessages(s.History())
 
-		// Build tool definitions for the request.
-		toolDefs := s.profile.Tools()
+		// Build tool definitions for the request, scoped to the active agent
+		// profile's toolset if one is active.
+		toolDefs := s.effectiveToolDefs()
 		sdkToolDefs := make([]unifiedllm.ToolDefinition, len(toolDefs))
 		for i, td := range toolDefs {
 			sdkToolDefs[i] = unifiedllm.ToolDefinition{
@@ -261,8 +494,26 @@ func (s *Session) processInput(ctx context.Context, userInput string) error {
 
 		s.mu.Lock()
 		reasoningEffort := s.config.ReasoningEffort
+		if s.activeAgent != nil && s.activeAgent.ReasoningEffort != "" {
+			reasoningEffort = s.activeAgent.ReasoningEffort
+		}
 		s.mu.Unlock()
 
+		s.mu.Lock()
+		retryPolicy := s.config.RetryPolicy
+		s.mu.Unlock()
+		if retryPolicy != nil {
+			policy := *retryPolicy
+			policy.OnRetry = func(err error, attempt int, delay time.Duration) {
+				s.emitter.Emit(EventRetry, map[string]interface{}{
+					"attempt":  attempt,
+					"delay_ms": delay.Milliseconds(),
+					"error":    err.Error(),
+				})
+			}
+			retryPolicy = &policy
+		}
+
 		request := unifiedllm.Request{
 			Model:           s.profile.ModelID(),
 			Messages:        append([]unifiedllm.Message{unifiedllm.SystemMessage(systemPrompt)}, messages...),
@@ -270,43 +521,43 @@ func (s *Session) processInput(ctx context.Context, userInput string) error {
 			ToolChoice:      &unifiedllm.ToolChoice{Mode: "auto"},
 			ReasoningEffort: reasoningEffort,
 			Provider:        s.profile.ID(),
-			ProviderOptions: s.profile.ProviderOptions(),
+			ProviderOptions: s.effectiveProviderOptions(),
+			RetryPolicy:     retryPolicy,
 		}
 
-		// 3. Call LLM via Unified LLM SDK.
+		// 3. Call LLM via Unified LLM SDK. request.RetryPolicy (from
+		// s.config.RetryPolicy) already retried any retryable error inside
+		// Complete; an error here means retries were exhausted, disabled, or
+		// the error wasn't retryable to begin with.
 		s.emitter.Emit(EventAssistantTextStart, nil)
 		response, err := s.llmClient.Complete(ctx, request)
 		if err != nil {
-			// Check if it's a non-retryable error.
-			if !unifiedllm.IsRetryable(err) {
-				s.mu.Lock()
-				s.state = StateClosed
-				s.mu.Unlock()
-				s.emitter.Emit(EventError, map[string]interface{}{
-					"error": err.Error(),
-				})
-				return fmt.Errorf("unrecoverable LLM error: %w", err)
-			}
-			// For retryable errors, the SDK should handle retry.
-			// If we still get an error, surface it.
+			s.mu.Lock()
+			s.state = StateClosed
+			s.mu.Unlock()
 			s.emitter.Emit(EventError, map[string]interface{}{
 				"error": err.Error(),
 			})
-			return fmt.Errorf("LLM error after retries: %w", err)
+			return fmt.Errorf("LLM error: %w", err)
 		}
 
-		// 4. Record assistant turn.
+		// 4. Record assistant turn. The first round of a continuation
+		// (see above) concatenates onto the trailing prefill turn instead
+		// of recording a new one.
 		toolCalls := response.ToolCallsFromResponse()
-		assistantTurn := NewAssistantTurn(
-			response.Text(),
-			toolCalls,
-			response.Reasoning(),
-			response.Usage,
-			response.ID,
-		)
-		s.mu.Lock()
-		s.history = append(s.history, assistantTurn)
-		s.mu.Unlock()
+		if firstRound && continuation {
+			s.finalizePrefillTurn(response.Text(), toolCalls, response.Reasoning(), response.Usage, response.ID, &request, response)
+		} else {
+			assistantTurn := NewAssistantTurn(
+				response.Text(),
+				toolCalls,
+				response.Reasoning(),
+				response.Usage,
+				response.ID,
+			)
+			s.recordTurn(assistantTurn, &request, response)
+		}
+		firstRound = false
 
 		s.emitter.Emit(EventAssistantTextEnd, map[string]interface{}{
 			"text":      response.Text(),
@@ -324,9 +575,7 @@ func (s *Session) processInput(ctx context.Context, userInput string) error {
 		// 7. Execute tool calls through the execution environment.
 		roundCount++
 		results := s.executeToolCalls(ctx, toolCalls)
-		s.mu.Lock()
-		s.history = append(s.history, NewToolResultsTurn(results))
-		s.mu.Unlock()
+		s.recordTurn(NewToolResultsTurn(results), nil, nil)
 
 		// 8. Drain steering messages injected during tool execution.
 		s.drainSteering()
@@ -342,9 +591,7 @@ func (s *Session) processInput(ctx context.Context, userInput string) error {
 		if enableLoop {
 			if DetectLoop(historyCopy, loopWindow) {
 				warning := fmt.Sprintf("Loop detected: the last %d tool calls follow a repeating pattern. Try a different approach.", loopWindow)
-				s.mu.Lock()
-				s.history = append(s.history, NewSteeringTurn(warning))
-				s.mu.Unlock()
+				s.recordTurn(NewSteeringTurn(warning), nil, nil)
 				s.emitter.Emit(EventLoopDetection, map[string]interface{}{
 					"message": warning,
 				})
@@ -376,9 +623,7 @@ func (s *Session) drainSteering() {
 	s.mu.Unlock()
 
 	for _, msg := range messages {
-		s.mu.Lock()
-		s.history = append(s.history, NewSteeringTurn(msg))
-		s.mu.Unlock()
+		s.recordTurn(NewSteeringTurn(msg), nil, nil)
 		s.emitter.Emit(EventSteeringInjected, map[string]interface{}{
 			"content": msg,
 		})
@@ -416,16 +661,33 @@ func (s *Session) executeToolCallsParallel(ctx context.Context, toolCalls []unif
 	return results
 }
 
-// executeSingleTool handles the full tool execution pipeline:
-// lookup -> execute -> truncate -> emit -> return
-func (s *Session) executeSingleTool(_ context.Context, toolCall unifiedllm.ToolCall) unifiedllm.ToolResult {
+// executeSingleTool instruments executeSingleToolImpl with a span and the
+// agentloop_tool_calls_total counter, recording exactly once regardless of
+// which of executeSingleToolImpl's exit paths (unknown tool, policy
+// denial, approval denial, executor error, success) was taken.
+func (s *Session) executeSingleTool(ctx context.Context, toolCall unifiedllm.ToolCall) unifiedllm.ToolResult {
+	ctx, span := s.startSpan(ctx, "agentloop.tool."+toolCall.Name, attribute.String("tool.name", toolCall.Name))
+
+	result := s.executeSingleToolImpl(ctx, toolCall)
+
+	s.metrics.recordToolCall(toolCall.Name, result.IsError)
+	if result.IsError {
+		span.SetStatus(codes.Error, fmt.Sprintf("%v", result.Content))
+	}
+	span.End()
+	return result
+}
+
+// executeSingleToolImpl handles the full tool execution pipeline:
+// lookup -> authorize -> approve -> execute -> truncate -> emit -> return
+func (s *Session) executeSingleToolImpl(ctx context.Context, toolCall unifiedllm.ToolCall) unifiedllm.ToolResult {
 	s.emitter.Emit(EventToolCallStart, map[string]interface{}{
 		"tool_name": toolCall.Name,
 		"call_id":   toolCall.ID,
 	})
 
-	// 1. Lookup tool in registry.
-	registered := s.profile.ToolRegistry().Get(toolCall.Name)
+	// 1. Lookup tool in registry, scoped to the active agent profile if any.
+	registered := s.effectiveToolRegistry().Get(toolCall.Name)
 	if registered == nil {
 		errorMsg := fmt.Sprintf("Unknown tool: %s", toolCall.Name)
 		s.emitter.Emit(EventToolCallEnd, map[string]interface{}{
@@ -439,8 +701,38 @@ func (s *Session) executeSingleTool(_ context.Context, toolCall unifiedllm.ToolC
 		}
 	}
 
-	// 2. Execute via execution environment.
-	rawOutput, err := registered.Executor(toolCall.Arguments, s.env)
+	// 2. Gate on declarative tool-authorization policy, if the provider
+	// profile configures one. Unlike approvalGate below, a denial here is
+	// final -- there's no human to escalate to -- so it runs first and
+	// short-circuits before any approval prompt would otherwise fire.
+	if authorizer := s.profile.Authorizer(); authorizer != nil {
+		workingDir := s.effectiveExecutionEnvironment().WorkingDirectory()
+		if decision := authorizer.Authorize(toolCall.Name, toolCall.Arguments, workingDir, s.config.Principal); !decision.Allowed {
+			errorMsg := fmt.Sprintf("Tool call %q was denied by policy: %s", toolCall.Name, decision.Reason)
+			s.emitter.Emit(EventToolCallEnd, map[string]interface{}{
+				"call_id": toolCall.ID,
+				"error":   errorMsg,
+			})
+			return unifiedllm.ToolResult{
+				ToolCallID: toolCall.ID,
+				Content:    errorMsg,
+				IsError:    true,
+			}
+		}
+	}
+
+	// 3. Gate on human approval, if this call requires it.
+	if proceed, denied := s.approvalGate(ctx, toolCall, registered.RequiresApproval); !proceed {
+		s.emitter.Emit(EventToolCallEnd, map[string]interface{}{
+			"call_id": toolCall.ID,
+			"error":   denied.Content,
+		})
+		return denied
+	}
+
+	// 4. Execute via execution environment, scoped to the active agent
+	// profile's credentials if any.
+	result, err := registered.Executor(toolCall.Arguments, s.effectiveExecutionEnvironment())
 	if err != nil {
 		errorMsg := fmt.Sprintf("Tool error (%s): %v", toolCall.Name, err)
 		s.emitter.Emit(EventToolCallEnd, map[string]interface{}{
@@ -453,67 +745,43 @@ func (s *Session) executeSingleTool(_ context.Context, toolCall unifiedllm.ToolC
 			IsError:    true,
 		}
 	}
+	rawOutput := RenderToolResultText(result)
 
-	// 3. Truncate output before sending to LLM.
-	s.mu.Lock()
-	charLimits := s.config.ToolOutputLimits
-	lineLimits := s.config.ToolLineLimits
-	s.mu.Unlock()
-	truncatedOutput := TruncateToolOutput(rawOutput, toolCall.Name, charLimits, lineLimits)
+	// 5. Truncate output before sending to LLM.
+	charLimits, lineLimits := s.effectiveToolLimits()
+	tokenizer, tokenLimits := s.effectiveTokenizer()
+	truncatedOutput := TruncateToolOutput(rawOutput, toolCall.Name, tokenizer, tokenLimits, charLimits, lineLimits)
 
-	// 4. Emit full output via event stream (not truncated).
+	// 6. Emit full output via event stream (not truncated).
 	s.emitter.Emit(EventToolCallEnd, map[string]interface{}{
 		"call_id": toolCall.ID,
 		"output":  rawOutput, // Full untruncated output.
 	})
 
-	// 5. Return truncated output as ToolResult.
+	// 7. Return truncated output as ToolResult.
 	return unifiedllm.ToolResult{
 		ToolCallID: toolCall.ID,
 		Content:    truncatedOutput,
-		IsError:    false,
+		IsError:    result.IsError,
 	}
 }
 
-// countTurns returns the number of user and assistant turns in the history.
+// countTurns returns the number of user and assistant turns in the history,
+// including those folded into a TurnSummary by compaction, so compacting
+// older turns away never lets MaxTurns silently stop applying.
 func (s *Session) countTurns() int {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 	count := 0
 	for _, turn := range s.history {
-		if turn.Kind == TurnUser || turn.Kind == TurnAssistant {
+		switch turn.Kind {
+		case TurnUser, TurnAssistant:
 			count++
-		}
-	}
-	return count
-}
-
-// checkContextUsage emits a warning if context usage exceeds 80%.
-func (s *Session) checkContextUsage() {
-	s.mu.Lock()
-	history := make([]Turn, len(s.history))
-	copy(history, s.history)
-	contextWindow := s.profile.ContextWindowSize()
-	s.mu.Unlock()
-
-	totalChars := 0
-	for _, turn := range history {
-		totalChars += len(turn.TextContent())
-		if turn.Kind == TurnToolResults && turn.ToolResults != nil {
-			for _, r := range turn.ToolResults.Results {
-				if s, ok := r.Content.(string); ok {
-					totalChars += len(s)
-				}
+		case TurnSummary:
+			if turn.Summary != nil {
+				count += turn.Summary.FoldedTurnCount
 			}
 		}
 	}
-
-	approxTokens := totalChars / 4
-	threshold := int(float64(contextWindow) * 0.8)
-	if approxTokens > threshold {
-		pct := int(float64(approxTokens) / float64(contextWindow) * 100)
-		s.emitter.Emit(EventWarning, map[string]interface{}{
-			"message": fmt.Sprintf("Context usage at ~%d%% of context window", pct),
-		})
-	}
+	return count
 }
@@ -0,0 +1,364 @@
+package agentloop
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// LegacyStringEditEnabled gates edit_file's original string-diff/fuzzy-match
+// path and apply_patch's v4a text-diff path. When true (the default, for
+// backward compatibility) both tools behave exactly as they always have.
+// When false, both dispatch onto the structured insert_at/replace_range/
+// delete_range primitive (spliceLines) instead: edit_file locates
+// old_string's line span and splices it directly rather than doing a raw
+// strings.Replace, and apply_patch's v4aUpdate hunks are applied the same
+// way instead of through gitdiff. Models are substantially more reliable
+// emitting a JSON tool call with explicit line ranges than producing a
+// unified diff or custom patch format inside a code block, so new
+// integrations should prefer the structured tools directly and set this to
+// false; it defaults to true so existing integrations built around
+// edit_file/apply_patch's current behavior don't change underfoot.
+var LegacyStringEditEnabled = true
+
+// RegisterStructuredEditTools registers insert_at, replace_range,
+// delete_range, create_file, and rename_file on reg: structured
+// alternatives to edit_file/apply_patch whose Parameters describe exact
+// file paths, 1-based line ranges, and replacement content, rather than
+// requiring the model to produce a unified diff or custom patch format.
+// allowlist restricts which of them get registered; a nil or empty
+// allowlist registers all five, matching RegisterCoreTools' convention.
+func RegisterStructuredEditTools(reg *ToolRegistry, allowlist ...string) {
+	if coreToolAllowed("insert_at", allowlist) {
+		registerInsertAt(reg)
+	}
+	if coreToolAllowed("replace_range", allowlist) {
+		registerReplaceRange(reg)
+	}
+	if coreToolAllowed("delete_range", allowlist) {
+		registerDeleteRange(reg)
+	}
+	if coreToolAllowed("create_file", allowlist) {
+		registerCreateFile(reg)
+	}
+	if coreToolAllowed("rename_file", allowlist) {
+		registerRenameFile(reg)
+	}
+}
+
+// spliceLines replaces content's lines [startLine, endLine] (1-based,
+// inclusive) with replacement, returning the resulting raw file content.
+// Pass endLine = startLine-1 for a pure insertion before startLine with
+// nothing removed, and a nil/empty replacement to delete the range. It's
+// the shared primitive behind insert_at, replace_range, delete_range, and
+// (when LegacyStringEditEnabled is false) edit_file and apply_patch.
+func spliceLines(content string, startLine, endLine int, replacement []string) (string, error) {
+	lines := strings.Split(content, "\n")
+	if startLine < 1 || startLine > len(lines)+1 {
+		return "", fmt.Errorf("start_line %d is out of range (file has %d lines)", startLine, len(lines))
+	}
+	if endLine < startLine-1 || endLine > len(lines) {
+		return "", fmt.Errorf("end_line %d is out of range (file has %d lines)", endLine, len(lines))
+	}
+
+	before := append([]string{}, lines[:startLine-1]...)
+	after := lines[endLine:]
+	result := append(append(before, replacement...), after...)
+	return strings.Join(result, "\n"), nil
+}
+
+// lineSpan returns the 1-based [startLine, endLine] (inclusive) range
+// substr occupies within content, assuming content's first occurrence of
+// substr is the one being replaced (edit_file's non-replace_all path).
+func lineSpan(content, substr string) (startLine, endLine int) {
+	idx := strings.Index(content, substr)
+	if idx < 0 {
+		return 0, 0
+	}
+	startLine = strings.Count(content[:idx], "\n") + 1
+	endLine = startLine + strings.Count(substr, "\n")
+	return startLine, endLine
+}
+
+// lineAligned reports whether substr's first occurrence in content starts
+// and ends on line boundaries, the precondition for dispatching edit_file
+// through spliceLines instead of a raw string substitution: a match that
+// starts or ends mid-line can't be expressed as a whole-line range.
+func lineAligned(content, substr string) bool {
+	idx := strings.Index(content, substr)
+	if idx < 0 {
+		return false
+	}
+	if idx != 0 && content[idx-1] != '\n' {
+		return false
+	}
+	end := idx + len(substr)
+	if end != len(content) && content[end] != '\n' {
+		return false
+	}
+	return true
+}
+
+func registerInsertAt(reg *ToolRegistry) {
+	reg.Register(RegisteredTool{
+		Definition: ToolDefinition{
+			Name:        "insert_at",
+			Description: "Insert content before a given line number, shifting existing lines down. Use line = (current line count + 1) to append at end of file.",
+			Parameters: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"file_path": map[string]interface{}{
+						"type":        "string",
+						"description": "Path to the file to edit.",
+					},
+					"line": map[string]interface{}{
+						"type":        "integer",
+						"description": "1-based line number to insert before.",
+					},
+					"content": map[string]interface{}{
+						"type":        "string",
+						"description": "Text to insert. May span multiple lines.",
+					},
+				},
+				"required": []string{"file_path", "line", "content"},
+			},
+		},
+		Executor: func(arguments json.RawMessage, env ExecutionEnvironment) (ToolResult, error) {
+			args, err := ParseToolArguments(arguments)
+			if err != nil {
+				return ToolResult{}, err
+			}
+			filePath, ok := GetStringArg(args, "file_path")
+			if !ok || filePath == "" {
+				return ToolResult{}, fmt.Errorf("file_path is required")
+			}
+			line, ok := GetIntArg(args, "line")
+			if !ok {
+				return ToolResult{}, fmt.Errorf("line is required")
+			}
+			content, _ := GetStringArg(args, "content")
+
+			rawContent, err := readRawFile(env, filePath)
+			if err != nil {
+				return ToolResult{}, fmt.Errorf("file not found: %s", filePath)
+			}
+			newContent, err := spliceLines(rawContent, line, line-1, strings.Split(content, "\n"))
+			if err != nil {
+				return ToolResult{}, err
+			}
+			if err := env.WriteFile(filePath, newContent); err != nil {
+				return ToolResult{}, err
+			}
+			return LegacyStringResult(fmt.Sprintf("Inserted content before line %d of %s", line, filePath)), nil
+		},
+	})
+}
+
+func registerReplaceRange(reg *ToolRegistry) {
+	reg.Register(RegisteredTool{
+		Definition: ToolDefinition{
+			Name:        "replace_range",
+			Description: "Replace an inclusive 1-based line range in a file with new content.",
+			Parameters: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"file_path": map[string]interface{}{
+						"type":        "string",
+						"description": "Path to the file to edit.",
+					},
+					"start_line": map[string]interface{}{
+						"type":        "integer",
+						"description": "1-based first line to replace, inclusive.",
+					},
+					"end_line": map[string]interface{}{
+						"type":        "integer",
+						"description": "1-based last line to replace, inclusive.",
+					},
+					"content": map[string]interface{}{
+						"type":        "string",
+						"description": "Replacement text. May span multiple lines.",
+					},
+				},
+				"required": []string{"file_path", "start_line", "end_line", "content"},
+			},
+		},
+		Executor: func(arguments json.RawMessage, env ExecutionEnvironment) (ToolResult, error) {
+			args, err := ParseToolArguments(arguments)
+			if err != nil {
+				return ToolResult{}, err
+			}
+			filePath, ok := GetStringArg(args, "file_path")
+			if !ok || filePath == "" {
+				return ToolResult{}, fmt.Errorf("file_path is required")
+			}
+			startLine, ok := GetIntArg(args, "start_line")
+			if !ok {
+				return ToolResult{}, fmt.Errorf("start_line is required")
+			}
+			endLine, ok := GetIntArg(args, "end_line")
+			if !ok {
+				return ToolResult{}, fmt.Errorf("end_line is required")
+			}
+			content, _ := GetStringArg(args, "content")
+
+			rawContent, err := readRawFile(env, filePath)
+			if err != nil {
+				return ToolResult{}, fmt.Errorf("file not found: %s", filePath)
+			}
+			newContent, err := spliceLines(rawContent, startLine, endLine, strings.Split(content, "\n"))
+			if err != nil {
+				return ToolResult{}, err
+			}
+			if err := env.WriteFile(filePath, newContent); err != nil {
+				return ToolResult{}, err
+			}
+			return LegacyStringResult(fmt.Sprintf("Replaced lines %d-%d of %s", startLine, endLine, filePath)), nil
+		},
+	})
+}
+
+func registerDeleteRange(reg *ToolRegistry) {
+	reg.Register(RegisteredTool{
+		Definition: ToolDefinition{
+			Name:        "delete_range",
+			Description: "Delete an inclusive 1-based line range from a file.",
+			Parameters: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"file_path": map[string]interface{}{
+						"type":        "string",
+						"description": "Path to the file to edit.",
+					},
+					"start_line": map[string]interface{}{
+						"type":        "integer",
+						"description": "1-based first line to delete, inclusive.",
+					},
+					"end_line": map[string]interface{}{
+						"type":        "integer",
+						"description": "1-based last line to delete, inclusive.",
+					},
+				},
+				"required": []string{"file_path", "start_line", "end_line"},
+			},
+		},
+		Executor: func(arguments json.RawMessage, env ExecutionEnvironment) (ToolResult, error) {
+			args, err := ParseToolArguments(arguments)
+			if err != nil {
+				return ToolResult{}, err
+			}
+			filePath, ok := GetStringArg(args, "file_path")
+			if !ok || filePath == "" {
+				return ToolResult{}, fmt.Errorf("file_path is required")
+			}
+			startLine, ok := GetIntArg(args, "start_line")
+			if !ok {
+				return ToolResult{}, fmt.Errorf("start_line is required")
+			}
+			endLine, ok := GetIntArg(args, "end_line")
+			if !ok {
+				return ToolResult{}, fmt.Errorf("end_line is required")
+			}
+
+			rawContent, err := readRawFile(env, filePath)
+			if err != nil {
+				return ToolResult{}, fmt.Errorf("file not found: %s", filePath)
+			}
+			newContent, err := spliceLines(rawContent, startLine, endLine, nil)
+			if err != nil {
+				return ToolResult{}, err
+			}
+			if err := env.WriteFile(filePath, newContent); err != nil {
+				return ToolResult{}, err
+			}
+			return LegacyStringResult(fmt.Sprintf("Deleted lines %d-%d of %s", startLine, endLine, filePath)), nil
+		},
+	})
+}
+
+func registerCreateFile(reg *ToolRegistry) {
+	reg.Register(RegisteredTool{
+		Definition: ToolDefinition{
+			Name:        "create_file",
+			Description: "Create a new file with the given content. Fails if the file already exists; use write_file to overwrite.",
+			Parameters: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"file_path": map[string]interface{}{
+						"type":        "string",
+						"description": "Absolute path of the file to create.",
+					},
+					"content": map[string]interface{}{
+						"type":        "string",
+						"description": "The full file content.",
+					},
+				},
+				"required": []string{"file_path", "content"},
+			},
+		},
+		Executor: func(arguments json.RawMessage, env ExecutionEnvironment) (ToolResult, error) {
+			args, err := ParseToolArguments(arguments)
+			if err != nil {
+				return ToolResult{}, err
+			}
+			filePath, ok := GetStringArg(args, "file_path")
+			if !ok || filePath == "" {
+				return ToolResult{}, fmt.Errorf("file_path is required")
+			}
+			content, ok := GetStringArg(args, "content")
+			if !ok {
+				return ToolResult{}, fmt.Errorf("content is required")
+			}
+			if env.FileExists(filePath) {
+				return ToolResult{}, fmt.Errorf("%s already exists; use write_file to overwrite", filePath)
+			}
+			if err := env.WriteFile(filePath, content); err != nil {
+				return ToolResult{}, err
+			}
+			return LegacyStringResult(fmt.Sprintf("Created %s (%d bytes)", filePath, len(content))), nil
+		},
+	})
+}
+
+func registerRenameFile(reg *ToolRegistry) {
+	reg.Register(RegisteredTool{
+		Definition: ToolDefinition{
+			Name:        "rename_file",
+			Description: "Rename (move) a file from old_path to new_path; the old path no longer exists afterward.",
+			Parameters: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"old_path": map[string]interface{}{
+						"type":        "string",
+						"description": "Path of the existing file.",
+					},
+					"new_path": map[string]interface{}{
+						"type":        "string",
+						"description": "Destination path.",
+					},
+				},
+				"required": []string{"old_path", "new_path"},
+			},
+		},
+		Executor: func(arguments json.RawMessage, env ExecutionEnvironment) (ToolResult, error) {
+			args, err := ParseToolArguments(arguments)
+			if err != nil {
+				return ToolResult{}, err
+			}
+			oldPath, ok := GetStringArg(args, "old_path")
+			if !ok || oldPath == "" {
+				return ToolResult{}, fmt.Errorf("old_path is required")
+			}
+			newPath, ok := GetStringArg(args, "new_path")
+			if !ok || newPath == "" {
+				return ToolResult{}, fmt.Errorf("new_path is required")
+			}
+			if !env.FileExists(oldPath) {
+				return ToolResult{}, fmt.Errorf("file not found: %s", oldPath)
+			}
+			if err := env.RenameFile(oldPath, newPath); err != nil {
+				return ToolResult{}, err
+			}
+			return LegacyStringResult(fmt.Sprintf("Renamed %s to %s", oldPath, newPath)), nil
+		},
+	})
+}
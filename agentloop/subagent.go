@@ -4,9 +4,18 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"reflect"
+	"strings"
 	"sync"
+	"time"
 
 	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/martinemde/attractor/metrics"
 )
 
 // SubAgentStatus represents the lifecycle state of a subagent.
@@ -16,15 +25,23 @@ const (
 	SubAgentRunning   SubAgentStatus = "running"
 	SubAgentCompleted SubAgentStatus = "completed"
 	SubAgentFailed    SubAgentStatus = "failed"
+	// SubAgentPaused marks a subagent closed with preserve_state: its
+	// context was cancelled like any other Close, but its state was
+	// persisted via SubAgentManager.SetStore so a later SubAgentManager.Resume
+	// can rehydrate and continue it instead of treating it as failed.
+	SubAgentPaused SubAgentStatus = "paused"
 )
 
 // SubAgentHandle tracks a running subagent.
 type SubAgentHandle struct {
-	ID      string         `json:"id"`
-	Session *Session       `json:"-"`
-	Status  SubAgentStatus `json:"status"`
+	ID      string          `json:"id"`
+	Session *Session        `json:"-"`
+	Status  SubAgentStatus  `json:"status"`
 	Result  *SubAgentResult `json:"result,omitempty"`
+	Task    string          `json:"task"`
 	cancel  context.CancelFunc
+	span    trace.Span    // covers the subagent's lifetime, from Spawn to its terminal status
+	done    chan struct{} // closed once: by the Spawn goroutine when Submit returns, or by Resume for a handle restored in a non-running status
 	mu      sync.Mutex
 }
 
@@ -41,14 +58,26 @@ type SubAgentManager struct {
 	mu       sync.RWMutex
 	maxDepth int
 	depth    int
+	registry metrics.Registry // propagated to spawned sessions, so their own turn/tool metrics land in the same registry
+	metrics  *AgentMetrics
+	tracer   trace.Tracer
+
+	store        SubAgentStore // optional; persists handle metadata across restarts, see SetStore
+	sessionStore SessionStore  // optional; attached to every spawned child session's transcript, see SetStore
 }
 
-// NewSubAgentManager creates a new subagent manager.
-func NewSubAgentManager(maxDepth, currentDepth int) *SubAgentManager {
+// NewSubAgentManager creates a new subagent manager. registry and tracer
+// come from the owning Session's configuration, so every sub-manager in a
+// nesting chain -- and every session it spawns -- reports through the same
+// instruments and emits spans under the same trace.
+func NewSubAgentManager(maxDepth, currentDepth int, registry metrics.Registry, tracer trace.Tracer) *SubAgentManager {
 	return &SubAgentManager{
 		agents:   make(map[string]*SubAgentHandle),
 		maxDepth: maxDepth,
 		depth:    currentDepth,
+		registry: registry,
+		metrics:  NewAgentMetrics(registry),
+		tracer:   tracer,
 	}
 }
 
@@ -64,7 +93,6 @@ func (m *SubAgentManager) Spawn(ctx context.Context, profile ProviderProfile, en
 	}
 
 	id := uuid.New().String()
-	subCtx, cancel := context.WithCancel(ctx)
 
 	subConfig := DefaultSessionConfig()
 	if config != nil {
@@ -73,56 +101,105 @@ func (m *SubAgentManager) Spawn(ctx context.Context, profile ProviderProfile, en
 	subConfig.MaxTurns = 50 // Default subagent turn limit.
 	subConfig.MaxSubagentDepth = m.maxDepth
 	subConfig.subagentDepth = m.depth + 1
+	subConfig.MetricsRegistry = m.registry
+	subConfig.Tracer = m.tracer
+
+	// spanCtx carries the subagent's lifetime span, so every span the
+	// subSession and its own tool calls/subagents create nests under it --
+	// and, transitively, under whatever span ctx already carried (none when
+	// called from the spawn_agent tool, which passes context.Background()).
+	spanCtx, span := m.startSpan(ctx, "agentloop.subagent", attribute.String("subagent.id", id))
+	subCtx, cancel := context.WithCancel(spanCtx)
 
 	subSession := NewSession(profile, env, &subConfig)
+	if m.sessionStore != nil {
+		_ = subSession.SetStore(m.sessionStore)
+	}
 
 	handle := &SubAgentHandle{
 		ID:      id,
 		Session: subSession,
 		Status:  SubAgentRunning,
+		Task:    task,
 		cancel:  cancel,
+		span:    span,
+		done:    make(chan struct{}),
 	}
 
 	m.mu.Lock()
 	m.agents[id] = handle
 	m.mu.Unlock()
+	m.recordEvent(SubAgentRunning)
+	m.persist(handle)
 
 	// Run subagent in background.
 	go func() {
 		err := subSession.Submit(subCtx, task)
 		handle.mu.Lock()
-		defer handle.mu.Unlock()
-
-		turnsUsed := len(subSession.History())
-		lastText := ""
-		for i := len(subSession.History()) - 1; i >= 0; i-- {
-			turn := subSession.History()[i]
-			if turn.Kind == TurnAssistant && turn.Assistant != nil {
-				lastText = turn.Assistant.Content
-				break
-			}
-		}
 
-		if err != nil {
-			handle.Status = SubAgentFailed
-			handle.Result = &SubAgentResult{
-				Output:    fmt.Sprintf("Error: %v", err),
-				Success:   false,
-				TurnsUsed: turnsUsed,
+		// Close(id, true) may have already marked this handle Paused and
+		// persisted it while Submit was unwinding from the context
+		// cancellation that pausing causes; Submit's resulting error in
+		// that case is expected, not a real failure, so the paused status
+		// and result it already recorded are left alone.
+		if handle.Status != SubAgentPaused {
+			turnsUsed := len(subSession.History())
+			lastText := ""
+			for i := len(subSession.History()) - 1; i >= 0; i-- {
+				turn := subSession.History()[i]
+				if turn.Kind == TurnAssistant && turn.Assistant != nil {
+					lastText = turn.Assistant.Content
+					break
+				}
 			}
-		} else {
-			handle.Status = SubAgentCompleted
-			handle.Result = &SubAgentResult{
-				Output:    lastText,
-				Success:   true,
-				TurnsUsed: turnsUsed,
+
+			if err != nil {
+				handle.Status = SubAgentFailed
+				handle.Result = &SubAgentResult{
+					Output:    fmt.Sprintf("Error: %v", err),
+					Success:   false,
+					TurnsUsed: turnsUsed,
+				}
+				span.SetStatus(codes.Error, err.Error())
+			} else {
+				handle.Status = SubAgentCompleted
+				handle.Result = &SubAgentResult{
+					Output:    lastText,
+					Success:   true,
+					TurnsUsed: turnsUsed,
+				}
 			}
 		}
+		handle.mu.Unlock()
+
+		span.End()
+		m.recordEvent(handle.Status)
+		m.persist(handle)
+		close(handle.done)
 	}()
 
 	return handle, nil
 }
 
+// startSpan starts a span named name under ctx via m.tracer, tolerating a
+// nil tracer (a sub-manager constructed directly rather than through
+// NewSession) by falling back to otel's default global TracerProvider,
+// which is a no-op until an application configures a real one.
+func (m *SubAgentManager) startSpan(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	tracer := m.tracer
+	if tracer == nil {
+		tracer = otel.Tracer("github.com/martinemde/attractor/agentloop")
+	}
+	return tracer.Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// recordEvent records a subagent lifecycle transition and the manager's
+// current depth.
+func (m *SubAgentManager) recordEvent(status SubAgentStatus) {
+	m.metrics.recordSubagentEvent(status)
+	m.metrics.recordSubagentDepth(m.depth + 1)
+}
+
 // Get returns a subagent handle by ID.
 func (m *SubAgentManager) Get(id string) *SubAgentHandle {
 	m.mu.RLock()
@@ -130,8 +207,13 @@ func (m *SubAgentManager) Get(id string) *SubAgentHandle {
 	return m.agents[id]
 }
 
-// Close terminates a subagent.
-func (m *SubAgentManager) Close(id string) error {
+// Close terminates a subagent. If preserveState is true, the handle is
+// marked SubAgentPaused instead of SubAgentFailed and, if a SubAgentStore is
+// attached (see SetStore), its current state is persisted, so
+// SubAgentManager.Resume can rehydrate it later instead of treating it as
+// lost. Without a store the handle is still marked paused, but nothing is
+// persisted for Resume to load.
+func (m *SubAgentManager) Close(id string, preserveState bool) error {
 	m.mu.Lock()
 	handle, ok := m.agents[id]
 	m.mu.Unlock()
@@ -139,12 +221,21 @@ func (m *SubAgentManager) Close(id string) error {
 		return fmt.Errorf("subagent %s not found", id)
 	}
 
+	handle.span.AddEvent("closed")
 	handle.cancel()
 	handle.mu.Lock()
 	if handle.Status == SubAgentRunning {
-		handle.Status = SubAgentFailed
+		if preserveState {
+			handle.Status = SubAgentPaused
+		} else {
+			handle.Status = SubAgentFailed
+		}
 	}
 	handle.mu.Unlock()
+
+	if preserveState {
+		m.persist(handle)
+	}
 	return nil
 }
 
@@ -157,8 +248,44 @@ func (m *SubAgentManager) CloseAll() {
 	}
 }
 
-// RegisterSubagentTools registers spawn_agent, send_input, wait, and
-// close_agent tools on the given registry.
+// waitOne blocks until handle reaches a terminal status or ctx is done,
+// returning false in the latter case.
+func waitOne(ctx context.Context, handle *SubAgentHandle) bool {
+	select {
+	case <-handle.done:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// waitForAny blocks until any handle in handles reaches a terminal status,
+// or until timeout elapses (timeout <= 0 means no additional deadline beyond
+// ctx itself), returning the first handle to finish or nil on timeout/cancel.
+// It uses reflect.Select because the number of handles is only known at
+// runtime.
+func waitForAny(ctx context.Context, handles []*SubAgentHandle, timeout time.Duration) *SubAgentHandle {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	cases := make([]reflect.SelectCase, 0, len(handles)+1)
+	for _, h := range handles {
+		cases = append(cases, reflect.SelectCase{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(h.done)})
+	}
+	cases = append(cases, reflect.SelectCase{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(ctx.Done())})
+
+	chosen, _, _ := reflect.Select(cases)
+	if chosen == len(handles) {
+		return nil
+	}
+	return handles[chosen]
+}
+
+// RegisterSubagentTools registers spawn_agent, send_input, wait, wait_any,
+// wait_all, and close_agent tools on the given registry.
 func RegisterSubagentTools(reg *ToolRegistry, manager *SubAgentManager, profile ProviderProfile, env ExecutionEnvironment) {
 	reg.Register(RegisteredTool{
 		Definition: ToolDefinition{
@@ -183,14 +310,14 @@ func RegisterSubagentTools(reg *ToolRegistry, manager *SubAgentManager, profile
 				"required": []string{"task"},
 			},
 		},
-		Executor: func(arguments json.RawMessage, execEnv ExecutionEnvironment) (string, error) {
+		Executor: func(arguments json.RawMessage, execEnv ExecutionEnvironment) (ToolResult, error) {
 			args, err := ParseToolArguments(arguments)
 			if err != nil {
-				return "", err
+				return ToolResult{}, err
 			}
 			task, ok := GetStringArg(args, "task")
 			if !ok || task == "" {
-				return "", fmt.Errorf("task is required")
+				return ToolResult{}, fmt.Errorf("task is required")
 			}
 
 			config := DefaultSessionConfig()
@@ -200,9 +327,9 @@ func RegisterSubagentTools(reg *ToolRegistry, manager *SubAgentManager, profile
 
 			handle, err := manager.Spawn(context.Background(), profile, execEnv, task, &config)
 			if err != nil {
-				return "", err
+				return ToolResult{}, err
 			}
-			return fmt.Sprintf("Subagent spawned with ID: %s\nStatus: %s", handle.ID, handle.Status), nil
+			return LegacyStringResult(fmt.Sprintf("Subagent spawned with ID: %s\nStatus: %s", handle.ID, handle.Status)), nil
 		},
 	})
 
@@ -225,21 +352,21 @@ func RegisterSubagentTools(reg *ToolRegistry, manager *SubAgentManager, profile
 				"required": []string{"agent_id", "message"},
 			},
 		},
-		Executor: func(arguments json.RawMessage, execEnv ExecutionEnvironment) (string, error) {
+		Executor: func(arguments json.RawMessage, execEnv ExecutionEnvironment) (ToolResult, error) {
 			args, err := ParseToolArguments(arguments)
 			if err != nil {
-				return "", err
+				return ToolResult{}, err
 			}
 			agentID, _ := GetStringArg(args, "agent_id")
 			message, _ := GetStringArg(args, "message")
 
 			handle := manager.Get(agentID)
 			if handle == nil {
-				return "", fmt.Errorf("subagent %s not found", agentID)
+				return ToolResult{}, fmt.Errorf("subagent %s not found", agentID)
 			}
 
 			handle.Session.Steer(message)
-			return fmt.Sprintf("Message sent to subagent %s", agentID), nil
+			return LegacyStringResult(fmt.Sprintf("Message sent to subagent %s", agentID)), nil
 		},
 	})
 
@@ -258,42 +385,165 @@ func RegisterSubagentTools(reg *ToolRegistry, manager *SubAgentManager, profile
 				"required": []string{"agent_id"},
 			},
 		},
-		Executor: func(arguments json.RawMessage, execEnv ExecutionEnvironment) (string, error) {
+		Executor: func(arguments json.RawMessage, execEnv ExecutionEnvironment) (ToolResult, error) {
 			args, err := ParseToolArguments(arguments)
 			if err != nil {
-				return "", err
+				return ToolResult{}, err
 			}
 			agentID, _ := GetStringArg(args, "agent_id")
 
 			handle := manager.Get(agentID)
 			if handle == nil {
-				return "", fmt.Errorf("subagent %s not found", agentID)
+				return ToolResult{}, fmt.Errorf("subagent %s not found", agentID)
 			}
 
-			// Poll until done.
-			for {
+			handle.span.AddEvent("wait_started")
+			waitOne(context.Background(), handle)
+
+			handle.mu.Lock()
+			status := handle.Status
+			result := handle.Result
+			handle.mu.Unlock()
+
+			handle.span.AddEvent("wait_completed", trace.WithAttributes(attribute.String("subagent.status", string(status))))
+			if result != nil {
+				return LegacyStringResult(fmt.Sprintf("Status: %s\nTurns used: %d\nOutput:\n%s",
+					status, result.TurnsUsed, result.Output)), nil
+			}
+			return LegacyStringResult(fmt.Sprintf("Status: %s", status)), nil
+		},
+	})
+
+	reg.Register(RegisteredTool{
+		Definition: ToolDefinition{
+			Name:        "wait_any",
+			Description: "Wait for the first of several subagents to complete and return its result.",
+			Parameters: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"agent_ids": map[string]interface{}{
+						"type":        "array",
+						"items":       map[string]interface{}{"type": "string"},
+						"description": "The subagent IDs to wait on.",
+					},
+					"timeout_ms": map[string]interface{}{
+						"type":        "integer",
+						"description": "Optional timeout in milliseconds. 0 or omitted waits indefinitely.",
+					},
+				},
+				"required": []string{"agent_ids"},
+			},
+		},
+		Executor: func(arguments json.RawMessage, execEnv ExecutionEnvironment) (ToolResult, error) {
+			args, err := ParseToolArguments(arguments)
+			if err != nil {
+				return ToolResult{}, err
+			}
+			agentIDs, ok := GetStringSliceArg(args, "agent_ids")
+			if !ok || len(agentIDs) == 0 {
+				return ToolResult{}, fmt.Errorf("agent_ids is required")
+			}
+			timeoutMs, _ := GetIntArg(args, "timeout_ms")
+
+			handles := make([]*SubAgentHandle, 0, len(agentIDs))
+			for _, id := range agentIDs {
+				handle := manager.Get(id)
+				if handle == nil {
+					return ToolResult{}, fmt.Errorf("subagent %s not found", id)
+				}
+				handles = append(handles, handle)
+			}
+
+			done := waitForAny(context.Background(), handles, time.Duration(timeoutMs)*time.Millisecond)
+			if done == nil {
+				return LegacyStringResult("Status: timed out waiting for any subagent"), nil
+			}
+
+			done.mu.Lock()
+			status := done.Status
+			result := done.Result
+			done.mu.Unlock()
+
+			if result != nil {
+				return LegacyStringResult(fmt.Sprintf("Agent ID: %s\nStatus: %s\nTurns used: %d\nOutput:\n%s",
+					done.ID, status, result.TurnsUsed, result.Output)), nil
+			}
+			return LegacyStringResult(fmt.Sprintf("Agent ID: %s\nStatus: %s", done.ID, status)), nil
+		},
+	})
+
+	reg.Register(RegisteredTool{
+		Definition: ToolDefinition{
+			Name:        "wait_all",
+			Description: "Wait for all of several subagents to complete and return their results.",
+			Parameters: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"agent_ids": map[string]interface{}{
+						"type":        "array",
+						"items":       map[string]interface{}{"type": "string"},
+						"description": "The subagent IDs to wait on.",
+					},
+					"timeout_ms": map[string]interface{}{
+						"type":        "integer",
+						"description": "Optional timeout in milliseconds, shared across all waits. 0 or omitted waits indefinitely.",
+					},
+				},
+				"required": []string{"agent_ids"},
+			},
+		},
+		Executor: func(arguments json.RawMessage, execEnv ExecutionEnvironment) (ToolResult, error) {
+			args, err := ParseToolArguments(arguments)
+			if err != nil {
+				return ToolResult{}, err
+			}
+			agentIDs, ok := GetStringSliceArg(args, "agent_ids")
+			if !ok || len(agentIDs) == 0 {
+				return ToolResult{}, fmt.Errorf("agent_ids is required")
+			}
+			timeoutMs, _ := GetIntArg(args, "timeout_ms")
+
+			handles := make([]*SubAgentHandle, 0, len(agentIDs))
+			for _, id := range agentIDs {
+				handle := manager.Get(id)
+				if handle == nil {
+					return ToolResult{}, fmt.Errorf("subagent %s not found", id)
+				}
+				handles = append(handles, handle)
+			}
+
+			ctx := context.Background()
+			if timeoutMs > 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, time.Duration(timeoutMs)*time.Millisecond)
+				defer cancel()
+			}
+
+			var sb []string
+			for _, handle := range handles {
+				if !waitOne(ctx, handle) {
+					sb = append(sb, fmt.Sprintf("Agent ID: %s\nStatus: timed out waiting", handle.ID))
+					continue
+				}
 				handle.mu.Lock()
 				status := handle.Status
 				result := handle.Result
 				handle.mu.Unlock()
-
-				if status != SubAgentRunning {
-					if result != nil {
-						return fmt.Sprintf("Status: %s\nTurns used: %d\nOutput:\n%s",
-							status, result.TurnsUsed, result.Output), nil
-					}
-					return fmt.Sprintf("Status: %s", status), nil
+				if result != nil {
+					sb = append(sb, fmt.Sprintf("Agent ID: %s\nStatus: %s\nTurns used: %d\nOutput:\n%s",
+						handle.ID, status, result.TurnsUsed, result.Output))
+				} else {
+					sb = append(sb, fmt.Sprintf("Agent ID: %s\nStatus: %s", handle.ID, status))
 				}
-				// Brief sleep to avoid busy-waiting (would use proper
-				// signaling in production).
 			}
+			return LegacyStringResult(strings.Join(sb, "\n\n")), nil
 		},
 	})
 
 	reg.Register(RegisteredTool{
 		Definition: ToolDefinition{
 			Name:        "close_agent",
-			Description: "Terminate a subagent.",
+			Description: "Terminate a subagent, optionally preserving its state for a later resume.",
 			Parameters: map[string]interface{}{
 				"type": "object",
 				"properties": map[string]interface{}{
@@ -301,21 +551,29 @@ func RegisterSubagentTools(reg *ToolRegistry, manager *SubAgentManager, profile
 						"type":        "string",
 						"description": "The subagent ID.",
 					},
+					"preserve_state": map[string]interface{}{
+						"type":        "boolean",
+						"description": "If true, persist the subagent's state (requires a SubAgentStore attached via SubAgentManager.SetStore) so it shows as paused and can later be rehydrated with SubAgentManager.Resume, instead of being marked failed.",
+					},
 				},
 				"required": []string{"agent_id"},
 			},
 		},
-		Executor: func(arguments json.RawMessage, execEnv ExecutionEnvironment) (string, error) {
+		Executor: func(arguments json.RawMessage, execEnv ExecutionEnvironment) (ToolResult, error) {
 			args, err := ParseToolArguments(arguments)
 			if err != nil {
-				return "", err
+				return ToolResult{}, err
 			}
 			agentID, _ := GetStringArg(args, "agent_id")
+			preserveState, _ := GetBoolArg(args, "preserve_state")
 
-			if err := manager.Close(agentID); err != nil {
-				return "", err
+			if err := manager.Close(agentID, preserveState); err != nil {
+				return ToolResult{}, err
+			}
+			if preserveState {
+				return LegacyStringResult(fmt.Sprintf("Subagent %s paused", agentID)), nil
 			}
-			return fmt.Sprintf("Subagent %s terminated", agentID), nil
+			return LegacyStringResult(fmt.Sprintf("Subagent %s terminated", agentID)), nil
 		},
 	})
 }
@@ -0,0 +1,254 @@
+package agentloop
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// SubAgentRecord is a SubAgentStore's persisted snapshot of one subagent's
+// handle: its task, lifecycle status, and terminal result (if any). The
+// child session's own turn-by-turn transcript is persisted separately, via
+// SubAgentManager.SetStore's sessionStore and the existing
+// SessionStore/ResumeSession machinery -- ChildSessionID is the key to look
+// it up there, so a SubAgentRecord doesn't have to duplicate it.
+type SubAgentRecord struct {
+	ID             string          `json:"id"`
+	ChildSessionID string          `json:"child_session_id"`
+	Task           string          `json:"task"`
+	Status         SubAgentStatus  `json:"status"`
+	Result         *SubAgentResult `json:"result,omitempty"`
+	// Version supports CASSubAgentStore's optimistic-concurrency check; it
+	// is ignored by stores (like JSONFileSubAgentStore) that don't need it.
+	Version int `json:"version"`
+}
+
+// SubAgentStore persists SubAgentRecords, so a SubAgentManager.Resume can
+// rehydrate a subagent after its parent process restarts.
+type SubAgentStore interface {
+	// Save writes (or overwrites) the record for record.ID.
+	Save(ctx context.Context, record SubAgentRecord) error
+	// Load returns the last record saved for id.
+	Load(ctx context.Context, id string) (SubAgentRecord, error)
+}
+
+// JSONFileSubAgentStore is the default SubAgentStore: one file per
+// subagent, "<dir>/<id>.json", holding the latest SubAgentRecord snapshot
+// (unlike JSONLSessionStore's append-only log, a subagent's record is
+// overwritten in place on every Save, since only the latest snapshot is
+// ever needed).
+type JSONFileSubAgentStore struct {
+	dir string
+	mu  sync.Mutex
+}
+
+// NewJSONFileSubAgentStore creates a JSONFileSubAgentStore rooted at dir.
+// dir is created on first write if it doesn't already exist.
+func NewJSONFileSubAgentStore(dir string) *JSONFileSubAgentStore {
+	return &JSONFileSubAgentStore{dir: dir}
+}
+
+func (s *JSONFileSubAgentStore) path(id string) string {
+	return filepath.Join(s.dir, id+".json")
+}
+
+func (s *JSONFileSubAgentStore) Save(_ context.Context, record SubAgentRecord) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := os.MkdirAll(s.dir, 0o755); err != nil {
+		return fmt.Errorf("subagent store: %w", err)
+	}
+	data, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("subagent store: %w", err)
+	}
+	if err := os.WriteFile(s.path(record.ID), data, 0o644); err != nil {
+		return fmt.Errorf("subagent store: %w", err)
+	}
+	return nil
+}
+
+func (s *JSONFileSubAgentStore) Load(_ context.Context, id string) (SubAgentRecord, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	data, err := os.ReadFile(s.path(id))
+	if err != nil {
+		return SubAgentRecord{}, fmt.Errorf("subagent store: %w", err)
+	}
+	var record SubAgentRecord
+	if err := json.Unmarshal(data, &record); err != nil {
+		return SubAgentRecord{}, fmt.Errorf("subagent store: %w", err)
+	}
+	return record, nil
+}
+
+// ErrVersionConflict is returned by CASSubAgentStore.Save when record.Version
+// does not match the version it last accepted for record.ID: another writer
+// won the race, and the caller should Load the current record and retry.
+var ErrVersionConflict = errors.New("subagent store: version conflict")
+
+// CASSubAgentStore wraps a backing SubAgentStore with an etcd-style
+// optimistic-concurrency check: Save only succeeds if the caller's
+// record.Version matches the version most recently accepted for that ID (0
+// for an ID never saved before), and advances the version on success. This
+// guards against two writers -- e.g. a parent and the supervisor it was
+// Handoff'd to -- silently clobbering each other's snapshot of the same
+// subagent.
+type CASSubAgentStore struct {
+	backing  SubAgentStore
+	mu       sync.Mutex
+	versions map[string]int
+}
+
+// NewCASSubAgentStore wraps backing with version-checked writes.
+func NewCASSubAgentStore(backing SubAgentStore) *CASSubAgentStore {
+	return &CASSubAgentStore{backing: backing, versions: make(map[string]int)}
+}
+
+// Save persists record if record.Version equals the version last accepted
+// for record.ID, then advances that version by one. Returns
+// ErrVersionConflict otherwise, leaving the stored version unchanged.
+func (s *CASSubAgentStore) Save(ctx context.Context, record SubAgentRecord) error {
+	s.mu.Lock()
+	current := s.versions[record.ID]
+	if record.Version != current {
+		s.mu.Unlock()
+		return ErrVersionConflict
+	}
+	s.versions[record.ID] = current + 1
+	s.mu.Unlock()
+
+	record.Version = current + 1
+	return s.backing.Save(ctx, record)
+}
+
+// Load returns the backing store's record for id unchanged.
+func (s *CASSubAgentStore) Load(ctx context.Context, id string) (SubAgentRecord, error) {
+	return s.backing.Load(ctx, id)
+}
+
+// SetStore attaches persistence for this manager's subagents. store saves a
+// handle's metadata snapshot at spawn and again on every terminal or paused
+// transition. sessionStore, if non-nil, is attached (via Session.SetStore)
+// to every subsequently spawned child session, so its turn-by-turn
+// transcript survives a restart the same way a top-level Session's does.
+// Passing nil for either disables that half of persistence; neither is
+// retroactively attached to subagents already spawned.
+func (m *SubAgentManager) SetStore(store SubAgentStore, sessionStore SessionStore) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.store = store
+	m.sessionStore = sessionStore
+}
+
+// persist snapshots handle's current metadata to m.store, if one is
+// attached. Best-effort: a save failure only costs the ability to Resume
+// after a crash, which is a lesser harm than failing a live subagent call
+// over a transient store error.
+func (m *SubAgentManager) persist(handle *SubAgentHandle) {
+	if m.store == nil {
+		return
+	}
+
+	handle.mu.Lock()
+	record := SubAgentRecord{
+		ID:     handle.ID,
+		Task:   handle.Task,
+		Status: handle.Status,
+		Result: handle.Result,
+	}
+	if handle.Session != nil {
+		record.ChildSessionID = handle.Session.ID()
+	}
+	handle.mu.Unlock()
+
+	_ = m.store.Save(context.Background(), record)
+}
+
+// Resume rehydrates a subagent from store: it reloads the handle's
+// SubAgentRecord, then reconstructs its child Session via ResumeSession
+// against sessionStore (using the recorded ChildSessionID), and registers
+// the handle under the manager so Get/wait/send_input/close_agent find it
+// by the same ID again.
+//
+// Resume does not restart the subagent's background Submit loop -- a paused
+// subagent only resumes making progress once a caller calls Submit on its
+// Session (or Continue, if its history ends in an assistant-prefill turn).
+// The send_input tool only queues a steering message, which that next
+// Submit picks up; on its own it runs nothing. A handle whose persisted Status was
+// already terminal (completed/failed) comes back exactly as it was, ready
+// to be read via wait.
+func (m *SubAgentManager) Resume(ctx context.Context, id string, profile ProviderProfile, env ExecutionEnvironment) (*SubAgentHandle, error) {
+	if m.store == nil {
+		return nil, fmt.Errorf("subagent manager: no store attached, cannot resume %s", id)
+	}
+	if m.sessionStore == nil {
+		return nil, fmt.Errorf("subagent manager: no session store attached, cannot resume %s", id)
+	}
+
+	record, err := m.store.Load(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("resume subagent %s: %w", id, err)
+	}
+
+	subSession, err := ResumeSession(ctx, record.ChildSessionID, m.sessionStore, profile, env)
+	if err != nil {
+		return nil, fmt.Errorf("resume subagent %s: %w", id, err)
+	}
+
+	_, cancel := context.WithCancel(ctx)
+	_, span := m.startSpan(ctx, "agentloop.subagent.resumed", attribute.String("subagent.id", id))
+
+	handle := &SubAgentHandle{
+		ID:      record.ID,
+		Session: subSession,
+		Status:  record.Status,
+		Result:  record.Result,
+		Task:    record.Task,
+		cancel:  cancel,
+		span:    span,
+		done:    make(chan struct{}),
+	}
+	if handle.Status != SubAgentRunning {
+		span.End()
+		close(handle.done)
+	}
+
+	m.mu.Lock()
+	m.agents[id] = handle
+	m.mu.Unlock()
+
+	return handle, nil
+}
+
+// Handoff migrates id's live handle from m to newManager without cancelling
+// or interrupting it: the handle (and its running background goroutine, if
+// any) is simply re-owned by newManager, so a replacement parent session can
+// take over supervising it. After a successful Handoff, m no longer tracks
+// id -- further Get/wait/send_input/close_agent calls against m's tools
+// will report it not found, and must instead go through newManager (e.g. a
+// tool registry built from the new parent Session).
+func (m *SubAgentManager) Handoff(id string, newManager *SubAgentManager) error {
+	m.mu.Lock()
+	handle, ok := m.agents[id]
+	if ok {
+		delete(m.agents, id)
+	}
+	m.mu.Unlock()
+	if !ok {
+		return fmt.Errorf("subagent %s not found", id)
+	}
+
+	newManager.mu.Lock()
+	newManager.agents[id] = handle
+	newManager.mu.Unlock()
+	return nil
+}
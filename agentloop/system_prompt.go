@@ -2,8 +2,6 @@ package agentloop
 
 import (
 	"fmt"
-	"os"
-	"os/exec"
 	"path/filepath"
 	"strings"
 	"time"
@@ -11,13 +9,20 @@ import (
 
 const maxProjectDocBytes = 32 * 1024 // 32KB
 
-// BuildEnvironmentContext generates the structured environment context block.
+// BuildEnvironmentContext generates the structured environment context
+// block using DefaultVCS for the git-awareness fields.
 func BuildEnvironmentContext(env ExecutionEnvironment, model string) string {
+	return BuildEnvironmentContextWithVCS(env, model, DefaultVCS)
+}
+
+// BuildEnvironmentContextWithVCS is BuildEnvironmentContext with an explicit
+// VCS, so callers can stub git state in tests or swap in GoGitVCS.
+func BuildEnvironmentContextWithVCS(env ExecutionEnvironment, model string, vcs VCS) string {
 	workingDir := env.WorkingDirectory()
-	isGitRepo := isGitRepository(workingDir)
+	isGitRepo := vcs.IsRepo(workingDir)
 	gitBranch := ""
 	if isGitRepo {
-		gitBranch = getGitBranch(workingDir)
+		gitBranch = vcs.Branch(workingDir)
 	}
 
 	var sb strings.Builder
@@ -37,66 +42,16 @@ func BuildEnvironmentContext(env ExecutionEnvironment, model string) string {
 	return sb.String()
 }
 
-// DiscoverProjectDocs finds and loads project instruction files.
-// It walks from the git root (or working directory) looking for recognized
-// instruction files and loads them according to the provider filter.
-func DiscoverProjectDocs(workingDir string, providerFilter string) string {
-	root := gitRoot(workingDir)
-	if root == "" {
-		root = workingDir
-	}
-
-	// Determine which files to load based on provider.
-	recognizedFiles := []string{"AGENTS.md"} // Always loaded.
-	switch providerFilter {
-	case "anthropic":
-		recognizedFiles = append(recognizedFiles, "CLAUDE.md")
-	case "gemini":
-		recognizedFiles = append(recognizedFiles, "GEMINI.md")
-	case "openai":
-		recognizedFiles = append(recognizedFiles, ".codex/instructions.md")
-	}
-
-	var docs []string
-	totalBytes := 0
-
-	// Collect directories from root to working dir.
-	dirs := collectPathHierarchy(root, workingDir)
-
-	for _, dir := range dirs {
-		for _, fileName := range recognizedFiles {
-			path := filepath.Join(dir, fileName)
-			content, err := os.ReadFile(path)
-			if err != nil {
-				continue
-			}
-
-			remaining := maxProjectDocBytes - totalBytes
-			if remaining <= 0 {
-				docs = append(docs, "[Project instructions truncated at 32KB]")
-				return strings.Join(docs, "\n\n---\n\n")
-			}
-
-			text := string(content)
-			if len(text) > remaining {
-				text = text[:remaining] + "\n[Project instructions truncated at 32KB]"
-			}
-
-			header := fmt.Sprintf("# %s (from %s)", fileName, dir)
-			docs = append(docs, header+"\n\n"+text)
-			totalBytes += len(text)
-		}
-	}
-
-	if len(docs) == 0 {
-		return ""
-	}
-	return strings.Join(docs, "\n\n---\n\n")
+// GetGitContext returns a summary of the git state for the system prompt,
+// using DefaultVCS.
+func GetGitContext(workingDir string) string {
+	return GetGitContextWithVCS(workingDir, DefaultVCS)
 }
 
-// GetGitContext returns a summary of the git state for the system prompt.
-func GetGitContext(workingDir string) string {
-	root := gitRoot(workingDir)
+// GetGitContextWithVCS is GetGitContext with an explicit VCS, so callers can
+// stub git state in tests or swap in GoGitVCS.
+func GetGitContextWithVCS(workingDir string, vcs VCS) string {
+	root := vcs.Root(workingDir)
 	if root == "" {
 		return ""
 	}
@@ -105,21 +60,21 @@ func GetGitContext(workingDir string) string {
 	sb.WriteString("<git_context>\n")
 
 	// Current branch.
-	branch := getGitBranch(root)
+	branch := vcs.Branch(root)
 	if branch != "" {
 		fmt.Fprintf(&sb, "Branch: %s\n", branch)
 	}
 
 	// Short status.
-	status := runGitCommand(root, "status", "--short")
-	if status != "" {
+	status, err := vcs.Status(root)
+	if err == nil && status != "" {
 		lines := strings.Split(strings.TrimSpace(status), "\n")
 		fmt.Fprintf(&sb, "Modified/untracked files: %d\n", len(lines))
 	}
 
 	// Recent commits.
-	log := runGitCommand(root, "log", "--oneline", "-10")
-	if log != "" {
+	log, err := vcs.RecentCommits(root, 10)
+	if err == nil && log != "" {
 		sb.WriteString("Recent commits:\n")
 		sb.WriteString(log)
 		sb.WriteString("\n")
@@ -158,39 +113,9 @@ func collectPathHierarchy(root, target string) []string {
 	return dirs
 }
 
-func isGitRepository(dir string) bool {
-	cmd := exec.Command("git", "rev-parse", "--is-inside-work-tree")
-	cmd.Dir = dir
-	out, err := cmd.Output()
-	return err == nil && strings.TrimSpace(string(out)) == "true"
-}
-
+// gitRoot returns dir's repository root via DefaultVCS, or "" if dir isn't
+// inside a repository. It's used by project-doc discovery, which only needs
+// the root path and not the rest of the VCS interface.
 func gitRoot(dir string) string {
-	cmd := exec.Command("git", "rev-parse", "--show-toplevel")
-	cmd.Dir = dir
-	out, err := cmd.Output()
-	if err != nil {
-		return ""
-	}
-	return strings.TrimSpace(string(out))
-}
-
-func getGitBranch(dir string) string {
-	cmd := exec.Command("git", "rev-parse", "--abbrev-ref", "HEAD")
-	cmd.Dir = dir
-	out, err := cmd.Output()
-	if err != nil {
-		return ""
-	}
-	return strings.TrimSpace(string(out))
-}
-
-func runGitCommand(dir string, args ...string) string {
-	cmd := exec.Command("git", args...)
-	cmd.Dir = dir
-	out, err := cmd.Output()
-	if err != nil {
-		return ""
-	}
-	return string(out)
+	return DefaultVCS.Root(dir)
 }
@@ -0,0 +1,173 @@
+package agentloop
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Tokenizer counts and slices text on token boundaries, so
+// TruncateToolOutput can keep tool output under a token budget instead of
+// the character budget that's the package's default: a char count is a
+// poor proxy for what actually fits in the model's context window (a tool
+// like read_file can return 50,000 chars of CJK or base64 that's
+// dramatically over or under whatever token budget the char limit was
+// meant to approximate).
+type Tokenizer interface {
+	// CountTokens returns the approximate number of tokens s encodes to.
+	CountTokens(s string) int
+	// Encode splits s into its approximate token pieces, in order, so a
+	// caller can slice head/tail by token count rather than byte count.
+	Encode(s string) []string
+	// Decode joins encoded pieces back into text.
+	Decode(tokens []string) string
+}
+
+// DefaultToolTokenLimits mirrors DefaultToolCharLimits but expressed as a
+// token budget. TruncateToolOutput consults this (instead of
+// DefaultToolCharLimits) when called with a non-nil Tokenizer.
+var DefaultToolTokenLimits = map[string]int{
+	"read_file":   12000,
+	"shell":       8000,
+	"grep":        5000,
+	"glob":        5000,
+	"edit_file":   2500,
+	"apply_patch": 2500,
+	"write_file":  250,
+	"spawn_agent": 5000,
+}
+
+// WordTokenizer is the cheap fallback Tokenizer: it approximates tokens as
+// fixed-size rune chunks, the classic "divide character count by ~4"
+// heuristic made slice-able so head/tail truncation can operate on token
+// boundaries without depending on a real tokenizer vocabulary.
+type WordTokenizer struct {
+	ChunkSize int // runes per approximate token; 0 defaults to 4
+}
+
+func (w WordTokenizer) chunkSize() int {
+	if w.ChunkSize > 0 {
+		return w.ChunkSize
+	}
+	return 4
+}
+
+// CountTokens implements Tokenizer.
+func (w WordTokenizer) CountTokens(s string) int {
+	return len(w.Encode(s))
+}
+
+// Encode implements Tokenizer.
+func (w WordTokenizer) Encode(s string) []string {
+	runes := []rune(s)
+	if len(runes) == 0 {
+		return nil
+	}
+	size := w.chunkSize()
+	tokens := make([]string, 0, (len(runes)+size-1)/size)
+	for i := 0; i < len(runes); i += size {
+		end := i + size
+		if end > len(runes) {
+			end = len(runes)
+		}
+		tokens = append(tokens, string(runes[i:end]))
+	}
+	return tokens
+}
+
+// Decode implements Tokenizer.
+func (w WordTokenizer) Decode(tokens []string) string {
+	return strings.Join(tokens, "")
+}
+
+// OpenAITokenizer approximates the token boundaries of OpenAI's
+// cl100k/o200k-family BPE encodings without vendoring tiktoken's
+// merge-rank tables (this repo has no dependency manifest to pull them in
+// from): it treats each run of letters/digits, and each individual
+// punctuation or whitespace rune, as one token. That tracks real BPE token
+// counts far more closely than a flat character-count heuristic for
+// typical English-language and code text.
+type OpenAITokenizer struct{}
+
+// CountTokens implements Tokenizer.
+func (t OpenAITokenizer) CountTokens(s string) int {
+	return len(t.Encode(s))
+}
+
+// Encode implements Tokenizer.
+func (t OpenAITokenizer) Encode(s string) []string {
+	var tokens []string
+	var run []rune
+	flush := func() {
+		if len(run) > 0 {
+			tokens = append(tokens, string(run))
+			run = nil
+		}
+	}
+	for _, r := range s {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			run = append(run, r)
+			continue
+		}
+		flush()
+		tokens = append(tokens, string(r))
+	}
+	flush()
+	return tokens
+}
+
+// Decode implements Tokenizer.
+func (t OpenAITokenizer) Decode(tokens []string) string {
+	return strings.Join(tokens, "")
+}
+
+// ClaudeTokenizer approximates Anthropic's BPE token boundaries. Like
+// OpenAITokenizer it treats each letter/digit run and each punctuation
+// rune as one token, but additionally splits CJK runes one per token:
+// BPE vocabularies trained mostly on whitespace-delimited text tend to
+// assign CJK characters their own token rather than grouping several into
+// one the way they do for English words.
+type ClaudeTokenizer struct{}
+
+// CountTokens implements Tokenizer.
+func (t ClaudeTokenizer) CountTokens(s string) int {
+	return len(t.Encode(s))
+}
+
+// Encode implements Tokenizer.
+func (t ClaudeTokenizer) Encode(s string) []string {
+	var tokens []string
+	var run []rune
+	flush := func() {
+		if len(run) > 0 {
+			tokens = append(tokens, string(run))
+			run = nil
+		}
+	}
+	for _, r := range s {
+		if isCJK(r) {
+			flush()
+			tokens = append(tokens, string(r))
+			continue
+		}
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			run = append(run, r)
+			continue
+		}
+		flush()
+		tokens = append(tokens, string(r))
+	}
+	flush()
+	return tokens
+}
+
+// Decode implements Tokenizer.
+func (t ClaudeTokenizer) Decode(tokens []string) string {
+	return strings.Join(tokens, "")
+}
+
+// isCJK reports whether r belongs to one of the CJK scripts, for
+// ClaudeTokenizer's per-rune tokenization of logographic/syllabic text.
+func isCJK(r rune) bool {
+	return unicode.Is(unicode.Han, r) || unicode.Is(unicode.Hiragana, r) ||
+		unicode.Is(unicode.Katakana, r) || unicode.Is(unicode.Hangul, r)
+}
@@ -0,0 +1,291 @@
+package agentloop
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Principal identifies who (or what tenant) a session is acting on behalf
+// of, supplied once at agent-loop start (see SessionConfig.Principal) and
+// threaded into every ToolAuthorizer.Authorize call. ID is an opaque
+// identifier a PolicyRule can match against; Attrs carries any additional
+// context a custom ToolAuthorizer wants (role, tenant plan, etc.) that this
+// package doesn't otherwise model.
+type Principal struct {
+	ID    string
+	Attrs map[string]string
+}
+
+// AuthzDecision is the result of evaluating a tool call against a
+// ToolAuthorizer.
+type AuthzDecision struct {
+	Allowed bool
+	// Reason explains a denial (empty when Allowed is true). It's surfaced
+	// back to the model in the synthesized tool-error result, so it should
+	// be phrased as guidance the model can act on, not just a log line.
+	Reason string
+}
+
+// authzAllow is the zero-cost happy path shared by every ToolAuthorizer.
+var authzAllow = AuthzDecision{Allowed: true}
+
+// ToolAuthorizer is consulted by Session.executeSingleTool before a looked-up
+// tool is run, the way CommandPolicy is consulted before a shell command
+// runs inside ExecCommand -- except ToolAuthorizer gates every tool call
+// (shell, edit_file, write_file, and any custom or webhook tool), not just
+// shell's command string. A denial is final: unlike approvalGate, there's no
+// human in the loop to escalate to.
+type ToolAuthorizer interface {
+	// Authorize inspects a tool call about to run in workingDir on behalf of
+	// principal and reports whether it's allowed.
+	Authorize(toolName string, arguments json.RawMessage, workingDir string, principal Principal) AuthzDecision
+}
+
+// PolicyDescriber is implemented by a ToolAuthorizer that can render a
+// human-readable summary of its rules. BaseProfile.appendPolicySummary uses
+// this to append the summary to BuildSystemPrompt's output, so the model
+// learns what's forbidden instead of repeatedly attempting it and getting
+// denied.
+type PolicyDescriber interface {
+	Describe() string
+}
+
+// ToolPolicyRule is one allow/deny rule in a ToolPolicyDocument. Rules are
+// evaluated in order; the first matching rule decides the call, so more
+// specific rules should precede general ones.
+type ToolPolicyRule struct {
+	// Tools lists the tool names this rule applies to. Empty matches every
+	// tool.
+	Tools []string `yaml:"tools,omitempty" json:"tools,omitempty"`
+
+	// Effect is "allow" or "deny". Anything else is rejected by
+	// NewPolicyAuthorizer.
+	Effect string `yaml:"effect" json:"effect"`
+
+	// ArgMatchers maps a tool argument name to a pattern it must match for
+	// this rule to apply. By default the pattern is a path.Match glob
+	// (suited to a "path" argument); set the same key in ArgMatcherKind to
+	// "regex" to match it as a regular expression instead (suited to a
+	// "command" argument). An argument whose value isn't a JSON string
+	// never matches.
+	ArgMatchers map[string]string `yaml:"arg_matchers,omitempty" json:"arg_matchers,omitempty"`
+
+	// ArgMatcherKind selects the matcher kind ("glob" or "regex", default
+	// "glob") for the same-named key in ArgMatchers.
+	ArgMatcherKind map[string]string `yaml:"arg_matcher_kind,omitempty" json:"arg_matcher_kind,omitempty"`
+
+	// WorkingDirPrefix restricts this rule to calls running under this
+	// directory prefix. Empty matches any working directory.
+	WorkingDirPrefix string `yaml:"working_dir_prefix,omitempty" json:"working_dir_prefix,omitempty"`
+
+	// PrincipalID restricts this rule to calls made on behalf of this
+	// Principal.ID. Empty matches any principal.
+	PrincipalID string `yaml:"principal_id,omitempty" json:"principal_id,omitempty"`
+
+	// Reason is surfaced to the model (via the synthesized tool-error
+	// result) and to BuildSystemPrompt's policy summary when this rule
+	// denies a call. A deny rule without a Reason gets a generic one.
+	Reason string `yaml:"reason,omitempty" json:"reason,omitempty"`
+}
+
+// ToolPolicyDocument is the on-disk (YAML or JSON) description of a
+// PolicyAuthorizer, the declarative-policy analog of ProfileConfig.
+type ToolPolicyDocument struct {
+	// DefaultAllow decides a call that no rule matches. Defaults to true
+	// (the pre-existing "all registered tools are always callable"
+	// behavior) when nil, so adding a policy document only needs to list
+	// the tools being restricted.
+	DefaultAllow *bool `yaml:"default_allow,omitempty" json:"default_allow,omitempty"`
+
+	Rules []ToolPolicyRule `yaml:"rules,omitempty" json:"rules,omitempty"`
+}
+
+// compiledRule is a ToolPolicyRule with its regex ArgMatchers pre-compiled,
+// so Authorize never pays regexp.Compile's cost on the hot path.
+type compiledRule struct {
+	ToolPolicyRule
+	deny      bool
+	regexArgs map[string]*regexp.Regexp
+}
+
+// PolicyAuthorizer is a ToolAuthorizer built from a declarative
+// ToolPolicyDocument: a first-match-wins rule list over tool name, argument
+// glob/regex matchers, working-directory prefix, and principal ID.
+type PolicyAuthorizer struct {
+	defaultAllow bool
+	rules        []compiledRule
+}
+
+// NewPolicyAuthorizer compiles doc into a PolicyAuthorizer, pre-compiling
+// every regex ArgMatcher so Authorize doesn't recompile patterns per call.
+// Returns an error if doc names an unknown Effect, an unknown
+// ArgMatcherKind, or an invalid regex pattern.
+func NewPolicyAuthorizer(doc ToolPolicyDocument) (*PolicyAuthorizer, error) {
+	defaultAllow := true
+	if doc.DefaultAllow != nil {
+		defaultAllow = *doc.DefaultAllow
+	}
+
+	rules := make([]compiledRule, 0, len(doc.Rules))
+	for i, rule := range doc.Rules {
+		var deny bool
+		switch strings.ToLower(rule.Effect) {
+		case "allow":
+			deny = false
+		case "deny":
+			deny = true
+		default:
+			return nil, fmt.Errorf("agentloop: tool policy rule %d: unknown effect %q (want \"allow\" or \"deny\")", i, rule.Effect)
+		}
+
+		cr := compiledRule{ToolPolicyRule: rule, deny: deny}
+		for key, pattern := range rule.ArgMatchers {
+			if rule.ArgMatcherKind[key] != "regex" {
+				continue
+			}
+			re, err := regexp.Compile(pattern)
+			if err != nil {
+				return nil, fmt.Errorf("agentloop: tool policy rule %d: arg matcher %q: %w", i, key, err)
+			}
+			if cr.regexArgs == nil {
+				cr.regexArgs = make(map[string]*regexp.Regexp)
+			}
+			cr.regexArgs[key] = re
+		}
+		rules = append(rules, cr)
+	}
+
+	return &PolicyAuthorizer{defaultAllow: defaultAllow, rules: rules}, nil
+}
+
+// Authorize evaluates toolName/arguments/workingDir/principal against every
+// rule in order, returning the first match's effect, or the document's
+// DefaultAllow if none match.
+func (a *PolicyAuthorizer) Authorize(toolName string, arguments json.RawMessage, workingDir string, principal Principal) AuthzDecision {
+	for _, rule := range a.rules {
+		if !rule.matches(toolName, arguments, workingDir, principal) {
+			continue
+		}
+		if !rule.deny {
+			return authzAllow
+		}
+		reason := rule.Reason
+		if reason == "" {
+			reason = fmt.Sprintf("tool %q is denied by policy", toolName)
+		}
+		return AuthzDecision{Allowed: false, Reason: reason}
+	}
+	if a.defaultAllow {
+		return authzAllow
+	}
+	return AuthzDecision{Allowed: false, Reason: fmt.Sprintf("tool %q is not on the allow list", toolName)}
+}
+
+func (r compiledRule) matches(toolName string, arguments json.RawMessage, workingDir string, principal Principal) bool {
+	if len(r.Tools) > 0 && !stringSliceContains(r.Tools, toolName) {
+		return false
+	}
+	if r.WorkingDirPrefix != "" && !strings.HasPrefix(workingDir, r.WorkingDirPrefix) {
+		return false
+	}
+	if r.PrincipalID != "" && r.PrincipalID != principal.ID {
+		return false
+	}
+	if len(r.ArgMatchers) == 0 {
+		return true
+	}
+
+	args, err := ParseToolArguments(arguments)
+	if err != nil {
+		return false
+	}
+	for key, pattern := range r.ArgMatchers {
+		value, ok := GetStringArg(args, key)
+		if !ok {
+			return false
+		}
+		if re, isRegex := r.regexArgs[key]; isRegex {
+			if !re.MatchString(value) {
+				return false
+			}
+			continue
+		}
+		matched, err := filepath.Match(pattern, value)
+		if err != nil || !matched {
+			return false
+		}
+	}
+	return true
+}
+
+func stringSliceContains(ss []string, s string) bool {
+	for _, v := range ss {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// Describe renders a's rules as a human-readable summary, in rule order, for
+// BuildSystemPrompt to append to the model's system prompt. It implements
+// PolicyDescriber.
+func (a *PolicyAuthorizer) Describe() string {
+	var sb strings.Builder
+	sb.WriteString("The following tool-use policy is enforced and cannot be bypassed:\n")
+	for _, rule := range a.rules {
+		effect := "allow"
+		if rule.deny {
+			effect = "deny"
+		}
+		scope := "all tools"
+		if len(rule.Tools) > 0 {
+			scope = strings.Join(rule.Tools, ", ")
+		}
+		fmt.Fprintf(&sb, "- %s: %s", effect, scope)
+		if len(rule.ArgMatchers) > 0 {
+			fmt.Fprintf(&sb, " (matching %v)", rule.ArgMatchers)
+		}
+		if rule.WorkingDirPrefix != "" {
+			fmt.Fprintf(&sb, " under %s", rule.WorkingDirPrefix)
+		}
+		if rule.deny && rule.Reason != "" {
+			fmt.Fprintf(&sb, " -- %s", rule.Reason)
+		}
+		sb.WriteString("\n")
+	}
+	if a.defaultAllow {
+		sb.WriteString("- default: allow (any call not matched above)\n")
+	} else {
+		sb.WriteString("- default: deny (any call not matched above)\n")
+	}
+	return sb.String()
+}
+
+// LoadToolPolicy parses a YAML or JSON document (selected by path's
+// extension: .json for JSON, anything else for YAML) into a
+// ToolPolicyDocument and compiles it, following LoadProfileConfigs's
+// extension-based dispatch.
+func LoadToolPolicy(path string) (*PolicyAuthorizer, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("agentloop: read tool policy %s: %w", path, err)
+	}
+
+	var doc ToolPolicyDocument
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		if err := json.Unmarshal(data, &doc); err != nil {
+			return nil, fmt.Errorf("agentloop: parse tool policy %s: %w", path, err)
+		}
+	} else if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("agentloop: parse tool policy %s: %w", path, err)
+	}
+
+	return NewPolicyAuthorizer(doc)
+}
@@ -8,7 +8,43 @@ import (
 
 // ToolExecutor is the function signature for tool execution.
 // It receives parsed arguments and the execution environment.
-type ToolExecutor func(arguments json.RawMessage, env ExecutionEnvironment) (string, error)
+type ToolExecutor func(arguments json.RawMessage, env ExecutionEnvironment) (ToolResult, error)
+
+// Artifact is a piece of non-text (or too-large-for-text) output a tool
+// attaches to its ToolResult, alongside the human-readable Text: a raw
+// file read, a command's stdout/stderr, an image. Exactly one of Bytes or
+// URI should be set; URI is for artifacts too large or unsuitable to
+// inline (e.g. already stored in a blob service).
+type Artifact struct {
+	MIMEType string
+	Name     string
+	Bytes    []byte
+	URI      string
+}
+
+// ToolResult is the structured output of a tool execution. Text is what a
+// provider that only accepts text content sees (via RenderToolResultText);
+// Artifacts and Metadata let callers that understand structure (evaluators,
+// UIs, MCP bridges) consume a tool's output without regex-scraping Text.
+type ToolResult struct {
+	Text      string
+	Artifacts []Artifact
+	Metadata  map[string]interface{}
+	IsError   bool
+}
+
+// LegacyStringResult wraps a plain string in a ToolResult with no
+// artifacts or metadata, for tools that have nothing structured to add.
+func LegacyStringResult(s string) ToolResult {
+	return ToolResult{Text: s}
+}
+
+// RenderToolResultText is the compatibility shim for providers that only
+// accept text content: it renders a ToolResult back down to the string
+// such a provider would have received before ToolResult existed.
+func RenderToolResultText(r ToolResult) string {
+	return r.Text
+}
 
 // ToolDefinition describes a tool for the LLM (serializable metadata).
 type ToolDefinition struct {
@@ -21,6 +57,11 @@ type ToolDefinition struct {
 type RegisteredTool struct {
 	Definition ToolDefinition
 	Executor   ToolExecutor
+	// RequiresApproval marks this tool as always needing a human decision via
+	// Session.ApproveTool before a call to it executes. SessionConfig's
+	// ApprovalPolicy can require approval for additional calls this doesn't
+	// cover, but cannot exempt a tool marked RequiresApproval here.
+	RequiresApproval bool
 }
 
 // ToolRegistry manages tool registration and lookup.
@@ -98,6 +139,21 @@ func (r *ToolRegistry) Clone() *ToolRegistry {
 	return clone
 }
 
+// Subset returns a new registry containing only the named tools that exist
+// in this one; names with no matching tool are silently skipped.
+func (r *ToolRegistry) Subset(names []string) *ToolRegistry {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	sub := NewToolRegistry()
+	for _, name := range names {
+		if tool, ok := r.tools[name]; ok {
+			cloned := *tool
+			sub.tools[name] = &cloned
+		}
+	}
+	return sub
+}
+
 // MergeFrom copies all tools from other into this registry.
 // Existing tools with the same name are overwritten (latest-wins).
 func (r *ToolRegistry) MergeFrom(other *ToolRegistry) {
@@ -183,3 +239,26 @@ func GetBoolArg(args map[string]interface{}, key string) (bool, bool) {
 	b, ok := v.(bool)
 	return b, ok
 }
+
+// GetStringSliceArg extracts a string-array argument from parsed tool
+// arguments (json.Unmarshal decodes a JSON array into []interface{}, so
+// this unwraps and type-asserts each element).
+func GetStringSliceArg(args map[string]interface{}, key string) ([]string, bool) {
+	v, ok := args[key]
+	if !ok {
+		return nil, false
+	}
+	raw, ok := v.([]interface{})
+	if !ok {
+		return nil, false
+	}
+	out := make([]string, 0, len(raw))
+	for _, item := range raw {
+		s, ok := item.(string)
+		if !ok {
+			return nil, false
+		}
+		out = append(out, s)
+	}
+	return out, true
+}
@@ -95,25 +95,72 @@ func TruncateLines(output string, maxLines int) string {
 		strings.Join(lines[len(lines)-tailCount:], "\n")
 }
 
-// TruncateToolOutput applies the full truncation pipeline for a tool:
-// 1. Character-based truncation (primary, handles pathological cases)
-// 2. Line-based truncation (secondary, for readability)
-func TruncateToolOutput(output string, toolName string, charLimits map[string]int, lineLimits map[string]int) string {
-	// Step 1: Character-based truncation.
-	maxChars, ok := charLimits[toolName]
-	if !ok {
-		maxChars, ok = DefaultToolCharLimits[toolName]
-		if !ok {
-			maxChars = 30000 // fallback default
-		}
+// TruncateTokens applies token-based truncation to output, the
+// tokenizer-aware analogue of TruncateOutput: it slices on token
+// boundaries (via tokenizer.Encode/Decode) rather than byte offsets, so
+// output where one token spans many bytes (CJK) or one byte is a fraction
+// of a token (dense base64) is truncated to an accurate budget instead of
+// a character-count proxy.
+func TruncateTokens(output string, tokenizer Tokenizer, maxTokens int, mode TruncationMode) string {
+	tokens := tokenizer.Encode(output)
+	if len(tokens) <= maxTokens {
+		return output
+	}
+
+	switch mode {
+	case TruncateTail:
+		removed := len(tokens) - maxTokens
+		return fmt.Sprintf("[WARNING: Tool output was truncated. First %d tokens were removed. "+
+			"The full output is available in the event stream.]\n\n",
+			removed) +
+			tokenizer.Decode(tokens[len(tokens)-maxTokens:])
+
+	default:
+		// Default to head_tail.
+		half := maxTokens / 2
+		removed := len(tokens) - maxTokens
+		return tokenizer.Decode(tokens[:half]) +
+			fmt.Sprintf("\n\n[WARNING: Tool output was truncated. %d tokens were removed from the middle. "+
+				"The full output is available in the event stream. "+
+				"If you need to see specific parts, re-run the tool with more targeted parameters.]\n\n",
+				removed) +
+			tokenizer.Decode(tokens[len(tokens)-half:])
 	}
+}
 
+// TruncateToolOutput applies the full truncation pipeline for a tool:
+//  1. Primary truncation, handling pathological cases: token-based (via
+//     tokenizer, against tokenLimits/DefaultToolTokenLimits) if tokenizer
+//     is non-nil, otherwise character-based (against charLimits/
+//     DefaultToolCharLimits) as before.
+//  2. Line-based truncation (secondary, for readability)
+func TruncateToolOutput(output string, toolName string, tokenizer Tokenizer, tokenLimits map[string]int, charLimits map[string]int, lineLimits map[string]int) string {
 	mode, ok := DefaultTruncationModes[toolName]
 	if !ok {
 		mode = TruncateHeadTail
 	}
 
-	result := TruncateOutput(output, maxChars, mode)
+	// Step 1: Token or character-based truncation.
+	var result string
+	if tokenizer != nil {
+		maxTokens, ok := tokenLimits[toolName]
+		if !ok {
+			maxTokens, ok = DefaultToolTokenLimits[toolName]
+			if !ok {
+				maxTokens = 8000 // fallback default
+			}
+		}
+		result = TruncateTokens(output, tokenizer, maxTokens, mode)
+	} else {
+		maxChars, ok := charLimits[toolName]
+		if !ok {
+			maxChars, ok = DefaultToolCharLimits[toolName]
+			if !ok {
+				maxChars = 30000 // fallback default
+			}
+		}
+		result = TruncateOutput(output, maxChars, mode)
+	}
 
 	// Step 2: Line-based truncation.
 	maxLines := 0
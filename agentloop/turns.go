@@ -15,17 +15,19 @@ const (
 	TurnToolResults TurnKind = "tool_results"
 	TurnSystem      TurnKind = "system"
 	TurnSteering    TurnKind = "steering"
+	TurnSummary     TurnKind = "summary"
 )
 
 // Turn is a single entry in the conversation history.
 type Turn struct {
-	Kind      TurnKind    `json:"kind"`
-	Timestamp time.Time   `json:"timestamp"`
-	User      *UserTurn   `json:"user,omitempty"`
-	Assistant *AssistantTurn `json:"assistant,omitempty"`
+	Kind        TurnKind         `json:"kind"`
+	Timestamp   time.Time        `json:"timestamp"`
+	User        *UserTurn        `json:"user,omitempty"`
+	Assistant   *AssistantTurn   `json:"assistant,omitempty"`
 	ToolResults *ToolResultsTurn `json:"tool_results,omitempty"`
-	System    *SystemTurn `json:"system,omitempty"`
-	Steering  *SteeringTurn `json:"steering,omitempty"`
+	System      *SystemTurn      `json:"system,omitempty"`
+	Steering    *SteeringTurn    `json:"steering,omitempty"`
+	Summary     *SummaryTurn     `json:"summary,omitempty"`
 }
 
 // UserTurn holds user input.
@@ -35,11 +37,18 @@ type UserTurn struct {
 
 // AssistantTurn holds the model's response.
 type AssistantTurn struct {
-	Content    string               `json:"content"`
+	Content    string                `json:"content"`
 	ToolCalls  []unifiedllm.ToolCall `json:"tool_calls,omitempty"`
-	Reasoning  string               `json:"reasoning,omitempty"`
-	Usage      unifiedllm.Usage     `json:"usage"`
-	ResponseID string               `json:"response_id,omitempty"`
+	Reasoning  string                `json:"reasoning,omitempty"`
+	Usage      unifiedllm.Usage      `json:"usage"`
+	ResponseID string                `json:"response_id,omitempty"`
+
+	// Prefill marks this as an assistant-prefill turn: Content is text the
+	// next Complete call should continue rather than respond to (the
+	// "assistant prefill" pattern some providers expose), so the model
+	// picks up mid-response instead of starting a fresh turn. See
+	// IsAssistantContinuation and Session.Prefill/Continue.
+	Prefill bool `json:"prefill,omitempty"`
 }
 
 // ToolResultsTurn holds tool execution results.
@@ -57,6 +66,18 @@ type SteeringTurn struct {
 	Content string `json:"content"`
 }
 
+// SummaryTurn holds a synthetic turn produced by compaction, replacing a
+// contiguous range of older turns with a condensed summary so the history
+// keeps fitting in the context window.
+type SummaryTurn struct {
+	Content         string `json:"content"`
+	FirstTurnIndex  int    `json:"first_turn_index"`  // index range in the pre-compaction history, inclusive
+	LastTurnIndex   int    `json:"last_turn_index"`   // inclusive
+	TokensBefore    int    `json:"tokens_before"`     // approx token estimate of the folded range
+	TokensAfter     int    `json:"tokens_after"`      // approx token estimate of the summary text
+	FoldedTurnCount int    `json:"folded_turn_count"` // number of user/assistant turns folded in, for countTurns
+}
+
 // NewUserTurn creates a Turn wrapping user input.
 func NewUserTurn(content string) Turn {
 	return Turn{
@@ -81,6 +102,19 @@ func NewAssistantTurn(content string, toolCalls []unifiedllm.ToolCall, reasoning
 	}
 }
 
+// NewPrefillAssistantTurn creates an assistant-prefill Turn: content the
+// next Complete call should continue rather than respond to. It unlocks
+// forced-format outputs (JSON, code blocks, the XML fallback adapter's
+// <function_calls> block) without relying on "please start your reply
+// with..." prompting. See IsAssistantContinuation.
+func NewPrefillAssistantTurn(content string) Turn {
+	return Turn{
+		Kind:      TurnAssistant,
+		Timestamp: time.Now(),
+		Assistant: &AssistantTurn{Content: content, Prefill: true},
+	}
+}
+
 // NewToolResultsTurn creates a Turn wrapping tool results.
 func NewToolResultsTurn(results []unifiedllm.ToolResult) Turn {
 	return Turn{
@@ -108,6 +142,23 @@ func NewSteeringTurn(content string) Turn {
 	}
 }
 
+// NewSummaryTurn creates a Turn wrapping a compaction summary, replacing the
+// turns originally at [firstTurnIndex, lastTurnIndex] in the history.
+func NewSummaryTurn(content string, firstTurnIndex, lastTurnIndex, tokensBefore, tokensAfter, foldedTurnCount int) Turn {
+	return Turn{
+		Kind:      TurnSummary,
+		Timestamp: time.Now(),
+		Summary: &SummaryTurn{
+			Content:         content,
+			FirstTurnIndex:  firstTurnIndex,
+			LastTurnIndex:   lastTurnIndex,
+			TokensBefore:    tokensBefore,
+			TokensAfter:     tokensAfter,
+			FoldedTurnCount: foldedTurnCount,
+		},
+	}
+}
+
 // TextContent returns the text content of a turn regardless of its kind.
 func (t Turn) TextContent() string {
 	switch t.Kind {
@@ -127,11 +178,33 @@ func (t Turn) TextContent() string {
 		if t.Steering != nil {
 			return t.Steering.Content
 		}
+	case TurnSummary:
+		if t.Summary != nil {
+			return t.Summary.Content
+		}
 	}
 	return ""
 }
 
-// ConvertHistoryToMessages converts the turn-based history into LLM messages.
+// IsAssistantContinuation reports whether history ends with an
+// assistant-prefill turn (Session.Prefill, NewPrefillAssistantTurn): one the
+// next Complete call should continue rather than respond to. The agent loop
+// consults this to skip appending a new user turn before that call and to
+// concatenate the continuation text onto the prefill turn afterward, instead
+// of recording a separate assistant turn.
+func IsAssistantContinuation(history []Turn) bool {
+	if len(history) == 0 {
+		return false
+	}
+	last := history[len(history)-1]
+	return last.Kind == TurnAssistant && last.Assistant != nil && last.Assistant.Prefill
+}
+
+// ConvertHistoryToMessages converts the turn-based history into LLM
+// messages. A trailing assistant-prefill turn (IsAssistantContinuation)
+// needs no special handling here: like any other turn it becomes the last
+// message in the slice, and the caller simply omits appending anything
+// after it so the model continues generating from that point.
 func ConvertHistoryToMessages(history []Turn) []unifiedllm.Message {
 	var messages []unifiedllm.Message
 	for _, turn := range history {
@@ -171,6 +244,14 @@ func ConvertHistoryToMessages(history []Turn) []unifiedllm.Message {
 			if turn.Steering != nil {
 				messages = append(messages, unifiedllm.UserMessage(turn.Steering.Content))
 			}
+		case TurnSummary:
+			// Summary turns replace a folded range of earlier turns; they are
+			// sent as a system message so the model treats them as background
+			// context rather than something to respond to directly.
+			if turn.Summary != nil {
+				messages = append(messages, unifiedllm.SystemMessage(
+					"Earlier conversation summary (details folded to save context):\n"+turn.Summary.Content))
+			}
 		}
 	}
 	return messages
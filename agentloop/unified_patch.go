@@ -0,0 +1,169 @@
+package agentloop
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/bluekeyes/go-gitdiff/gitdiff"
+)
+
+// RegisterUnifiedPatch registers the apply_unified_patch tool: an
+// alternative to apply_patch's OpenAI-specific v4a envelope that instead
+// accepts a standard unified/git diff, including git's extended headers
+// ("rename from"/"rename to", "new file mode", "deleted file mode").
+func RegisterUnifiedPatch(reg *ToolRegistry) {
+	reg.Register(RegisteredTool{
+		Definition: ToolDefinition{
+			Name: "apply_unified_patch",
+			Description: "Apply code changes using a standard unified/git diff (\"diff --git\" or " +
+				"\"--- a/f\"/\"+++ b/f\" headers with \"@@ -l,s +l,s @@\" hunks). Supports creating, " +
+				"deleting, renaming, and modifying files, including git's rename/new-file/deleted-file " +
+				"headers, in a single operation.",
+			Parameters: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"patch": map[string]interface{}{
+						"type":        "string",
+						"description": "The patch content as a unified or git diff.",
+					},
+				},
+				"required": []string{"patch"},
+			},
+		},
+		Executor: func(arguments json.RawMessage, env ExecutionEnvironment) (ToolResult, error) {
+			args, err := ParseToolArguments(arguments)
+			if err != nil {
+				return ToolResult{}, err
+			}
+			patch, ok := GetStringArg(args, "patch")
+			if !ok || patch == "" {
+				return ToolResult{}, fmt.Errorf("patch is required")
+			}
+			text, err := applyUnifiedPatch(env, patch)
+			if err != nil {
+				return ToolResult{}, err
+			}
+			return LegacyStringResult(text), nil
+		},
+	})
+}
+
+// applyUnifiedPatch parses patch as a standard unified/git diff with
+// gitdiff and applies each file's operation to env in turn: a deleted file
+// is removed, a new file is written from its added lines, and a modified
+// (optionally renamed) file has its hunks located and spliced the same way
+// v4a's applyV4aHunksStructured does. A hunk whose leading context can't be
+// found in the current content is reported by file and hunk index rather
+// than silently dropped.
+func applyUnifiedPatch(env ExecutionEnvironment, patch string) (string, error) {
+	files, _, err := gitdiff.Parse(strings.NewReader(patch))
+	if err != nil {
+		return "", fmt.Errorf("invalid unified diff: %w", err)
+	}
+	if len(files) == 0 {
+		return "No operations performed.", nil
+	}
+
+	var results []string
+	for _, f := range files {
+		switch {
+		case f.IsDelete:
+			if err := env.DeleteFile(f.OldName); err != nil {
+				return "", fmt.Errorf("failed to delete %s: %w", f.OldName, err)
+			}
+			results = append(results, fmt.Sprintf("Deleted: %s", f.OldName))
+
+		case f.IsNew:
+			content, err := applyTextFragments(f, "")
+			if err != nil {
+				return "", fmt.Errorf("failed to build %s: %w", f.NewName, err)
+			}
+			if err := env.WriteFile(f.NewName, content); err != nil {
+				return "", fmt.Errorf("failed to create %s: %w", f.NewName, err)
+			}
+			results = append(results, fmt.Sprintf("Created: %s", f.NewName))
+
+		default:
+			rawContent, err := readRawFile(env, f.OldName)
+			if err != nil {
+				return "", fmt.Errorf("cannot read %s for update: %w", f.OldName, err)
+			}
+			newContent, err := applyTextFragments(f, rawContent)
+			if err != nil {
+				return "", fmt.Errorf("cannot apply update to %s: %w", f.OldName, err)
+			}
+
+			writePath := f.OldName
+			if f.IsRename {
+				writePath = f.NewName
+			}
+			if err := env.WriteFile(writePath, newContent); err != nil {
+				return "", fmt.Errorf("failed to write %s: %w", writePath, err)
+			}
+			if f.IsRename {
+				if err := env.DeleteFile(f.OldName); err != nil {
+					return "", fmt.Errorf("failed to remove renamed source %s: %w", f.OldName, err)
+				}
+				results = append(results, fmt.Sprintf("Updated and renamed: %s -> %s", f.OldName, f.NewName))
+			} else {
+				results = append(results, fmt.Sprintf("Updated: %s", f.OldName))
+			}
+		}
+	}
+
+	return strings.Join(results, "\n"), nil
+}
+
+// applyTextFragments applies f's TextFragments to rawContent (empty for a
+// new file), locating each fragment's leading context the same way
+// locateHunkContext does for v4a hunks and splicing the result with
+// spliceLines, rather than handing the whole diff to gitdiff.Apply -- this
+// keeps fuzz (trailing-whitespace-tolerant context matching) and rejected-
+// hunk reporting consistent between apply_patch and apply_unified_patch.
+func applyTextFragments(f *gitdiff.File, rawContent string) (string, error) {
+	content := rawContent
+	searchFrom := 0
+	for i, frag := range f.TextFragments {
+		ops := make([]hunkOp, 0, len(frag.Lines))
+		for _, l := range frag.Lines {
+			line := strings.TrimSuffix(l.Line, "\n")
+			switch l.Op {
+			case gitdiff.OpContext:
+				ops = append(ops, hunkOp{op: ' ', line: line})
+			case gitdiff.OpDelete:
+				ops = append(ops, hunkOp{op: '-', line: line})
+			case gitdiff.OpAdd:
+				ops = append(ops, hunkOp{op: '+', line: line})
+			}
+		}
+
+		fileLines := strings.Split(content, "\n")
+		start, ok := locateHunkContext(fileLines, ops, searchFrom)
+		if !ok {
+			return "", fmt.Errorf("hunk %d: could not locate context", i)
+		}
+
+		oldCount := 0
+		var replacement []string
+		for _, op := range ops {
+			switch op.op {
+			case ' ':
+				oldCount++
+				replacement = append(replacement, op.line)
+			case '-':
+				oldCount++
+			case '+':
+				replacement = append(replacement, op.line)
+			}
+		}
+
+		spliced, err := spliceLines(content, start+1, start+oldCount, replacement)
+		if err != nil {
+			return "", fmt.Errorf("hunk %d: %w", i, err)
+		}
+		content = spliced
+		searchFrom = start + len(replacement)
+	}
+	return content, nil
+}
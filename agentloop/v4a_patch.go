@@ -0,0 +1,330 @@
+package agentloop
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/bluekeyes/go-gitdiff/gitdiff"
+)
+
+// v4aOpKind identifies which of the three v4a envelope operations a
+// v4aFileOp describes.
+type v4aOpKind int
+
+const (
+	v4aAdd v4aOpKind = iota
+	v4aDelete
+	v4aUpdate
+)
+
+// v4aFileOp is one "*** Add/Delete/Update File: " section of a v4a patch.
+type v4aFileOp struct {
+	kind    v4aOpKind
+	path    string
+	newPath string   // set only for Update File with a "*** Move to: " line
+	content []string // added lines, for v4aAdd
+	hunks   []v4aHunk
+}
+
+// v4aHunk is one "@@ ..." section within an Update File operation. Its body
+// lines already use the standard unified-diff prefixes (' ', '-', '+'), so
+// translating a hunk into a real diff only requires synthesizing the
+// numeric "@@ -l,s +l,s @@" header v4a omits.
+type v4aHunk struct {
+	ops []hunkOp
+}
+
+// hunkOp represents a single operation within a patch hunk.
+type hunkOp struct {
+	op   byte   // ' ' = context, '-' = delete, '+' = add
+	line string // line content
+}
+
+// parseV4aPatch parses the v4a envelope format into a sequence of file
+// operations. It does not touch the filesystem or interpret hunk positions;
+// applyV4aPatch does that, via applyV4aHunks or applyV4aHunksStructured
+// depending on LegacyStringEditEnabled.
+func parseV4aPatch(patch string) ([]v4aFileOp, error) {
+	lines := strings.Split(patch, "\n")
+	if len(lines) < 2 {
+		return nil, fmt.Errorf("invalid patch: too short")
+	}
+	if strings.TrimSpace(lines[0]) != "*** Begin Patch" {
+		return nil, fmt.Errorf("invalid patch: missing '*** Begin Patch' header")
+	}
+
+	var ops []v4aFileOp
+	i := 1
+	for i < len(lines) {
+		line := strings.TrimSpace(lines[i])
+
+		switch {
+		case line == "*** End Patch" || line == "":
+			i++
+
+		case strings.HasPrefix(line, "*** Add File: "):
+			path := strings.TrimPrefix(line, "*** Add File: ")
+			i++
+			var content []string
+			for i < len(lines) && !strings.HasPrefix(lines[i], "*** ") {
+				if strings.HasPrefix(lines[i], "+") {
+					content = append(content, lines[i][1:])
+				}
+				i++
+			}
+			ops = append(ops, v4aFileOp{kind: v4aAdd, path: path, content: content})
+
+		case strings.HasPrefix(line, "*** Delete File: "):
+			path := strings.TrimPrefix(line, "*** Delete File: ")
+			ops = append(ops, v4aFileOp{kind: v4aDelete, path: path})
+			i++
+
+		case strings.HasPrefix(line, "*** Update File: "):
+			path := strings.TrimPrefix(line, "*** Update File: ")
+			i++
+
+			newPath := ""
+			if i < len(lines) && strings.HasPrefix(strings.TrimSpace(lines[i]), "*** Move to: ") {
+				newPath = strings.TrimPrefix(strings.TrimSpace(lines[i]), "*** Move to: ")
+				i++
+			}
+
+			var hunks []v4aHunk
+			for i < len(lines) {
+				trimmed := strings.TrimSpace(lines[i])
+				if strings.HasPrefix(trimmed, "*** ") && trimmed != "*** End of File" {
+					break
+				}
+				if trimmed == "*** End of File" {
+					i++
+					continue
+				}
+				if !strings.HasPrefix(trimmed, "@@") {
+					i++
+					continue
+				}
+
+				i++ // consume the "@@ ..." header; v4a carries no line numbers in it.
+				var hunk v4aHunk
+				for i < len(lines) {
+					if lines[i] == "" {
+						i++
+						continue
+					}
+					prefix := lines[i][0]
+					if prefix != ' ' && prefix != '-' && prefix != '+' {
+						break
+					}
+					hunk.ops = append(hunk.ops, hunkOp{op: prefix, line: lines[i][1:]})
+					i++
+				}
+				hunks = append(hunks, hunk)
+			}
+
+			ops = append(ops, v4aFileOp{kind: v4aUpdate, path: path, newPath: newPath, hunks: hunks})
+
+		default:
+			i++
+		}
+	}
+	return ops, nil
+}
+
+// applyV4aPatch parses a v4a format patch and applies it to env. Add and
+// Delete operations are straightforward file writes. With
+// LegacyStringEditEnabled (the default), Update operations are translated
+// into a real unified diff (one "@@ -l,s +l,s @@" hunk per v4a hunk, with
+// line numbers located by context search against the original file) and
+// applied with gitdiff; with it false, applyV4aHunksStructured splices each
+// located hunk directly with spliceLines.
+func applyV4aPatch(env ExecutionEnvironment, patch string) (string, error) {
+	ops, err := parseV4aPatch(patch)
+	if err != nil {
+		return "", err
+	}
+
+	var results []string
+	for _, op := range ops {
+		switch op.kind {
+		case v4aAdd:
+			if err := env.WriteFile(op.path, strings.Join(op.content, "\n")); err != nil {
+				return "", fmt.Errorf("failed to create %s: %w", op.path, err)
+			}
+			results = append(results, fmt.Sprintf("Created: %s", op.path))
+
+		case v4aDelete:
+			if err := env.DeleteFile(op.path); err != nil {
+				return "", fmt.Errorf("failed to delete %s: %w", op.path, err)
+			}
+			results = append(results, fmt.Sprintf("Deleted: %s", op.path))
+
+		case v4aUpdate:
+			rawContent, err := readRawFile(env, op.path)
+			if err != nil {
+				return "", fmt.Errorf("cannot read %s for update: %w", op.path, err)
+			}
+
+			var newContent string
+			if LegacyStringEditEnabled {
+				newContent, err = applyV4aHunks(op.path, rawContent, op.hunks)
+			} else {
+				newContent, err = applyV4aHunksStructured(op.path, rawContent, op.hunks)
+			}
+			if err != nil {
+				return "", fmt.Errorf("cannot apply update to %s: %w", op.path, err)
+			}
+
+			writePath := op.path
+			if op.newPath != "" {
+				writePath = op.newPath
+			}
+			if err := env.WriteFile(writePath, newContent); err != nil {
+				return "", fmt.Errorf("failed to write %s: %w", writePath, err)
+			}
+			if op.newPath != "" {
+				if err := env.DeleteFile(op.path); err != nil {
+					return "", fmt.Errorf("failed to remove moved source %s: %w", op.path, err)
+				}
+				results = append(results, fmt.Sprintf("Updated and moved: %s -> %s", op.path, op.newPath))
+			} else {
+				results = append(results, fmt.Sprintf("Updated: %s", op.path))
+			}
+		}
+	}
+
+	if len(results) == 0 {
+		return "No operations performed.", nil
+	}
+	return strings.Join(results, "\n"), nil
+}
+
+// applyV4aHunks builds a standard unified diff from hunks (locating each
+// hunk's starting line by searching its leading context against the
+// original content, in order) and applies it with gitdiff.
+func applyV4aHunks(path, rawContent string, hunks []v4aHunk) (string, error) {
+	if len(hunks) == 0 {
+		return rawContent, nil
+	}
+
+	fileLines := strings.Split(rawContent, "\n")
+
+	var diffText strings.Builder
+	fmt.Fprintf(&diffText, "--- a/%s\n+++ b/%s\n", path, path)
+
+	searchFrom := 0
+	for _, hunk := range hunks {
+		start, ok := locateHunkContext(fileLines, hunk.ops, searchFrom)
+		if !ok {
+			return "", fmt.Errorf("could not locate context for a hunk in %s", path)
+		}
+
+		oldCount, newCount := 0, 0
+		for _, op := range hunk.ops {
+			switch op.op {
+			case ' ':
+				oldCount++
+				newCount++
+			case '-':
+				oldCount++
+			case '+':
+				newCount++
+			}
+		}
+
+		fmt.Fprintf(&diffText, "@@ -%d,%d +%d,%d @@\n", start+1, oldCount, start+1, newCount)
+		for _, op := range hunk.ops {
+			fmt.Fprintf(&diffText, "%c%s\n", op.op, op.line)
+		}
+
+		searchFrom = start + oldCount
+	}
+
+	files, _, err := gitdiff.Parse(strings.NewReader(diffText.String()))
+	if err != nil {
+		return "", fmt.Errorf("translated hunk was not a valid unified diff: %w", err)
+	}
+	if len(files) != 1 {
+		return "", fmt.Errorf("expected exactly one file in translated diff, got %d", len(files))
+	}
+
+	var out bytes.Buffer
+	if err := gitdiff.Apply(&out, strings.NewReader(rawContent), files[0]); err != nil {
+		return "", fmt.Errorf("failed to apply hunks: %w", err)
+	}
+	return out.String(), nil
+}
+
+// applyV4aHunksStructured is applyV4aHunks' LegacyStringEditEnabled=false
+// counterpart: instead of translating hunks into a unified diff and
+// applying it with gitdiff, it locates each hunk's context the same way
+// (locateHunkContext) and splices the result directly with spliceLines,
+// the same primitive behind replace_range/delete_range/insert_at.
+func applyV4aHunksStructured(path, rawContent string, hunks []v4aHunk) (string, error) {
+	content := rawContent
+	searchFrom := 0
+	for _, hunk := range hunks {
+		fileLines := strings.Split(content, "\n")
+		start, ok := locateHunkContext(fileLines, hunk.ops, searchFrom)
+		if !ok {
+			return "", fmt.Errorf("could not locate context for a hunk in %s", path)
+		}
+
+		oldCount := 0
+		var replacement []string
+		for _, op := range hunk.ops {
+			switch op.op {
+			case ' ':
+				oldCount++
+				replacement = append(replacement, op.line)
+			case '-':
+				oldCount++
+			case '+':
+				replacement = append(replacement, op.line)
+			}
+		}
+
+		spliced, err := spliceLines(content, start+1, start+oldCount, replacement)
+		if err != nil {
+			return "", fmt.Errorf("apply hunk in %s: %w", path, err)
+		}
+		content = spliced
+		searchFrom = start + len(replacement)
+	}
+	return content, nil
+}
+
+// locateHunkContext finds where a hunk's leading context/delete lines occur
+// in fileLines, searching no earlier than fromLine. It returns the 0-based
+// line index of the match, trimming trailing whitespace so minor
+// indentation drift doesn't block a match.
+func locateHunkContext(fileLines []string, ops []hunkOp, fromLine int) (int, bool) {
+	var contextPrefix []string
+	for _, op := range ops {
+		if op.op == ' ' || op.op == '-' {
+			contextPrefix = append(contextPrefix, op.line)
+		} else {
+			break
+		}
+	}
+	if len(contextPrefix) == 0 {
+		if fromLine <= len(fileLines) {
+			return fromLine, true
+		}
+		return 0, false
+	}
+
+	for i := fromLine; i <= len(fileLines)-len(contextPrefix); i++ {
+		match := true
+		for j, ctx := range contextPrefix {
+			if strings.TrimRight(fileLines[i+j], " \t") != strings.TrimRight(ctx, " \t") {
+				match = false
+				break
+			}
+		}
+		if match {
+			return i, true
+		}
+	}
+	return 0, false
+}
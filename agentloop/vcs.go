@@ -0,0 +1,214 @@
+package agentloop
+
+import (
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/storer"
+)
+
+// VCS abstracts the version-control queries BuildEnvironmentContext and
+// GetGitContext need, so the system prompt's git-awareness isn't tied to
+// shelling out to a git binary. ShellGitVCS is the historical
+// implementation; GoGitVCS is a pure-Go alternative that avoids forking a
+// process per call and works in sandboxes without a git binary installed.
+type VCS interface {
+	// IsRepo reports whether dir is inside a repository's work tree.
+	IsRepo(dir string) bool
+	// Root returns the repository's top-level directory, or "" if dir isn't
+	// inside a repository.
+	Root(dir string) string
+	// Branch returns the current branch name, or "" if unknown (detached
+	// HEAD, not a repository, etc).
+	Branch(dir string) string
+	// Status returns a short-format status summary, one line per changed
+	// path, matching `git status --short`.
+	Status(dir string) (string, error)
+	// RecentCommits returns the last n commits, one per line, formatted
+	// like `git log --oneline -n`.
+	RecentCommits(dir string, n int) (string, error)
+}
+
+// DefaultVCS is the VCS implementation package-level helpers and the
+// profile system prompts use unless a caller opts into a different one via
+// BuildEnvironmentContextWithVCS / GetGitContextWithVCS.
+var DefaultVCS VCS = ShellGitVCS{}
+
+// ShellGitVCS implements VCS by shelling out to the git binary on PATH. It
+// is the original behavior of this package, kept as the default since it
+// needs no extra dependency and matches the git binary's own semantics
+// exactly (including any repo-local config, hooks-adjacent behavior, etc).
+type ShellGitVCS struct{}
+
+func (ShellGitVCS) IsRepo(dir string) bool {
+	cmd := exec.Command("git", "rev-parse", "--is-inside-work-tree")
+	cmd.Dir = dir
+	out, err := cmd.Output()
+	return err == nil && strings.TrimSpace(string(out)) == "true"
+}
+
+func (ShellGitVCS) Root(dir string) string {
+	cmd := exec.Command("git", "rev-parse", "--show-toplevel")
+	cmd.Dir = dir
+	out, err := cmd.Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(out))
+}
+
+func (ShellGitVCS) Branch(dir string) string {
+	cmd := exec.Command("git", "rev-parse", "--abbrev-ref", "HEAD")
+	cmd.Dir = dir
+	out, err := cmd.Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(out))
+}
+
+func (ShellGitVCS) Status(dir string) (string, error) {
+	cmd := exec.Command("git", "status", "--short")
+	cmd.Dir = dir
+	out, err := cmd.Output()
+	if err != nil {
+		return "", err
+	}
+	return string(out), nil
+}
+
+func (ShellGitVCS) RecentCommits(dir string, n int) (string, error) {
+	cmd := exec.Command("git", "log", "--oneline", "-"+strconv.Itoa(n))
+	cmd.Dir = dir
+	out, err := cmd.Output()
+	if err != nil {
+		return "", err
+	}
+	return string(out), nil
+}
+
+// GoGitVCS implements VCS with go-git instead of a git binary. It opens the
+// repository once per call rather than forking a git subprocess, which
+// matters when BuildEnvironmentContext/GetGitContext run on every agent
+// turn, and it works in sandboxes that don't ship a git binary at all.
+type GoGitVCS struct{}
+
+func (GoGitVCS) open(dir string) (*git.Repository, error) {
+	return git.PlainOpenWithOptions(dir, &git.PlainOpenOptions{DetectDotGit: true})
+}
+
+func (v GoGitVCS) IsRepo(dir string) bool {
+	_, err := v.open(dir)
+	return err == nil
+}
+
+func (v GoGitVCS) Root(dir string) string {
+	repo, err := v.open(dir)
+	if err != nil {
+		return ""
+	}
+	wt, err := repo.Worktree()
+	if err != nil {
+		return ""
+	}
+	return wt.Filesystem.Root()
+}
+
+func (v GoGitVCS) Branch(dir string) string {
+	repo, err := v.open(dir)
+	if err != nil {
+		return ""
+	}
+	head, err := repo.Head()
+	if err != nil {
+		return ""
+	}
+	if head.Name().IsBranch() {
+		return head.Name().Short()
+	}
+	return ""
+}
+
+func (v GoGitVCS) Status(dir string) (string, error) {
+	repo, err := v.open(dir)
+	if err != nil {
+		return "", err
+	}
+	wt, err := repo.Worktree()
+	if err != nil {
+		return "", err
+	}
+	status, err := wt.Status()
+	if err != nil {
+		return "", err
+	}
+
+	var sb strings.Builder
+	for path, s := range status {
+		fmt.Fprintf(&sb, "%c%c %s\n", statusCode(s.Staging), statusCode(s.Worktree), path)
+	}
+	return sb.String(), nil
+}
+
+func (v GoGitVCS) RecentCommits(dir string, n int) (string, error) {
+	repo, err := v.open(dir)
+	if err != nil {
+		return "", err
+	}
+	head, err := repo.Head()
+	if err != nil {
+		return "", err
+	}
+	commits, err := repo.Log(&git.LogOptions{From: head.Hash()})
+	if err != nil {
+		return "", err
+	}
+
+	var sb strings.Builder
+	count := 0
+	err = commits.ForEach(func(c *object.Commit) error {
+		if count >= n {
+			return storer.ErrStop
+		}
+		subject := c.Message
+		if idx := strings.IndexByte(subject, '\n'); idx >= 0 {
+			subject = subject[:idx]
+		}
+		fmt.Fprintf(&sb, "%s %s\n", c.Hash.String()[:7], subject)
+		count++
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	return sb.String(), nil
+}
+
+// statusCode renders a go-git file status constant as the single-letter
+// code git status --short uses (e.g. 'M', 'A', 'D', '?').
+func statusCode(code git.StatusCode) byte {
+	switch code {
+	case git.Unmodified:
+		return ' '
+	case git.Untracked:
+		return '?'
+	case git.Modified:
+		return 'M'
+	case git.Added:
+		return 'A'
+	case git.Deleted:
+		return 'D'
+	case git.Renamed:
+		return 'R'
+	case git.Copied:
+		return 'C'
+	case git.UpdatedButUnmerged:
+		return 'U'
+	default:
+		return ' '
+	}
+}
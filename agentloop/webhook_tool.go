@@ -0,0 +1,276 @@
+package agentloop
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/martinemde/attractor/unifiedllm"
+)
+
+// WebhookHMACConfig signs the outgoing request body with HMAC-SHA256, the
+// way Mattermost and n8n authenticate inbound webhook calls: the receiver
+// recomputes the digest over the raw body and compares it to the header.
+type WebhookHMACConfig struct {
+	// SecretEnv names the environment variable holding the signing secret.
+	// The secret itself never appears in config.
+	SecretEnv string `yaml:"secret_env" json:"secret_env"`
+	// Header is the header the signature is sent in. Defaults to
+	// "X-Signature-256" if empty.
+	Header string `yaml:"header,omitempty" json:"header,omitempty"`
+}
+
+// WebhookToolConfig declares a tool backed by an HTTP endpoint instead of Go
+// code: the model calls the tool, the runtime POSTs (or sends via Method)
+// the arguments as JSON to URL, and the response body (or a
+// ResponsePath-selected subset of it) becomes the tool result. This lets a
+// deployment wire an agent to an arbitrary business system — analogous to a
+// Mattermost or n8n webhook integration — by pointing at a URL rather than
+// building a plugin.
+type WebhookToolConfig struct {
+	Name        string                 `yaml:"name" json:"name"`
+	Description string                 `yaml:"description" json:"description"`
+	Parameters  map[string]interface{} `yaml:"parameters" json:"parameters"`
+
+	URL     string            `yaml:"url" json:"url"`
+	Method  string            `yaml:"method,omitempty" json:"method,omitempty"` // defaults to POST
+	Headers map[string]string `yaml:"headers,omitempty" json:"headers,omitempty"`
+
+	// ResponsePath, if set, is a dotted path (e.g. "data.items.0.id") into
+	// the JSON response body; only the value at that path is returned as
+	// the tool result instead of the whole body.
+	ResponsePath string `yaml:"response_path,omitempty" json:"response_path,omitempty"`
+
+	// HMAC signs the request body and attaches the signature as a header,
+	// if set.
+	HMAC *WebhookHMACConfig `yaml:"hmac,omitempty" json:"hmac,omitempty"`
+
+	// TimeoutMs bounds a single attempt. Defaults to 30000 (30s) if zero.
+	TimeoutMs int `yaml:"timeout_ms,omitempty" json:"timeout_ms,omitempty"`
+
+	// Retry configures retry/backoff for the HTTP call, reusing
+	// unifiedllm's retry machinery rather than a second implementation.
+	// A zero value retries never (MaxRetries 0).
+	Retry unifiedllm.RetryPolicy `yaml:"retry,omitempty" json:"retry,omitempty"`
+}
+
+// RegisterWebhookTool registers cfg as a tool in reg. It returns an error if
+// cfg is missing a name or URL.
+func RegisterWebhookTool(reg *ToolRegistry, cfg WebhookToolConfig) error {
+	if cfg.Name == "" {
+		return fmt.Errorf("agentloop: webhook tool: name is required")
+	}
+	if cfg.URL == "" {
+		return fmt.Errorf("agentloop: webhook tool %q: url is required", cfg.Name)
+	}
+	method := cfg.Method
+	if method == "" {
+		method = http.MethodPost
+	}
+	timeoutMs := cfg.TimeoutMs
+	if timeoutMs <= 0 {
+		timeoutMs = 30000
+	}
+
+	reg.Register(RegisteredTool{
+		Definition: ToolDefinition{
+			Name:        cfg.Name,
+			Description: cfg.Description,
+			Parameters:  cfg.Parameters,
+		},
+		Executor: func(arguments json.RawMessage, env ExecutionEnvironment) (ToolResult, error) {
+			body := []byte(arguments)
+			if len(body) == 0 {
+				body = []byte("{}")
+			}
+
+			client := &http.Client{Timeout: time.Duration(timeoutMs) * time.Millisecond}
+
+			resp, err := unifiedllm.Retry(context.Background(), cfg.Retry, func(ctx context.Context) (*webhookResponse, error) {
+				return doWebhookRequest(ctx, client, method, cfg, body)
+			})
+			if err != nil {
+				return ToolResult{}, fmt.Errorf("webhook tool %q: %w", cfg.Name, err)
+			}
+
+			if resp.statusCode < 200 || resp.statusCode >= 300 {
+				return ToolResult{
+					Text:    fmt.Sprintf("webhook returned status %d: %s", resp.statusCode, string(resp.body)),
+					IsError: true,
+					Metadata: map[string]interface{}{
+						"status_code": resp.statusCode,
+					},
+				}, nil
+			}
+
+			text := string(resp.body)
+			if cfg.ResponsePath != "" {
+				extracted, err := extractJSONPath(resp.body, cfg.ResponsePath)
+				if err != nil {
+					return ToolResult{}, fmt.Errorf("webhook tool %q: %w", cfg.Name, err)
+				}
+				text = extracted
+			}
+
+			return ToolResult{
+				Text: text,
+				Artifacts: []Artifact{
+					{MIMEType: resp.contentType, Name: "response", Bytes: resp.body},
+				},
+				Metadata: map[string]interface{}{
+					"status_code": resp.statusCode,
+				},
+			}, nil
+		},
+	})
+	return nil
+}
+
+// webhookResponse is the raw result of a single HTTP attempt, before
+// response-path extraction.
+type webhookResponse struct {
+	statusCode  int
+	contentType string
+	body        []byte
+}
+
+// doWebhookRequest performs one HTTP attempt: building the request, signing
+// it if cfg.HMAC is set, and reading the response body.
+func doWebhookRequest(ctx context.Context, client *http.Client, method string, cfg WebhookToolConfig, body []byte) (*webhookResponse, error) {
+	req, err := http.NewRequestWithContext(ctx, method, cfg.URL, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("build request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range cfg.Headers {
+		req.Header.Set(k, v)
+	}
+	if cfg.HMAC != nil {
+		sig, err := signWebhookBody(cfg.HMAC, body)
+		if err != nil {
+			return nil, err
+		}
+		header := cfg.HMAC.Header
+		if header == "" {
+			header = "X-Signature-256"
+		}
+		req.Header.Set(header, sig)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("read response: %w", err)
+	}
+
+	return &webhookResponse{
+		statusCode:  resp.StatusCode,
+		contentType: resp.Header.Get("Content-Type"),
+		body:        respBody,
+	}, nil
+}
+
+// signWebhookBody computes the hex-encoded HMAC-SHA256 signature of body
+// using the secret named by cfg.SecretEnv.
+func signWebhookBody(cfg *WebhookHMACConfig, body []byte) (string, error) {
+	if cfg.SecretEnv == "" {
+		return "", fmt.Errorf("hmac: secret_env is required")
+	}
+	secret := os.Getenv(cfg.SecretEnv)
+	if secret == "" {
+		return "", fmt.Errorf("hmac: environment variable %s is not set", cfg.SecretEnv)
+	}
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil)), nil
+}
+
+// extractJSONPath selects the value at a dotted path (e.g.
+// "data.items.0.id") within a JSON document and renders it back to text:
+// strings are returned unquoted, everything else is re-marshaled as JSON.
+// This is a minimal subset of JSONPath sufficient for picking a field or
+// array element out of a webhook response, not the full JSONPath spec.
+func extractJSONPath(data []byte, path string) (string, error) {
+	var value interface{}
+	if err := json.Unmarshal(data, &value); err != nil {
+		return "", fmt.Errorf("response is not valid JSON: %w", err)
+	}
+
+	path = strings.TrimPrefix(path, "$.")
+	path = strings.TrimPrefix(path, "$")
+	for _, segment := range strings.Split(path, ".") {
+		if segment == "" {
+			continue
+		}
+		switch v := value.(type) {
+		case map[string]interface{}:
+			next, ok := v[segment]
+			if !ok {
+				return "", fmt.Errorf("response_path %q: no field %q", path, segment)
+			}
+			value = next
+		case []interface{}:
+			idx, err := strconv.Atoi(segment)
+			if err != nil || idx < 0 || idx >= len(v) {
+				return "", fmt.Errorf("response_path %q: invalid index %q", path, segment)
+			}
+			value = v[idx]
+		default:
+			return "", fmt.Errorf("response_path %q: %q is not an object or array", path, segment)
+		}
+	}
+
+	if s, ok := value.(string); ok {
+		return s, nil
+	}
+	out, err := json.Marshal(value)
+	if err != nil {
+		return "", fmt.Errorf("response_path %q: %w", path, err)
+	}
+	return string(out), nil
+}
+
+// LoadWebhookToolConfigsYAML parses a YAML document containing a list of
+// WebhookToolConfig.
+func LoadWebhookToolConfigsYAML(data []byte) ([]WebhookToolConfig, error) {
+	var configs []WebhookToolConfig
+	if err := yaml.Unmarshal(data, &configs); err != nil {
+		return nil, fmt.Errorf("agentloop: parse webhook tool config YAML: %w", err)
+	}
+	return configs, nil
+}
+
+// LoadWebhookToolConfigs reads path (YAML, or JSON if its extension is
+// .json) and parses it into a list of WebhookToolConfig.
+func LoadWebhookToolConfigs(path string) ([]WebhookToolConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("agentloop: read webhook tool config %s: %w", path, err)
+	}
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		var configs []WebhookToolConfig
+		if err := json.Unmarshal(data, &configs); err != nil {
+			return nil, fmt.Errorf("agentloop: parse webhook tool config %s: %w", path, err)
+		}
+		return configs, nil
+	}
+	return LoadWebhookToolConfigsYAML(data)
+}
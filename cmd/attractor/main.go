@@ -0,0 +1,64 @@
+// Command attractor is a small operational CLI for the attractor
+// toolchain. Today its only subcommand is "models sync", which writes the
+// merged model catalog (the built-in unifiedllm.Models plus anything loaded
+// from ATTRACTOR_MODELS_DIR) to disk so it can be reviewed, diffed, or
+// shipped as a pinned snapshot for an environment that can't reach the
+// catalog's file/HTTP sources directly.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/martinemde/attractor/unifiedllm"
+)
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+
+	switch os.Args[1] {
+	case "models":
+		runModels(os.Args[2:])
+	default:
+		usage()
+		os.Exit(2)
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: attractor models sync [-out path]")
+}
+
+// runModels dispatches attractor's "models" subcommands.
+func runModels(args []string) {
+	if len(args) < 1 || args[0] != "sync" {
+		usage()
+		os.Exit(2)
+	}
+
+	fs := flag.NewFlagSet("models sync", flag.ExitOnError)
+	out := fs.String("out", "models.json", "path to write the merged model catalog to")
+	fs.Parse(args[1:])
+
+	if _, err := unifiedllm.LoadModelsFromEnv(); err != nil {
+		fmt.Fprintf(os.Stderr, "attractor: load models from ATTRACTOR_MODELS_DIR: %v\n", err)
+		os.Exit(1)
+	}
+
+	models := unifiedllm.ListModels("")
+	data, err := json.MarshalIndent(models, "", "  ")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "attractor: marshal model catalog: %v\n", err)
+		os.Exit(1)
+	}
+	if err := os.WriteFile(*out, data, 0o644); err != nil {
+		fmt.Fprintf(os.Stderr, "attractor: write %s: %v\n", *out, err)
+		os.Exit(1)
+	}
+	fmt.Printf("attractor: wrote %d models to %s\n", len(models), *out)
+}
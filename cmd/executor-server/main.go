@@ -0,0 +1,263 @@
+// Command executor-server is the server half of the agentloop executor
+// plugin protocol (see proto/executor.proto). Run it inside a VM, a
+// container, or at the far end of an SSH-tunneled unix socket, then point a
+// agentloop.RemoteExecutionEnvironment at its listen address.
+package main
+
+import (
+	"bufio"
+	"context"
+	"flag"
+	"io"
+	"log"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"syscall"
+	"time"
+
+	"google.golang.org/grpc"
+
+	"github.com/martinemde/attractor/agentloop/executorpb"
+)
+
+func main() {
+	addr := flag.String("listen", "127.0.0.1:7865", "address to listen on (use unix:/path for a unix socket)")
+	workingDir := flag.String("working-dir", "", "working directory reported to clients (default: current directory)")
+	flag.Parse()
+
+	wd := *workingDir
+	if wd == "" {
+		var err error
+		wd, err = os.Getwd()
+		if err != nil {
+			log.Fatalf("executor-server: %v", err)
+		}
+	}
+
+	network, address := "tcp", *addr
+	if strings.HasPrefix(*addr, "unix:") {
+		network, address = "unix", strings.TrimPrefix(*addr, "unix:")
+	}
+
+	lis, err := net.Listen(network, address)
+	if err != nil {
+		log.Fatalf("executor-server: listen: %v", err)
+	}
+
+	srv := grpc.NewServer()
+	executorpb.RegisterExecutorServer(srv, &executorServer{workingDir: wd})
+
+	log.Printf("executor-server: listening on %s %s", network, address)
+	if err := srv.Serve(lis); err != nil {
+		log.Fatalf("executor-server: serve: %v", err)
+	}
+}
+
+// executorServer implements executorpb.ExecutorServer on top of the local
+// filesystem and process table, the same primitives
+// agentloop.LocalExecutionEnvironment uses. DeleteFile and RenameFile are
+// not implemented and return codes.Unimplemented (from the embedded
+// UnimplementedExecutorServer). ListDirectory lists only the immediate
+// entries and ignores depth; Grep ignores glob_filter and max_results.
+type executorServer struct {
+	executorpb.UnimplementedExecutorServer
+	workingDir string
+}
+
+func (s *executorServer) Handshake(ctx context.Context, req *executorpb.HandshakeRequest) (*executorpb.HandshakeResponse, error) {
+	return &executorpb.HandshakeResponse{
+		WorkingDirectory: s.workingDir,
+		Platform:         runtime.GOOS,
+		OsVersion:        runtime.GOOS + "/" + runtime.GOARCH,
+	}, nil
+}
+
+func (s *executorServer) resolvePath(path string) string {
+	if filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(s.workingDir, path)
+}
+
+func (s *executorServer) ReadFile(ctx context.Context, req *executorpb.ReadFileRequest) (*executorpb.ReadFileResponse, error) {
+	data, err := os.ReadFile(s.resolvePath(req.Path))
+	if err != nil {
+		return nil, err
+	}
+
+	lines := strings.Split(string(data), "\n")
+	start := 0
+	if req.Offset > 0 {
+		start = int(req.Offset) - 1
+	}
+	if start >= len(lines) {
+		return &executorpb.ReadFileResponse{}, nil
+	}
+	end := len(lines)
+	if req.Limit > 0 && start+int(req.Limit) < end {
+		end = start + int(req.Limit)
+	}
+	return &executorpb.ReadFileResponse{Content: strings.Join(lines[start:end], "\n")}, nil
+}
+
+func (s *executorServer) WriteFile(ctx context.Context, req *executorpb.WriteFileRequest) (*executorpb.WriteFileResponse, error) {
+	resolved := s.resolvePath(req.Path)
+	if err := os.MkdirAll(filepath.Dir(resolved), 0755); err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(resolved, []byte(req.Content), 0644); err != nil {
+		return nil, err
+	}
+	return &executorpb.WriteFileResponse{}, nil
+}
+
+func (s *executorServer) FileExists(ctx context.Context, req *executorpb.FileExistsRequest) (*executorpb.FileExistsResponse, error) {
+	_, err := os.Stat(s.resolvePath(req.Path))
+	return &executorpb.FileExistsResponse{Exists: err == nil}, nil
+}
+
+func (s *executorServer) ListDirectory(ctx context.Context, req *executorpb.ListDirectoryRequest) (*executorpb.ListDirectoryResponse, error) {
+	entries, err := os.ReadDir(s.resolvePath(req.Path))
+	if err != nil {
+		return nil, err
+	}
+	resp := &executorpb.ListDirectoryResponse{}
+	for _, entry := range entries {
+		de := &executorpb.DirEntryProto{Name: entry.Name(), IsDir: entry.IsDir()}
+		if info, err := entry.Info(); err == nil {
+			de.Size = info.Size()
+		}
+		resp.Entries = append(resp.Entries, de)
+	}
+	return resp, nil
+}
+
+// ExecCommand runs the command via /bin/bash -c in its own process group,
+// streaming stdout/stderr back as ExecChunk frames as they're produced, and
+// kills the process group if the client disconnects or the stream context is
+// cancelled (ctx.Done()) before the command finishes.
+func (s *executorServer) ExecCommand(req *executorpb.ExecCommandRequest, stream executorpb.Executor_ExecCommandServer) error {
+	ctx := stream.Context()
+	if req.TimeoutMs > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, time.Duration(req.TimeoutMs)*time.Millisecond)
+		defer cancel()
+	}
+
+	workingDir := req.WorkingDir
+	if workingDir == "" {
+		workingDir = s.workingDir
+	} else {
+		workingDir = s.resolvePath(workingDir)
+	}
+
+	cmd := exec.Command("/bin/bash", "-c", req.Command)
+	cmd.Dir = workingDir
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+
+	env := os.Environ()
+	for k, v := range req.EnvVars {
+		env = append(env, k+"="+v)
+	}
+	cmd.Env = env
+
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	stderr, err := cmd.StderrPipe()
+	if err != nil {
+		return err
+	}
+
+	start := time.Now()
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			if cmd.Process != nil {
+				_ = syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+			}
+		case <-done:
+		}
+	}()
+
+	relay := func(streamType executorpb.ExecChunk_StreamType, r io.Reader) {
+		buf := bufio.NewReader(r)
+		chunk := make([]byte, 4096)
+		for {
+			n, err := buf.Read(chunk)
+			if n > 0 {
+				_ = stream.Send(&executorpb.ExecChunk{StreamType: streamType, Data: append([]byte(nil), chunk[:n]...)})
+			}
+			if err != nil {
+				return
+			}
+		}
+	}
+	stdoutDone := make(chan struct{})
+	go func() {
+		relay(executorpb.ExecChunk_STDOUT, stdout)
+		close(stdoutDone)
+	}()
+	relay(executorpb.ExecChunk_STDERR, stderr)
+	// Both pipes must be drained before Wait closes them, and every output
+	// frame must be sent before the final Done frame.
+	<-stdoutDone
+
+	waitErr := cmd.Wait()
+	duration := time.Since(start)
+
+	final := &executorpb.ExecChunk{Done: true, DurationMs: duration.Milliseconds()}
+	if waitErr != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			final.TimedOut = true
+			final.ExitCode = -1
+		} else if exitErr, ok := waitErr.(*exec.ExitError); ok {
+			final.ExitCode = int32(exitErr.ExitCode())
+		} else {
+			return waitErr
+		}
+	}
+	return stream.Send(final)
+}
+
+func (s *executorServer) Grep(ctx context.Context, req *executorpb.GrepRequest) (*executorpb.GrepResponse, error) {
+	path := req.Path
+	if path == "" {
+		path = s.workingDir
+	} else {
+		path = s.resolvePath(path)
+	}
+
+	args := []string{"-rn", req.Pattern, path}
+	if req.CaseInsensitive {
+		args = append([]string{"-i"}, args...)
+	}
+	cmd := exec.CommandContext(ctx, "grep", args...)
+	out, _ := cmd.Output() // grep exits 1 on no matches, which is fine.
+	return &executorpb.GrepResponse{Output: string(out)}, nil
+}
+
+func (s *executorServer) Glob(ctx context.Context, req *executorpb.GlobRequest) (*executorpb.GlobResponse, error) {
+	path := req.Path
+	if path == "" {
+		path = s.workingDir
+	} else {
+		path = s.resolvePath(path)
+	}
+	matches, err := filepath.Glob(filepath.Join(path, req.Pattern))
+	if err != nil {
+		return nil, err
+	}
+	return &executorpb.GlobResponse{Matches: matches}, nil
+}
@@ -0,0 +1,98 @@
+// Command llm-provider-server is the server half of the unifiedllm LLM
+// provider plugin protocol (see proto/llm_provider.proto). It wraps a real
+// unifiedllm.ProviderAdapter and exposes it over gRPC so an organization can
+// run rate-limiting, audit, and policy enforcement for model calls in a
+// separate process -- inside a locked-down gateway host, behind mTLS, or on
+// the far side of a VPN -- instead of linking a provider SDK directly into
+// the agent loop. Point a unifiedllm.GRPCAdapter at its listen address.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"net"
+	"os"
+	"strings"
+
+	"github.com/martinemde/attractor/unifiedllm"
+	"github.com/martinemde/attractor/unifiedllm/providers/anthropic"
+	"github.com/martinemde/attractor/unifiedllm/providers/google"
+	"github.com/martinemde/attractor/unifiedllm/providers/openai"
+)
+
+func main() {
+	addr := flag.String("listen", "127.0.0.1:7866", "address to listen on (use unix:/path for a unix socket)")
+	provider := flag.String("provider", "anthropic", "backing provider adapter to wrap: anthropic, gemini, or openai")
+	model := flag.String("model", "", "default model for the backing adapter (optional)")
+	apiKeyEnv := flag.String("api-key-env", "", "environment variable to read the backing provider's API key from (default: provider-specific)")
+	flag.Parse()
+
+	adapter, err := newBackingAdapter(*provider, *model, *apiKeyEnv)
+	if err != nil {
+		log.Fatalf("llm-provider-server: %v", err)
+	}
+
+	network, address := "tcp", *addr
+	if strings.HasPrefix(*addr, "unix:") {
+		network, address = "unix", strings.TrimPrefix(*addr, "unix:")
+	}
+
+	lis, err := net.Listen(network, address)
+	if err != nil {
+		log.Fatalf("llm-provider-server: listen: %v", err)
+	}
+
+	log.Printf("llm-provider-server: serving %s over %s %s", adapter.Name(), network, address)
+	if err := unifiedllm.Serve(lis, adapter); err != nil {
+		log.Fatalf("llm-provider-server: serve: %v", err)
+	}
+}
+
+// newBackingAdapter constructs the real ProviderAdapter this server
+// delegates to, the same adapters a Client would register directly.
+func newBackingAdapter(provider, model, apiKeyEnv string) (unifiedllm.ProviderAdapter, error) {
+	switch provider {
+	case "anthropic":
+		if apiKeyEnv == "" {
+			apiKeyEnv = "ANTHROPIC_API_KEY"
+		}
+		apiKey := os.Getenv(apiKeyEnv)
+		if apiKey == "" {
+			return nil, fmt.Errorf("%s is not set", apiKeyEnv)
+		}
+		var opts []anthropic.Option
+		if model != "" {
+			opts = append(opts, anthropic.WithModel(model))
+		}
+		return anthropic.NewAdapter(apiKey, opts...), nil
+	case "gemini":
+		if apiKeyEnv == "" {
+			apiKeyEnv = "GEMINI_API_KEY"
+		}
+		apiKey := os.Getenv(apiKeyEnv)
+		if apiKey == "" {
+			return nil, fmt.Errorf("%s is not set", apiKeyEnv)
+		}
+		var opts []google.Option
+		if model != "" {
+			opts = append(opts, google.WithModel(model))
+		}
+		return google.NewAdapter(apiKey, opts...), nil
+	case "openai":
+		if apiKeyEnv == "" {
+			apiKeyEnv = "OPENAI_API_KEY"
+		}
+		apiKey := os.Getenv(apiKeyEnv)
+		if apiKey == "" {
+			return nil, fmt.Errorf("%s is not set", apiKeyEnv)
+		}
+		var opts []openai.Option
+		if model != "" {
+			opts = append(opts, openai.WithModel(model))
+		}
+		return openai.NewAdapter(apiKey, opts...), nil
+	default:
+		return nil, fmt.Errorf("unknown provider %q (want anthropic, gemini, or openai)", provider)
+	}
+}
@@ -0,0 +1,22 @@
+package metrics
+
+// NoopRegistry is a Registry that discards every observation -- the
+// metrics.Registry equivalent of io.Discard, and the default for callers
+// that haven't wired in a real backend.
+type NoopRegistry struct{}
+
+func (NoopRegistry) Counter(name, help string, labels ...string) CounterVec { return noopVec{} }
+
+func (NoopRegistry) Histogram(name, help string, buckets []float64, labels ...string) HistogramVec {
+	return noopVec{}
+}
+
+func (NoopRegistry) Gauge(name, help string, labels ...string) GaugeVec { return noopVec{} }
+
+type noopVec struct{}
+
+func (noopVec) Inc(labelValues ...string)                    {}
+func (noopVec) Add(delta float64, labelValues ...string)     {}
+func (noopVec) Observe(value float64, labelValues ...string) {}
+func (noopVec) Set(value float64, labelValues ...string)     {}
+func (noopVec) Dec(labelValues ...string)                    {}
@@ -0,0 +1,98 @@
+package metrics
+
+import (
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// PrometheusRegistry is the default Registry implementation, backed by a
+// prometheus.Registerer. Instruments are created lazily on first use and
+// cached by name, mirroring how unifiedllm.Metrics builds its own
+// CounterVec/HistogramVec/GaugeVec set (see
+// unifiedllm/metrics_middleware.go) -- the difference here is the
+// name-to-vec cache is dynamic, since callers declare their own metric
+// names against a shared Registry instead of a fixed struct of fields.
+type PrometheusRegistry struct {
+	reg prometheus.Registerer
+
+	mu         sync.Mutex
+	counters   map[string]*prometheus.CounterVec
+	histograms map[string]*prometheus.HistogramVec
+	gauges     map[string]*prometheus.GaugeVec
+}
+
+// NewPrometheusRegistry creates a Registry that registers every instrument
+// it creates with reg.
+func NewPrometheusRegistry(reg prometheus.Registerer) *PrometheusRegistry {
+	return &PrometheusRegistry{
+		reg:        reg,
+		counters:   make(map[string]*prometheus.CounterVec),
+		histograms: make(map[string]*prometheus.HistogramVec),
+		gauges:     make(map[string]*prometheus.GaugeVec),
+	}
+}
+
+// Counter implements Registry.
+func (r *PrometheusRegistry) Counter(name, help string, labels ...string) CounterVec {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	c, ok := r.counters[name]
+	if !ok {
+		c = prometheus.NewCounterVec(prometheus.CounterOpts{Name: name, Help: help}, labels)
+		r.reg.MustRegister(c)
+		r.counters[name] = c
+	}
+	return promCounterVec{c}
+}
+
+// Histogram implements Registry.
+func (r *PrometheusRegistry) Histogram(name, help string, buckets []float64, labels ...string) HistogramVec {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	h, ok := r.histograms[name]
+	if !ok {
+		opts := prometheus.HistogramOpts{Name: name, Help: help}
+		if buckets != nil {
+			opts.Buckets = buckets
+		}
+		h = prometheus.NewHistogramVec(opts, labels)
+		r.reg.MustRegister(h)
+		r.histograms[name] = h
+	}
+	return promHistogramVec{h}
+}
+
+// Gauge implements Registry.
+func (r *PrometheusRegistry) Gauge(name, help string, labels ...string) GaugeVec {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	g, ok := r.gauges[name]
+	if !ok {
+		g = prometheus.NewGaugeVec(prometheus.GaugeOpts{Name: name, Help: help}, labels)
+		r.reg.MustRegister(g)
+		r.gauges[name] = g
+	}
+	return promGaugeVec{g}
+}
+
+type promCounterVec struct{ v *prometheus.CounterVec }
+
+func (c promCounterVec) Inc(labelValues ...string) { c.v.WithLabelValues(labelValues...).Inc() }
+func (c promCounterVec) Add(delta float64, labelValues ...string) {
+	c.v.WithLabelValues(labelValues...).Add(delta)
+}
+
+type promHistogramVec struct{ v *prometheus.HistogramVec }
+
+func (h promHistogramVec) Observe(value float64, labelValues ...string) {
+	h.v.WithLabelValues(labelValues...).Observe(value)
+}
+
+type promGaugeVec struct{ v *prometheus.GaugeVec }
+
+func (g promGaugeVec) Set(value float64, labelValues ...string) {
+	g.v.WithLabelValues(labelValues...).Set(value)
+}
+func (g promGaugeVec) Inc(labelValues ...string) { g.v.WithLabelValues(labelValues...).Inc() }
+func (g promGaugeVec) Dec(labelValues ...string) { g.v.WithLabelValues(labelValues...).Dec() }
@@ -0,0 +1,95 @@
+package metrics
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+func TestPrometheusRegistryCounter(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	r := NewPrometheusRegistry(reg)
+
+	c := r.Counter("test_requests_total", "Test counter.", "provider")
+	c.Inc("anthropic")
+	c.Add(2, "anthropic")
+
+	// A second call for the same name must return the same underlying
+	// instrument rather than creating (and re-registering) a duplicate.
+	again := r.Counter("test_requests_total", "Test counter.", "provider")
+	again.Inc("anthropic")
+
+	got := counterValue(t, reg, "test_requests_total", "anthropic")
+	if got != 4 {
+		t.Errorf("counter value = %v, want 4", got)
+	}
+}
+
+func TestPrometheusRegistryGauge(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	r := NewPrometheusRegistry(reg)
+
+	g := r.Gauge("test_depth", "Test gauge.", "kind")
+	g.Set(3, "subagent")
+	g.Inc("subagent")
+	g.Dec("subagent")
+
+	mfs, err := reg.Gather()
+	if err != nil {
+		t.Fatalf("Gather: %v", err)
+	}
+	var found bool
+	for _, mf := range mfs {
+		if mf.GetName() != "test_depth" {
+			continue
+		}
+		found = true
+		if got := mf.Metric[0].GetGauge().GetValue(); got != 3 {
+			t.Errorf("gauge value = %v, want 3", got)
+		}
+	}
+	if !found {
+		t.Fatal("test_depth metric not found")
+	}
+}
+
+func TestNoopRegistry(t *testing.T) {
+	var reg Registry = NoopRegistry{}
+	// Must not panic with no backend wired in.
+	reg.Counter("x", "x", "a").Inc("v")
+	reg.Histogram("y", "y", nil, "a").Observe(1, "v")
+	reg.Gauge("z", "z", "a").Set(1, "v")
+}
+
+func counterValue(t *testing.T, reg *prometheus.Registry, name string, labelValues ...string) float64 {
+	t.Helper()
+	mfs, err := reg.Gather()
+	if err != nil {
+		t.Fatalf("Gather: %v", err)
+	}
+	for _, mf := range mfs {
+		if mf.GetName() != name {
+			continue
+		}
+		for _, m := range mf.Metric {
+			if labelsMatch(m, labelValues) {
+				return m.GetCounter().GetValue()
+			}
+		}
+	}
+	t.Fatalf("metric %s%v not found", name, labelValues)
+	return 0
+}
+
+func labelsMatch(m *dto.Metric, values []string) bool {
+	if len(m.Label) != len(values) {
+		return false
+	}
+	for i, l := range m.Label {
+		if l.GetValue() != values[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,47 @@
+// Package metrics provides a provider-neutral instrumentation surface for
+// the rest of the tree: agentloop declares its named counters, histograms,
+// and gauges against a Registry instead of importing a metrics backend
+// directly. (unifiedllm.Metrics, in unifiedllm/metrics_middleware.go, uses
+// the Prometheus client directly and does not go through a Registry.) The
+// default implementation,
+// PrometheusRegistry, backs every instrument with a Prometheus
+// CounterVec/HistogramVec/GaugeVec; NoopRegistry discards observations for
+// callers that haven't wired one in.
+package metrics
+
+// Registry is a factory for named, labeled instruments. Each method is a
+// register-or-fetch: the first call for a given name creates the
+// instrument with the given label names, and every later call for that
+// name returns the same instrument and must supply label values in the
+// same order.
+type Registry interface {
+	// Counter returns a monotonically increasing counter vector labeled by labels.
+	Counter(name, help string, labels ...string) CounterVec
+
+	// Histogram returns a histogram vector labeled by labels, bucketed by
+	// buckets. A nil buckets selects the implementation's default buckets.
+	Histogram(name, help string, buckets []float64, labels ...string) HistogramVec
+
+	// Gauge returns a gauge vector labeled by labels.
+	Gauge(name, help string, labels ...string) GaugeVec
+}
+
+// CounterVec is a counter with labels bound per observation. labelValues
+// must be supplied in the same order as the labels passed to the Registry
+// method that created it.
+type CounterVec interface {
+	Inc(labelValues ...string)
+	Add(delta float64, labelValues ...string)
+}
+
+// HistogramVec is a histogram with labels bound per observation.
+type HistogramVec interface {
+	Observe(value float64, labelValues ...string)
+}
+
+// GaugeVec is a gauge with labels bound per observation.
+type GaugeVec interface {
+	Set(value float64, labelValues ...string)
+	Inc(labelValues ...string)
+	Dec(labelValues ...string)
+}
@@ -0,0 +1,232 @@
+package unifiedllm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+)
+
+// RequireApproval gates a tool call behind a caller-supplied decision, e.g. a
+// human-in-the-loop confirmation prompt for tools with side effects. It
+// returns false (without error) to deny the call.
+type RequireApproval func(name string, args json.RawMessage) (bool, error)
+
+// registeredTool pairs a Tool with its optional approval gate.
+type registeredTool struct {
+	tool     Tool
+	approval RequireApproval
+}
+
+// ToolRegistry manages the tools available to an Agent, optionally gating
+// individual tools behind a RequireApproval callback.
+type ToolRegistry struct {
+	mu    sync.RWMutex
+	tools map[string]*registeredTool
+}
+
+// NewToolRegistry creates an empty ToolRegistry.
+func NewToolRegistry() *ToolRegistry {
+	return &ToolRegistry{tools: make(map[string]*registeredTool)}
+}
+
+// Register adds or replaces a tool in the registry. approval may be nil for
+// tools that don't require gating.
+func (r *ToolRegistry) Register(tool Tool, approval RequireApproval) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.tools[tool.Name] = &registeredTool{tool: tool, approval: approval}
+}
+
+// Unregister removes a tool from the registry.
+func (r *ToolRegistry) Unregister(name string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.tools, name)
+}
+
+// Definitions returns all tool definitions (for sending to the LLM).
+func (r *ToolRegistry) Definitions() []ToolDefinition {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	defs := make([]ToolDefinition, 0, len(r.tools))
+	for _, rt := range r.tools {
+		defs = append(defs, ToolDefinition{
+			Name:        rt.tool.Name,
+			Description: rt.tool.Description,
+			Parameters:  rt.tool.Parameters,
+		})
+	}
+	return defs
+}
+
+// Tools returns the registered Tools for use as Request.Tools/GenerateOptions.Tools.
+// Tools with an approval gate have their Execute handler wrapped so the gate
+// runs before the real handler and its rejection surfaces as a tool error.
+func (r *ToolRegistry) Tools() []Tool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	result := make([]Tool, 0, len(r.tools))
+	for _, rt := range r.tools {
+		t := rt.tool
+		if rt.approval != nil && t.Execute != nil {
+			execute := t.Execute
+			name := t.Name
+			approval := rt.approval
+			t.Execute = func(args json.RawMessage) (interface{}, error) {
+				approved, err := approval(name, args)
+				if err != nil {
+					return nil, fmt.Errorf("tool %q approval check: %w", name, err)
+				}
+				if !approved {
+					return nil, fmt.Errorf("tool %q was not approved", name)
+				}
+				return execute(args)
+			}
+		}
+		result = append(result, t)
+	}
+	return result
+}
+
+// Agent bundles a name, system prompt, tool registry, and default
+// provider/model configuration into a reusable, named unit, so callers don't
+// have to re-specify the same GenerateOptions on every turn.
+type Agent struct {
+	Name            string
+	SystemPrompt    string
+	Tools           *ToolRegistry
+	ResponseFormat  *ResponseFormat
+	ReasoningEffort string
+	Provider        string
+	Model           string
+	MaxToolRounds   int
+	MaxRetries      int
+	StopConditions  []StopCondition
+	Timeout         *TimeoutConfig
+	Client          *Client
+}
+
+// NewAgent creates an Agent with an empty ToolRegistry.
+func NewAgent(name, systemPrompt string) *Agent {
+	return &Agent{Name: name, SystemPrompt: systemPrompt, Tools: NewToolRegistry()}
+}
+
+// Run executes a fresh, single-turn conversation consisting of userInput.
+func (a *Agent) Run(ctx context.Context, userInput string) (*GenerateResult, error) {
+	return a.Chat(ctx, nil, userInput)
+}
+
+// Chat continues an existing conversation history with a new user turn.
+func (a *Agent) Chat(ctx context.Context, history []Message, userInput string) (*GenerateResult, error) {
+	messages := make([]Message, len(history), len(history)+1)
+	copy(messages, history)
+	messages = append(messages, UserMessage(userInput))
+
+	return Generate(ctx, GenerateOptions{
+		Model:           a.Model,
+		Messages:        messages,
+		System:          a.SystemPrompt,
+		Tools:           a.Tools.Tools(),
+		ResponseFormat:  a.ResponseFormat,
+		ReasoningEffort: a.ReasoningEffort,
+		Provider:        a.Provider,
+		MaxToolRounds:   a.MaxToolRounds,
+		MaxRetries:      a.MaxRetries,
+		StopConditions:  a.StopConditions,
+		Timeout:         a.Timeout,
+		Client:          a.Client,
+	})
+}
+
+// AgentConfig is the serializable configuration for an Agent, loaded from
+// YAML or JSON so callers can do e.g. `--agent code-reviewer`.
+type AgentConfig struct {
+	Name            string   `json:"name" yaml:"name"`
+	SystemPrompt    string   `json:"system_prompt" yaml:"system_prompt"`
+	Provider        string   `json:"provider,omitempty" yaml:"provider,omitempty"`
+	Model           string   `json:"model,omitempty" yaml:"model,omitempty"`
+	ReasoningEffort string   `json:"reasoning_effort,omitempty" yaml:"reasoning_effort,omitempty"`
+	MaxToolRounds   int      `json:"max_tool_rounds,omitempty" yaml:"max_tool_rounds,omitempty"`
+	Tools           []string `json:"tools,omitempty" yaml:"tools,omitempty"`
+}
+
+// AgentRegistry holds named Agents for lookup by callers such as a --agent
+// CLI flag.
+type AgentRegistry struct {
+	mu     sync.RWMutex
+	agents map[string]*Agent
+}
+
+// NewAgentRegistry creates an empty AgentRegistry.
+func NewAgentRegistry() *AgentRegistry {
+	return &AgentRegistry{agents: make(map[string]*Agent)}
+}
+
+// Register adds or replaces an Agent, keyed by its Name.
+func (r *AgentRegistry) Register(agent *Agent) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.agents[agent.Name] = agent
+}
+
+// Get returns the named Agent, or false if no such agent is registered.
+func (r *AgentRegistry) Get(name string) (*Agent, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	agent, ok := r.agents[name]
+	return agent, ok
+}
+
+// Names returns the names of all registered agents.
+func (r *AgentRegistry) Names() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	names := make([]string, 0, len(r.agents))
+	for name := range r.agents {
+		names = append(names, name)
+	}
+	return names
+}
+
+// BuildAgent materializes an AgentConfig into an Agent, resolving each
+// configured tool name against catalog. Tool names absent from catalog are
+// skipped rather than treated as an error, since catalogs are assembled
+// per-binary and an agent config may be shared across binaries with
+// different tool sets.
+func BuildAgent(cfg AgentConfig, catalog map[string]Tool) *Agent {
+	agent := NewAgent(cfg.Name, cfg.SystemPrompt)
+	agent.Provider = cfg.Provider
+	agent.Model = cfg.Model
+	agent.ReasoningEffort = cfg.ReasoningEffort
+	agent.MaxToolRounds = cfg.MaxToolRounds
+	for _, name := range cfg.Tools {
+		if tool, ok := catalog[name]; ok {
+			agent.Tools.Register(tool, nil)
+		}
+	}
+	return agent
+}
+
+// LoadAgentConfigsJSON parses a JSON array of AgentConfig.
+func LoadAgentConfigsJSON(data []byte) ([]AgentConfig, error) {
+	var configs []AgentConfig
+	if err := json.Unmarshal(data, &configs); err != nil {
+		return nil, fmt.Errorf("unifiedllm: parse agent config JSON: %w", err)
+	}
+	return configs, nil
+}
+
+// LoadAgentRegistryJSON builds an AgentRegistry from a JSON config document,
+// resolving each agent's tools against catalog.
+func LoadAgentRegistryJSON(data []byte, catalog map[string]Tool) (*AgentRegistry, error) {
+	configs, err := LoadAgentConfigsJSON(data)
+	if err != nil {
+		return nil, err
+	}
+	registry := NewAgentRegistry()
+	for _, cfg := range configs {
+		registry.Register(BuildAgent(cfg, catalog))
+	}
+	return registry, nil
+}
@@ -0,0 +1,126 @@
+package unifiedllm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestAgentRunInjectsSystemPromptAndTools(t *testing.T) {
+	mock := newMockAdapter("test", "Hello from agent")
+	var captured Request
+	client := NewClient(WithProvider("test", mock), WithMiddleware(
+		func(ctx context.Context, req Request, next func(context.Context, Request) (*Response, error)) (*Response, error) {
+			captured = req
+			return next(ctx, req)
+		}))
+
+	agent := NewAgent("greeter", "You are a friendly greeter.")
+	agent.Provider = "test"
+	agent.Model = "test-model"
+	agent.Client = client
+	agent.Tools.Register(Tool{
+		Name:        "get_time",
+		Description: "Returns the current time",
+		Parameters:  map[string]interface{}{"type": "object"},
+		Execute: func(args json.RawMessage) (interface{}, error) {
+			return "noon", nil
+		},
+	}, nil)
+
+	result, err := agent.Run(context.Background(), "hi there")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Text != "Hello from agent" {
+		t.Errorf("expected %q, got %q", "Hello from agent", result.Text)
+	}
+	if len(captured.Messages) == 0 || captured.Messages[0].Role != RoleSystem ||
+		captured.Messages[0].TextContent() != "You are a friendly greeter." {
+		t.Errorf("expected the system prompt as the first message, got %+v", captured.Messages)
+	}
+	if len(captured.ToolDefs) != 1 || captured.ToolDefs[0].Name != "get_time" {
+		t.Errorf("expected get_time in the request's tool definitions, got %+v", captured.ToolDefs)
+	}
+}
+
+func TestToolRegistryGatesUnapprovedTool(t *testing.T) {
+	registry := NewToolRegistry()
+	registry.Register(Tool{
+		Name: "delete_file",
+		Execute: func(args json.RawMessage) (interface{}, error) {
+			return "deleted", nil
+		},
+	}, func(name string, args json.RawMessage) (bool, error) {
+		return false, nil
+	})
+
+	tools := registry.Tools()
+	if len(tools) != 1 {
+		t.Fatalf("expected one tool, got %d", len(tools))
+	}
+
+	_, err := tools[0].Execute(json.RawMessage(`{}`))
+	if err == nil {
+		t.Fatal("expected an error when the approval gate denies the call")
+	}
+}
+
+func TestToolRegistryPropagatesApprovalError(t *testing.T) {
+	boom := errors.New("boom")
+	registry := NewToolRegistry()
+	registry.Register(Tool{
+		Name: "delete_file",
+		Execute: func(args json.RawMessage) (interface{}, error) {
+			return "deleted", nil
+		},
+	}, func(name string, args json.RawMessage) (bool, error) {
+		return false, boom
+	})
+
+	_, err := registry.Tools()[0].Execute(json.RawMessage(`{}`))
+	if !errors.Is(err, boom) {
+		t.Errorf("expected wrapped approval error, got %v", err)
+	}
+}
+
+func TestBuildAgentResolvesToolsAgainstCatalog(t *testing.T) {
+	catalog := map[string]Tool{
+		"get_weather": {Name: "get_weather", Description: "weather lookup"},
+	}
+	cfg := AgentConfig{
+		Name:         "weather-bot",
+		SystemPrompt: "You report the weather.",
+		Tools:        []string{"get_weather", "unknown_tool"},
+	}
+
+	agent := BuildAgent(cfg, catalog)
+	defs := agent.Tools.Definitions()
+	if len(defs) != 1 || defs[0].Name != "get_weather" {
+		t.Fatalf("expected only get_weather to resolve, got %+v", defs)
+	}
+}
+
+func TestLoadAgentRegistryJSON(t *testing.T) {
+	data := []byte(`[
+		{"name": "code-reviewer", "system_prompt": "Review code.", "model": "claude-sonnet-4-5"},
+		{"name": "greeter", "system_prompt": "Say hi."}
+	]`)
+
+	registry, err := LoadAgentRegistryJSON(data, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	agent, ok := registry.Get("code-reviewer")
+	if !ok {
+		t.Fatal("expected code-reviewer to be registered")
+	}
+	if agent.Model != "claude-sonnet-4-5" {
+		t.Errorf("expected model claude-sonnet-4-5, got %q", agent.Model)
+	}
+	if len(registry.Names()) != 2 {
+		t.Errorf("expected 2 registered agents, got %d", len(registry.Names()))
+	}
+}
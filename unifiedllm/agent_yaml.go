@@ -0,0 +1,30 @@
+package unifiedllm
+
+import (
+	"fmt"
+
+	"gopkg.in/yaml.v3"
+)
+
+// LoadAgentConfigsYAML parses a YAML document containing a list of AgentConfig.
+func LoadAgentConfigsYAML(data []byte) ([]AgentConfig, error) {
+	var configs []AgentConfig
+	if err := yaml.Unmarshal(data, &configs); err != nil {
+		return nil, fmt.Errorf("unifiedllm: parse agent config YAML: %w", err)
+	}
+	return configs, nil
+}
+
+// LoadAgentRegistryYAML builds an AgentRegistry from a YAML config document,
+// resolving each agent's tools against catalog.
+func LoadAgentRegistryYAML(data []byte, catalog map[string]Tool) (*AgentRegistry, error) {
+	configs, err := LoadAgentConfigsYAML(data)
+	if err != nil {
+		return nil, err
+	}
+	registry := NewAgentRegistry()
+	for _, cfg := range configs {
+		registry.Register(BuildAgent(cfg, catalog))
+	}
+	return registry, nil
+}
@@ -0,0 +1,24 @@
+package unifiedllm
+
+import "testing"
+
+func TestLoadAgentRegistryYAML(t *testing.T) {
+	data := []byte(`
+- name: code-reviewer
+  system_prompt: Review code.
+  model: claude-sonnet-4-5
+- name: greeter
+  system_prompt: Say hi.
+`)
+
+	registry, err := LoadAgentRegistryYAML(data, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, ok := registry.Get("code-reviewer"); !ok {
+		t.Fatal("expected code-reviewer to be registered")
+	}
+	if len(registry.Names()) != 2 {
+		t.Errorf("expected 2 registered agents, got %d", len(registry.Names()))
+	}
+}
@@ -0,0 +1,235 @@
+package unifiedllm
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// AsyncDeadlineFunc reports how much retry budget remains for taskID: ok is
+// false once the task should be abandoned outright (its owning unit of work
+// was superseded -- a new user turn started, the session closed -- not
+// merely "out of time"), and remaining is how long the task's logical
+// deadline still has to run otherwise. AsyncRetryer calls it before every
+// attempt, so a deadline that depends on live state (e.g. "has the session
+// moved on to a later turn") is re-evaluated on every retry, not fixed at
+// Submit time.
+type AsyncDeadlineFunc func(taskID string) (remaining time.Duration, ok bool)
+
+// AsyncRetryer runs tasks with RetryPolicy's backoff/jitter logic in
+// background goroutines, modeled on Charon's slot-based async retryer: a
+// task's retry budget is tied to a logical deadline (Charon's "5 slots
+// later" rule) supplied by AsyncDeadlineFunc, not a fixed wall-clock
+// timeout. This fits an agent loop where a tool call or streaming request
+// belongs to a user turn: once AsyncDeadlineFunc reports that turn is
+// superseded, every outstanding retry for it is abandoned instead of racing
+// a stale response to completion and burning tokens on an answer nobody
+// will read.
+type AsyncRetryer[T any] struct {
+	policy     RetryPolicy
+	deadlineFn AsyncDeadlineFunc
+	onRetry    func(taskID string, err error, attempt int, delay, remaining time.Duration)
+
+	mu       sync.Mutex
+	inFlight map[string]context.CancelFunc
+	wg       sync.WaitGroup
+	closed   bool
+}
+
+// AsyncRetryerOption configures an AsyncRetryer, the way ClientOption
+// configures a Client.
+type AsyncRetryerOption[T any] func(*AsyncRetryer[T])
+
+// WithAsyncOnRetry sets the hook AsyncRetryer.run fires before every retry
+// sleep, alongside policy.OnRetry if that's also set. Unlike
+// RetryPolicy.OnRetry, it receives the task ID and the remaining logical
+// budget AsyncDeadlineFunc reported for this attempt.
+func WithAsyncOnRetry[T any](fn func(taskID string, err error, attempt int, delay, remaining time.Duration)) AsyncRetryerOption[T] {
+	return func(r *AsyncRetryer[T]) { r.onRetry = fn }
+}
+
+// AsyncResult is delivered exactly once on Submit's returned channel: either
+// a successful Value, or an Err that's either the terminal attempt's error
+// (Abandoned false) or the reason the task was given up on before it could
+// finish (Abandoned true: the logical deadline elapsed, a retry delay would
+// have outlived the remaining budget, or Shutdown cancelled it).
+type AsyncResult[T any] struct {
+	Value     T
+	Err       error
+	Abandoned bool
+}
+
+// NewAsyncRetryer creates an AsyncRetryer using policy's backoff/jitter
+// configuration and deadlineFn to decide, before each attempt, how much
+// retry budget taskID still has.
+func NewAsyncRetryer[T any](policy RetryPolicy, deadlineFn AsyncDeadlineFunc, opts ...AsyncRetryerOption[T]) *AsyncRetryer[T] {
+	r := &AsyncRetryer[T]{
+		policy:     policy,
+		deadlineFn: deadlineFn,
+		inFlight:   make(map[string]context.CancelFunc),
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+// Submit runs fn in a background goroutine under the retryer's policy,
+// keyed by taskID, and returns a channel receiving its single AsyncResult.
+// Cancelling ctx (or a prior Cancel(taskID)/Shutdown call) stops retries in
+// progress; fn itself receives a context derived from ctx, so an
+// in-progress attempt is told to stop too, not just the sleep between
+// attempts. Submitting after Shutdown has started returns an already-closed
+// channel carrying an Abandoned error.
+func (r *AsyncRetryer[T]) Submit(ctx context.Context, taskID string, fn func(ctx context.Context) (T, error)) <-chan AsyncResult[T] {
+	resultCh := make(chan AsyncResult[T], 1)
+
+	r.mu.Lock()
+	if r.closed {
+		r.mu.Unlock()
+		resultCh <- AsyncResult[T]{Err: fmt.Errorf("unifiedllm: async retryer is shut down"), Abandoned: true}
+		close(resultCh)
+		return resultCh
+	}
+	taskCtx, cancel := context.WithCancel(ctx)
+	r.inFlight[taskID] = cancel
+	r.wg.Add(1)
+	r.mu.Unlock()
+
+	go func() {
+		defer r.wg.Done()
+
+		result := r.run(taskCtx, taskID, fn)
+		// Drop the task from inFlight before delivering its result, so a
+		// caller that has received the result sees Cancel report false.
+		r.mu.Lock()
+		delete(r.inFlight, taskID)
+		r.mu.Unlock()
+		cancel()
+
+		resultCh <- result
+		close(resultCh)
+	}()
+
+	return resultCh
+}
+
+// Cancel stops taskID's in-flight retries, if any, delivering an Abandoned
+// AsyncResult on its Submit channel once the current attempt (if any)
+// observes ctx.Done(). Returns false if no task is in flight under that ID.
+func (r *AsyncRetryer[T]) Cancel(taskID string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	cancel, ok := r.inFlight[taskID]
+	if !ok {
+		return false
+	}
+	cancel()
+	return true
+}
+
+// Shutdown cancels every in-flight task and blocks until they've all
+// delivered a result (draining, not abandoning work already past its final
+// fn call), or ctx is done first.
+func (r *AsyncRetryer[T]) Shutdown(ctx context.Context) error {
+	r.mu.Lock()
+	if !r.closed {
+		r.closed = true
+		for _, cancel := range r.inFlight {
+			cancel()
+		}
+	}
+	r.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		r.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// run is AsyncRetryer's retry loop: Retry's backoff/jitter logic,
+// re-checked each attempt against AsyncDeadlineFunc instead of Retry's
+// single context-cancellation check, so a task abandons as soon as its
+// owning turn is superseded rather than sleeping out a delay nobody needs
+// the answer to anymore. A Retry-After hint replaces the computed delay and
+// is bounded only by the task's remaining budget; unlike Retry, run does
+// not give up when it exceeds MaxDelay.
+func (r *AsyncRetryer[T]) run(ctx context.Context, taskID string, fn func(context.Context) (T, error)) AsyncResult[T] {
+	clock := r.policy.effectiveClock()
+	var prevDelay time.Duration
+
+	for attempt := 0; ; attempt++ {
+		remaining, ok := r.deadlineFn(taskID)
+		if !ok {
+			return AsyncResult[T]{
+				Err:       fmt.Errorf("unifiedllm: task %q abandoned: logical deadline elapsed", taskID),
+				Abandoned: true,
+			}
+		}
+
+		result, err := fn(ctx)
+		if err == nil {
+			return AsyncResult[T]{Value: result}
+		}
+
+		// fn itself observes ctx, so a cancellation (Cancel/Shutdown) that
+		// lands mid-attempt surfaces here as fn's returned error, not just
+		// in the sleep-before-retry select below. Report it the same way:
+		// abandoned, not a terminal attempt error.
+		if ctx.Err() != nil {
+			return AsyncResult[T]{Err: ctx.Err(), Abandoned: true}
+		}
+
+		if attempt >= r.policy.MaxRetries || !isRetryableFor(r.policy, err) {
+			return AsyncResult[T]{Err: err}
+		}
+
+		effective := r.policy.policyForError(err)
+		var delay time.Duration
+		switch {
+		case effective.DelayFunc != nil:
+			delay = effective.DelayFunc(attempt, err)
+		case effective.DecorrelatedJitter:
+			delay = effective.DecorrelatedDelay(prevDelay)
+		default:
+			delay = effective.Delay(attempt)
+		}
+
+		if retryAfter := retryAfterFor(err); retryAfter != nil {
+			delay = time.Duration(*retryAfter * float64(time.Second))
+		}
+		prevDelay = delay
+
+		// A delay (including a provider's Retry-After) that would outlive
+		// the task's remaining logical budget is abandoned immediately
+		// instead of slept out only to be cancelled partway through.
+		if remaining > 0 && delay > remaining {
+			return AsyncResult[T]{
+				Err:       fmt.Errorf("unifiedllm: task %q abandoned: retry delay %s exceeds remaining budget %s: %w", taskID, delay, remaining, err),
+				Abandoned: true,
+			}
+		}
+
+		if r.onRetry != nil {
+			r.onRetry(taskID, err, attempt+1, delay, remaining)
+		}
+		if r.policy.OnRetry != nil {
+			r.policy.OnRetry(err, attempt+1, delay)
+		}
+
+		select {
+		case <-ctx.Done():
+			return AsyncResult[T]{Err: ctx.Err(), Abandoned: true}
+		case <-clock.After(delay):
+		}
+	}
+}
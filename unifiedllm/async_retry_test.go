@@ -0,0 +1,175 @@
+package unifiedllm
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestAsyncRetrySuccess(t *testing.T) {
+	policy := RetryPolicy{MaxRetries: 3, BaseDelay: 0.001, BackoffMultiplier: 1, MaxDelay: 0.001, Jitter: false}
+	retryer := NewAsyncRetryer[string](policy, func(string) (time.Duration, bool) {
+		return time.Minute, true
+	})
+
+	callCount := 0
+	resultCh := retryer.Submit(context.Background(), "task-1", func(ctx context.Context) (string, error) {
+		callCount++
+		if callCount < 3 {
+			return "", &ServerError{ProviderError: ProviderError{
+				SDKError: SDKError{Message: "server error"}, Retryable: true,
+			}}
+		}
+		return "success", nil
+	})
+
+	result := <-resultCh
+	if result.Err != nil {
+		t.Fatalf("unexpected error: %v", result.Err)
+	}
+	if result.Value != "success" {
+		t.Errorf("expected %q, got %q", "success", result.Value)
+	}
+	if callCount != 3 {
+		t.Errorf("expected 3 calls, got %d", callCount)
+	}
+}
+
+func TestAsyncRetryAbandonedByDeadline(t *testing.T) {
+	policy := RetryPolicy{MaxRetries: 5, BaseDelay: 0.001, BackoffMultiplier: 1, MaxDelay: 0.001, Jitter: false}
+
+	callCount := 0
+	retryer := NewAsyncRetryer[string](policy, func(string) (time.Duration, bool) {
+		callCount++
+		// Allow the first attempt through, then report the logical
+		// deadline elapsed, as if a later user turn superseded this task.
+		return time.Minute, callCount == 1
+	})
+
+	resultCh := retryer.Submit(context.Background(), "task-1", func(ctx context.Context) (string, error) {
+		return "", &ServerError{ProviderError: ProviderError{
+			SDKError: SDKError{Message: "server error"}, Retryable: true,
+		}}
+	})
+
+	result := <-resultCh
+	if !result.Abandoned {
+		t.Fatal("expected Abandoned to be true")
+	}
+	if result.Err == nil {
+		t.Fatal("expected an error explaining the abandonment")
+	}
+}
+
+func TestAsyncRetryAbandonedWhenDelayExceedsBudget(t *testing.T) {
+	policy := RetryPolicy{MaxRetries: 3, BaseDelay: 10.0, BackoffMultiplier: 1, MaxDelay: 10.0, Jitter: false}
+	retryer := NewAsyncRetryer[string](policy, func(string) (time.Duration, bool) {
+		return time.Millisecond, true // remaining budget is far less than the 10s backoff delay
+	})
+
+	resultCh := retryer.Submit(context.Background(), "task-1", func(ctx context.Context) (string, error) {
+		return "", &ServerError{ProviderError: ProviderError{
+			SDKError: SDKError{Message: "server error"}, Retryable: true,
+		}}
+	})
+
+	result := <-resultCh
+	if !result.Abandoned {
+		t.Fatal("expected Abandoned to be true when the retry delay exceeds the remaining budget")
+	}
+}
+
+func TestAsyncRetryOnRetryHookReceivesTaskIDAndBudget(t *testing.T) {
+	policy := RetryPolicy{MaxRetries: 2, BaseDelay: 0.001, BackoffMultiplier: 1, MaxDelay: 0.001, Jitter: false}
+
+	var gotTaskID string
+	var gotRemaining time.Duration
+	hookCalls := 0
+	retryer := NewAsyncRetryer[string](policy, func(string) (time.Duration, bool) {
+		return 5 * time.Second, true
+	}, WithAsyncOnRetry[string](func(taskID string, err error, attempt int, delay, remaining time.Duration) {
+		hookCalls++
+		gotTaskID = taskID
+		gotRemaining = remaining
+	}))
+
+	callCount := 0
+	resultCh := retryer.Submit(context.Background(), "turn-42", func(ctx context.Context) (string, error) {
+		callCount++
+		if callCount < 2 {
+			return "", &ServerError{ProviderError: ProviderError{
+				SDKError: SDKError{Message: "server error"}, Retryable: true,
+			}}
+		}
+		return "success", nil
+	})
+
+	result := <-resultCh
+	if result.Err != nil {
+		t.Fatalf("unexpected error: %v", result.Err)
+	}
+	if hookCalls != 1 {
+		t.Fatalf("expected the retry hook to fire once, got %d", hookCalls)
+	}
+	if gotTaskID != "turn-42" {
+		t.Errorf("expected task ID %q, got %q", "turn-42", gotTaskID)
+	}
+	if gotRemaining != 5*time.Second {
+		t.Errorf("expected remaining budget 5s, got %v", gotRemaining)
+	}
+}
+
+func TestAsyncRetryCancel(t *testing.T) {
+	policy := RetryPolicy{MaxRetries: 5, BaseDelay: 1.0, BackoffMultiplier: 1, MaxDelay: 1.0, Jitter: false}
+	retryer := NewAsyncRetryer[string](policy, func(string) (time.Duration, bool) {
+		return time.Hour, true
+	})
+
+	started := make(chan struct{})
+	resultCh := retryer.Submit(context.Background(), "task-1", func(ctx context.Context) (string, error) {
+		close(started)
+		<-ctx.Done()
+		return "", ctx.Err()
+	})
+
+	<-started
+	if !retryer.Cancel("task-1") {
+		t.Fatal("expected Cancel to find the in-flight task")
+	}
+
+	result := <-resultCh
+	if !result.Abandoned {
+		t.Error("expected a cancelled task to be reported as Abandoned")
+	}
+	if retryer.Cancel("task-1") {
+		t.Error("expected a second Cancel of the same task to report no in-flight task")
+	}
+}
+
+func TestAsyncRetryShutdownDrains(t *testing.T) {
+	policy := RetryPolicy{MaxRetries: 2, BaseDelay: 0.001, BackoffMultiplier: 1, MaxDelay: 0.001, Jitter: false}
+	retryer := NewAsyncRetryer[string](policy, func(string) (time.Duration, bool) {
+		return time.Minute, true
+	})
+
+	resultCh := retryer.Submit(context.Background(), "task-1", func(ctx context.Context) (string, error) {
+		<-ctx.Done()
+		return "", ctx.Err()
+	})
+
+	if err := retryer.Shutdown(context.Background()); err != nil {
+		t.Fatalf("unexpected Shutdown error: %v", err)
+	}
+
+	result := <-resultCh
+	if !result.Abandoned {
+		t.Error("expected the in-flight task to be reported as Abandoned after Shutdown")
+	}
+
+	submitAfterShutdown := retryer.Submit(context.Background(), "task-2", func(ctx context.Context) (string, error) {
+		return "unreachable", nil
+	})
+	if result := <-submitAfterShutdown; !result.Abandoned {
+		t.Error("expected Submit after Shutdown to return an Abandoned result")
+	}
+}
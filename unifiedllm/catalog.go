@@ -1,21 +1,63 @@
 package unifiedllm
 
-// ModelInfo describes a known model in the catalog.
+// ModelInfo describes a known model in the catalog. User-supplied model
+// config files (see LoadModelsFromFile) mirror this shape in YAML or JSON.
 type ModelInfo struct {
-	ID                  string   `json:"id"`
-	Provider            string   `json:"provider"`
-	DisplayName         string   `json:"display_name"`
-	ContextWindow       int      `json:"context_window"`
-	MaxOutput           *int     `json:"max_output,omitempty"`
-	SupportsTools       bool     `json:"supports_tools"`
-	SupportsVision      bool     `json:"supports_vision"`
-	SupportsReasoning   bool     `json:"supports_reasoning"`
-	InputCostPerMillion  *float64 `json:"input_cost_per_million,omitempty"`
-	OutputCostPerMillion *float64 `json:"output_cost_per_million,omitempty"`
-	Aliases             []string `json:"aliases,omitempty"`
+	ID                string `yaml:"id" json:"id"`
+	Provider          string `yaml:"provider" json:"provider"`
+	DisplayName       string `yaml:"display_name" json:"display_name"`
+	ContextWindow     int    `yaml:"context_window" json:"context_window"`
+	MaxOutput         *int   `yaml:"max_output,omitempty" json:"max_output,omitempty"`
+	SupportsTools     bool   `yaml:"supports_tools" json:"supports_tools"`
+	SupportsVision    bool   `yaml:"supports_vision" json:"supports_vision"`
+	SupportsReasoning bool   `yaml:"supports_reasoning" json:"supports_reasoning"`
+
+	// SupportsJSONMode reports whether the provider can be asked to
+	// constrain output to valid JSON (e.g. for GenerateObject) without tool
+	// calling.
+	SupportsJSONMode bool `yaml:"supports_json_mode,omitempty" json:"supports_json_mode,omitempty"`
+	// SupportsStreaming reports whether the model has a working Stream path.
+	// Nearly every current model does; this mostly exists to flag the rare
+	// batch-only or reasoning-only model that doesn't.
+	SupportsStreaming bool `yaml:"supports_streaming,omitempty" json:"supports_streaming,omitempty"`
+	// SupportsAudioInput reports whether the model accepts audio content
+	// blocks in a request.
+	SupportsAudioInput bool `yaml:"supports_audio_input,omitempty" json:"supports_audio_input,omitempty"`
+	// SupportsPromptCaching reports whether the provider honors cache-control
+	// hints on messages, making CachedInputCostPerMillion meaningful.
+	SupportsPromptCaching bool `yaml:"supports_prompt_caching,omitempty" json:"supports_prompt_caching,omitempty"`
+
+	InputCostPerMillion  *float64 `yaml:"input_cost_per_million,omitempty" json:"input_cost_per_million,omitempty"`
+	OutputCostPerMillion *float64 `yaml:"output_cost_per_million,omitempty" json:"output_cost_per_million,omitempty"`
+	// CachedInputCostPerMillion is the discounted per-million-token rate for
+	// cache-hit input, when SupportsPromptCaching is true.
+	CachedInputCostPerMillion *float64 `yaml:"cached_input_cost_per_million,omitempty" json:"cached_input_cost_per_million,omitempty"`
+
+	// Modalities lists the content kinds the model accepts as input, e.g.
+	// "text", "image", "audio". Unset means "text only" by convention.
+	Modalities []string `yaml:"modalities,omitempty" json:"modalities,omitempty"`
+	// TokenizerID names the tokenizer this model's ContextWindow and costs
+	// are measured against (e.g. "cl100k_base", "claude"), for callers doing
+	// their own local token estimation.
+	TokenizerID string `yaml:"tokenizer_id,omitempty" json:"tokenizer_id,omitempty"`
+
+	// Deprecated marks a model the provider has announced it will retire.
+	// Deprecated models stay in the catalog (existing pins must keep
+	// resolving) but are skipped by GetLatestModel.
+	Deprecated bool `yaml:"deprecated,omitempty" json:"deprecated,omitempty"`
+	// DeprecationDate is the provider-announced retirement date, if known,
+	// as a free-form string (e.g. "2026-12-01") since providers don't agree
+	// on a single format.
+	DeprecationDate string `yaml:"deprecation_date,omitempty" json:"deprecation_date,omitempty"`
+	// ReplacedBy names the model ID callers pinned to this one should move
+	// to, if the provider has suggested one.
+	ReplacedBy string `yaml:"replaced_by,omitempty" json:"replaced_by,omitempty"`
+
+	Aliases     []string `yaml:"aliases,omitempty" json:"aliases,omitempty"`
+	ReleaseDate string   `yaml:"release_date,omitempty" json:"release_date,omitempty"`
 }
 
-func intPtr(v int) *int       { return &v }
+func intPtr(v int) *int           { return &v }
 func floatPtr(v float64) *float64 { return &v }
 
 // Models is the built-in model catalog (February 2026).
@@ -77,7 +119,20 @@ var Models = []ModelInfo{
 }
 
 // GetModelInfo returns the catalog entry for a model, or nil if unknown.
+// User-registered models (see RegisterModel) take precedence over the
+// built-in catalog when an ID or alias collides with both.
 func GetModelInfo(modelID string) *ModelInfo {
+	registered := registeredModels()
+	for i := range registered {
+		if registered[i].ID == modelID {
+			return &registered[i]
+		}
+		for _, alias := range registered[i].Aliases {
+			if alias == modelID {
+				return &registered[i]
+			}
+		}
+	}
 	for i := range Models {
 		if Models[i].ID == modelID {
 			return &Models[i]
@@ -92,14 +147,26 @@ func GetModelInfo(modelID string) *ModelInfo {
 }
 
 // ListModels returns all known models, optionally filtered by provider.
+// User-registered models (see RegisterModel) are included alongside the
+// built-in catalog; one registered under a built-in ID replaces that entry.
 func ListModels(provider string) []ModelInfo {
+	registered := registeredModels()
+	shadowed := make(map[string]bool, len(registered))
+	for _, m := range registered {
+		shadowed[m.ID] = true
+	}
+	all := make([]ModelInfo, 0, len(Models)+len(registered))
+	for _, m := range Models {
+		if !shadowed[m.ID] {
+			all = append(all, m)
+		}
+	}
+	all = append(all, registered...)
 	if provider == "" {
-		result := make([]ModelInfo, len(Models))
-		copy(result, Models)
-		return result
+		return all
 	}
 	var result []ModelInfo
-	for _, m := range Models {
+	for _, m := range all {
 		if m.Provider == provider {
 			result = append(result, m)
 		}
@@ -107,28 +174,59 @@ func ListModels(provider string) []ModelInfo {
 	return result
 }
 
-// GetLatestModel returns the first (newest/best) model for a provider,
-// optionally filtered by capability.
-func GetLatestModel(provider string, capability string) *ModelInfo {
-	for i := range Models {
-		if Models[i].Provider != provider {
+// Capabilities predicates GetLatestModel's search: every field set to true
+// must be satisfied by a candidate's matching ModelInfo field. The zero
+// value, Capabilities{}, matches any model. This replaces the earlier
+// single magic-string capability parameter, which couldn't express "vision
+// and reasoning" or the newer capability flags added alongside it.
+type Capabilities struct {
+	Vision        bool
+	Tools         bool
+	Reasoning     bool
+	JSONMode      bool
+	Streaming     bool
+	AudioInput    bool
+	PromptCaching bool
+}
+
+// satisfiedBy reports whether m has every capability c requires.
+func (c Capabilities) satisfiedBy(m ModelInfo) bool {
+	if c.Vision && !m.SupportsVision {
+		return false
+	}
+	if c.Tools && !m.SupportsTools {
+		return false
+	}
+	if c.Reasoning && !m.SupportsReasoning {
+		return false
+	}
+	if c.JSONMode && !m.SupportsJSONMode {
+		return false
+	}
+	if c.Streaming && !m.SupportsStreaming {
+		return false
+	}
+	if c.AudioInput && !m.SupportsAudioInput {
+		return false
+	}
+	if c.PromptCaching && !m.SupportsPromptCaching {
+		return false
+	}
+	return true
+}
+
+// GetLatestModel returns the first (newest/best) non-deprecated model for a
+// provider that satisfies capabilities. User-registered models (see
+// RegisterModel) are preferred over the built-in catalog, so ops teams can
+// pin an internal proxy model as the default for a provider.
+func GetLatestModel(provider string, capabilities Capabilities) *ModelInfo {
+	all := append(registeredModels(), Models...)
+	for i := range all {
+		if all[i].Provider != provider || all[i].Deprecated {
 			continue
 		}
-		switch capability {
-		case "":
-			return &Models[i]
-		case "vision":
-			if Models[i].SupportsVision {
-				return &Models[i]
-			}
-		case "tools":
-			if Models[i].SupportsTools {
-				return &Models[i]
-			}
-		case "reasoning":
-			if Models[i].SupportsReasoning {
-				return &Models[i]
-			}
+		if capabilities.satisfiedBy(all[i]) {
+			return &all[i]
 		}
 	}
 	return nil
@@ -67,7 +67,7 @@ func TestListModels(t *testing.T) {
 }
 
 func TestGetLatestModel(t *testing.T) {
-	info := GetLatestModel("anthropic", "")
+	info := GetLatestModel("anthropic", Capabilities{})
 	if info == nil {
 		t.Fatal("expected to find latest Anthropic model")
 	}
@@ -75,7 +75,7 @@ func TestGetLatestModel(t *testing.T) {
 		t.Errorf("expected %q, got %q", "claude-opus-4-6", info.ID)
 	}
 
-	info = GetLatestModel("openai", "reasoning")
+	info = GetLatestModel("openai", Capabilities{Reasoning: true})
 	if info == nil {
 		t.Fatal("expected to find OpenAI reasoning model")
 	}
@@ -86,10 +86,25 @@ func TestGetLatestModel(t *testing.T) {
 		t.Error("expected supports_reasoning = true")
 	}
 
-	info = GetLatestModel("nonexistent", "")
+	info = GetLatestModel("nonexistent", Capabilities{})
 	if info != nil {
 		t.Errorf("expected nil for nonexistent provider, got %v", info)
 	}
+
+	info = GetLatestModel("openai", Capabilities{Vision: true, Reasoning: true})
+	if info == nil || !info.SupportsVision || !info.SupportsReasoning {
+		t.Fatalf("expected an OpenAI model satisfying vision+reasoning, got %+v", info)
+	}
+}
+
+func TestGetLatestModelSkipsDeprecated(t *testing.T) {
+	resetUserModels(t)
+	RegisterModel(ModelInfo{ID: "gpt-5.2-preview", Provider: "openai", DisplayName: "GPT-5.2 Preview", ContextWindow: 128000, Deprecated: true})
+
+	info := GetLatestModel("openai", Capabilities{})
+	if info == nil || info.ID == "gpt-5.2-preview" {
+		t.Fatalf("expected deprecated model to be skipped, got %+v", info)
+	}
 }
 
 func TestModelInfoFields(t *testing.T) {
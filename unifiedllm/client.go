@@ -3,7 +3,9 @@ package unifiedllm
 import (
 	"context"
 	"fmt"
+	"sort"
 	"sync"
+	"time"
 )
 
 // Middleware wraps a provider call. It receives the request and a next function
@@ -19,8 +21,10 @@ type Client struct {
 	providers       map[string]ProviderAdapter
 	defaultProvider string
 	middleware      []Middleware
-	streamMW       []StreamMiddleware
-	mu             sync.RWMutex
+	streamMW        []StreamMiddleware
+	retryPolicy     *RetryPolicy  // nil means Complete makes no retry attempts
+	routingPolicy   RoutingPolicy // nil means Complete uses resolveProvider directly
+	mu              sync.RWMutex
 }
 
 // ClientOption configures a Client.
@@ -54,6 +58,25 @@ func WithStreamMiddleware(mw ...StreamMiddleware) ClientOption {
 	}
 }
 
+// WithRetryPolicy sets the retry policy Complete applies to every request
+// that doesn't set its own Request.RetryPolicy. Retry does not apply to
+// Stream, since a partially-streamed response can't be safely replayed.
+func WithRetryPolicy(policy RetryPolicy) ClientOption {
+	return func(c *Client) {
+		c.retryPolicy = &policy
+	}
+}
+
+// WithRoutingPolicy sets the policy Complete uses to choose among every
+// registered provider when a request doesn't name one explicitly (see
+// RoutingPolicy). Requests that set Request.Provider bypass routing
+// entirely, same as with a single registered provider.
+func WithRoutingPolicy(policy RoutingPolicy) ClientOption {
+	return func(c *Client) {
+		c.routingPolicy = policy
+	}
+}
+
 // NewClient creates a new Client with the given options.
 func NewClient(opts ...ClientOption) *Client {
 	c := &Client{
@@ -62,6 +85,7 @@ func NewClient(opts ...ClientOption) *Client {
 	for _, opt := range opts {
 		opt(c)
 	}
+	c.registerReattachProviders()
 	// If no default and exactly one provider, use it.
 	if c.defaultProvider == "" && len(c.providers) == 1 {
 		for name := range c.providers {
@@ -111,8 +135,35 @@ func (c *Client) resolveProvider(req Request) (ProviderAdapter, error) {
 	return adapter, nil
 }
 
-// Complete sends a blocking request through middleware to the resolved provider.
+// candidateProviders returns every registered provider name, sorted for a
+// deterministic iteration order.
+func (c *Client) candidateProviders() []string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	names := make([]string, 0, len(c.providers))
+	for name := range c.providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Complete sends a blocking request through middleware to the resolved
+// provider. If req.RetryPolicy is set, it's used in place of the Client's
+// configured retry policy (see WithRetryPolicy); if neither is set, Complete
+// makes no retry attempts.
+//
+// If req.Provider is empty and a routing policy is configured (see
+// WithRoutingPolicy), Complete routes through completeWithRouting instead of
+// resolving a single provider directly.
 func (c *Client) Complete(ctx context.Context, req Request) (*Response, error) {
+	c.mu.RLock()
+	routingPolicy := c.routingPolicy
+	c.mu.RUnlock()
+	if req.Provider == "" && routingPolicy != nil {
+		return c.completeWithRouting(ctx, req, routingPolicy)
+	}
+
 	adapter, err := c.resolveProvider(req)
 	if err != nil {
 		return nil, err
@@ -137,7 +188,66 @@ func (c *Client) Complete(ctx context.Context, req Request) (*Response, error) {
 		}
 	}
 
-	return handler(ctx, req)
+	c.mu.RLock()
+	policy := req.RetryPolicy
+	if policy == nil {
+		policy = c.retryPolicy
+	}
+	c.mu.RUnlock()
+
+	if policy == nil {
+		return handler(ctx, req)
+	}
+	// Wrap the whole chain in RetryPolicyMiddleware, outermost, so a retried
+	// attempt re-runs every registered middleware (logging, metrics,
+	// tracing) rather than just the adapter call.
+	return RetryPolicyMiddleware(*policy)(ctx, req, handler)
+}
+
+// completeWithRouting dispatches req across every registered provider under
+// policy's direction, trying candidates in the order policy.Select returns
+// them and failing over to the next candidate when the chosen provider
+// returns a retryable SDKError. The returned Response's Provider field
+// reports whichever provider actually served the request.
+func (c *Client) completeWithRouting(ctx context.Context, req Request, policy RoutingPolicy) (*Response, error) {
+	candidates := c.candidateProviders()
+	tried := make(map[string]bool, len(candidates))
+
+	var lastErr error
+	for len(tried) < len(candidates) {
+		remaining := make([]string, 0, len(candidates)-len(tried))
+		for _, name := range candidates {
+			if !tried[name] {
+				remaining = append(remaining, name)
+			}
+		}
+
+		name, err := policy.Select(req, remaining)
+		if err != nil {
+			if lastErr != nil {
+				return nil, lastErr
+			}
+			return nil, err
+		}
+		tried[name] = true
+
+		attempt := req
+		attempt.Provider = name
+
+		start := time.Now()
+		resp, err := c.Complete(ctx, attempt)
+		if observer, ok := policy.(RoutingObserver); ok {
+			observer.Observe(name, time.Since(start), err)
+		}
+		if err == nil {
+			return resp, nil
+		}
+		lastErr = err
+		if !IsRetryable(err) {
+			return nil, err
+		}
+	}
+	return nil, lastErr
 }
 
 // Stream sends a streaming request through middleware to the resolved provider.
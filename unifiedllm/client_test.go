@@ -3,7 +3,10 @@ package unifiedllm
 import (
 	"context"
 	"encoding/json"
+	"fmt"
+	"sync"
 	"testing"
+	"time"
 )
 
 // mockAdapter is a test double for ProviderAdapter.
@@ -409,11 +412,339 @@ func (s *sequenceAdapter) Complete(ctx context.Context, req Request) (*Response,
 }
 
 func (s *sequenceAdapter) Stream(ctx context.Context, req Request) (<-chan StreamEvent, error) {
-	ch := make(chan StreamEvent)
+	resp, _ := s.Complete(ctx, req)
+	ch := make(chan StreamEvent, 8)
+	ch <- StreamEvent{Type: StreamStart}
+	for _, part := range resp.Message.Content {
+		if part.Kind == ContentText && part.Text != "" {
+			ch <- StreamEvent{Type: TextDelta, Delta: part.Text}
+		}
+		if part.Kind == ContentToolCall && part.ToolCall != nil {
+			ch <- StreamEvent{Type: ToolCallEnd, ToolCall: &ToolCall{
+				ID: part.ToolCall.ID, Name: part.ToolCall.Name, Arguments: part.ToolCall.Arguments,
+			}}
+		}
+	}
+	ch <- StreamEvent{Type: StreamFinish, FinishReason: &resp.FinishReason, Usage: &resp.Usage, Response: resp}
 	close(ch)
 	return ch, nil
 }
 
+func TestStreamGenerateRunsToolLoop(t *testing.T) {
+	toolResponse := &Response{
+		Message: Message{
+			Role:    RoleAssistant,
+			Content: []ContentPart{ToolCallPart("call_1", "get_weather", json.RawMessage(`{"city":"SF"}`))},
+		},
+		FinishReason: FinishReason{Reason: "tool_calls"},
+		Usage:        Usage{InputTokens: 10, OutputTokens: 5, TotalTokens: 15},
+	}
+	textResponse := &Response{
+		Message:      Message{Role: RoleAssistant, Content: []ContentPart{TextPart("It's 72F in SF")}},
+		FinishReason: FinishReason{Reason: "stop"},
+		Usage:        Usage{InputTokens: 10, OutputTokens: 5, TotalTokens: 15},
+	}
+	adapter := &sequenceAdapter{name: "test", responses: []*Response{toolResponse, textResponse}}
+	client := NewClient(WithProvider("test", adapter))
+
+	callCount := 0
+	weatherTool := Tool{
+		Name:        "get_weather",
+		Description: "Get weather",
+		Parameters:  map[string]interface{}{"type": "object"},
+		Execute: func(args json.RawMessage) (interface{}, error) {
+			callCount++
+			return "72F and sunny", nil
+		},
+	}
+
+	sr, err := StreamGenerate(context.Background(), GenerateOptions{
+		Model:         "test-model",
+		Prompt:        "What's the weather in SF?",
+		Tools:         []Tool{weatherTool},
+		MaxToolRounds: 3,
+		Provider:      "test",
+		Client:        client,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var finishes int
+	for event := range sr.Events() {
+		if event.Type == StreamFinish {
+			finishes++
+		}
+	}
+	if callCount != 1 {
+		t.Errorf("expected tool to be called once, got %d", callCount)
+	}
+	if finishes != 2 {
+		t.Errorf("expected a StreamFinish per round (2), got %d", finishes)
+	}
+	if sr.Response().Text() != "It's 72F in SF" {
+		t.Errorf("expected final text %q, got %q", "It's 72F in SF", sr.Response().Text())
+	}
+}
+
+func TestStreamGenerateRoundBoundaryAndCumulativeUsage(t *testing.T) {
+	toolResponse := &Response{
+		Message: Message{
+			Role:    RoleAssistant,
+			Content: []ContentPart{ToolCallPart("call_1", "get_weather", json.RawMessage(`{"city":"SF"}`))},
+		},
+		FinishReason: FinishReason{Reason: "tool_calls"},
+		Usage:        Usage{InputTokens: 10, OutputTokens: 5, TotalTokens: 15},
+	}
+	textResponse := &Response{
+		Message:      Message{Role: RoleAssistant, Content: []ContentPart{TextPart("It's 72F in SF")}},
+		FinishReason: FinishReason{Reason: "stop"},
+		Usage:        Usage{InputTokens: 20, OutputTokens: 8, TotalTokens: 28},
+	}
+	adapter := &sequenceAdapter{name: "test", responses: []*Response{toolResponse, textResponse}}
+	client := NewClient(WithProvider("test", adapter))
+
+	weatherTool := Tool{
+		Name:        "get_weather",
+		Description: "Get weather",
+		Parameters:  map[string]interface{}{"type": "object"},
+		Execute: func(args json.RawMessage) (interface{}, error) {
+			return "72F and sunny", nil
+		},
+	}
+
+	sr, err := StreamGenerate(context.Background(), GenerateOptions{
+		Model:         "test-model",
+		Prompt:        "What's the weather in SF?",
+		Tools:         []Tool{weatherTool},
+		MaxToolRounds: 3,
+		Provider:      "test",
+		Client:        client,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var boundaries int
+	var finishEvents []StreamEvent
+	for event := range sr.Events() {
+		if event.Type == RoundBoundary {
+			boundaries++
+			if event.Round != 0 {
+				t.Errorf("expected the boundary after round 0, got round %d", event.Round)
+			}
+		}
+		if event.Type == StreamFinish {
+			finishEvents = append(finishEvents, event)
+		}
+	}
+
+	if boundaries != 1 {
+		t.Errorf("expected exactly 1 RoundBoundary between the two rounds, got %d", boundaries)
+	}
+	if len(finishEvents) != 2 {
+		t.Fatalf("expected 2 StreamFinish events, got %d", len(finishEvents))
+	}
+	if finishEvents[0].Round != 0 || finishEvents[1].Round != 1 {
+		t.Errorf("expected StreamFinish events tagged rounds 0 and 1, got %d and %d", finishEvents[0].Round, finishEvents[1].Round)
+	}
+	if finishEvents[0].Usage.TotalTokens != 15 {
+		t.Errorf("expected the first round's StreamFinish to carry its own usage (15), got %d", finishEvents[0].Usage.TotalTokens)
+	}
+	wantCumulative := 15 + 28
+	if finishEvents[1].Usage.TotalTokens != wantCumulative {
+		t.Errorf("expected the final StreamFinish to carry cumulative usage (%d), got %d", wantCumulative, finishEvents[1].Usage.TotalTokens)
+	}
+	if sr.TotalUsage().TotalTokens != wantCumulative {
+		t.Errorf("expected TotalUsage() to report %d, got %d", wantCumulative, sr.TotalUsage().TotalTokens)
+	}
+}
+
+func TestExecuteToolsConcurrentlyBoundsWorkerPool(t *testing.T) {
+	const numCalls = 10
+	var mu sync.Mutex
+	var active, maxActive int
+
+	tool := Tool{
+		Name: "slow",
+		Execute: func(args json.RawMessage) (interface{}, error) {
+			mu.Lock()
+			active++
+			if active > maxActive {
+				maxActive = active
+			}
+			mu.Unlock()
+
+			<-time.After(5 * time.Millisecond)
+
+			mu.Lock()
+			active--
+			mu.Unlock()
+			return "done", nil
+		},
+	}
+
+	calls := make([]ToolCall, numCalls)
+	for i := range calls {
+		calls[i] = ToolCall{ID: fmt.Sprintf("call_%d", i), Name: "slow"}
+	}
+
+	results := executeToolsConcurrently(context.Background(), map[string]Tool{"slow": tool}, calls, 2, 0)
+	if len(results) != numCalls {
+		t.Fatalf("expected %d results, got %d", numCalls, len(results))
+	}
+	if maxActive > 2 {
+		t.Errorf("expected at most 2 concurrent executions, observed %d", maxActive)
+	}
+}
+
+func TestExecuteToolsConcurrentlyCancelsOnParentContext(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+	tool := Tool{
+		Name: "slow",
+		ExecuteCtx: func(ctx context.Context, args json.RawMessage) (interface{}, error) {
+			close(started)
+			<-ctx.Done()
+			// Stay outstanding until the results are in, so the call is
+			// still running when executeToolsConcurrently sees ctx done.
+			<-release
+			return nil, ctx.Err()
+		},
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	calls := []ToolCall{{ID: "call_1", Name: "slow"}}
+
+	resultCh := make(chan []ToolResult, 1)
+	go func() {
+		resultCh <- executeToolsConcurrently(ctx, map[string]Tool{"slow": tool}, calls, 1, 0)
+	}()
+
+	<-started
+	cancel()
+
+	results := <-resultCh
+	close(release)
+	if len(results) != 1 {
+		t.Fatalf("expected 1 result, got %d", len(results))
+	}
+	if !results[0].IsError || results[0].Content != "cancelled" {
+		t.Errorf("expected a cancelled result, got %+v", results[0])
+	}
+}
+
+func TestExecuteToolsConcurrentlyPerToolTimeout(t *testing.T) {
+	tool := Tool{
+		Name: "slow",
+		ExecuteCtx: func(ctx context.Context, args json.RawMessage) (interface{}, error) {
+			<-ctx.Done()
+			return nil, ctx.Err()
+		},
+	}
+
+	calls := []ToolCall{{ID: "call_1", Name: "slow"}}
+	results := executeToolsConcurrently(context.Background(), map[string]Tool{"slow": tool}, calls, 1, 5*time.Millisecond)
+
+	if len(results) != 1 {
+		t.Fatalf("expected 1 result, got %d", len(results))
+	}
+	if !results[0].IsError {
+		t.Errorf("expected the per-tool timeout to surface as an error result, got %+v", results[0])
+	}
+}
+
+func TestGenerateLoopDetectionStop(t *testing.T) {
+	toolResponse := &Response{
+		Message: Message{
+			Role:    RoleAssistant,
+			Content: []ContentPart{ToolCallPart("call_1", "get_weather", json.RawMessage(`{"city":"SF"}`))},
+		},
+		FinishReason: FinishReason{Reason: "tool_calls"},
+		Usage:        Usage{InputTokens: 10, OutputTokens: 5, TotalTokens: 15},
+	}
+	adapter := &sequenceAdapter{name: "test", responses: []*Response{toolResponse}}
+	client := NewClient(WithProvider("test", adapter))
+
+	weatherTool := Tool{
+		Name:        "get_weather",
+		Description: "Get weather",
+		Parameters:  map[string]interface{}{"type": "object"},
+		Execute: func(args json.RawMessage) (interface{}, error) {
+			return "72F and sunny", nil
+		},
+	}
+
+	result, err := Generate(context.Background(), GenerateOptions{
+		Model:               "test-model",
+		Prompt:              "What's the weather in SF?",
+		Tools:               []Tool{weatherTool},
+		MaxToolRounds:       5,
+		Provider:            "test",
+		Client:              client,
+		MaxRetries:          0,
+		LoopDetectionWindow: 2,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(result.Steps) != 2 {
+		t.Fatalf("expected the loop to stop after 2 rounds, got %d steps", len(result.Steps))
+	}
+	if result.FinishReason.Reason != "loop_detected" {
+		t.Errorf("expected FinishReason %q, got %q", "loop_detected", result.FinishReason.Reason)
+	}
+	if result.LoopPatternLength != 1 {
+		t.Errorf("expected a pattern length of 1, got %d", result.LoopPatternLength)
+	}
+}
+
+func TestGenerateLoopDetectionNudge(t *testing.T) {
+	toolResponse := &Response{
+		Message: Message{
+			Role:    RoleAssistant,
+			Content: []ContentPart{ToolCallPart("call_1", "get_weather", json.RawMessage(`{"city":"SF"}`))},
+		},
+		FinishReason: FinishReason{Reason: "tool_calls"},
+		Usage:        Usage{InputTokens: 10, OutputTokens: 5, TotalTokens: 15},
+	}
+	adapter := &sequenceAdapter{name: "test", responses: []*Response{toolResponse}}
+	client := NewClient(WithProvider("test", adapter))
+
+	weatherTool := Tool{
+		Name:        "get_weather",
+		Description: "Get weather",
+		Parameters:  map[string]interface{}{"type": "object"},
+		Execute: func(args json.RawMessage) (interface{}, error) {
+			return "72F and sunny", nil
+		},
+	}
+
+	result, err := Generate(context.Background(), GenerateOptions{
+		Model:               "test-model",
+		Prompt:              "What's the weather in SF?",
+		Tools:               []Tool{weatherTool},
+		MaxToolRounds:       3,
+		Provider:            "test",
+		Client:              client,
+		MaxRetries:          0,
+		LoopDetectionWindow: 2,
+		LoopDetectionMode:   LoopDetectionNudge,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(result.Steps) != 4 {
+		t.Fatalf("expected the nudge to let the loop run its full budget (4 rounds), got %d steps", len(result.Steps))
+	}
+	if result.FinishReason.Reason == "loop_detected" {
+		t.Error("expected LoopDetectionNudge not to set loop_detected as the final FinishReason")
+	}
+	if result.LoopPatternLength != 1 {
+		t.Errorf("expected a pattern length of 1, got %d", result.LoopPatternLength)
+	}
+}
+
 func TestStreamAccumulator(t *testing.T) {
 	acc := NewStreamAccumulator()
 
@@ -441,3 +772,32 @@ func TestStreamAccumulator(t *testing.T) {
 		t.Errorf("expected total_tokens 15, got %d", resp.Usage.TotalTokens)
 	}
 }
+
+func TestStreamAccumulatorReassemblesFragmentedToolCallArguments(t *testing.T) {
+	acc := NewStreamAccumulator()
+
+	events := []StreamEvent{
+		{Type: StreamStart},
+		{Type: ToolCallStart, ToolCall: &ToolCall{ID: "call_1", Name: "get_weather"}},
+		{Type: ToolCallDelta, ToolCall: &ToolCall{ID: "call_1"}, Delta: `{"city":`},
+		{Type: ToolCallDelta, ToolCall: &ToolCall{ID: "call_1"}, Delta: `"Denver"}`},
+		{Type: ToolCallEnd, ToolCall: &ToolCall{ID: "call_1"}},
+		{Type: StreamFinish, FinishReason: &FinishReason{Reason: "tool_calls"}},
+	}
+
+	for _, e := range events {
+		acc.Process(e)
+	}
+
+	resp := acc.Response()
+	calls := resp.Message.ToolCalls()
+	if len(calls) != 1 {
+		t.Fatalf("expected 1 assembled tool call, got %d", len(calls))
+	}
+	if calls[0].Name != "get_weather" {
+		t.Errorf("expected name get_weather, got %q", calls[0].Name)
+	}
+	if string(calls[0].Arguments) != `{"city":"Denver"}` {
+		t.Errorf("expected reassembled arguments, got %s", calls[0].Arguments)
+	}
+}
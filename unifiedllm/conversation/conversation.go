@@ -0,0 +1,121 @@
+// Package conversation models a chat as a DAG of unifiedllm.Message nodes,
+// each keyed by a stable ID with an optional parent ID, rather than a flat
+// slice. This supports "message branching": editing and resubmitting an
+// earlier turn creates a new branch alongside the original rather than
+// overwriting it.
+package conversation
+
+import (
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/martinemde/attractor/unifiedllm"
+)
+
+// Node is one message in the conversation DAG.
+type Node struct {
+	ID       string
+	ParentID string // "" marks a root node
+	Message  unifiedllm.Message
+	Usage    unifiedllm.Usage
+}
+
+// Conversation is a handle onto a branch of a Store-backed DAG. Append calls
+// extend the branch anchored at head; Fork returns a new handle anchored
+// elsewhere in the same DAG without disturbing this one.
+type Conversation struct {
+	store Store
+	head  string
+}
+
+// New creates a Conversation backed by store, with no head (the next Append
+// becomes a root node).
+func New(store Store) *Conversation {
+	return &Conversation{store: store}
+}
+
+// Fork returns a new Conversation sharing the same Store, anchored at
+// messageID. Appends through the fork branch off messageID; the receiver's
+// own head is untouched, so the previous branch remains intact and
+// reachable via Path.
+func (c *Conversation) Fork(messageID string) *Conversation {
+	return &Conversation{store: c.store, head: messageID}
+}
+
+// Head returns the ID of the most recently appended node on this branch, or
+// "" if nothing has been appended yet.
+func (c *Conversation) Head() string {
+	return c.head
+}
+
+// Append adds msg as a child of the current head and advances the head to
+// the new node. usage is recorded against the node so it can be aggregated
+// later via Usage; pass the zero Usage for messages that didn't consume
+// tokens directly (e.g. a user turn).
+func (c *Conversation) Append(msg unifiedllm.Message, usage unifiedllm.Usage) (string, error) {
+	id := uuid.New().String()
+	node := Node{ID: id, ParentID: c.head, Message: msg, Usage: usage}
+	if err := c.store.Put(node); err != nil {
+		return "", fmt.Errorf("conversation: append: %w", err)
+	}
+	c.head = id
+	return id, nil
+}
+
+// Path returns the messages from the DAG's root down to leafID, in request
+// order, suitable for assigning directly to Request.Messages. Because each
+// node has exactly one parent, a tool_call node and the tool_result node
+// appended as its child stay adjacent in the returned slice regardless of
+// any forking elsewhere in the DAG.
+func (c *Conversation) Path(leafID string) ([]unifiedllm.Message, error) {
+	var nodes []Node
+	for id := leafID; id != ""; {
+		node, ok, err := c.store.Get(id)
+		if err != nil {
+			return nil, fmt.Errorf("conversation: path: %w", err)
+		}
+		if !ok {
+			return nil, fmt.Errorf("conversation: path: node %q not found", id)
+		}
+		nodes = append(nodes, node)
+		id = node.ParentID
+	}
+
+	messages := make([]unifiedllm.Message, len(nodes))
+	for i, node := range nodes {
+		messages[len(nodes)-1-i] = node.Message
+	}
+	return messages, nil
+}
+
+// Children returns the messages appended directly as children of id ("" for
+// the DAG's roots).
+func (c *Conversation) Children(id string) ([]unifiedllm.Message, error) {
+	nodes, err := c.store.ChildrenOf(id)
+	if err != nil {
+		return nil, fmt.Errorf("conversation: children: %w", err)
+	}
+	messages := make([]unifiedllm.Message, len(nodes))
+	for i, node := range nodes {
+		messages[i] = node.Message
+	}
+	return messages, nil
+}
+
+// UsageAlong sums the recorded Usage of every node from the DAG's root down
+// to leafID, giving the total token cost of that branch.
+func (c *Conversation) UsageAlong(leafID string) (unifiedllm.Usage, error) {
+	var total unifiedllm.Usage
+	for id := leafID; id != ""; {
+		node, ok, err := c.store.Get(id)
+		if err != nil {
+			return unifiedllm.Usage{}, fmt.Errorf("conversation: usage: %w", err)
+		}
+		if !ok {
+			return unifiedllm.Usage{}, fmt.Errorf("conversation: usage: node %q not found", id)
+		}
+		total = total.Add(node.Usage)
+		id = node.ParentID
+	}
+	return total, nil
+}
@@ -0,0 +1,130 @@
+package conversation
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/martinemde/attractor/unifiedllm"
+)
+
+func TestConversationPathFollowsParentChain(t *testing.T) {
+	conv := New(NewMemoryStore())
+
+	systemID, err := conv.Append(unifiedllm.SystemMessage("be concise"), unifiedllm.Usage{})
+	if err != nil {
+		t.Fatalf("append system: %v", err)
+	}
+	userID, err := conv.Append(unifiedllm.UserMessage("hi"), unifiedllm.Usage{})
+	if err != nil {
+		t.Fatalf("append user: %v", err)
+	}
+	_, err = conv.Append(unifiedllm.AssistantMessage("hello"), unifiedllm.Usage{TotalTokens: 10})
+	if err != nil {
+		t.Fatalf("append assistant: %v", err)
+	}
+
+	path, err := conv.Path(conv.Head())
+	if err != nil {
+		t.Fatalf("path: %v", err)
+	}
+	if len(path) != 3 {
+		t.Fatalf("expected 3 messages, got %d", len(path))
+	}
+	if path[0].TextContent() != "be concise" || path[1].TextContent() != "hi" || path[2].TextContent() != "hello" {
+		t.Fatalf("unexpected path order: %+v", path)
+	}
+	_ = systemID
+	_ = userID
+}
+
+func TestConversationForkPreservesOriginalBranch(t *testing.T) {
+	conv := New(NewMemoryStore())
+
+	conv.Append(unifiedllm.SystemMessage("be concise"), unifiedllm.Usage{})
+	userID, _ := conv.Append(unifiedllm.UserMessage("what's 2+2?"), unifiedllm.Usage{})
+	originalLeaf, _ := conv.Append(unifiedllm.AssistantMessage("4"), unifiedllm.Usage{})
+
+	edited := conv.Fork(userID)
+	editedLeaf, err := edited.Append(unifiedllm.UserMessage("what's 3+3?"), unifiedllm.Usage{})
+	if err != nil {
+		t.Fatalf("append on fork: %v", err)
+	}
+
+	children, err := conv.Children(userID)
+	if err != nil {
+		t.Fatalf("children: %v", err)
+	}
+	if len(children) != 2 {
+		t.Fatalf("expected 2 children of the forked message, got %d", len(children))
+	}
+
+	originalPath, err := conv.Path(originalLeaf)
+	if err != nil {
+		t.Fatalf("original path: %v", err)
+	}
+	if originalPath[len(originalPath)-1].TextContent() != "4" {
+		t.Fatalf("expected original branch to still end in \"4\", got %+v", originalPath)
+	}
+
+	editedPath, err := edited.Path(editedLeaf)
+	if err != nil {
+		t.Fatalf("edited path: %v", err)
+	}
+	if editedPath[len(editedPath)-1].TextContent() != "what's 3+3?" {
+		t.Fatalf("expected edited branch to end in the new question, got %+v", editedPath)
+	}
+	if editedPath[1].TextContent() != "what's 2+2?" {
+		t.Fatalf("expected edited branch to share the original prefix, got %+v", editedPath)
+	}
+}
+
+func TestConversationKeepsToolCallAndResultAdjacentAcrossForks(t *testing.T) {
+	conv := New(NewMemoryStore())
+
+	conv.Append(unifiedllm.UserMessage("what's the weather?"), unifiedllm.Usage{})
+	toolCallID, _ := conv.Append(unifiedllm.Message{
+		Role:    unifiedllm.RoleAssistant,
+		Content: []unifiedllm.ContentPart{unifiedllm.ToolCallPart("call_1", "get_weather", json.RawMessage(`{}`))},
+	}, unifiedllm.Usage{})
+	resultLeaf, _ := conv.Append(unifiedllm.ToolResultMessage("call_1", "sunny", false), unifiedllm.Usage{})
+
+	// Fork off somewhere else entirely; the tool_call -> tool_result pair
+	// must stay adjacent on the original branch regardless.
+	other := conv.Fork(toolCallID)
+	other.Append(unifiedllm.UserMessage("unrelated branch"), unifiedllm.Usage{})
+
+	path, err := conv.Path(resultLeaf)
+	if err != nil {
+		t.Fatalf("path: %v", err)
+	}
+	last, secondLast := path[len(path)-1], path[len(path)-2]
+	if len(secondLast.ToolCalls()) != 1 {
+		t.Fatalf("expected the tool_call message immediately before the tool_result, got %+v", secondLast)
+	}
+	if last.ToolCallID != "call_1" {
+		t.Fatalf("expected the tool_result to reference call_1, got %+v", last)
+	}
+}
+
+func TestConversationUsageAlongAggregatesPerBranch(t *testing.T) {
+	conv := New(NewMemoryStore())
+
+	conv.Append(unifiedllm.UserMessage("hi"), unifiedllm.Usage{})
+	conv.Append(unifiedllm.AssistantMessage("hello"), unifiedllm.Usage{TotalTokens: 10})
+	leaf, _ := conv.Append(unifiedllm.UserMessage("thanks"), unifiedllm.Usage{TotalTokens: 2})
+
+	total, err := conv.UsageAlong(leaf)
+	if err != nil {
+		t.Fatalf("usage along: %v", err)
+	}
+	if total.TotalTokens != 12 {
+		t.Errorf("expected total tokens 12, got %d", total.TotalTokens)
+	}
+}
+
+func TestConversationPathErrorsOnUnknownLeaf(t *testing.T) {
+	conv := New(NewMemoryStore())
+	if _, err := conv.Path("does-not-exist"); err == nil {
+		t.Fatal("expected an error for an unknown leaf ID")
+	}
+}
@@ -0,0 +1,118 @@
+package conversation
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+)
+
+// SQLiteStore is a Store backed by a SQLite database via database/sql,
+// persisting each Node as a JSON-encoded message/usage pair keyed by ID.
+// Callers open db with whichever SQLite driver they've registered (e.g.
+// modernc.org/sqlite) and own its lifecycle.
+type SQLiteStore struct {
+	db *sql.DB
+}
+
+// NewSQLiteStore migrates the conversation_nodes table against db and
+// returns a Store backed by it.
+func NewSQLiteStore(db *sql.DB) (*SQLiteStore, error) {
+	s := &SQLiteStore{db: db}
+	if err := s.migrate(); err != nil {
+		return nil, fmt.Errorf("conversation: sqlite store: %w", err)
+	}
+	return s, nil
+}
+
+func (s *SQLiteStore) migrate() error {
+	_, err := s.db.Exec(`
+		CREATE TABLE IF NOT EXISTS conversation_nodes (
+			id        TEXT PRIMARY KEY,
+			parent_id TEXT NOT NULL,
+			message   TEXT NOT NULL,
+			usage     TEXT NOT NULL
+		);
+		CREATE INDEX IF NOT EXISTS idx_conversation_nodes_parent ON conversation_nodes(parent_id);
+	`)
+	return err
+}
+
+// Put implements Store.
+func (s *SQLiteStore) Put(node Node) error {
+	if node.ID == "" {
+		return fmt.Errorf("conversation: sqlite store: node ID is required")
+	}
+	msgJSON, err := json.Marshal(node.Message)
+	if err != nil {
+		return fmt.Errorf("conversation: sqlite store: encode message: %w", err)
+	}
+	usageJSON, err := json.Marshal(node.Usage)
+	if err != nil {
+		return fmt.Errorf("conversation: sqlite store: encode usage: %w", err)
+	}
+
+	_, err = s.db.Exec(
+		`INSERT INTO conversation_nodes (id, parent_id, message, usage) VALUES (?, ?, ?, ?)
+		 ON CONFLICT(id) DO UPDATE SET parent_id = excluded.parent_id, message = excluded.message, usage = excluded.usage`,
+		node.ID, node.ParentID, string(msgJSON), string(usageJSON),
+	)
+	if err != nil {
+		return fmt.Errorf("conversation: sqlite store: put: %w", err)
+	}
+	return nil
+}
+
+// Get implements Store.
+func (s *SQLiteStore) Get(id string) (Node, bool, error) {
+	row := s.db.QueryRow(`SELECT id, parent_id, message, usage FROM conversation_nodes WHERE id = ?`, id)
+	node, err := scanNode(row)
+	if err == sql.ErrNoRows {
+		return Node{}, false, nil
+	}
+	if err != nil {
+		return Node{}, false, fmt.Errorf("conversation: sqlite store: get: %w", err)
+	}
+	return node, true, nil
+}
+
+// ChildrenOf implements Store.
+func (s *SQLiteStore) ChildrenOf(parentID string) ([]Node, error) {
+	rows, err := s.db.Query(`SELECT id, parent_id, message, usage FROM conversation_nodes WHERE parent_id = ? ORDER BY rowid`, parentID)
+	if err != nil {
+		return nil, fmt.Errorf("conversation: sqlite store: children of: %w", err)
+	}
+	defer rows.Close()
+
+	var nodes []Node
+	for rows.Next() {
+		node, err := scanNode(rows)
+		if err != nil {
+			return nil, fmt.Errorf("conversation: sqlite store: children of: %w", err)
+		}
+		nodes = append(nodes, node)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("conversation: sqlite store: children of: %w", err)
+	}
+	return nodes, nil
+}
+
+// rowScanner is satisfied by both *sql.Row and *sql.Rows.
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanNode(row rowScanner) (Node, error) {
+	var node Node
+	var msgJSON, usageJSON string
+	if err := row.Scan(&node.ID, &node.ParentID, &msgJSON, &usageJSON); err != nil {
+		return Node{}, err
+	}
+	if err := json.Unmarshal([]byte(msgJSON), &node.Message); err != nil {
+		return Node{}, fmt.Errorf("decode message: %w", err)
+	}
+	if err := json.Unmarshal([]byte(usageJSON), &node.Usage); err != nil {
+		return Node{}, fmt.Errorf("decode usage: %w", err)
+	}
+	return node, nil
+}
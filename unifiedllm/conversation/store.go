@@ -0,0 +1,77 @@
+package conversation
+
+import (
+	"fmt"
+	"sync"
+)
+
+// Store persists conversation Nodes for later retrieval by Conversation.
+type Store interface {
+	// Put inserts or replaces node.
+	Put(node Node) error
+	// Get returns the node with the given id, or ok=false if none exists.
+	Get(id string) (Node, bool, error)
+	// ChildrenOf returns every node whose ParentID equals parentID ("" for
+	// roots), in the order they were Put.
+	ChildrenOf(parentID string) ([]Node, error)
+}
+
+// MemoryStore is an in-memory Store backed by a mutex-guarded map. It does
+// not persist across process restarts.
+type MemoryStore struct {
+	mu       sync.RWMutex
+	nodes    map[string]Node
+	children map[string][]string // parentID -> ordered child IDs
+}
+
+// NewMemoryStore creates an empty MemoryStore.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		nodes:    make(map[string]Node),
+		children: make(map[string][]string),
+	}
+}
+
+// Put implements Store.
+func (s *MemoryStore) Put(node Node) error {
+	if node.ID == "" {
+		return fmt.Errorf("conversation: memory store: node ID is required")
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	old, exists := s.nodes[node.ID]
+	if exists && old.ParentID != node.ParentID {
+		siblings := s.children[old.ParentID]
+		for i, id := range siblings {
+			if id == node.ID {
+				s.children[old.ParentID] = append(siblings[:i:i], siblings[i+1:]...)
+				break
+			}
+		}
+	}
+	if !exists || old.ParentID != node.ParentID {
+		s.children[node.ParentID] = append(s.children[node.ParentID], node.ID)
+	}
+	s.nodes[node.ID] = node
+	return nil
+}
+
+// Get implements Store.
+func (s *MemoryStore) Get(id string) (Node, bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	node, ok := s.nodes[id]
+	return node, ok, nil
+}
+
+// ChildrenOf implements Store.
+func (s *MemoryStore) ChildrenOf(parentID string) ([]Node, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	ids := s.children[parentID]
+	nodes := make([]Node, len(ids))
+	for i, id := range ids {
+		nodes[i] = s.nodes[id]
+	}
+	return nodes, nil
+}
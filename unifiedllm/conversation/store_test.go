@@ -0,0 +1,59 @@
+package conversation
+
+import "testing"
+
+func TestMemoryStorePutGet(t *testing.T) {
+	store := NewMemoryStore()
+	if err := store.Put(Node{ID: "a"}); err != nil {
+		t.Fatalf("put: %v", err)
+	}
+
+	node, ok, err := store.Get("a")
+	if err != nil || !ok {
+		t.Fatalf("expected node a to be found, got ok=%v err=%v", ok, err)
+	}
+	if node.ID != "a" {
+		t.Errorf("expected ID a, got %q", node.ID)
+	}
+
+	if _, ok, _ := store.Get("missing"); ok {
+		t.Error("expected missing node to not be found")
+	}
+}
+
+func TestMemoryStorePutRequiresID(t *testing.T) {
+	store := NewMemoryStore()
+	if err := store.Put(Node{}); err == nil {
+		t.Fatal("expected an error when putting a node without an ID")
+	}
+}
+
+func TestMemoryStoreChildrenOfPreservesInsertionOrder(t *testing.T) {
+	store := NewMemoryStore()
+	store.Put(Node{ID: "root"})
+	store.Put(Node{ID: "first", ParentID: "root"})
+	store.Put(Node{ID: "second", ParentID: "root"})
+
+	children, err := store.ChildrenOf("root")
+	if err != nil {
+		t.Fatalf("children of: %v", err)
+	}
+	if len(children) != 2 || children[0].ID != "first" || children[1].ID != "second" {
+		t.Fatalf("unexpected children order: %+v", children)
+	}
+}
+
+func TestMemoryStorePutReplacesExistingNodeWithoutDuplicatingChild(t *testing.T) {
+	store := NewMemoryStore()
+	store.Put(Node{ID: "root"})
+	store.Put(Node{ID: "child", ParentID: "root"})
+	store.Put(Node{ID: "child", ParentID: "root"}) // re-put, e.g. after an edit
+
+	children, err := store.ChildrenOf("root")
+	if err != nil {
+		t.Fatalf("children of: %v", err)
+	}
+	if len(children) != 1 {
+		t.Fatalf("expected re-putting a node to not duplicate it as a child, got %+v", children)
+	}
+}
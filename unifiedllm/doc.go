@@ -32,11 +32,33 @@
 //	})
 //	fmt.Println(resp.Text())
 //
+// # Native Provider Adapters
+//
+// unifiedllm/providers/openai, unifiedllm/providers/anthropic, and
+// unifiedllm/providers/google implement ProviderAdapter by calling each
+// provider's HTTP API directly -- translating Request.Messages into the
+// provider's native message shape (tool_calls for OpenAI, tool_use/
+// tool_result blocks for Anthropic, functionCall/functionResponse parts for
+// Gemini) and returning real token usage from the response body. These
+// packages live outside unifiedllm itself to avoid an import cycle (they
+// import unifiedllm's types; unifiedllm cannot import them back), so wire
+// one in explicitly instead of via NewClientFromEnv:
+//
+//	client := unifiedllm.NewClient(
+//	    unifiedllm.WithProvider("openai", openai.NewAdapter(os.Getenv("OPENAI_API_KEY"))),
+//	    unifiedllm.WithProvider("anthropic", anthropic.NewAdapter(os.Getenv("ANTHROPIC_API_KEY"))),
+//	)
+//
+// Prefer these over GollmAdapter for OpenAI, Anthropic, and Gemini; they
+// don't estimate token usage or scrape tool calls out of text the way
+// GollmAdapter's gollm-backed translation does.
+//
 // # GollmAdapter
 //
 // The GollmAdapter wraps gollm.LLM to implement the ProviderAdapter interface.
 // It translates between the unified spec types and gollm's native API, supporting
-// OpenAI, Anthropic, and other providers that gollm supports.
+// OpenAI, Anthropic, and other providers that gollm supports. It remains useful
+// as a fallback for providers without a native adapter above.
 //
 // # Tool Calling
 //
@@ -62,5 +84,14 @@
 //
 //	info := unifiedllm.GetModelInfo("claude-opus-4-6")
 //	models := unifiedllm.ListModels("anthropic")
-//	latest := unifiedllm.GetLatestModel("openai", "reasoning")
+//	latest := unifiedllm.GetLatestModel("openai", unifiedllm.Capabilities{Reasoning: true})
+//
+// Ops teams can pin internal proxy names (e.g. an Azure deployment alias)
+// as first-class models without forking the repo, either one at a time via
+// RegisterModel or in bulk via LoadModelsFromFile/LoadModelsFromDir. Calling
+// LoadModelsFromEnv loads every config file in the directory named by
+// ATTRACTOR_MODELS_DIR, if set. For a catalog that needs to change while the
+// process is running, DefaultCatalog (and the file/HTTP-backed FileCatalog
+// and HTTPCatalog wrapping it) implement the pluggable ModelCatalog
+// interface.
 package unifiedllm
@@ -1,6 +1,10 @@
 package unifiedllm
 
-import "fmt"
+import (
+	"context"
+	"errors"
+	"fmt"
+)
 
 // SDKError is the base error type for all unified LLM errors.
 type SDKError struct {
@@ -99,11 +103,17 @@ func ErrorFromStatusCode(statusCode int, message, provider, errorCode string, ra
 	}
 }
 
-// IsRetryable returns true if the error is safe to retry.
+// IsRetryable returns true if the error is safe to retry. context.Canceled
+// and context.DeadlineExceeded are always terminal: the caller has already
+// given up, so retrying would just burn another attempt against a context
+// that's going to reject it too.
 func IsRetryable(err error) bool {
 	if err == nil {
 		return false
 	}
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
 	switch e := err.(type) {
 	case *ProviderError:
 		return e.Retryable
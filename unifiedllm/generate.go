@@ -3,33 +3,93 @@ package unifiedllm
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"strings"
 	"sync"
+	"time"
 )
 
+// defaultMaxConcurrentTools bounds the worker pool used to dispatch tool
+// calls within a single step when GenerateOptions.MaxConcurrentTools is unset.
+const defaultMaxConcurrentTools = 8
+
 // GenerateOptions configures a high-level generate() call.
 type GenerateOptions struct {
-	Model           string
-	Prompt          string     // simple text prompt (mutually exclusive with Messages)
-	Messages        []Message  // full conversation (mutually exclusive with Prompt)
-	System          string
-	Tools           []Tool
-	ToolChoice      *ToolChoice
-	MaxToolRounds   int // default 1
-	StopWhen        StopCondition
-	ResponseFormat  *ResponseFormat
-	Temperature     *float64
-	TopP            *float64
-	MaxTokens       *int
-	StopSequences   []string
-	ReasoningEffort string
-	Provider        string
-	ProviderOptions map[string]interface{}
-	MaxRetries      int // default 2
-	Timeout         *TimeoutConfig
-	Client          *Client
+	Model              string
+	Prompt             string    // simple text prompt (mutually exclusive with Messages)
+	Messages           []Message // full conversation (mutually exclusive with Prompt)
+	System             string
+	Tools              []Tool
+	ToolChoice         *ToolChoice
+	MaxToolRounds      int // default 1
+	StopWhen           StopCondition
+	StopConditions     []StopCondition // evaluated in addition to StopWhen; any match stops the loop
+	MaxConcurrentTools int             // bounds concurrent Tool.Execute dispatch per step; default 8
+	ResponseFormat     *ResponseFormat
+	Temperature        *float64
+	TopP               *float64
+	MaxTokens          *int
+	StopSequences      []string
+	ReasoningEffort    string
+	Provider           string
+	ProviderOptions    map[string]interface{}
+	MaxRetries         int // default 2
+	Timeout            *TimeoutConfig
+	Client             *Client
+
+	// ApproveToolCall, if set, is invoked by Generate for every proposed
+	// tool call before executeToolsConcurrently runs it, letting an
+	// interactive frontend prompt the user before a side-effectful tool
+	// (shell, file edit, ...) actually executes. A nil ApproveToolCall
+	// preserves Generate's historical behavior of running every
+	// Execute-bearing tool call unconditionally. See ToolApprovalDecision.
+	ApproveToolCall func(ctx context.Context, call ToolCall) (ToolApprovalDecision, error)
+
+	// LoopDetectionWindow, if > 0, has Generate check after every round
+	// whether the last LoopDetectionWindow tool calls across all steps so
+	// far (in chronological order) form a repeating pattern of length 1-3
+	// (see DetectToolCallLoop). 0 disables loop detection, Generate's
+	// historical behavior.
+	LoopDetectionWindow int
+
+	// LoopDetectionMode controls what happens once LoopDetectionWindow
+	// detects a repeating pattern. The zero value, LoopDetectionStop, ends
+	// the round loop immediately.
+	LoopDetectionMode LoopDetectionMode
+
+	// RepairAttempts is how many times GenerateObject re-invokes Generate
+	// after its output fails to parse as JSON matching the schema or fails
+	// Validator, feeding the bad output and the specific parse/validation
+	// error back as a repair turn. 0 (the default) disables repair: a
+	// single failure is reported immediately, as before.
+	RepairAttempts int
+
+	// Validator, if set, is run by GenerateObject on the parsed output
+	// after a successful json.Unmarshal; an error triggers the same
+	// RepairAttempts retry loop as a parse failure.
+	Validator func(interface{}) error
 }
 
+// LoopDetectionMode controls Generate's response to LoopDetectionWindow
+// detecting a repeating tool-call pattern.
+type LoopDetectionMode int
+
+const (
+	// LoopDetectionStop ends the round loop immediately, with the final
+	// step's FinishReason set to {Reason: "loop_detected"}.
+	LoopDetectionStop LoopDetectionMode = iota
+	// LoopDetectionNudge doesn't stop the loop; instead it injects a
+	// synthetic user message nudging the model to reconsider into the
+	// next round's conversation, giving the model one more chance to
+	// break out of the pattern on its own.
+	LoopDetectionNudge
+)
+
+// loopDetectionNudge is the synthetic message Generate appends when
+// LoopDetectionMode is LoopDetectionNudge and a loop is detected.
+const loopDetectionNudge = "You appear to be repeating the same tool calls; try a different approach or stop."
+
 // Generate is the high-level blocking generation function.
 // It wraps Client.Complete with tool execution loops, automatic retries,
 // and prompt standardization.
@@ -50,6 +110,12 @@ func Generate(ctx context.Context, opts GenerateOptions) (*GenerateResult, error
 		opts.MaxToolRounds = 1
 	}
 
+	if opts.Timeout != nil && opts.Timeout.Total > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, opts.Timeout.Total)
+		defer cancel()
+	}
+
 	retryPolicy := DefaultRetryPolicy()
 	if opts.MaxRetries > 0 {
 		retryPolicy.MaxRetries = opts.MaxRetries
@@ -83,9 +149,20 @@ func Generate(ctx context.Context, opts GenerateOptions) (*GenerateResult, error
 		}
 	}
 
+	var perToolTimeout time.Duration
+	if opts.Timeout != nil {
+		perToolTimeout = opts.Timeout.PerTool
+	}
+	for _, t := range opts.Tools {
+		if t.ExecuteCtx != nil {
+			hasActiveTools = true // a tool with only ExecuteCtx is active too
+		}
+	}
+
 	// Tool execution loop.
 	var steps []StepResult
 	var totalUsage Usage
+	var loopPatternLength int
 	conversation := make([]Message, len(messages))
 	copy(conversation, messages)
 
@@ -106,8 +183,15 @@ func Generate(ctx context.Context, opts GenerateOptions) (*GenerateResult, error
 			ProviderOptions: opts.ProviderOptions,
 		}
 
+		stepCtx := ctx
+		if opts.Timeout != nil && opts.Timeout.PerStep > 0 {
+			var cancel context.CancelFunc
+			stepCtx, cancel = context.WithTimeout(ctx, opts.Timeout.PerStep)
+			defer cancel()
+		}
+
 		// Call with retry.
-		resp, err := Retry(ctx, retryPolicy, func(ctx context.Context) (*Response, error) {
+		resp, err := Retry(stepCtx, retryPolicy, func(ctx context.Context) (*Response, error) {
 			return client.Complete(ctx, req)
 		})
 		if err != nil {
@@ -119,8 +203,19 @@ func Generate(ctx context.Context, opts GenerateOptions) (*GenerateResult, error
 
 		// Execute active tools if present.
 		var toolResults []ToolResult
+		abortedRun := false
 		if len(toolCalls) > 0 && resp.FinishReason.Reason == "tool_calls" && hasActiveTools {
-			toolResults = executeToolsConcurrently(toolMap, toolCalls)
+			execCalls := toolCalls
+			if opts.ApproveToolCall != nil {
+				var decided map[string]ToolResult
+				execCalls, decided, abortedRun, err = gateToolCalls(ctx, opts.ApproveToolCall, toolCalls)
+				if err != nil {
+					return nil, err
+				}
+				toolResults = mergeToolResults(toolCalls, executeToolsConcurrently(stepCtx, toolMap, execCalls, opts.MaxConcurrentTools, perToolTimeout), decided)
+			} else {
+				toolResults = executeToolsConcurrently(stepCtx, toolMap, execCalls, opts.MaxConcurrentTools, perToolTimeout)
+			}
 		}
 
 		step := StepResult{
@@ -137,6 +232,9 @@ func Generate(ctx context.Context, opts GenerateOptions) (*GenerateResult, error
 		totalUsage = totalUsage.Add(resp.Usage)
 
 		// Check stop conditions.
+		if abortedRun {
+			break // opts.ApproveToolCall returned ApprovalActionAbortRun.
+		}
 		if len(toolCalls) == 0 || resp.FinishReason.Reason != "tool_calls" {
 			break // Natural completion.
 		}
@@ -146,10 +244,24 @@ func Generate(ctx context.Context, opts GenerateOptions) (*GenerateResult, error
 		if round >= opts.MaxToolRounds {
 			break // Budget exhausted.
 		}
-		if opts.StopWhen != nil && opts.StopWhen(steps) {
+		if evaluateStopConditions(opts, steps) {
 			break // Custom stop condition.
 		}
 
+		nudge := false
+		if opts.LoopDetectionWindow > 0 {
+			sigs := toolCallSignaturesFromSteps(steps)
+			if detected, patternLen := DetectToolCallLoop(sigs, opts.LoopDetectionWindow); detected {
+				loopPatternLength = patternLen
+				if opts.LoopDetectionMode == LoopDetectionNudge {
+					nudge = true
+				} else {
+					steps[len(steps)-1].FinishReason = FinishReason{Reason: "loop_detected"}
+					break // Loop detected.
+				}
+			}
+		}
+
 		// Append assistant message with tool calls and tool results.
 		conversation = append(conversation, resp.Message)
 		for _, result := range toolResults {
@@ -160,70 +272,151 @@ func Generate(ctx context.Context, opts GenerateOptions) (*GenerateResult, error
 				result.IsError,
 			))
 		}
+		if nudge {
+			conversation = append(conversation, UserMessage(loopDetectionNudge))
+		}
 	}
 
 	lastStep := steps[len(steps)-1]
 	return &GenerateResult{
-		Text:         lastStep.Text,
-		Reasoning:    lastStep.Reasoning,
-		ToolCalls:    lastStep.ToolCalls,
-		ToolResults:  lastStep.ToolResults,
-		FinishReason: lastStep.FinishReason,
-		Usage:        lastStep.Usage,
-		TotalUsage:   totalUsage,
-		Steps:        steps,
-		Response:     lastStep.Response,
+		Text:              lastStep.Text,
+		Reasoning:         lastStep.Reasoning,
+		ToolCalls:         lastStep.ToolCalls,
+		ToolResults:       lastStep.ToolResults,
+		FinishReason:      lastStep.FinishReason,
+		Usage:             lastStep.Usage,
+		LoopPatternLength: loopPatternLength,
+		TotalUsage:        totalUsage,
+		Steps:             steps,
+		Response:          lastStep.Response,
 	}, nil
 }
 
-// executeToolsConcurrently executes all tool calls in parallel.
-func executeToolsConcurrently(toolMap map[string]Tool, calls []ToolCall) []ToolResult {
+// evaluateStopConditions reports whether any of opts.StopWhen or
+// opts.StopConditions fire against the accumulated steps.
+func evaluateStopConditions(opts GenerateOptions, steps []StepResult) bool {
+	if opts.StopWhen != nil && opts.StopWhen(steps) {
+		return true
+	}
+	for _, cond := range opts.StopConditions {
+		if cond != nil && cond(steps) {
+			return true
+		}
+	}
+	return false
+}
+
+// executeToolsConcurrently executes all tool calls in parallel, bounded by a
+// worker pool of size maxConcurrent (defaultMaxConcurrentTools if <= 0).
+// Each call gets its own context derived from ctx: Tool.Timeout if set,
+// otherwise perToolDefault (GenerateOptions.Timeout.PerTool), otherwise ctx
+// unbounded. A Tool with ExecuteCtx set receives that context directly and
+// is expected to observe its cancellation/deadline; a Tool with only the
+// legacy Execute runs to completion regardless, since it has no way to be
+// told to stop.
+//
+// If ctx is done before every call finishes, executeToolsConcurrently
+// returns immediately rather than waiting for Execute calls that can't be
+// cancelled: any call still outstanding at that point is reported as
+// ToolResult{IsError: true, Content: "cancelled"}, and its goroutine (for a
+// legacy Execute) is left to finish in the background with its eventual
+// result discarded.
+func executeToolsConcurrently(ctx context.Context, toolMap map[string]Tool, calls []ToolCall, maxConcurrent int, perToolDefault time.Duration) []ToolResult {
+	if maxConcurrent <= 0 {
+		maxConcurrent = defaultMaxConcurrentTools
+	}
+
 	results := make([]ToolResult, len(calls))
+	written := make([]bool, len(calls))
+	var mu sync.Mutex
+	setResult := func(idx int, r ToolResult) {
+		mu.Lock()
+		defer mu.Unlock()
+		if written[idx] {
+			return
+		}
+		written[idx] = true
+		results[idx] = r
+	}
+
+	sem := make(chan struct{}, maxConcurrent)
 	var wg sync.WaitGroup
 
 	for i, call := range calls {
 		wg.Add(1)
+		sem <- struct{}{}
 		go func(idx int, tc ToolCall) {
 			defer wg.Done()
+			defer func() { <-sem }()
 
 			tool, ok := toolMap[tc.Name]
-			if !ok || tool.Execute == nil {
-				results[idx] = ToolResult{
+			if !ok || (tool.Execute == nil && tool.ExecuteCtx == nil) {
+				setResult(idx, ToolResult{
 					ToolCallID: tc.ID,
 					Content:    fmt.Sprintf("Unknown tool: %s", tc.Name),
 					IsError:    true,
-				}
+				})
 				return
 			}
 
-			output, err := tool.Execute(tc.Arguments)
+			var output interface{}
+			var err error
+			if tool.ExecuteCtx != nil {
+				callCtx := ctx
+				timeout := tool.Timeout
+				if timeout <= 0 {
+					timeout = perToolDefault
+				}
+				if timeout > 0 {
+					var cancel context.CancelFunc
+					callCtx, cancel = context.WithTimeout(callCtx, timeout)
+					defer cancel()
+				}
+				output, err = tool.ExecuteCtx(callCtx, tc.Arguments)
+			} else {
+				output, err = tool.Execute(tc.Arguments)
+			}
 			if err != nil {
-				results[idx] = ToolResult{
+				setResult(idx, ToolResult{
 					ToolCallID: tc.ID,
 					Content:    fmt.Sprintf("Tool execution error: %v", err),
 					IsError:    true,
-				}
+				})
 				return
 			}
 
-			results[idx] = ToolResult{
+			setResult(idx, ToolResult{
 				ToolCallID: tc.ID,
 				Content:    output,
 				IsError:    false,
-			}
+			})
 		}(i, call)
 	}
 
-	wg.Wait()
+	allDone := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(allDone)
+	}()
+
+	select {
+	case <-allDone:
+	case <-ctx.Done():
+		for i, call := range calls {
+			setResult(i, ToolResult{ToolCallID: call.ID, Content: "cancelled", IsError: true})
+		}
+	}
+
 	return results
 }
 
 // StreamResult wraps a streaming response with convenience accessors.
 type StreamResult struct {
-	events   <-chan StreamEvent
-	response *Response
-	mu       sync.Mutex
-	done     bool
+	events     <-chan StreamEvent
+	response   *Response
+	totalUsage Usage
+	mu         sync.Mutex
+	done       bool
 }
 
 // Events returns the channel of stream events.
@@ -231,15 +424,35 @@ func (sr *StreamResult) Events() <-chan StreamEvent {
 	return sr.events
 }
 
-// Response returns the accumulated response after the stream ends.
-// Returns nil if the stream has not finished yet.
+// Response returns the last round's accumulated response after that round
+// ends. Returns nil before the first round has finished. For a multi-round
+// tool-calling run, this updates after every round, not just the final one;
+// use TotalUsage for usage summed across all rounds.
 func (sr *StreamResult) Response() *Response {
 	sr.mu.Lock()
 	defer sr.mu.Unlock()
 	return sr.response
 }
 
-// StreamGenerate is the high-level streaming generation function.
+// TotalUsage returns token usage summed across every round run so far. It
+// grows as StreamGenerate's tool-calling loop progresses and is final once
+// Events() is drained.
+func (sr *StreamResult) TotalUsage() Usage {
+	sr.mu.Lock()
+	defer sr.mu.Unlock()
+	return sr.totalUsage
+}
+
+// StreamGenerate is the high-level streaming generation function. Like
+// Generate, it drives a multi-round tool-calling loop (respecting
+// MaxToolRounds, StopWhen/StopConditions, and ApproveToolCall), but forwards
+// every provider StreamEvent from every round onto the returned StreamResult
+// as it arrives rather than collecting a single final Response. Each event
+// is tagged with the Round it belongs to, and a synthetic RoundBoundary
+// event separates one round's events from the next so a consumer can group
+// deltas by round without inferring boundaries from StreamFinish. Only the
+// very last StreamFinish carries cumulative Usage across all rounds;
+// earlier rounds' StreamFinish events carry that round's own usage.
 func StreamGenerate(ctx context.Context, opts GenerateOptions) (*StreamResult, error) {
 	if opts.Prompt != "" && len(opts.Messages) > 0 {
 		return nil, &ConfigurationError{SDKError: SDKError{
@@ -252,6 +465,10 @@ func StreamGenerate(ctx context.Context, opts GenerateOptions) (*StreamResult, e
 		client = GetDefaultClient()
 	}
 
+	if opts.MaxToolRounds == 0 && len(opts.Tools) > 0 {
+		opts.MaxToolRounds = 1
+	}
+
 	messages := opts.Messages
 	if opts.Prompt != "" {
 		messages = []Message{UserMessage(opts.Prompt)}
@@ -261,48 +478,169 @@ func StreamGenerate(ctx context.Context, opts GenerateOptions) (*StreamResult, e
 	}
 
 	var toolDefs []ToolDefinition
+	toolMap := make(map[string]Tool)
+	hasActiveTools := false
 	for _, t := range opts.Tools {
 		toolDefs = append(toolDefs, ToolDefinition{
 			Name:        t.Name,
 			Description: t.Description,
 			Parameters:  t.Parameters,
 		})
+		toolMap[t.Name] = t
+		if t.Execute != nil || t.ExecuteCtx != nil {
+			hasActiveTools = true
+		}
 	}
 
-	req := Request{
-		Model:           opts.Model,
-		Messages:        messages,
-		Provider:        opts.Provider,
-		Tools:           opts.Tools,
-		ToolDefs:        toolDefs,
-		ToolChoice:      opts.ToolChoice,
-		ResponseFormat:  opts.ResponseFormat,
-		Temperature:     opts.Temperature,
-		TopP:            opts.TopP,
-		MaxTokens:       opts.MaxTokens,
-		StopSequences:   opts.StopSequences,
-		ReasoningEffort: opts.ReasoningEffort,
-		ProviderOptions: opts.ProviderOptions,
-	}
-
-	eventCh, err := client.Stream(ctx, req)
-	if err != nil {
-		return nil, err
-	}
-
-	// Wrap the event channel to capture the final response.
 	outCh := make(chan StreamEvent, 64)
 	sr := &StreamResult{events: outCh}
 
 	go func() {
 		defer close(outCh)
-		for event := range eventCh {
-			outCh <- event
-			if event.Type == StreamFinish && event.Response != nil {
-				sr.mu.Lock()
-				sr.response = event.Response
-				sr.done = true
-				sr.mu.Unlock()
+
+		if opts.Timeout != nil && opts.Timeout.Total > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, opts.Timeout.Total)
+			defer cancel()
+		}
+
+		var perToolTimeout time.Duration
+		if opts.Timeout != nil {
+			perToolTimeout = opts.Timeout.PerTool
+		}
+
+		conversation := make([]Message, len(messages))
+		copy(conversation, messages)
+
+		var steps []StepResult
+		var totalUsage Usage
+
+		for round := 0; round <= opts.MaxToolRounds; round++ {
+			req := Request{
+				Model:           opts.Model,
+				Messages:        conversation,
+				Provider:        opts.Provider,
+				Tools:           opts.Tools,
+				ToolDefs:        toolDefs,
+				ToolChoice:      opts.ToolChoice,
+				ResponseFormat:  opts.ResponseFormat,
+				Temperature:     opts.Temperature,
+				TopP:            opts.TopP,
+				MaxTokens:       opts.MaxTokens,
+				StopSequences:   opts.StopSequences,
+				ReasoningEffort: opts.ReasoningEffort,
+				ProviderOptions: opts.ProviderOptions,
+			}
+
+			stepCtx := ctx
+			if opts.Timeout != nil && opts.Timeout.PerStep > 0 {
+				var cancel context.CancelFunc
+				stepCtx, cancel = context.WithTimeout(ctx, opts.Timeout.PerStep)
+				defer cancel()
+			}
+
+			eventCh, err := client.Stream(stepCtx, req)
+			if err != nil {
+				outCh <- StreamEvent{Type: StreamError, Error: err, Round: round}
+				return
+			}
+
+			// StreamFinish is held back rather than forwarded immediately:
+			// whether it should carry this round's usage or the cumulative
+			// total isn't known until after the round's tool calls (if any)
+			// are resolved below.
+			acc := NewStreamAccumulator()
+			var finishEvent *StreamEvent
+			for event := range eventCh {
+				event.Round = round
+				if event.Type == StreamFinish {
+					ev := event
+					finishEvent = &ev
+					acc.Process(event)
+					continue
+				}
+				outCh <- event
+				acc.Process(event)
+			}
+			resp := acc.Response()
+			totalUsage = totalUsage.Add(resp.Usage)
+
+			toolCalls := resp.ToolCallsFromResponse()
+			var toolResults []ToolResult
+			abortedRun := false
+			if len(toolCalls) > 0 && resp.FinishReason.Reason == "tool_calls" && hasActiveTools {
+				execCalls := toolCalls
+				if opts.ApproveToolCall != nil {
+					var decided map[string]ToolResult
+					execCalls, decided, abortedRun, err = gateToolCalls(ctx, opts.ApproveToolCall, toolCalls)
+					if err != nil {
+						outCh <- StreamEvent{Type: StreamError, Error: err, Round: round}
+						return
+					}
+					toolResults = mergeToolResults(toolCalls, executeToolsConcurrently(stepCtx, toolMap, execCalls, opts.MaxConcurrentTools, perToolTimeout), decided)
+				} else {
+					toolResults = executeToolsConcurrently(stepCtx, toolMap, execCalls, opts.MaxConcurrentTools, perToolTimeout)
+				}
+			}
+
+			steps = append(steps, StepResult{
+				Text:         resp.Text(),
+				Reasoning:    resp.Reasoning(),
+				ToolCalls:    toolCalls,
+				ToolResults:  toolResults,
+				FinishReason: resp.FinishReason,
+				Usage:        resp.Usage,
+				Response:     *resp,
+				Warnings:     resp.Warnings,
+			})
+
+			sr.mu.Lock()
+			sr.response = resp
+			sr.totalUsage = totalUsage
+			sr.done = true
+			sr.mu.Unlock()
+
+			// Check stop conditions; stop takes the same precedence order as
+			// Generate's equivalent checks.
+			stop := false
+			switch {
+			case abortedRun:
+				stop = true // opts.ApproveToolCall returned ApprovalActionAbortRun.
+			case len(toolCalls) == 0 || resp.FinishReason.Reason != "tool_calls":
+				stop = true // Natural completion.
+			case !hasActiveTools:
+				stop = true // Passive tools; return to caller.
+			case round >= opts.MaxToolRounds:
+				stop = true // Budget exhausted.
+			case evaluateStopConditions(opts, steps):
+				stop = true // Custom stop condition.
+			}
+
+			if finishEvent != nil {
+				fin := *finishEvent
+				if stop {
+					// The final StreamFinish a caller sees reflects usage
+					// across every round, not just this one.
+					cumulative := totalUsage
+					fin.Usage = &cumulative
+				}
+				outCh <- fin
+			}
+
+			if stop {
+				return
+			}
+
+			outCh <- StreamEvent{Type: RoundBoundary, Round: round}
+
+			conversation = append(conversation, resp.Message)
+			for _, result := range toolResults {
+				contentBytes, _ := json.Marshal(result.Content)
+				conversation = append(conversation, ToolResultMessage(
+					result.ToolCallID,
+					string(contentBytes),
+					result.IsError,
+				))
 			}
 		}
 	}()
@@ -332,30 +670,155 @@ func GenerateObject(ctx context.Context, opts GenerateOptions, schema map[string
 		opts.System = schemaInstruction
 	}
 
-	result, err := Generate(ctx, opts)
-	if err != nil {
-		return nil, err
+	// roundOpts.Messages carries the conversation across repair rounds;
+	// after the first round it replaces opts.Prompt, since a repair turn
+	// has to be appended to something. Copied rather than aliased so
+	// appending a repair turn below never mutates the caller's opts.Messages.
+	messages := opts.Messages
+	if opts.Prompt != "" {
+		messages = []Message{UserMessage(opts.Prompt)}
 	}
+	roundOpts := opts
+	roundOpts.Prompt = ""
+	roundOpts.Messages = make([]Message, len(messages))
+	copy(roundOpts.Messages, messages)
 
-	// Parse the output.
-	var output interface{}
-	text := result.Text
-	if err := json.Unmarshal([]byte(text), &output); err != nil {
-		return nil, &NoObjectGeneratedError{SDKError: SDKError{
-			Message: fmt.Sprintf("failed to parse structured output: %v", err),
-			Cause:   err,
-		}}
+	var totalUsage Usage
+	var lastErr error
+	for attempt := 0; attempt <= opts.RepairAttempts; attempt++ {
+		result, err := Generate(ctx, roundOpts)
+		if err != nil {
+			return nil, err
+		}
+		totalUsage = totalUsage.Add(result.TotalUsage)
+
+		output, parseErr := parseStructuredOutput(result.Text, opts.Validator)
+		if parseErr == nil {
+			result.Output = output
+			result.TotalUsage = totalUsage
+			return result, nil
+		}
+		lastErr = parseErr
+
+		if attempt == opts.RepairAttempts {
+			break
+		}
+
+		roundOpts.Messages = append(roundOpts.Messages,
+			AssistantMessage(result.Text),
+			UserMessage(repairInstruction(parseErr)),
+		)
 	}
 
-	result.Output = output
-	return result, nil
+	return nil, &NoObjectGeneratedError{SDKError: SDKError{
+		Message: fmt.Sprintf("failed to parse structured output after %d repair attempt(s): %v", opts.RepairAttempts, lastErr),
+		Cause:   lastErr,
+	}}
+}
+
+// parseStructuredOutput parses text as JSON, falling back to
+// extractJSONCandidate if text itself doesn't parse, then runs validate
+// (if set) against whichever candidate parsed. If every candidate fails it
+// returns the last validation error when some candidate parsed, otherwise
+// the first JSON parse error, so repairInstruction has something concrete
+// to quote back to the model.
+func parseStructuredOutput(text string, validate func(interface{}) error) (interface{}, error) {
+	var lastErr error
+	for _, candidate := range []string{text, extractJSONCandidate(text)} {
+		if candidate == "" {
+			continue
+		}
+		var output interface{}
+		if err := json.Unmarshal([]byte(candidate), &output); err != nil {
+			if lastErr == nil {
+				lastErr = err
+			}
+			continue
+		}
+		if validate != nil {
+			if err := validate(output); err != nil {
+				lastErr = err
+				continue
+			}
+		}
+		return output, nil
+	}
+	return nil, lastErr
 }
 
-// StreamAccumulator collects stream events into a complete Response.
+// extractJSONCandidate strips a ``` fenced block wrapping text, if any,
+// then returns the largest balanced {...} substring -- a model's JSON
+// wrapped in prose or markdown usually parses once isolated this way.
+// Returns "" if no balanced object is found.
+func extractJSONCandidate(text string) string {
+	s := stripCodeFences(text)
+
+	best := ""
+	for i, c := range s {
+		if c != '{' {
+			continue
+		}
+		depth := 0
+		for j := i; j < len(s); j++ {
+			switch s[j] {
+			case '{':
+				depth++
+			case '}':
+				depth--
+			}
+			if depth == 0 {
+				if j > i && j-i+1 > len(best) {
+					best = s[i : j+1]
+				}
+				break
+			}
+		}
+	}
+	return best
+}
+
+// stripCodeFences removes a single ``` or ```json fence wrapping all of
+// text, if text (after trimming whitespace) is fenced.
+func stripCodeFences(text string) string {
+	trimmed := strings.TrimSpace(text)
+	if !strings.HasPrefix(trimmed, "```") {
+		return text
+	}
+	trimmed = strings.TrimPrefix(trimmed, "```json")
+	trimmed = strings.TrimPrefix(trimmed, "```")
+	if end := strings.LastIndex(trimmed, "```"); end != -1 {
+		trimmed = trimmed[:end]
+	}
+	return trimmed
+}
+
+// repairInstruction builds the user turn GenerateObject appends after a
+// failed parse/validation, quoting the json.SyntaxError offset when
+// available so the model can locate the problem instead of regenerating
+// blind.
+func repairInstruction(parseErr error) string {
+	detail := parseErr.Error()
+	var syntaxErr *json.SyntaxError
+	if errors.As(parseErr, &syntaxErr) {
+		detail = fmt.Sprintf("%s (at byte offset %d)", detail, syntaxErr.Offset)
+	}
+	return fmt.Sprintf(
+		"Your last response was not valid JSON matching the schema: %s\nRespond ONLY with corrected JSON matching the schema, with no prose or code fences.",
+		detail,
+	)
+}
+
+// StreamAccumulator collects stream events into a complete Response. Tool
+// calls are reassembled from their ToolCallStart/ToolCallDelta/ToolCallEnd
+// fragments via an internal ToolCallAssembler, so a provider that streams
+// arguments incrementally (Anthropic's input_json_delta, OpenAI's arguments
+// chunks) reconstructs the same final ToolCall a provider that hands back
+// complete arguments at ToolCallEnd (Gemini) does.
 type StreamAccumulator struct {
 	textParts      map[string]string
 	reasoningParts []string
 	toolCalls      []ToolCall
+	assembler      *ToolCallAssembler
 	finishReason   *FinishReason
 	usage          *Usage
 	response       *Response
@@ -363,9 +826,14 @@ type StreamAccumulator struct {
 
 // NewStreamAccumulator creates a new StreamAccumulator.
 func NewStreamAccumulator() *StreamAccumulator {
-	return &StreamAccumulator{
+	sa := &StreamAccumulator{
 		textParts: make(map[string]string),
+		assembler: NewToolCallAssembler(),
 	}
+	sa.assembler.OnToolCallReady(func(tc ToolCall) {
+		sa.toolCalls = append(sa.toolCalls, tc)
+	})
+	return sa
 }
 
 // Process ingests a single stream event.
@@ -379,10 +847,8 @@ func (sa *StreamAccumulator) Process(event StreamEvent) {
 		sa.textParts[id] += event.Delta
 	case ReasoningDelta:
 		sa.reasoningParts = append(sa.reasoningParts, event.ReasoningDelta)
-	case ToolCallEnd:
-		if event.ToolCall != nil {
-			sa.toolCalls = append(sa.toolCalls, *event.ToolCall)
-		}
+	case ToolCallStart, ToolCallDelta, ToolCallEnd:
+		sa.assembler.Process(event)
 	case StreamFinish:
 		sa.finishReason = event.FinishReason
 		sa.usage = event.Usage
@@ -418,5 +884,6 @@ func (sa *StreamAccumulator) Response() *Response {
 		Message:      Message{Role: RoleAssistant, Content: content},
 		FinishReason: fr,
 		Usage:        usage,
+		Warnings:     sa.assembler.Warnings(),
 	}
 }
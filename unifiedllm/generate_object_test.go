@@ -0,0 +1,163 @@
+package unifiedllm
+
+import (
+	"context"
+	"testing"
+)
+
+func TestGenerateObjectParsesDirectJSON(t *testing.T) {
+	adapter := &sequenceAdapter{
+		name:      "test",
+		responses: []*Response{newMockAdapter("test", `{"name":"ok"}`).response},
+	}
+	client := NewClient(WithProvider("test", adapter))
+
+	result, err := GenerateObject(context.Background(), GenerateOptions{
+		Model:      "test-model",
+		Prompt:     "give me an object",
+		Provider:   "test",
+		Client:     client,
+		MaxRetries: 0,
+	}, map[string]interface{}{"type": "object"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	obj, ok := result.Output.(map[string]interface{})
+	if !ok || obj["name"] != "ok" {
+		t.Errorf("expected parsed output with name=ok, got %#v", result.Output)
+	}
+}
+
+func TestGenerateObjectExtractsFencedJSON(t *testing.T) {
+	adapter := &sequenceAdapter{
+		name:      "test",
+		responses: []*Response{newMockAdapter("test", "Sure, here you go:\n```json\n{\"name\":\"ok\"}\n```\nLet me know if that works.").response},
+	}
+	client := NewClient(WithProvider("test", adapter))
+
+	result, err := GenerateObject(context.Background(), GenerateOptions{
+		Model:      "test-model",
+		Prompt:     "give me an object",
+		Provider:   "test",
+		Client:     client,
+		MaxRetries: 0,
+	}, map[string]interface{}{"type": "object"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	obj, ok := result.Output.(map[string]interface{})
+	if !ok || obj["name"] != "ok" {
+		t.Errorf("expected extracted output with name=ok, got %#v", result.Output)
+	}
+}
+
+func TestGenerateObjectRepairsAfterBadJSON(t *testing.T) {
+	adapter := &sequenceAdapter{
+		name: "test",
+		responses: []*Response{
+			newMockAdapter("test", `{"name":"ok",}`).response, // trailing comma
+			newMockAdapter("test", `{"name":"ok"}`).response,
+		},
+	}
+	client := NewClient(WithProvider("test", adapter))
+
+	result, err := GenerateObject(context.Background(), GenerateOptions{
+		Model:          "test-model",
+		Prompt:         "give me an object",
+		Provider:       "test",
+		Client:         client,
+		MaxRetries:     0,
+		RepairAttempts: 1,
+	}, map[string]interface{}{"type": "object"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	obj, ok := result.Output.(map[string]interface{})
+	if !ok || obj["name"] != "ok" {
+		t.Errorf("expected repaired output with name=ok, got %#v", result.Output)
+	}
+	if len(adapter.responses) != 2 {
+		t.Fatalf("test setup error: expected 2 responses configured")
+	}
+}
+
+func TestGenerateObjectFailsAfterExhaustingRepairAttempts(t *testing.T) {
+	adapter := &sequenceAdapter{
+		name:      "test",
+		responses: []*Response{newMockAdapter("test", "not json at all").response},
+	}
+	client := NewClient(WithProvider("test", adapter))
+
+	_, err := GenerateObject(context.Background(), GenerateOptions{
+		Model:          "test-model",
+		Prompt:         "give me an object",
+		Provider:       "test",
+		Client:         client,
+		MaxRetries:     0,
+		RepairAttempts: 2,
+	}, map[string]interface{}{"type": "object"})
+	if err == nil {
+		t.Fatal("expected an error once every repair attempt is exhausted")
+	}
+	if _, ok := err.(*NoObjectGeneratedError); !ok {
+		t.Errorf("expected *NoObjectGeneratedError, got %T: %v", err, err)
+	}
+}
+
+func TestGenerateObjectValidatorTriggersRepair(t *testing.T) {
+	adapter := &sequenceAdapter{
+		name: "test",
+		responses: []*Response{
+			newMockAdapter("test", `{"name":""}`).response,
+			newMockAdapter("test", `{"name":"ok"}`).response,
+		},
+	}
+	client := NewClient(WithProvider("test", adapter))
+
+	result, err := GenerateObject(context.Background(), GenerateOptions{
+		Model:          "test-model",
+		Prompt:         "give me an object",
+		Provider:       "test",
+		Client:         client,
+		MaxRetries:     0,
+		RepairAttempts: 1,
+		Validator: func(v interface{}) error {
+			obj, _ := v.(map[string]interface{})
+			if obj["name"] == "" {
+				return errEmptyName
+			}
+			return nil
+		},
+	}, map[string]interface{}{"type": "object"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	obj, ok := result.Output.(map[string]interface{})
+	if !ok || obj["name"] != "ok" {
+		t.Errorf("expected repaired output with name=ok, got %#v", result.Output)
+	}
+}
+
+var errEmptyName = &NoObjectGeneratedError{SDKError: SDKError{Message: "name must not be empty"}}
+
+func TestExtractJSONCandidate(t *testing.T) {
+	cases := []struct {
+		name string
+		text string
+		want string
+	}{
+		{"plain", `{"a":1}`, `{"a":1}`},
+		{"fenced", "```json\n{\"a\":1}\n```", `{"a":1}`},
+		{"prose with object", `Sure, here's the data: {"a":1} -- hope that helps!`, `{"a":1}`},
+		{"nested braces", `prefix {"a":{"b":1}} suffix`, `{"a":{"b":1}}`},
+		{"no object", "no json here", ""},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := extractJSONCandidate(tc.text)
+			if got != tc.want {
+				t.Errorf("extractJSONCandidate(%q) = %q, want %q", tc.text, got, tc.want)
+			}
+		})
+	}
+}
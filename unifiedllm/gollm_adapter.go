@@ -80,7 +80,7 @@ func NewGollmAdapter(provider string, apiKey string, opts ...GollmAdapterOption)
 	// Determine default model for provider.
 	model := cfg.model
 	if model == "" {
-		if info := GetLatestModel(provider, ""); info != nil {
+		if info := GetLatestModel(provider, Capabilities{}); info != nil {
 			model = info.ID
 		} else {
 			// Fallback defaults.
@@ -153,7 +153,10 @@ func (a *GollmAdapter) Complete(ctx context.Context, req Request) (*Response, er
 	return a.buildResponse(req, text), nil
 }
 
-// Stream sends a streaming request and returns a channel of StreamEvent objects.
+// Stream sends a streaming request and returns a channel of StreamEvent
+// objects, including incremental ToolCallStart/ToolCallDelta/ToolCallEnd
+// events as gollmToolCallScanner detects tool-call JSON embedded in the
+// text as it arrives.
 func (a *GollmAdapter) Stream(ctx context.Context, req Request) (<-chan StreamEvent, error) {
 	prompt, err := a.translateRequest(req)
 	if err != nil {
@@ -181,6 +184,11 @@ func (a *GollmAdapter) Stream(ctx context.Context, req Request) (<-chan StreamEv
 			ch <- StreamEvent{Type: TextDelta, Delta: text, TextID: textID}
 			ch <- StreamEvent{Type: TextEnd, TextID: textID}
 
+			scanner := newGollmToolCallScanner()
+			for _, evt := range scanner.Feed(text) {
+				ch <- evt
+			}
+
 			resp := a.buildResponse(req, text)
 			ch <- StreamEvent{
 				Type:         StreamFinish,
@@ -207,6 +215,7 @@ func (a *GollmAdapter) Stream(ctx context.Context, req Request) (<-chan StreamEv
 		textID := "text_0"
 		started := false
 		var fullText strings.Builder
+		toolScanner := newGollmToolCallScanner()
 
 		for {
 			token, err := stream.Next(ctx)
@@ -228,6 +237,10 @@ func (a *GollmAdapter) Stream(ctx context.Context, req Request) (<-chan StreamEv
 
 			ch <- StreamEvent{Type: TextDelta, Delta: token.Text, TextID: textID}
 			fullText.WriteString(token.Text)
+
+			for _, evt := range toolScanner.Feed(token.Text) {
+				ch <- evt
+			}
 		}
 
 		if started {
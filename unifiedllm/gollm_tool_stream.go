@@ -0,0 +1,211 @@
+package unifiedllm
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// gollmToolCallScanner incrementally detects gollm's embedded tool-call
+// JSON (the `{"tool_calls":[...]}` or bare `[{"name":...}]` shapes
+// GollmAdapter.parseToolCalls recognizes post-hoc) as text streams in,
+// token by token, emitting ToolCallStart/ToolCallDelta/ToolCallEnd events
+// as soon as each call's name and argument fragments are available rather
+// than waiting for the whole response. It assumes each call's "arguments"
+// value is a JSON object, matching every ToolDefinition.Parameters in this
+// codebase (`"type": "object"`).
+type gollmToolCallScanner struct {
+	buf  strings.Builder
+	pos  int // next unscanned byte in buf
+	done bool
+
+	started bool // the array start marker has been found
+	depth   int  // generic brace/bracket nesting depth from the array's '['
+
+	inString bool
+	escaped  bool
+	strBuf   strings.Builder
+
+	awaitingKey    bool // at a position where the next string is an object key
+	awaitingColon  bool // a key string just closed; waiting for ':'
+	afterColon     bool // ':' consumed; waiting for the value's first token
+	currentKey     string
+	capturingKey   bool
+	capturingName  bool
+	expectArgsOpen bool // "arguments" key's colon consumed; waiting for '{'
+
+	objActive   bool
+	id          string
+	name        string
+	startSent   bool
+	argsOpen    bool
+	argsStart   int
+	lastFlushed int
+}
+
+// newGollmToolCallScanner creates a scanner ready to Feed() accumulated
+// stream text.
+func newGollmToolCallScanner() *gollmToolCallScanner {
+	return &gollmToolCallScanner{}
+}
+
+// Feed appends chunk (gollm's latest streamed token) and returns the
+// StreamEvents newly detected as a result. It is safe to call repeatedly
+// as more text arrives; state persists across calls.
+func (s *gollmToolCallScanner) Feed(chunk string) []StreamEvent {
+	if s.done || chunk == "" {
+		return nil
+	}
+	s.buf.WriteString(chunk)
+	full := s.buf.String()
+
+	if !s.started {
+		arrayStart := findToolCallArrayStart(full)
+		if arrayStart == -1 {
+			return nil
+		}
+		s.started = true
+		s.pos = arrayStart
+		s.awaitingKey = false
+	}
+
+	var events []StreamEvent
+	i := s.pos
+	for ; i < len(full); i++ {
+		c := full[i]
+
+		if s.inString {
+			switch {
+			case s.escaped:
+				s.escaped = false
+				s.strBuf.WriteByte(c)
+			case c == '\\':
+				s.escaped = true
+			case c == '"':
+				s.inString = false
+				value := s.strBuf.String()
+				s.strBuf.Reset()
+				if s.capturingKey {
+					s.capturingKey = false
+					s.currentKey = value
+					s.awaitingColon = true
+				} else if s.capturingName {
+					s.capturingName = false
+					s.name = value
+					if s.objActive && !s.startSent {
+						s.id = "call_" + uuid.New().String()[:8]
+						s.startSent = true
+						events = append(events, StreamEvent{Type: ToolCallStart, ToolCall: &ToolCall{ID: s.id, Name: s.name}})
+					}
+				}
+			default:
+				s.strBuf.WriteByte(c)
+			}
+			continue
+		}
+
+		switch c {
+		case '"':
+			s.inString = true
+			if s.depth == 2 && s.awaitingKey {
+				s.capturingKey = true
+				s.awaitingKey = false
+			} else if s.afterColon && s.currentKey == "name" {
+				s.capturingName = true
+				s.afterColon = false
+			}
+		case ':':
+			if s.awaitingColon {
+				s.awaitingColon = false
+				s.afterColon = true
+				if s.currentKey == "arguments" {
+					s.expectArgsOpen = true
+				}
+			}
+		case ',':
+			if s.depth == 2 {
+				s.awaitingKey = true
+			}
+		case '{', '[':
+			s.depth++
+			switch {
+			case s.depth == 2:
+				s.objActive = true
+				s.awaitingKey = true
+				s.name = ""
+				s.startSent = false
+			case s.expectArgsOpen && c == '{':
+				s.expectArgsOpen = false
+				s.afterColon = false
+				s.argsOpen = true
+				s.argsStart = i
+				s.lastFlushed = i
+			}
+		case '}', ']':
+			prevDepth := s.depth
+			s.depth--
+			if s.argsOpen && prevDepth == 3 && s.depth == 2 {
+				events = append(events, s.flushArgsDelta(full[:i+1]))
+				rawArgs := full[s.argsStart : i+1]
+				s.argsOpen = false
+				if !s.startSent {
+					s.id = "call_" + uuid.New().String()[:8]
+					s.startSent = true
+					events = append(events, StreamEvent{Type: ToolCallStart, ToolCall: &ToolCall{ID: s.id, Name: s.name}})
+				}
+				events = append(events, StreamEvent{
+					Type:     ToolCallEnd,
+					ToolCall: &ToolCall{ID: s.id, Name: s.name, Arguments: json.RawMessage(rawArgs)},
+				})
+			}
+			if prevDepth == 2 && s.depth == 1 {
+				s.objActive = false
+			}
+			if prevDepth == 1 && s.depth == 0 {
+				s.done = true
+				i++
+				goto finished
+			}
+		}
+	}
+finished:
+	if s.argsOpen {
+		if evt := s.flushArgsDelta(full[:i]); evt.Delta != "" {
+			events = append(events, evt)
+		}
+	}
+	s.pos = i
+	return events
+}
+
+// flushArgsDelta returns a ToolCallDelta covering the unflushed tail of
+// the in-progress arguments value, up to full (the text scanned so far).
+func (s *gollmToolCallScanner) flushArgsDelta(full string) StreamEvent {
+	if s.lastFlushed >= len(full) {
+		return StreamEvent{}
+	}
+	fragment := full[s.lastFlushed:]
+	s.lastFlushed = len(full)
+	if fragment == "" {
+		return StreamEvent{}
+	}
+	return StreamEvent{Type: ToolCallDelta, ToolCall: &ToolCall{ID: s.id}, Delta: fragment}
+}
+
+// findToolCallArrayStart locates the '[' beginning gollm's tool-call array,
+// recognizing both the `{"tool_calls":[...]}` and bare `[{"name":...}]`
+// shapes GollmAdapter.parseToolCalls handles. It returns -1 if neither
+// marker has appeared yet.
+func findToolCallArrayStart(text string) int {
+	if idx := strings.Index(text, `{"tool_calls"`); idx != -1 {
+		if b := strings.IndexByte(text[idx:], '['); b != -1 {
+			return idx + b
+		}
+		return -1
+	}
+	if idx := strings.Index(text, `[{"name"`); idx != -1 {
+		return idx
+	}
+	return -1
+}
@@ -0,0 +1,77 @@
+package unifiedllm
+
+import "testing"
+
+func feedAll(t *testing.T, s *gollmToolCallScanner, chunks ...string) []StreamEvent {
+	t.Helper()
+	var events []StreamEvent
+	for _, c := range chunks {
+		events = append(events, s.Feed(c)...)
+	}
+	return events
+}
+
+func TestGollmToolCallScannerEmitsStartDeltaEndForBareArray(t *testing.T) {
+	s := newGollmToolCallScanner()
+	events := feedAll(t, s,
+		`Sure, let me check that. [{"name": "get_`,
+		`weather", "arguments": {"ci`,
+		`ty": "Paris"}}]`,
+	)
+
+	if len(events) < 3 {
+		t.Fatalf("expected at least start/delta/end events, got %d: %+v", len(events), events)
+	}
+	if events[0].Type != ToolCallStart || events[0].ToolCall.Name != "get_weather" {
+		t.Fatalf("expected a leading ToolCallStart for get_weather, got %+v", events[0])
+	}
+
+	var deltas string
+	var end *ToolCall
+	for _, evt := range events[1:] {
+		switch evt.Type {
+		case ToolCallDelta:
+			deltas += evt.Delta
+		case ToolCallEnd:
+			end = evt.ToolCall
+		}
+	}
+	if deltas != `{"city": "Paris"}` {
+		t.Errorf("expected deltas to reconstruct the arguments value, got %q", deltas)
+	}
+	if end == nil || end.Name != "get_weather" {
+		t.Fatalf("expected a ToolCallEnd for get_weather, got %+v", end)
+	}
+	if string(end.Arguments) != `{"city": "Paris"}` {
+		t.Errorf("expected ToolCallEnd to carry the full arguments, got %q", end.Arguments)
+	}
+}
+
+func TestGollmToolCallScannerHandlesToolCallsWrapper(t *testing.T) {
+	s := newGollmToolCallScanner()
+	events := feedAll(t, s, `{"tool_calls": [{"name": "ping", "arguments": {}}]}`)
+
+	var sawStart, sawEnd bool
+	for _, evt := range events {
+		if evt.Type == ToolCallStart {
+			sawStart = true
+		}
+		if evt.Type == ToolCallEnd {
+			sawEnd = true
+			if evt.ToolCall.Name != "ping" {
+				t.Errorf("expected name %q, got %q", "ping", evt.ToolCall.Name)
+			}
+		}
+	}
+	if !sawStart || !sawEnd {
+		t.Fatalf("expected both a ToolCallStart and ToolCallEnd, got %+v", events)
+	}
+}
+
+func TestGollmToolCallScannerIgnoresPlainText(t *testing.T) {
+	s := newGollmToolCallScanner()
+	events := feedAll(t, s, "just a plain assistant reply, no tool calls here")
+	if len(events) != 0 {
+		t.Fatalf("expected no events for plain text, got %+v", events)
+	}
+}
@@ -0,0 +1,738 @@
+package unifiedllm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/protobuf/types/known/structpb"
+
+	"github.com/martinemde/attractor/unifiedllm/llmproviderpb"
+)
+
+// GRPCAdapter implements ProviderAdapter by dialing an out-of-process LLM
+// gateway (see proto/llm_provider.proto and cmd/llm-provider-server), the
+// way RemoteExecutionEnvironment runs tool execution over the network
+// instead of in-process. This lets an organization put rate-limiting,
+// audit, and policy enforcement for model calls behind mTLS in a separate
+// process rather than linking a provider SDK directly into the agent loop.
+//
+// name is cached from the server's Name RPC during Initialize, so a
+// GRPCAdapter can be registered with Client.RegisterProvider before it has
+// dialed.
+type GRPCAdapter struct {
+	target   string
+	dialOpts []grpc.DialOption
+
+	conn   *grpc.ClientConn
+	client llmproviderpb.LLMProviderClient
+	name   string
+	models []ModelInfo
+}
+
+// NewGRPCAdapter creates a client for the LLM provider plugin server
+// listening at target (a standard grpc.Dial target: "host:port" for TCP, or
+// "unix:/path/to.sock"). Call Initialize before using it, or let
+// Client.RegisterProvider's first Complete/Stream call fail with a clear
+// error if it wasn't.
+func NewGRPCAdapter(target string, dialOpts ...grpc.DialOption) *GRPCAdapter {
+	return &GRPCAdapter{target: target, dialOpts: dialOpts}
+}
+
+// Initialize dials target, fetches the adapter's Name via the Name RPC, and
+// performs the Register handshake to learn which models the backend
+// serves. A backend that doesn't know its own catalog (yet) can legally
+// return zero models from Register; Models will just report none.
+func (a *GRPCAdapter) Initialize() error {
+	opts := a.dialOpts
+	if len(opts) == 0 {
+		opts = []grpc.DialOption{grpc.WithTransportCredentials(insecure.NewCredentials())}
+	}
+
+	conn, err := grpc.NewClient(a.target, opts...)
+	if err != nil {
+		return fmt.Errorf("grpc_adapter: dial %s: %w", a.target, err)
+	}
+	a.conn = conn
+	a.client = llmproviderpb.NewLLMProviderClient(conn)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	nameResp, err := a.client.Name(ctx, &llmproviderpb.NameRequest{})
+	if err != nil {
+		_ = conn.Close()
+		return fmt.Errorf("grpc_adapter: name: %w", err)
+	}
+	a.name = nameResp.Name
+
+	regResp, err := a.client.Register(ctx, &llmproviderpb.RegisterRequest{})
+	if err != nil {
+		_ = conn.Close()
+		return fmt.Errorf("grpc_adapter: register: %w", err)
+	}
+	a.models = make([]ModelInfo, len(regResp.Models))
+	for i, m := range regResp.Models {
+		a.models[i] = ModelInfoFromProto(m)
+	}
+	return nil
+}
+
+// Models returns the model catalog the backend reported during Register,
+// so a caller can add it to its own catalog (e.g. via a config-driven
+// discovery step) without hardcoding the backend's models in advance.
+func (a *GRPCAdapter) Models() []ModelInfo {
+	return a.models
+}
+
+// SupportsToolChoice asks the backend, over the SupportsToolChoice RPC,
+// whether it supports the given tool_choice mode. It implements the
+// optional ToolChoiceSupporter interface.
+func (a *GRPCAdapter) SupportsToolChoice(mode string) bool {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	resp, err := a.client.SupportsToolChoice(ctx, &llmproviderpb.SupportsToolChoiceRequest{Mode: mode})
+	if err != nil {
+		return false
+	}
+	return resp.Supported
+}
+
+// Close releases the underlying gRPC connection.
+func (a *GRPCAdapter) Close() error {
+	if a.conn == nil {
+		return nil
+	}
+	return a.conn.Close()
+}
+
+func (a *GRPCAdapter) Name() string { return a.name }
+
+// Complete sends req to the gateway's Complete RPC and translates its
+// response (or any translated error it returns) back into unifiedllm types.
+func (a *GRPCAdapter) Complete(ctx context.Context, req Request) (*Response, error) {
+	if a.client == nil {
+		return nil, fmt.Errorf("grpc_adapter: %s: not initialized; call Initialize first", a.target)
+	}
+	proto, err := RequestToProto(req)
+	if err != nil {
+		return nil, fmt.Errorf("grpc_adapter: %w", err)
+	}
+
+	resp, err := a.client.Complete(ctx, &llmproviderpb.CompleteRequest{Request: proto})
+	if err != nil {
+		return nil, fmt.Errorf("grpc_adapter: complete: %w", err)
+	}
+	return ResponseFromProto(resp.Response)
+}
+
+// Stream sends req to the gateway's Stream RPC and relays StreamEventProto
+// frames onto a StreamEvent channel until the server closes the stream or
+// ctx is cancelled, mirroring RemoteExecutionEnvironment.ExecCommand's relay
+// loop for a streaming RPC.
+func (a *GRPCAdapter) Stream(ctx context.Context, req Request) (<-chan StreamEvent, error) {
+	if a.client == nil {
+		return nil, fmt.Errorf("grpc_adapter: %s: not initialized; call Initialize first", a.target)
+	}
+	proto, err := RequestToProto(req)
+	if err != nil {
+		return nil, fmt.Errorf("grpc_adapter: %w", err)
+	}
+
+	stream, err := a.client.Stream(ctx, &llmproviderpb.CompleteRequest{Request: proto})
+	if err != nil {
+		return nil, fmt.Errorf("grpc_adapter: stream: %w", err)
+	}
+
+	ch := make(chan StreamEvent)
+	go func() {
+		defer close(ch)
+		for {
+			frame, err := stream.Recv()
+			if err == io.EOF {
+				return
+			}
+			if err != nil {
+				select {
+				case ch <- StreamEvent{Type: StreamError, Error: fmt.Errorf("grpc_adapter: stream: %w", err)}:
+				case <-ctx.Done():
+				}
+				return
+			}
+
+			event, err := StreamEventFromProto(frame)
+			if err != nil {
+				select {
+				case ch <- StreamEvent{Type: StreamError, Error: fmt.Errorf("grpc_adapter: %w", err)}:
+				case <-ctx.Done():
+				}
+				return
+			}
+
+			select {
+			case ch <- event:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return ch, nil
+}
+
+// CountTokens implements the optional TokenCounter interface by calling the
+// backend's CountTokens RPC.
+func (a *GRPCAdapter) CountTokens(ctx context.Context, req Request) (int, error) {
+	proto, err := RequestToProto(req)
+	if err != nil {
+		return 0, fmt.Errorf("grpc_adapter: %w", err)
+	}
+
+	resp, err := a.client.CountTokens(ctx, &llmproviderpb.CountTokensRequest{Request: proto})
+	if err != nil {
+		return 0, fmt.Errorf("grpc_adapter: count_tokens: %w", err)
+	}
+	return int(resp.TokenCount), nil
+}
+
+// Embed implements the optional Embedder interface by calling the backend's
+// Embed RPC.
+func (a *GRPCAdapter) Embed(ctx context.Context, req EmbedRequest) (*EmbedResponse, error) {
+	resp, err := a.client.Embed(ctx, &llmproviderpb.EmbedRequest{Model: req.Model, Input: req.Input})
+	if err != nil {
+		return nil, fmt.Errorf("grpc_adapter: embed: %w", err)
+	}
+
+	embeddings := make([][]float64, len(resp.Embeddings))
+	for i, e := range resp.Embeddings {
+		embeddings[i] = e.Values
+	}
+	return &EmbedResponse{Embeddings: embeddings, Usage: UsageFromProto(resp.Usage)}, nil
+}
+
+// ListModels implements the optional ModelRefresher interface by calling
+// the backend's ListModels RPC and refreshing the catalog Models() reports.
+func (a *GRPCAdapter) ListModels(ctx context.Context) ([]ModelInfo, error) {
+	resp, err := a.client.ListModels(ctx, &llmproviderpb.ListModelsRequest{})
+	if err != nil {
+		return nil, fmt.Errorf("grpc_adapter: list_models: %w", err)
+	}
+
+	models := make([]ModelInfo, len(resp.Models))
+	for i, m := range resp.Models {
+		models[i] = ModelInfoFromProto(m)
+	}
+	a.models = models
+	return models, nil
+}
+
+// The ToProto/FromProto functions below translate between unifiedllm's
+// domain types and the wire types generated from proto/llm_provider.proto.
+// GRPCAdapter (the client half) uses RequestToProto/ResponseFromProto/
+// StreamEventFromProto; cmd/llm-provider-server (the server half) uses the
+// mirror-image RequestFromProto/ResponseToProto/StreamEventToProto. They're
+// exported from this package, rather than duplicated in cmd, so both halves
+// of the protocol stay in sync with a single source of truth.
+
+// RequestToProto converts a Request into its wire representation.
+func RequestToProto(req Request) (*llmproviderpb.RequestProto, error) {
+	messages := make([]*llmproviderpb.MessageProto, len(req.Messages))
+	for i, m := range req.Messages {
+		proto, err := MessageToProto(m)
+		if err != nil {
+			return nil, err
+		}
+		messages[i] = proto
+	}
+
+	tools := make([]*llmproviderpb.ToolDefinitionProto, len(req.ToolDefs))
+	for i, t := range req.ToolDefs {
+		params, err := structpb.NewStruct(t.Parameters)
+		if err != nil {
+			return nil, fmt.Errorf("tool %q parameters: %w", t.Name, err)
+		}
+		tools[i] = &llmproviderpb.ToolDefinitionProto{Name: t.Name, Description: t.Description, Parameters: params}
+	}
+
+	providerOptions, err := structpb.NewStruct(req.ProviderOptions)
+	if err != nil {
+		return nil, fmt.Errorf("provider_options: %w", err)
+	}
+
+	p := &llmproviderpb.RequestProto{
+		Model:           req.Model,
+		Messages:        messages,
+		Provider:        req.Provider,
+		Tools:           tools,
+		ReasoningEffort: req.ReasoningEffort,
+		StopSequences:   req.StopSequences,
+		Metadata:        req.Metadata,
+		ProviderOptions: providerOptions,
+	}
+	if req.ToolChoice != nil {
+		p.ToolChoice = &llmproviderpb.ToolChoiceProto{Mode: req.ToolChoice.Mode, ToolName: req.ToolChoice.ToolName}
+	}
+	if req.ResponseFormat != nil {
+		schema, err := structpb.NewStruct(req.ResponseFormat.JSONSchema)
+		if err != nil {
+			return nil, fmt.Errorf("response_format: %w", err)
+		}
+		p.ResponseFormat = &llmproviderpb.ResponseFormatProto{
+			Type:       req.ResponseFormat.Type,
+			JsonSchema: schema,
+			Strict:     req.ResponseFormat.Strict,
+		}
+	}
+	if req.Temperature != nil {
+		p.Temperature = req.Temperature
+	}
+	if req.TopP != nil {
+		p.TopP = req.TopP
+	}
+	if req.MaxTokens != nil {
+		v := int32(*req.MaxTokens)
+		p.MaxTokens = &v
+	}
+	return p, nil
+}
+
+// RequestFromProto converts a wire RequestProto back into a Request, the
+// mirror image of RequestToProto. It's used by the server half of the
+// protocol to reconstruct the caller's request before dispatching it to a
+// real ProviderAdapter.
+func RequestFromProto(p *llmproviderpb.RequestProto) (Request, error) {
+	if p == nil {
+		return Request{}, fmt.Errorf("grpc_adapter: request_from_proto: nil request")
+	}
+
+	messages := make([]Message, len(p.Messages))
+	for i, m := range p.Messages {
+		msg, err := MessageFromProto(m)
+		if err != nil {
+			return Request{}, err
+		}
+		messages[i] = msg
+	}
+
+	toolDefs := make([]ToolDefinition, len(p.Tools))
+	for i, t := range p.Tools {
+		var params map[string]interface{}
+		if t.Parameters != nil {
+			params = t.Parameters.AsMap()
+		}
+		toolDefs[i] = ToolDefinition{Name: t.Name, Description: t.Description, Parameters: params}
+	}
+
+	req := Request{
+		Model:           p.Model,
+		Messages:        messages,
+		Provider:        p.Provider,
+		ToolDefs:        toolDefs,
+		ReasoningEffort: p.ReasoningEffort,
+		StopSequences:   p.StopSequences,
+		Metadata:        p.Metadata,
+	}
+	if p.ProviderOptions != nil {
+		req.ProviderOptions = p.ProviderOptions.AsMap()
+	}
+	if p.ToolChoice != nil {
+		req.ToolChoice = &ToolChoice{Mode: p.ToolChoice.Mode, ToolName: p.ToolChoice.ToolName}
+	}
+	if p.ResponseFormat != nil {
+		rf := &ResponseFormat{Type: p.ResponseFormat.Type, Strict: p.ResponseFormat.Strict}
+		if p.ResponseFormat.JsonSchema != nil {
+			rf.JSONSchema = p.ResponseFormat.JsonSchema.AsMap()
+		}
+		req.ResponseFormat = rf
+	}
+	if p.Temperature != nil {
+		req.Temperature = p.Temperature
+	}
+	if p.TopP != nil {
+		req.TopP = p.TopP
+	}
+	if p.MaxTokens != nil {
+		v := int(*p.MaxTokens)
+		req.MaxTokens = &v
+	}
+	return req, nil
+}
+
+// MessageToProto converts a Message into its wire representation.
+func MessageToProto(m Message) (*llmproviderpb.MessageProto, error) {
+	parts := make([]*llmproviderpb.ContentPartProto, len(m.Content))
+	for i, part := range m.Content {
+		proto, err := ContentPartToProto(part)
+		if err != nil {
+			return nil, err
+		}
+		parts[i] = proto
+	}
+	return &llmproviderpb.MessageProto{
+		Role:         string(m.Role),
+		Content:      parts,
+		Name:         m.Name,
+		ToolCallId:   m.ToolCallID,
+		CacheControl: CacheHintToProto(m.CacheControl),
+	}, nil
+}
+
+// ContentPartToProto converts a ContentPart into its wire representation.
+func ContentPartToProto(part ContentPart) (*llmproviderpb.ContentPartProto, error) {
+	p := &llmproviderpb.ContentPartProto{
+		Kind:         string(part.Kind),
+		Text:         part.Text,
+		CacheControl: CacheHintToProto(part.CacheControl),
+	}
+	if part.Image != nil {
+		p.Image = &llmproviderpb.ImageDataProto{
+			Url: part.Image.URL, Data: part.Image.Data, MediaType: part.Image.MediaType, Detail: part.Image.Detail,
+		}
+	}
+	if part.Audio != nil {
+		p.Audio = &llmproviderpb.AudioDataProto{Url: part.Audio.URL, Data: part.Audio.Data, MediaType: part.Audio.MediaType}
+	}
+	if part.Document != nil {
+		p.Document = &llmproviderpb.DocumentDataProto{
+			Url: part.Document.URL, Data: part.Document.Data, MediaType: part.Document.MediaType, FileName: part.Document.FileName,
+		}
+	}
+	if part.ToolCall != nil {
+		p.ToolCall = &llmproviderpb.ToolCallDataProto{
+			Id: part.ToolCall.ID, Name: part.ToolCall.Name, Arguments: []byte(part.ToolCall.Arguments), Type: part.ToolCall.Type,
+		}
+	}
+	if part.ToolResult != nil {
+		p.ToolResult = &llmproviderpb.ToolResultDataProto{
+			ToolCallId: part.ToolResult.ToolCallID, Content: []byte(part.ToolResult.Content), IsError: part.ToolResult.IsError,
+			ImageData: part.ToolResult.ImageData, ImageMediaType: part.ToolResult.ImageMediaType,
+		}
+	}
+	if part.Thinking != nil {
+		p.Thinking = &llmproviderpb.ThinkingDataProto{
+			Text: part.Thinking.Text, Signature: part.Thinking.Signature, Redacted: part.Thinking.Redacted,
+		}
+	}
+	return p, nil
+}
+
+// CacheHintToProto converts a CacheHint into its wire representation.
+func CacheHintToProto(hint *CacheHint) *llmproviderpb.CacheHintProto {
+	if hint == nil {
+		return nil
+	}
+	return &llmproviderpb.CacheHintProto{Type: hint.Type, TtlMs: hint.TTL.Milliseconds()}
+}
+
+// ResponseFromProto converts a wire ResponseProto into a Response.
+func ResponseFromProto(p *llmproviderpb.ResponseProto) (*Response, error) {
+	if p == nil {
+		return nil, fmt.Errorf("grpc_adapter: response_from_proto: nil response")
+	}
+	message, err := MessageFromProto(p.Message)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &Response{
+		ID:       p.Id,
+		Model:    p.Model,
+		Provider: p.Provider,
+		Message:  message,
+		Usage:    UsageFromProto(p.Usage),
+	}
+	if p.FinishReason != nil {
+		resp.FinishReason = FinishReason{Reason: p.FinishReason.Reason, Raw: p.FinishReason.Raw}
+	}
+	if p.Raw != nil {
+		resp.Raw = p.Raw.AsMap()
+	}
+	for _, w := range p.Warnings {
+		resp.Warnings = append(resp.Warnings, Warning{Message: w.Message, Code: w.Code})
+	}
+	if p.RateLimit != nil {
+		resp.RateLimit = &RateLimitInfo{
+			RequestsRemaining: int32PtrToIntPtr(p.RateLimit.RequestsRemaining),
+			RequestsLimit:     int32PtrToIntPtr(p.RateLimit.RequestsLimit),
+			TokensRemaining:   int32PtrToIntPtr(p.RateLimit.TokensRemaining),
+			TokensLimit:       int32PtrToIntPtr(p.RateLimit.TokensLimit),
+		}
+		if p.RateLimit.ResetAtUnixMs > 0 {
+			resetAt := time.UnixMilli(p.RateLimit.ResetAtUnixMs)
+			resp.RateLimit.ResetAt = &resetAt
+		}
+	}
+	return resp, nil
+}
+
+// ResponseToProto converts a Response into its wire representation, the
+// mirror image of ResponseFromProto. It's used by the server half of the
+// protocol to relay a real ProviderAdapter's response back to the caller.
+func ResponseToProto(resp *Response) (*llmproviderpb.ResponseProto, error) {
+	if resp == nil {
+		return nil, fmt.Errorf("grpc_adapter: response_to_proto: nil response")
+	}
+	message, err := MessageToProto(resp.Message)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &llmproviderpb.ResponseProto{
+		Id:           resp.ID,
+		Model:        resp.Model,
+		Provider:     resp.Provider,
+		Message:      message,
+		FinishReason: &llmproviderpb.FinishReasonProto{Reason: resp.FinishReason.Reason, Raw: resp.FinishReason.Raw},
+		Usage:        UsageToProto(resp.Usage),
+	}
+	if resp.Raw != nil {
+		raw, err := structpb.NewStruct(resp.Raw)
+		if err != nil {
+			return nil, fmt.Errorf("raw: %w", err)
+		}
+		p.Raw = raw
+	}
+	for _, w := range resp.Warnings {
+		p.Warnings = append(p.Warnings, &llmproviderpb.WarningProto{Message: w.Message, Code: w.Code})
+	}
+	if resp.RateLimit != nil {
+		rl := &llmproviderpb.RateLimitInfoProto{
+			RequestsRemaining: intPtrToInt32Ptr(resp.RateLimit.RequestsRemaining),
+			RequestsLimit:     intPtrToInt32Ptr(resp.RateLimit.RequestsLimit),
+			TokensRemaining:   intPtrToInt32Ptr(resp.RateLimit.TokensRemaining),
+			TokensLimit:       intPtrToInt32Ptr(resp.RateLimit.TokensLimit),
+		}
+		if resp.RateLimit.ResetAt != nil {
+			rl.ResetAtUnixMs = resp.RateLimit.ResetAt.UnixMilli()
+		}
+		p.RateLimit = rl
+	}
+	return p, nil
+}
+
+// MessageFromProto converts a wire MessageProto into a Message.
+func MessageFromProto(p *llmproviderpb.MessageProto) (Message, error) {
+	if p == nil {
+		return Message{}, nil
+	}
+	content := make([]ContentPart, len(p.Content))
+	for i, part := range p.Content {
+		content[i] = ContentPartFromProto(part)
+	}
+	return Message{
+		Role:         Role(p.Role),
+		Content:      content,
+		Name:         p.Name,
+		ToolCallID:   p.ToolCallId,
+		CacheControl: CacheHintFromProto(p.CacheControl),
+	}, nil
+}
+
+// ContentPartFromProto converts a wire ContentPartProto into a ContentPart.
+func ContentPartFromProto(p *llmproviderpb.ContentPartProto) ContentPart {
+	if p == nil {
+		return ContentPart{}
+	}
+	part := ContentPart{Kind: ContentKind(p.Kind), Text: p.Text, CacheControl: CacheHintFromProto(p.CacheControl)}
+	if p.Image != nil {
+		part.Image = &ImageData{URL: p.Image.Url, Data: p.Image.Data, MediaType: p.Image.MediaType, Detail: p.Image.Detail}
+	}
+	if p.Audio != nil {
+		part.Audio = &AudioData{URL: p.Audio.Url, Data: p.Audio.Data, MediaType: p.Audio.MediaType}
+	}
+	if p.Document != nil {
+		part.Document = &DocumentData{URL: p.Document.Url, Data: p.Document.Data, MediaType: p.Document.MediaType, FileName: p.Document.FileName}
+	}
+	if p.ToolCall != nil {
+		part.ToolCall = &ToolCallData{ID: p.ToolCall.Id, Name: p.ToolCall.Name, Arguments: json.RawMessage(p.ToolCall.Arguments), Type: p.ToolCall.Type}
+	}
+	if p.ToolResult != nil {
+		part.ToolResult = &ToolResultData{
+			ToolCallID: p.ToolResult.ToolCallId, Content: json.RawMessage(p.ToolResult.Content), IsError: p.ToolResult.IsError,
+			ImageData: p.ToolResult.ImageData, ImageMediaType: p.ToolResult.ImageMediaType,
+		}
+	}
+	if p.Thinking != nil {
+		part.Thinking = &ThinkingData{Text: p.Thinking.Text, Signature: p.Thinking.Signature, Redacted: p.Thinking.Redacted}
+	}
+	return part
+}
+
+// CacheHintFromProto converts a wire CacheHintProto into a CacheHint.
+func CacheHintFromProto(p *llmproviderpb.CacheHintProto) *CacheHint {
+	if p == nil {
+		return nil
+	}
+	return &CacheHint{Type: p.Type, TTL: time.Duration(p.TtlMs) * time.Millisecond}
+}
+
+// UsageFromProto converts a wire UsageProto into a Usage.
+func UsageFromProto(p *llmproviderpb.UsageProto) Usage {
+	if p == nil {
+		return Usage{}
+	}
+	u := Usage{InputTokens: int(p.InputTokens), OutputTokens: int(p.OutputTokens), TotalTokens: int(p.TotalTokens)}
+	u.ReasoningTokens = int32PtrToIntPtr(p.ReasoningTokens)
+	u.CacheReadTokens = int32PtrToIntPtr(p.CacheReadTokens)
+	u.CacheWriteTokens = int32PtrToIntPtr(p.CacheWriteTokens)
+	if p.Raw != nil {
+		u.Raw = p.Raw.AsMap()
+	}
+	return u
+}
+
+// UsageToProto converts a Usage into its wire representation.
+func UsageToProto(u Usage) *llmproviderpb.UsageProto {
+	p := &llmproviderpb.UsageProto{
+		InputTokens:  int32(u.InputTokens),
+		OutputTokens: int32(u.OutputTokens),
+		TotalTokens:  int32(u.TotalTokens),
+	}
+	p.ReasoningTokens = intPtrToInt32Ptr(u.ReasoningTokens)
+	p.CacheReadTokens = intPtrToInt32Ptr(u.CacheReadTokens)
+	p.CacheWriteTokens = intPtrToInt32Ptr(u.CacheWriteTokens)
+	if u.Raw != nil {
+		if raw, err := structpb.NewStruct(u.Raw); err == nil {
+			p.Raw = raw
+		}
+	}
+	return p
+}
+
+// ModelInfoToProto converts a ModelInfo into its wire representation. It's
+// used by the server half of the protocol to answer the Register RPC with
+// the backing adapter's own model catalog.
+func ModelInfoToProto(m ModelInfo) *llmproviderpb.ModelInfoProto {
+	return &llmproviderpb.ModelInfoProto{
+		Id:                   m.ID,
+		Provider:             m.Provider,
+		DisplayName:          m.DisplayName,
+		ContextWindow:        int32(m.ContextWindow),
+		MaxOutput:            intPtrToInt32Ptr(m.MaxOutput),
+		SupportsTools:        m.SupportsTools,
+		SupportsVision:       m.SupportsVision,
+		SupportsReasoning:    m.SupportsReasoning,
+		InputCostPerMillion:  m.InputCostPerMillion,
+		OutputCostPerMillion: m.OutputCostPerMillion,
+		Aliases:              m.Aliases,
+	}
+}
+
+// ModelInfoFromProto converts a wire ModelInfoProto into a ModelInfo, the
+// mirror image of ModelInfoToProto. It's used by GRPCAdapter.Initialize to
+// populate Models from the backend's Register response.
+func ModelInfoFromProto(p *llmproviderpb.ModelInfoProto) ModelInfo {
+	if p == nil {
+		return ModelInfo{}
+	}
+	return ModelInfo{
+		ID:                   p.Id,
+		Provider:             p.Provider,
+		DisplayName:          p.DisplayName,
+		ContextWindow:        int(p.ContextWindow),
+		MaxOutput:            int32PtrToIntPtr(p.MaxOutput),
+		SupportsTools:        p.SupportsTools,
+		SupportsVision:       p.SupportsVision,
+		SupportsReasoning:    p.SupportsReasoning,
+		InputCostPerMillion:  p.InputCostPerMillion,
+		OutputCostPerMillion: p.OutputCostPerMillion,
+		Aliases:              p.Aliases,
+	}
+}
+
+func int32PtrToIntPtr(p *int32) *int {
+	if p == nil {
+		return nil
+	}
+	v := int(*p)
+	return &v
+}
+
+func intPtrToInt32Ptr(p *int) *int32 {
+	if p == nil {
+		return nil
+	}
+	v := int32(*p)
+	return &v
+}
+
+// StreamEventFromProto converts a wire StreamEventProto into a StreamEvent.
+func StreamEventFromProto(p *llmproviderpb.StreamEventProto) (StreamEvent, error) {
+	event := StreamEvent{
+		Type:           StreamEventType(p.EventType),
+		Delta:          p.Delta,
+		TextID:         p.TextId,
+		ReasoningDelta: p.ReasoningDelta,
+	}
+	if p.ToolCall != nil {
+		event.ToolCall = &ToolCall{
+			ID: p.ToolCall.Id, Name: p.ToolCall.Name, Arguments: json.RawMessage(p.ToolCall.Arguments), RawArguments: p.ToolCall.RawArguments,
+		}
+	}
+	if p.FinishReason != nil {
+		event.FinishReason = &FinishReason{Reason: p.FinishReason.Reason, Raw: p.FinishReason.Raw}
+	}
+	if p.Usage != nil {
+		usage := UsageFromProto(p.Usage)
+		event.Usage = &usage
+	}
+	if p.Response != nil {
+		resp, err := ResponseFromProto(p.Response)
+		if err != nil {
+			return StreamEvent{}, err
+		}
+		event.Response = resp
+	}
+	if p.Error != "" {
+		event.Error = fmt.Errorf("%s", p.Error)
+	}
+	if p.Raw != nil {
+		event.Raw = p.Raw.AsMap()
+	}
+	return event, nil
+}
+
+// StreamEventToProto converts a StreamEvent into its wire representation,
+// the mirror image of StreamEventFromProto. It's used by the server half of
+// the protocol to relay a real ProviderAdapter's stream back to the caller.
+func StreamEventToProto(e StreamEvent) (*llmproviderpb.StreamEventProto, error) {
+	p := &llmproviderpb.StreamEventProto{
+		EventType:      string(e.Type),
+		Delta:          e.Delta,
+		TextId:         e.TextID,
+		ReasoningDelta: e.ReasoningDelta,
+	}
+	if e.ToolCall != nil {
+		p.ToolCall = &llmproviderpb.ToolCallProto{
+			Id: e.ToolCall.ID, Name: e.ToolCall.Name, Arguments: []byte(e.ToolCall.Arguments), RawArguments: e.ToolCall.RawArguments,
+		}
+	}
+	if e.FinishReason != nil {
+		p.FinishReason = &llmproviderpb.FinishReasonProto{Reason: e.FinishReason.Reason, Raw: e.FinishReason.Raw}
+	}
+	if e.Usage != nil {
+		p.Usage = UsageToProto(*e.Usage)
+	}
+	if e.Response != nil {
+		resp, err := ResponseToProto(e.Response)
+		if err != nil {
+			return nil, err
+		}
+		p.Response = resp
+	}
+	if e.Error != nil {
+		p.Error = e.Error.Error()
+	}
+	if e.Raw != nil {
+		raw, err := structpb.NewStruct(e.Raw)
+		if err != nil {
+			return nil, fmt.Errorf("raw: %w", err)
+		}
+		p.Raw = raw
+	}
+	return p, nil
+}
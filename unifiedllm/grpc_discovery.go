@@ -0,0 +1,139 @@
+package unifiedllm
+
+import (
+	"fmt"
+	"os/exec"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+
+	"gopkg.in/yaml.v3"
+)
+
+// GRPCBackendConfig declares one out-of-process LLM provider backend to
+// discover, in either of two modes:
+//
+//   - Attach: Target is already listening (a long-running gateway process,
+//     or one started outside attractor's control); DiscoverGRPCBackends
+//     just dials it.
+//   - Spawn: Command names a binary (e.g. cmd/llm-provider-server, or a
+//     third-party grpc-backend binary such as a llama.cpp/vLLM wrapper)
+//     that DiscoverGRPCBackends starts as a subprocess and then dials at
+//     Target, the way a LocalAI grpc backend is launched on demand.
+//     Command is run exactly as given; Target is not passed to it, so its
+//     arguments must make the backend listen on Target.
+//
+// A non-empty Command selects spawn over attach; Target is always
+// required, since a spawned backend is still dialed at an address.
+type GRPCBackendConfig struct {
+	Name        string        `yaml:"name"`
+	Target      string        `yaml:"target"`
+	Command     []string      `yaml:"command,omitempty"`
+	DialTimeout time.Duration `yaml:"-"`
+}
+
+// LoadGRPCBackendConfigsYAML parses a YAML document containing a list of
+// GRPCBackendConfig, the config-file counterpart to DiscoverGRPCBackends.
+func LoadGRPCBackendConfigsYAML(data []byte) ([]GRPCBackendConfig, error) {
+	var configs []GRPCBackendConfig
+	if err := yaml.Unmarshal(data, &configs); err != nil {
+		return nil, fmt.Errorf("unifiedllm: parse grpc backend config YAML: %w", err)
+	}
+	return configs, nil
+}
+
+// DiscoveredGRPCBackend pairs a config entry's name with the GRPCAdapter
+// DiscoverGRPCBackends built for it and (if spawned) the subprocess backing
+// it, so the caller can register the adapter under Name and later shut the
+// subprocess down via Close.
+type DiscoveredGRPCBackend struct {
+	Name    string
+	Adapter *GRPCAdapter
+	cmd     *exec.Cmd
+}
+
+// Close stops the backend's adapter connection and, if DiscoverGRPCBackends
+// spawned a subprocess for it, terminates that subprocess too. Attached
+// backends (no Command in config) are left running, since attractor didn't
+// start them.
+func (b *DiscoveredGRPCBackend) Close() error {
+	err := b.Adapter.Close()
+	if b.cmd != nil && b.cmd.Process != nil {
+		_ = b.cmd.Process.Kill()
+	}
+	return err
+}
+
+// DiscoverGRPCBackends dials (or spawns then dials) each configured
+// backend and performs its GRPCAdapter.Initialize handshake, returning one
+// DiscoveredGRPCBackend per config entry that came up successfully. It
+// stops and returns an error at the first backend that fails, after
+// closing any backends already discovered.
+func DiscoverGRPCBackends(configs []GRPCBackendConfig) ([]*DiscoveredGRPCBackend, error) {
+	discovered := make([]*DiscoveredGRPCBackend, 0, len(configs))
+
+	closeAll := func() {
+		for _, b := range discovered {
+			_ = b.Close()
+		}
+	}
+
+	for _, cfg := range configs {
+		b, err := discoverOne(cfg)
+		if err != nil {
+			closeAll()
+			return nil, fmt.Errorf("unifiedllm: discover grpc backend %q: %w", cfg.Name, err)
+		}
+		discovered = append(discovered, b)
+	}
+	return discovered, nil
+}
+
+func discoverOne(cfg GRPCBackendConfig) (*DiscoveredGRPCBackend, error) {
+	if cfg.Target == "" {
+		return nil, fmt.Errorf("target is required")
+	}
+
+	var cmd *exec.Cmd
+	if len(cfg.Command) > 0 {
+		cmd = exec.Command(cfg.Command[0], cfg.Command[1:]...)
+		if err := cmd.Start(); err != nil {
+			return nil, fmt.Errorf("spawn %v: %w", cfg.Command, err)
+		}
+	}
+
+	timeout := cfg.DialTimeout
+	if timeout == 0 {
+		timeout = 10 * time.Second
+	}
+
+	adapter := NewGRPCAdapter(cfg.Target, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err := waitForInitialize(adapter, timeout); err != nil {
+		if cmd != nil && cmd.Process != nil {
+			_ = cmd.Process.Kill()
+		}
+		return nil, err
+	}
+
+	return &DiscoveredGRPCBackend{Name: cfg.Name, Adapter: adapter, cmd: cmd}, nil
+}
+
+// waitForInitialize retries adapter.Initialize until it succeeds or
+// timeout elapses, since a just-spawned backend's listener may not be
+// accepting connections yet.
+func waitForInitialize(adapter *GRPCAdapter, timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	var lastErr error
+	for {
+		if err := adapter.Initialize(); err == nil {
+			return nil
+		} else {
+			lastErr = err
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("timed out waiting for backend to come up: %w", lastErr)
+		}
+		time.Sleep(200 * time.Millisecond)
+	}
+}
@@ -0,0 +1,66 @@
+package unifiedllm
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// ReattachEnvVar is the environment variable NewClient checks for
+// already-running provider plugin processes to connect to instead of
+// instantiating a GollmAdapter in-process, mirroring Terraform's
+// TF_REATTACH_PROVIDERS. Its value is a JSON object keyed by provider name:
+//
+//	{"openai": {"target": "unix:/tmp/openai-provider.sock"}}
+//
+// Each target is dialed with NewGRPCAdapter and must already be listening,
+// serving the LLMProvider gRPC service (see GRPCServer/Serve, or the
+// unifiedllm/plugin package) -- NewClient never spawns a process for a
+// ReattachEnvVar entry, unlike DiscoverGRPCBackends with a Command set.
+const ReattachEnvVar = "UNIFIEDLLM_REATTACH_PROVIDERS"
+
+// ReattachTarget is one entry in ReattachEnvVar's JSON object.
+type ReattachTarget struct {
+	Target string `json:"target"`
+}
+
+// ParseReattachProviders parses ReattachEnvVar's JSON format into a
+// provider name -> ReattachTarget map. An empty raw returns a nil map and
+// no error.
+func ParseReattachProviders(raw string) (map[string]ReattachTarget, error) {
+	if raw == "" {
+		return nil, nil
+	}
+	var targets map[string]ReattachTarget
+	if err := json.Unmarshal([]byte(raw), &targets); err != nil {
+		return nil, fmt.Errorf("unifiedllm: parse %s: %w", ReattachEnvVar, err)
+	}
+	return targets, nil
+}
+
+// registerReattachProviders dials and registers every provider named in
+// ReattachEnvVar, if set. Like NewClientFromEnv silently skipping a
+// provider whose API key env var is unset, a target that fails to dial or
+// complete the Initialize handshake is skipped rather than failing
+// NewClient outright -- there's no error return this deep in Client
+// construction to report it through.
+func (c *Client) registerReattachProviders() {
+	raw := os.Getenv(ReattachEnvVar)
+	if raw == "" {
+		return
+	}
+	targets, err := ParseReattachProviders(raw)
+	if err != nil {
+		return
+	}
+	for name, target := range targets {
+		adapter := NewGRPCAdapter(target.Target, grpc.WithTransportCredentials(insecure.NewCredentials()))
+		if err := adapter.Initialize(); err != nil {
+			continue
+		}
+		c.RegisterProvider(name, adapter)
+	}
+}
@@ -0,0 +1,32 @@
+package unifiedllm
+
+import "testing"
+
+func TestParseReattachProvidersEmpty(t *testing.T) {
+	targets, err := ParseReattachProviders("")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if targets != nil {
+		t.Errorf("expected nil map for empty input, got %v", targets)
+	}
+}
+
+func TestParseReattachProviders(t *testing.T) {
+	targets, err := ParseReattachProviders(`{"openai": {"target": "unix:/tmp/openai.sock"}, "anthropic": {"target": "localhost:9090"}}`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got, want := targets["openai"].Target, "unix:/tmp/openai.sock"; got != want {
+		t.Errorf("openai target: expected %q, got %q", want, got)
+	}
+	if got, want := targets["anthropic"].Target, "localhost:9090"; got != want {
+		t.Errorf("anthropic target: expected %q, got %q", want, got)
+	}
+}
+
+func TestParseReattachProvidersInvalidJSON(t *testing.T) {
+	if _, err := ParseReattachProviders("not json"); err == nil {
+		t.Fatal("expected error for invalid JSON")
+	}
+}
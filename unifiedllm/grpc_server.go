@@ -0,0 +1,170 @@
+package unifiedllm
+
+import (
+	"context"
+	"net"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/martinemde/attractor/unifiedllm/llmproviderpb"
+)
+
+// GRPCServer exposes a real ProviderAdapter over the LLMProvider gRPC
+// service (see proto/llm_provider.proto), the server half of the protocol
+// GRPCAdapter dials. It's the reusable counterpart to GRPCAdapter: any Go
+// ProviderAdapter can be wrapped in a GRPCServer and exposed as a
+// subprocess or standalone gateway with Serve, without each backend having
+// to reimplement the wire translation cmd/llm-provider-server used to do
+// inline.
+type GRPCServer struct {
+	llmproviderpb.UnimplementedLLMProviderServer
+	Adapter ProviderAdapter
+}
+
+// Serve registers adapter on a new grpc.Server and blocks serving lis until
+// the server stops or returns an error. Callers that need more control
+// over the grpc.Server (reflection, interceptors, TLS) should construct one
+// themselves and call RegisterLLMProviderServer with a *GRPCServer instead.
+func Serve(lis net.Listener, adapter ProviderAdapter, opts ...grpc.ServerOption) error {
+	srv := grpc.NewServer(opts...)
+	llmproviderpb.RegisterLLMProviderServer(srv, &GRPCServer{Adapter: adapter})
+	return srv.Serve(lis)
+}
+
+// Name implements the Name RPC.
+func (s *GRPCServer) Name(ctx context.Context, req *llmproviderpb.NameRequest) (*llmproviderpb.NameResponse, error) {
+	return &llmproviderpb.NameResponse{Name: s.Adapter.Name()}, nil
+}
+
+// Register implements the Register RPC, reporting the adapter's model
+// catalog if it implements ModelLister, or none if it doesn't.
+func (s *GRPCServer) Register(ctx context.Context, req *llmproviderpb.RegisterRequest) (*llmproviderpb.RegisterResponse, error) {
+	lister, ok := s.Adapter.(ModelLister)
+	if !ok {
+		return &llmproviderpb.RegisterResponse{}, nil
+	}
+	models := lister.Models()
+	resp := &llmproviderpb.RegisterResponse{Models: make([]*llmproviderpb.ModelInfoProto, len(models))}
+	for i, m := range models {
+		resp.Models[i] = ModelInfoToProto(m)
+	}
+	return resp, nil
+}
+
+// SupportsToolChoice implements the SupportsToolChoice RPC, deferring to
+// the adapter if it implements ToolChoiceSupporter, or reporting false if
+// it doesn't.
+func (s *GRPCServer) SupportsToolChoice(ctx context.Context, req *llmproviderpb.SupportsToolChoiceRequest) (*llmproviderpb.SupportsToolChoiceResponse, error) {
+	supporter, ok := s.Adapter.(ToolChoiceSupporter)
+	if !ok {
+		return &llmproviderpb.SupportsToolChoiceResponse{Supported: false}, nil
+	}
+	return &llmproviderpb.SupportsToolChoiceResponse{Supported: supporter.SupportsToolChoice(req.Mode)}, nil
+}
+
+// Complete implements the Complete RPC.
+func (s *GRPCServer) Complete(ctx context.Context, req *llmproviderpb.CompleteRequest) (*llmproviderpb.CompleteResponse, error) {
+	domainReq, err := RequestFromProto(req.Request)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := s.Adapter.Complete(ctx, domainReq)
+	if err != nil {
+		return nil, err
+	}
+
+	proto, err := ResponseToProto(resp)
+	if err != nil {
+		return nil, err
+	}
+	return &llmproviderpb.CompleteResponse{Response: proto}, nil
+}
+
+// Stream implements the Stream RPC.
+func (s *GRPCServer) Stream(req *llmproviderpb.CompleteRequest, stream llmproviderpb.LLMProvider_StreamServer) error {
+	domainReq, err := RequestFromProto(req.Request)
+	if err != nil {
+		return err
+	}
+
+	events, err := s.Adapter.Stream(stream.Context(), domainReq)
+	if err != nil {
+		return err
+	}
+
+	for event := range events {
+		proto, err := StreamEventToProto(event)
+		if err != nil {
+			return err
+		}
+		if err := stream.Send(proto); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CountTokens implements the CountTokens RPC, deferring to the adapter if
+// it implements TokenCounter, or reporting Unimplemented if it doesn't.
+func (s *GRPCServer) CountTokens(ctx context.Context, req *llmproviderpb.CountTokensRequest) (*llmproviderpb.CountTokensResponse, error) {
+	counter, ok := s.Adapter.(TokenCounter)
+	if !ok {
+		return nil, status.Error(codes.Unimplemented, "grpc_server: adapter does not implement TokenCounter")
+	}
+
+	domainReq, err := RequestFromProto(req.Request)
+	if err != nil {
+		return nil, err
+	}
+
+	count, err := counter.CountTokens(ctx, domainReq)
+	if err != nil {
+		return nil, err
+	}
+	return &llmproviderpb.CountTokensResponse{TokenCount: int32(count)}, nil
+}
+
+// Embed implements the Embed RPC, deferring to the adapter if it implements
+// Embedder, or reporting Unimplemented if it doesn't.
+func (s *GRPCServer) Embed(ctx context.Context, req *llmproviderpb.EmbedRequest) (*llmproviderpb.EmbedResponse, error) {
+	embedder, ok := s.Adapter.(Embedder)
+	if !ok {
+		return nil, status.Error(codes.Unimplemented, "grpc_server: adapter does not implement Embedder")
+	}
+
+	resp, err := embedder.Embed(ctx, EmbedRequest{Model: req.Model, Input: req.Input})
+	if err != nil {
+		return nil, err
+	}
+
+	embeddings := make([]*llmproviderpb.EmbeddingProto, len(resp.Embeddings))
+	for i, e := range resp.Embeddings {
+		embeddings[i] = &llmproviderpb.EmbeddingProto{Values: e}
+	}
+	return &llmproviderpb.EmbedResponse{Embeddings: embeddings, Usage: UsageToProto(resp.Usage)}, nil
+}
+
+// ListModels implements the ListModels RPC, deferring to the adapter if it
+// implements ModelRefresher, or falling back to ModelLister's static
+// catalog if it only implements that.
+func (s *GRPCServer) ListModels(ctx context.Context, req *llmproviderpb.ListModelsRequest) (*llmproviderpb.ListModelsResponse, error) {
+	var models []ModelInfo
+	if refresher, ok := s.Adapter.(ModelRefresher); ok {
+		var err error
+		models, err = refresher.ListModels(ctx)
+		if err != nil {
+			return nil, err
+		}
+	} else if lister, ok := s.Adapter.(ModelLister); ok {
+		models = lister.Models()
+	}
+
+	resp := &llmproviderpb.ListModelsResponse{Models: make([]*llmproviderpb.ModelInfoProto, len(models))}
+	for i, m := range models {
+		resp.Models[i] = ModelInfoToProto(m)
+	}
+	return resp, nil
+}
@@ -0,0 +1,11 @@
+// Package llmproviderpb holds the generated client/server bindings for
+// proto/llm_provider.proto (protoc-gen-go + protoc-gen-go-grpc). Regenerate
+// with:
+//
+//	protoc --go_out=. --go-grpc_out=. proto/llm_provider.proto
+//
+// The generated llm_provider.pb.go and llm_provider_grpc.pb.go files are not
+// checked in to this tree; unifiedllm/grpc_adapter.go and
+// cmd/llm-provider-server/main.go both depend on the types and
+// client/server interfaces protoc produces from the service definition.
+package llmproviderpb
@@ -0,0 +1,147 @@
+package unifiedllm
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// correlationIDKey is the context key LoggingMiddleware and
+// StreamLoggingMiddleware use to propagate a correlation ID across a
+// middleware chain. Use ContextWithCorrelationID to seed one explicitly
+// (e.g. from an inbound HTTP handler) before calling Client.Complete/Stream.
+type correlationIDKey struct{}
+
+// ContextWithCorrelationID attaches id to ctx so LoggingMiddleware and
+// StreamLoggingMiddleware use it instead of minting a new one.
+func ContextWithCorrelationID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, correlationIDKey{}, id)
+}
+
+// CorrelationIDFromContext returns the correlation ID attached to ctx, if any.
+func CorrelationIDFromContext(ctx context.Context) (string, bool) {
+	id, ok := ctx.Value(correlationIDKey{}).(string)
+	return id, ok
+}
+
+// correlationID resolves the ID LoggingMiddleware/StreamLoggingMiddleware
+// should use for ctx: an explicit ContextWithCorrelationID value first (an
+// inbound HTTP handler should seed this from its X-Request-ID header), then
+// the current OpenTelemetry span's trace ID, and finally a freshly minted
+// UUID.
+func correlationID(ctx context.Context) string {
+	if id, ok := CorrelationIDFromContext(ctx); ok && id != "" {
+		return id
+	}
+	if sc := trace.SpanContextFromContext(ctx); sc.HasTraceID() {
+		return sc.TraceID().String()
+	}
+	return uuid.New().String()
+}
+
+// LoggingMiddleware returns a Middleware that logs request start, finish
+// reason, token usage, tool-call dispatch, and errors to logger as
+// structured events, all tagged with a correlation_id field. The same ID is
+// propagated downstream two ways: attached to ctx (so a nested
+// LoggingMiddleware reuses it instead of minting a new one) and written to
+// req.Metadata["correlation_id"] (so the provider adapter, and anything it
+// logs on the far side of the wire, sees the same ID).
+func LoggingMiddleware(logger *slog.Logger) Middleware {
+	return func(ctx context.Context, req Request, next func(context.Context, Request) (*Response, error)) (*Response, error) {
+		id := correlationID(ctx)
+		ctx = ContextWithCorrelationID(ctx, id)
+		req = withCorrelationMetadata(req, id)
+
+		log := logger.With("correlation_id", id)
+		start := time.Now()
+		log.Info("llm request start", "provider", req.Provider, "model", req.Model, "messages", len(req.Messages))
+
+		resp, err := next(ctx, req)
+		if err != nil {
+			log.Error("llm request error", "error", err, "duration", time.Since(start))
+			return nil, err
+		}
+
+		log.Info("llm request finish",
+			"finish_reason", resp.FinishReason.Reason,
+			"input_tokens", resp.Usage.InputTokens,
+			"output_tokens", resp.Usage.OutputTokens,
+			"duration", time.Since(start),
+		)
+		for _, call := range resp.ToolCallsFromResponse() {
+			log.Info("llm tool call dispatched", "tool", call.Name, "tool_call_id", call.ID)
+		}
+		return resp, nil
+	}
+}
+
+// StreamLoggingMiddleware is LoggingMiddleware's streaming
+// counterpart: it logs a checkpoint for each StreamEvent as it passes
+// through, plus a summary finish event once the final "finish" event
+// arrives, all tagged with the same correlation_id propagation
+// LoggingMiddleware uses.
+func StreamLoggingMiddleware(logger *slog.Logger) StreamMiddleware {
+	return func(ctx context.Context, req Request, next func(context.Context, Request) (<-chan StreamEvent, error)) (<-chan StreamEvent, error) {
+		id := correlationID(ctx)
+		ctx = ContextWithCorrelationID(ctx, id)
+		req = withCorrelationMetadata(req, id)
+
+		log := logger.With("correlation_id", id)
+		log.Info("llm stream start", "provider", req.Provider, "model", req.Model, "messages", len(req.Messages))
+
+		events, err := next(ctx, req)
+		if err != nil {
+			log.Error("llm stream error", "error", err)
+			return nil, err
+		}
+
+		out := make(chan StreamEvent)
+		go func() {
+			defer close(out)
+			for event := range events {
+				logStreamEvent(log, event)
+				out <- event
+			}
+		}()
+		return out, nil
+	}
+}
+
+func logStreamEvent(log *slog.Logger, event StreamEvent) {
+	switch event.Type {
+	case ToolCallEnd:
+		fields := []any{"event", string(event.Type)}
+		if event.ToolCall != nil {
+			fields = append(fields, "tool", event.ToolCall.Name, "tool_call_id", event.ToolCall.ID)
+		}
+		log.Info("llm tool call dispatched", fields...)
+	case StreamFinish:
+		fields := []any{"event", string(event.Type)}
+		if event.FinishReason != nil {
+			fields = append(fields, "finish_reason", event.FinishReason.Reason)
+		}
+		if event.Usage != nil {
+			fields = append(fields, "input_tokens", event.Usage.InputTokens, "output_tokens", event.Usage.OutputTokens)
+		}
+		log.Info("llm stream finish", fields...)
+	case StreamError:
+		log.Error("llm stream error", "event", string(event.Type), "error", event.Error)
+	default:
+		log.Debug("llm stream checkpoint", "event", string(event.Type), "text_id", event.TextID)
+	}
+}
+
+// withCorrelationMetadata returns a copy of req with Metadata["correlation_id"]
+// set to id, leaving the rest of req.Metadata intact.
+func withCorrelationMetadata(req Request, id string) Request {
+	metadata := make(map[string]string, len(req.Metadata)+1)
+	for k, v := range req.Metadata {
+		metadata[k] = v
+	}
+	metadata["correlation_id"] = id
+	req.Metadata = metadata
+	return req
+}
@@ -0,0 +1,80 @@
+package unifiedllm
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestLoggingMiddlewareAttachesCorrelationID(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, nil))
+	mw := LoggingMiddleware(logger)
+
+	var gotMetadata map[string]string
+	_, err := mw(context.Background(), Request{Model: "m"}, func(ctx context.Context, r Request) (*Response, error) {
+		gotMetadata = r.Metadata
+		return &Response{}, nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	id, ok := gotMetadata["correlation_id"]
+	if !ok || id == "" {
+		t.Fatalf("expected a minted correlation_id in request metadata, got %v", gotMetadata)
+	}
+	if !strings.Contains(buf.String(), id) {
+		t.Errorf("expected log output to contain correlation_id %q, got %q", id, buf.String())
+	}
+}
+
+func TestLoggingMiddlewarePropagatesExplicitCorrelationID(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, nil))
+	mw := LoggingMiddleware(logger)
+
+	ctx := ContextWithCorrelationID(context.Background(), "req-123")
+	var gotMetadata map[string]string
+	_, err := mw(ctx, Request{}, func(ctx context.Context, r Request) (*Response, error) {
+		gotMetadata = r.Metadata
+		return &Response{}, nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if gotMetadata["correlation_id"] != "req-123" {
+		t.Errorf("expected correlation_id %q propagated into metadata, got %q", "req-123", gotMetadata["correlation_id"])
+	}
+}
+
+func TestStreamLoggingMiddlewareForwardsEvents(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, nil))
+	mw := StreamLoggingMiddleware(logger)
+
+	in := make(chan StreamEvent, 2)
+	in <- StreamEvent{Type: TextDelta, Delta: "hi"}
+	in <- StreamEvent{Type: StreamFinish, FinishReason: &FinishReason{Reason: "stop"}}
+	close(in)
+
+	out, err := mw(context.Background(), Request{}, func(ctx context.Context, r Request) (<-chan StreamEvent, error) {
+		return in, nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var events []StreamEvent
+	for event := range out {
+		events = append(events, event)
+	}
+	if len(events) != 2 {
+		t.Fatalf("expected 2 forwarded events, got %d", len(events))
+	}
+	if !strings.Contains(buf.String(), "llm stream finish") {
+		t.Errorf("expected a finish checkpoint logged, got %q", buf.String())
+	}
+}
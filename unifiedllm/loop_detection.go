@@ -0,0 +1,65 @@
+package unifiedllm
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+)
+
+// ToolCallSignature computes a deterministic signature for a tool call
+// (name + hash of arguments), used to compare tool calls for equality
+// without matching on argument key order or whitespace.
+func ToolCallSignature(name string, arguments json.RawMessage) string {
+	h := sha256.Sum256(arguments)
+	return fmt.Sprintf("%s:%x", name, h[:8])
+}
+
+// DetectToolCallLoop checks whether the last windowSize entries of sigs
+// follow a repeating pattern of length 1, 2, or 3, returning the pattern
+// length on detection. It is the shared primitive behind
+// GenerateOptions.LoopDetectionWindow and agentloop.DetectLoop's
+// turn-history loop detection, so a model oscillating between the same
+// tool calls is caught the same way whether driven through Generate
+// directly or through an agentloop.Session.
+func DetectToolCallLoop(sigs []string, windowSize int) (detected bool, patternLen int) {
+	if windowSize <= 0 || len(sigs) < windowSize {
+		return false, 0
+	}
+	window := sigs[len(sigs)-windowSize:]
+
+	for patternLen := 1; patternLen <= 3; patternLen++ {
+		if windowSize%patternLen != 0 {
+			continue
+		}
+		pattern := window[:patternLen]
+		allMatch := true
+		for i := patternLen; i < windowSize; i += patternLen {
+			for j := 0; j < patternLen; j++ {
+				if window[i+j] != pattern[j] {
+					allMatch = false
+					break
+				}
+			}
+			if !allMatch {
+				break
+			}
+		}
+		if allMatch {
+			return true, patternLen
+		}
+	}
+
+	return false, 0
+}
+
+// toolCallSignaturesFromSteps flattens the tool-call signatures across
+// steps, in chronological order, for DetectToolCallLoop.
+func toolCallSignaturesFromSteps(steps []StepResult) []string {
+	var sigs []string
+	for _, step := range steps {
+		for _, tc := range step.ToolCalls {
+			sigs = append(sigs, ToolCallSignature(tc.Name, tc.Arguments))
+		}
+	}
+	return sigs
+}
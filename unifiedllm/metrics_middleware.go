@@ -0,0 +1,313 @@
+package unifiedllm
+
+import (
+	"context"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// MetricsOption configures a Metrics collector.
+type MetricsOption func(*Metrics)
+
+// WithPrometheusRegisterer registers the collector's Prometheus metrics with
+// reg. Equivalent to calling RegisterMetrics(reg, m) after NewMetrics.
+func WithPrometheusRegisterer(reg prometheus.Registerer) MetricsOption {
+	return func(m *Metrics) { m.registerer = reg }
+}
+
+// WithMeter additionally records every observation to an OpenTelemetry
+// meter, for callers whose metrics pipeline is otel-native rather than
+// Prometheus-native. Both may be configured at once; each gets its own set
+// of instruments fed from the same observations.
+func WithMeter(meter metric.Meter) MetricsOption {
+	return func(m *Metrics) { m.meter = meter }
+}
+
+// Metrics holds the per-provider, per-model instruments MetricsMiddleware
+// and StreamMetricsMiddleware record to: request and error counts, total
+// latency, time-to-first-stream-event, inter-chunk latency, token usage,
+// estimated cost (from the model catalog's per-million pricing), and
+// retries (from Response.RetryCount, which only RetryMiddleware sets).
+// Construct with
+// NewMetrics, wire the Prometheus half into your own /metrics handler with
+// RegisterMetrics if you didn't pass WithPrometheusRegisterer.
+type Metrics struct {
+	registerer prometheus.Registerer
+	meter      metric.Meter
+
+	requests *prometheus.CounterVec
+	errors   *prometheus.CounterVec
+	latency  *prometheus.HistogramVec
+	ttft     *prometheus.HistogramVec
+	tokens   *prometheus.CounterVec
+	cost     *prometheus.CounterVec
+	retries  *prometheus.CounterVec
+	chunkGap *prometheus.HistogramVec
+
+	routerFallbacks *prometheus.CounterVec
+
+	otelRequests metric.Int64Counter
+	otelErrors   metric.Int64Counter
+	otelLatency  metric.Float64Histogram
+	otelTTFT     metric.Float64Histogram
+	otelTokens   metric.Int64Counter
+	otelCost     metric.Float64Counter
+	otelRetries  metric.Int64Counter
+	otelChunkGap metric.Float64Histogram
+
+	otelRouterFallbacks metric.Int64Counter
+}
+
+// NewMetrics creates a Metrics collector. Pass WithPrometheusRegisterer
+// and/or WithMeter to wire it into a real metrics backend; with neither, the
+// returned collector is inert (useful in tests).
+func NewMetrics(opts ...MetricsOption) *Metrics {
+	m := &Metrics{
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "unifiedllm_requests_total", Help: "Total LLM requests by provider and model.",
+		}, []string{"provider", "model"}),
+		errors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "unifiedllm_errors_total", Help: "Total LLM request errors by provider, model, and error type.",
+		}, []string{"provider", "model", "error_type"}),
+		latency: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name: "unifiedllm_request_duration_seconds", Help: "Total request latency by provider and model.",
+		}, []string{"provider", "model"}),
+		ttft: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name: "unifiedllm_stream_ttft_seconds", Help: "Time to first stream event by provider and model.",
+		}, []string{"provider", "model"}),
+		tokens: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "unifiedllm_tokens_total", Help: "Tokens consumed by provider, model, and kind.",
+		}, []string{"provider", "model", "kind"}),
+		cost: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "unifiedllm_estimated_cost_usd_total", Help: "Estimated cost in USD by provider and model.",
+		}, []string{"provider", "model"}),
+		retries: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "unifiedllm_retries_total", Help: "Total retries performed before a request succeeded, by provider and model.",
+		}, []string{"provider", "model"}),
+		chunkGap: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name: "unifiedllm_stream_chunk_latency_seconds", Help: "Time between consecutive stream events, by provider and model.",
+		}, []string{"provider", "model"}),
+		routerFallbacks: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "unifiedllm_router_fallbacks_total", Help: "Total Router fallbacks to the next candidate model, by the provider and model that failed.",
+		}, []string{"provider", "model"}),
+	}
+	for _, opt := range opts {
+		opt(m)
+	}
+
+	if m.registerer != nil {
+		RegisterMetrics(m.registerer, m)
+	}
+	if m.meter != nil {
+		m.otelRequests, _ = m.meter.Int64Counter("unifiedllm.requests", metric.WithDescription("Total LLM requests."))
+		m.otelErrors, _ = m.meter.Int64Counter("unifiedllm.errors", metric.WithDescription("Total LLM request errors."))
+		m.otelLatency, _ = m.meter.Float64Histogram("unifiedllm.request.duration", metric.WithUnit("s"))
+		m.otelTTFT, _ = m.meter.Float64Histogram("unifiedllm.stream.ttft", metric.WithUnit("s"))
+		m.otelTokens, _ = m.meter.Int64Counter("unifiedllm.tokens", metric.WithDescription("Tokens consumed."))
+		m.otelCost, _ = m.meter.Float64Counter("unifiedllm.estimated_cost_usd", metric.WithUnit("USD"))
+		m.otelRetries, _ = m.meter.Int64Counter("unifiedllm.retries", metric.WithDescription("Retries performed before a request succeeded."))
+		m.otelChunkGap, _ = m.meter.Float64Histogram("unifiedllm.stream.chunk_latency", metric.WithUnit("s"))
+		m.otelRouterFallbacks, _ = m.meter.Int64Counter("unifiedllm.router.fallbacks", metric.WithDescription("Router fallbacks to the next candidate model."))
+	}
+	return m
+}
+
+// RegisterMetrics registers m's Prometheus collectors with reg. Safe to
+// call once per (reg, m) pair; a second registration on the same reg
+// returns the AlreadyRegisteredError a prometheus.Registerer normally would.
+func RegisterMetrics(reg prometheus.Registerer, m *Metrics) error {
+	for _, c := range []prometheus.Collector{m.requests, m.errors, m.latency, m.ttft, m.tokens, m.cost, m.retries, m.chunkGap, m.routerFallbacks} {
+		if err := reg.Register(c); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *Metrics) recordRequest(provider, model string) {
+	m.requests.WithLabelValues(provider, model).Inc()
+	if m.otelRequests != nil {
+		m.otelRequests.Add(context.Background(), 1, metric.WithAttributes(
+			attribute.String("provider", provider), attribute.String("model", model)))
+	}
+}
+
+func (m *Metrics) recordError(provider, model string, err error) {
+	errType := errorTypeName(err)
+	if errType == "" {
+		errType = "unknown"
+	}
+	m.errors.WithLabelValues(provider, model, errType).Inc()
+	if m.otelErrors != nil {
+		m.otelErrors.Add(context.Background(), 1, metric.WithAttributes(
+			attribute.String("provider", provider), attribute.String("model", model), attribute.String("error_type", errType)))
+	}
+}
+
+func (m *Metrics) recordLatency(provider, model string, d time.Duration) {
+	m.latency.WithLabelValues(provider, model).Observe(d.Seconds())
+	if m.otelLatency != nil {
+		m.otelLatency.Record(context.Background(), d.Seconds(), metric.WithAttributes(
+			attribute.String("provider", provider), attribute.String("model", model)))
+	}
+}
+
+func (m *Metrics) recordTTFT(provider, model string, d time.Duration) {
+	m.ttft.WithLabelValues(provider, model).Observe(d.Seconds())
+	if m.otelTTFT != nil {
+		m.otelTTFT.Record(context.Background(), d.Seconds(), metric.WithAttributes(
+			attribute.String("provider", provider), attribute.String("model", model)))
+	}
+}
+
+func (m *Metrics) recordTokens(provider, model, kind string, n int) {
+	if n == 0 {
+		return
+	}
+	m.tokens.WithLabelValues(provider, model, kind).Add(float64(n))
+	if m.otelTokens != nil {
+		m.otelTokens.Add(context.Background(), int64(n), metric.WithAttributes(
+			attribute.String("provider", provider), attribute.String("model", model), attribute.String("kind", kind)))
+	}
+}
+
+func (m *Metrics) recordUsage(provider, model string, usage Usage) {
+	m.recordTokens(provider, model, "input", usage.InputTokens)
+	m.recordTokens(provider, model, "output", usage.OutputTokens)
+	if usage.ReasoningTokens != nil {
+		m.recordTokens(provider, model, "reasoning", *usage.ReasoningTokens)
+	}
+	if usage.CacheReadTokens != nil {
+		m.recordTokens(provider, model, "cache_read", *usage.CacheReadTokens)
+	}
+	if usage.CacheWriteTokens != nil {
+		m.recordTokens(provider, model, "cache_write", *usage.CacheWriteTokens)
+	}
+
+	cost := estimatedCostUSD(model, usage)
+	if cost == 0 {
+		return
+	}
+	m.cost.WithLabelValues(provider, model).Add(cost)
+	if m.otelCost != nil {
+		m.otelCost.Add(context.Background(), cost, metric.WithAttributes(
+			attribute.String("provider", provider), attribute.String("model", model)))
+	}
+}
+
+func (m *Metrics) recordRetries(provider, model string, n int) {
+	if n == 0 {
+		return
+	}
+	m.retries.WithLabelValues(provider, model).Add(float64(n))
+	if m.otelRetries != nil {
+		m.otelRetries.Add(context.Background(), int64(n), metric.WithAttributes(
+			attribute.String("provider", provider), attribute.String("model", model)))
+	}
+}
+
+func (m *Metrics) recordChunkGap(provider, model string, d time.Duration) {
+	m.chunkGap.WithLabelValues(provider, model).Observe(d.Seconds())
+	if m.otelChunkGap != nil {
+		m.otelChunkGap.Record(context.Background(), d.Seconds(), metric.WithAttributes(
+			attribute.String("provider", provider), attribute.String("model", model)))
+	}
+}
+
+// recordRouterFallback records that Router.CompleteWithFallback gave up on
+// provider/model (a rate-limit or context-length error) and moved on to its
+// next-best candidate.
+func (m *Metrics) recordRouterFallback(provider, model string) {
+	m.routerFallbacks.WithLabelValues(provider, model).Inc()
+	if m.otelRouterFallbacks != nil {
+		m.otelRouterFallbacks.Add(context.Background(), 1, metric.WithAttributes(
+			attribute.String("provider", provider), attribute.String("model", model)))
+	}
+}
+
+// estimatedCostUSD estimates the dollar cost of usage against model's
+// catalog pricing. Unknown models and models with no published pricing
+// estimate to 0.
+func estimatedCostUSD(model string, usage Usage) float64 {
+	info := GetModelInfo(model)
+	if info == nil {
+		return 0
+	}
+	var cost float64
+	if info.InputCostPerMillion != nil {
+		cost += float64(usage.InputTokens) / 1_000_000 * *info.InputCostPerMillion
+	}
+	if info.OutputCostPerMillion != nil {
+		cost += float64(usage.OutputTokens) / 1_000_000 * *info.OutputCostPerMillion
+	}
+	return cost
+}
+
+// MetricsMiddleware returns a Middleware that records request count, error
+// count (by SDKError subtype), total latency, token usage, estimated
+// cost, and retry count to m, labeled by provider and model.
+func MetricsMiddleware(m *Metrics) Middleware {
+	return func(ctx context.Context, req Request, next func(context.Context, Request) (*Response, error)) (*Response, error) {
+		start := time.Now()
+		m.recordRequest(req.Provider, req.Model)
+
+		resp, err := next(ctx, req)
+		m.recordLatency(req.Provider, req.Model, time.Since(start))
+		if err != nil {
+			m.recordError(req.Provider, req.Model, err)
+			return nil, err
+		}
+
+		m.recordUsage(req.Provider, req.Model, resp.Usage)
+		m.recordRetries(req.Provider, req.Model, resp.RetryCount)
+		return resp, nil
+	}
+}
+
+// StreamMetricsMiddleware is MetricsMiddleware's streaming counterpart. It
+// observes TTFT (time to the first StreamEvent), the latency between each
+// subsequent pair of events, and total duration without buffering events,
+// and records token usage and estimated cost from the final "finish"
+// event's Usage once the stream completes.
+func StreamMetricsMiddleware(m *Metrics) StreamMiddleware {
+	return func(ctx context.Context, req Request, next func(context.Context, Request) (<-chan StreamEvent, error)) (<-chan StreamEvent, error) {
+		start := time.Now()
+		m.recordRequest(req.Provider, req.Model)
+
+		events, err := next(ctx, req)
+		if err != nil {
+			m.recordLatency(req.Provider, req.Model, time.Since(start))
+			m.recordError(req.Provider, req.Model, err)
+			return nil, err
+		}
+
+		out := make(chan StreamEvent)
+		go func() {
+			defer close(out)
+			first := true
+			lastEvent := start
+			for event := range events {
+				now := time.Now()
+				if first {
+					m.recordTTFT(req.Provider, req.Model, now.Sub(start))
+					first = false
+				} else {
+					m.recordChunkGap(req.Provider, req.Model, now.Sub(lastEvent))
+				}
+				lastEvent = now
+				if event.Type == StreamError {
+					m.recordError(req.Provider, req.Model, event.Error)
+				}
+				if event.Type == StreamFinish && event.Usage != nil {
+					m.recordUsage(req.Provider, req.Model, *event.Usage)
+				}
+				out <- event
+			}
+			m.recordLatency(req.Provider, req.Model, time.Since(start))
+		}()
+		return out, nil
+	}
+}
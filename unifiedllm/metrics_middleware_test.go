@@ -0,0 +1,129 @@
+package unifiedllm
+
+import (
+	"context"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+func TestEstimatedCostUSD(t *testing.T) {
+	cost := estimatedCostUSD("claude-opus-4-6", Usage{InputTokens: 1_000_000, OutputTokens: 1_000_000})
+	if cost != 90.0 {
+		t.Errorf("expected $90 (15 input + 75 output per million), got %v", cost)
+	}
+}
+
+func TestEstimatedCostUSDUnknownModel(t *testing.T) {
+	if cost := estimatedCostUSD("not-a-real-model", Usage{InputTokens: 1000}); cost != 0 {
+		t.Errorf("expected 0 for an unknown model, got %v", cost)
+	}
+}
+
+func TestMetricsMiddlewareRecordsRequestAndUsage(t *testing.T) {
+	m := NewMetrics()
+	mw := MetricsMiddleware(m)
+
+	_, err := mw(context.Background(), Request{Provider: "anthropic", Model: "claude-opus-4-6"},
+		func(ctx context.Context, r Request) (*Response, error) {
+			return &Response{Usage: Usage{InputTokens: 100, OutputTokens: 50}}, nil
+		})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var metric dto.Metric
+	if err := m.requests.WithLabelValues("anthropic", "claude-opus-4-6").Write(&metric); err != nil {
+		t.Fatalf("write metric: %v", err)
+	}
+	if metric.GetCounter().GetValue() != 1 {
+		t.Errorf("expected 1 request recorded, got %v", metric.GetCounter().GetValue())
+	}
+}
+
+func TestMetricsMiddlewareRecordsErrors(t *testing.T) {
+	m := NewMetrics()
+	mw := MetricsMiddleware(m)
+
+	_, err := mw(context.Background(), Request{Provider: "anthropic", Model: "claude-opus-4-6"},
+		func(ctx context.Context, r Request) (*Response, error) {
+			return nil, &RateLimitError{ProviderError: ProviderError{SDKError: SDKError{Message: "slow down"}, Retryable: true}}
+		})
+	if err == nil {
+		t.Fatal("expected error")
+	}
+
+	var metric dto.Metric
+	if err := m.errors.WithLabelValues("anthropic", "claude-opus-4-6", "RateLimitError").Write(&metric); err != nil {
+		t.Fatalf("write metric: %v", err)
+	}
+	if metric.GetCounter().GetValue() != 1 {
+		t.Errorf("expected 1 error recorded, got %v", metric.GetCounter().GetValue())
+	}
+}
+
+func TestStreamMetricsMiddlewareRecordsTTFTAndUsage(t *testing.T) {
+	m := NewMetrics()
+	mw := StreamMetricsMiddleware(m)
+
+	in := make(chan StreamEvent, 2)
+	in <- StreamEvent{Type: TextDelta, Delta: "hi"}
+	in <- StreamEvent{Type: StreamFinish, Usage: &Usage{InputTokens: 10, OutputTokens: 5}}
+	close(in)
+
+	out, err := mw(context.Background(), Request{Provider: "anthropic", Model: "claude-opus-4-6"},
+		func(ctx context.Context, r Request) (<-chan StreamEvent, error) { return in, nil })
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for range out {
+	}
+
+	var ttft dto.Metric
+	histogram := m.ttft.WithLabelValues("anthropic", "claude-opus-4-6").(prometheus.Histogram)
+	if err := histogram.Write(&ttft); err != nil {
+		t.Fatalf("write metric: %v", err)
+	}
+	if ttft.GetHistogram().GetSampleCount() != 1 {
+		t.Errorf("expected 1 TTFT sample, got %v", ttft.GetHistogram().GetSampleCount())
+	}
+
+	var tokens dto.Metric
+	if err := m.tokens.WithLabelValues("anthropic", "claude-opus-4-6", "input").Write(&tokens); err != nil {
+		t.Fatalf("write metric: %v", err)
+	}
+	if tokens.GetCounter().GetValue() != 10 {
+		t.Errorf("expected 10 input tokens recorded, got %v", tokens.GetCounter().GetValue())
+	}
+
+	var chunkGap dto.Metric
+	gapHistogram := m.chunkGap.WithLabelValues("anthropic", "claude-opus-4-6").(prometheus.Histogram)
+	if err := gapHistogram.Write(&chunkGap); err != nil {
+		t.Fatalf("write metric: %v", err)
+	}
+	if chunkGap.GetHistogram().GetSampleCount() != 1 {
+		t.Errorf("expected 1 chunk-gap sample (between the 2 events), got %v", chunkGap.GetHistogram().GetSampleCount())
+	}
+}
+
+func TestMetricsMiddlewareRecordsRetries(t *testing.T) {
+	m := NewMetrics()
+	mw := MetricsMiddleware(m)
+
+	_, err := mw(context.Background(), Request{Provider: "anthropic", Model: "claude-opus-4-6"},
+		func(ctx context.Context, r Request) (*Response, error) {
+			return &Response{RetryCount: 2}, nil
+		})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var metric dto.Metric
+	if err := m.retries.WithLabelValues("anthropic", "claude-opus-4-6").Write(&metric); err != nil {
+		t.Fatalf("write metric: %v", err)
+	}
+	if metric.GetCounter().GetValue() != 2 {
+		t.Errorf("expected 2 retries recorded, got %v", metric.GetCounter().GetValue())
+	}
+}
@@ -0,0 +1,57 @@
+package unifiedllm
+
+import "context"
+
+// ModelCatalog is the pluggable interface behind the model catalog
+// consulted by GetModelInfo, ListModels, and GetLatestModel. DefaultCatalog
+// is the package-level registry (the built-in Models slice layered with
+// RegisterModel entries); FileCatalog and HTTPCatalog wrap a ModelCatalog
+// to keep it synced with an external source.
+type ModelCatalog interface {
+	// Register adds or replaces the entry for m.ID.
+	Register(m ModelInfo)
+	// Deregister removes the entry for id, if present. For the package-level
+	// registry this only removes registered entries; built-in Models entries
+	// stay.
+	Deregister(id string)
+	// Lookup returns the entry for id (matched by ID or alias), and whether
+	// one was found.
+	Lookup(id string) (ModelInfo, bool)
+	// List returns every known model, optionally filtered by provider.
+	List(provider string) []ModelInfo
+	// Refresh reloads the catalog from whatever backs it. It is a no-op for
+	// catalogs with no external source.
+	Refresh(ctx context.Context) error
+}
+
+// registryCatalog adapts the package-level model registry (RegisterModel,
+// GetModelInfo, ListModels, GetLatestModel; see model_registry.go and
+// catalog.go) to the ModelCatalog interface, so the same in-memory default
+// catalog the rest of the package already uses can be passed anywhere a
+// ModelCatalog is expected.
+type registryCatalog struct{}
+
+func (registryCatalog) Register(m ModelInfo) { RegisterModel(m) }
+
+func (registryCatalog) Deregister(id string) { DeregisterModel(id) }
+
+func (registryCatalog) Lookup(id string) (ModelInfo, bool) {
+	info := GetModelInfo(id)
+	if info == nil {
+		return ModelInfo{}, false
+	}
+	return *info, true
+}
+
+func (registryCatalog) List(provider string) []ModelInfo { return ListModels(provider) }
+
+// Refresh is a no-op: the registry has no external source of its own. It
+// exists so registryCatalog satisfies ModelCatalog for callers (like
+// FileCatalog and HTTPCatalog) that treat every catalog uniformly.
+func (registryCatalog) Refresh(ctx context.Context) error { return nil }
+
+// DefaultCatalog is the built-in model catalog (Models plus anything added
+// via RegisterModel or the file/env loaders) exposed as a ModelCatalog.
+// FileCatalog and HTTPCatalog wrap DefaultCatalog unless constructed with a
+// different backing catalog.
+var DefaultCatalog ModelCatalog = registryCatalog{}
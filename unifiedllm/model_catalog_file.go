@@ -0,0 +1,79 @@
+package unifiedllm
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// FileCatalog wraps a ModelCatalog and keeps it synced with a directory of
+// JSON/YAML model config files (the same format LoadModelsFromFile reads),
+// re-loading the whole directory whenever Refresh is called or, if Watch is
+// running, whenever fsnotify reports a file create, write, or rename under
+// Dir. Reloading only registers what the files currently declare; entries
+// from a deleted file, or dropped from an edited one, stay registered.
+type FileCatalog struct {
+	ModelCatalog
+	Dir string
+}
+
+// NewFileCatalog returns a FileCatalog that reloads dir into backing. If
+// backing is nil, it defaults to DefaultCatalog.
+func NewFileCatalog(backing ModelCatalog, dir string) *FileCatalog {
+	if backing == nil {
+		backing = DefaultCatalog
+	}
+	return &FileCatalog{ModelCatalog: backing, Dir: dir}
+}
+
+// Refresh reloads every model config file in Dir into the backing catalog.
+// It overrides the embedded ModelCatalog's Refresh, which otherwise has no
+// way to know about Dir.
+func (c *FileCatalog) Refresh(ctx context.Context) error {
+	_, err := loadModelsFromDirInto(c.ModelCatalog, c.Dir)
+	return err
+}
+
+// Watch starts a background fsnotify watch on Dir and calls Refresh
+// whenever a file underneath it is created, written, or renamed, until ctx
+// is done or the watcher itself fails to start. A reload error for one
+// malformed file is logged rather than returned, so it doesn't kill the
+// watch for every file after it.
+func (c *FileCatalog) Watch(ctx context.Context) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("unifiedllm: start model catalog watcher: %w", err)
+	}
+	if err := watcher.Add(c.Dir); err != nil {
+		watcher.Close()
+		return fmt.Errorf("unifiedllm: watch model catalog dir %s: %w", c.Dir, err)
+	}
+
+	go func() {
+		defer watcher.Close()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				if event.Op&(fsnotify.Write|fsnotify.Create|fsnotify.Rename) == 0 {
+					continue
+				}
+				if err := c.Refresh(ctx); err != nil {
+					log.Printf("unifiedllm: model catalog reload after %s: %v", event, err)
+				}
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+				log.Printf("unifiedllm: model catalog watcher on %s: %v", c.Dir, err)
+			}
+		}
+	}()
+	return nil
+}
@@ -0,0 +1,106 @@
+package unifiedllm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// HTTPCatalog wraps a ModelCatalog and pulls pricing and context-window
+// updates from a remote JSON endpoint returning a []ModelInfo document,
+// merging each entry into the backing catalog via Register. It's meant for
+// ops teams who maintain a small internal pricing feed (regenerated nightly
+// from a vendor's pricing page, say) without wanting to restart the process
+// to pick up changes.
+type HTTPCatalog struct {
+	ModelCatalog
+
+	// URL is the endpoint to GET a JSON []ModelInfo document from.
+	URL string
+	// Interval is how often Start re-fetches URL. Refresh always performs a
+	// single fetch regardless of Interval.
+	Interval time.Duration
+	// Client is the HTTP client used to fetch URL. Defaults to
+	// http.DefaultClient if nil.
+	Client *http.Client
+}
+
+// NewHTTPCatalog returns an HTTPCatalog that refreshes from url into
+// backing every interval. If backing is nil, it defaults to DefaultCatalog.
+func NewHTTPCatalog(backing ModelCatalog, url string, interval time.Duration) *HTTPCatalog {
+	if backing == nil {
+		backing = DefaultCatalog
+	}
+	return &HTTPCatalog{ModelCatalog: backing, URL: url, Interval: interval}
+}
+
+// Refresh performs one synchronous fetch-and-merge of the remote pricing
+// document into the backing catalog. It overrides the embedded
+// ModelCatalog's Refresh, which otherwise has no way to know about URL.
+func (c *HTTPCatalog) Refresh(ctx context.Context) error {
+	client := c.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.URL, nil)
+	if err != nil {
+		return fmt.Errorf("unifiedllm: build model catalog refresh request: %w", err)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("unifiedllm: fetch model catalog from %s: %w", c.URL, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unifiedllm: model catalog refresh from %s: unexpected status %s", c.URL, resp.Status)
+	}
+
+	var models []ModelInfo
+	if err := json.NewDecoder(resp.Body).Decode(&models); err != nil {
+		return fmt.Errorf("unifiedllm: decode model catalog from %s: %w", c.URL, err)
+	}
+	for _, m := range models {
+		c.ModelCatalog.Register(m)
+	}
+	return nil
+}
+
+// Start runs Refresh once immediately, then again every Interval until ctx
+// is done. Refresh errors are sent to the returned channel (buffered by
+// one, dropping further errors until the consumer reads) rather than
+// logged directly, so callers can route them into their own logging or
+// metrics. The channel is closed when ctx is done.
+func (c *HTTPCatalog) Start(ctx context.Context) <-chan error {
+	errCh := make(chan error, 1)
+
+	reportErr := func(err error) {
+		select {
+		case errCh <- err:
+		default:
+		}
+	}
+
+	go func() {
+		defer close(errCh)
+		if err := c.Refresh(ctx); err != nil {
+			reportErr(err)
+		}
+
+		ticker := time.NewTicker(c.Interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := c.Refresh(ctx); err != nil {
+					reportErr(err)
+				}
+			}
+		}
+	}()
+	return errCh
+}
@@ -0,0 +1,40 @@
+package unifiedllm
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestHTTPCatalogRefresh(t *testing.T) {
+	resetUserModels(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`[{"id":"http-catalog-model","provider":"openai","display_name":"HTTP Catalog Model","context_window":128000}]`))
+	}))
+	defer server.Close()
+
+	hc := NewHTTPCatalog(nil, server.URL, time.Minute)
+	if err := hc.Refresh(context.Background()); err != nil {
+		t.Fatalf("HTTPCatalog.Refresh: %v", err)
+	}
+
+	if GetModelInfo("http-catalog-model") == nil {
+		t.Fatal("expected HTTPCatalog.Refresh to register the model into the backing catalog")
+	}
+}
+
+func TestHTTPCatalogRefreshRejectsNonOK(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	hc := NewHTTPCatalog(nil, server.URL, time.Minute)
+	if err := hc.Refresh(context.Background()); err == nil {
+		t.Fatal("expected an error for a non-200 response")
+	}
+}
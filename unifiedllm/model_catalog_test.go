@@ -0,0 +1,62 @@
+package unifiedllm
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRegistryCatalogSatisfiesModelCatalog(t *testing.T) {
+	resetUserModels(t)
+
+	var catalog ModelCatalog = DefaultCatalog
+	catalog.Register(ModelInfo{ID: "internal-proxy-2", Provider: "anthropic", DisplayName: "Internal Proxy 2", ContextWindow: 100000})
+
+	info, ok := catalog.Lookup("internal-proxy-2")
+	if !ok || info.DisplayName != "Internal Proxy 2" {
+		t.Fatalf("expected Lookup to find registered model, got (%+v, %v)", info, ok)
+	}
+
+	found := false
+	for _, m := range catalog.List("anthropic") {
+		if m.ID == "internal-proxy-2" {
+			found = true
+		}
+	}
+	if !found {
+		t.Error("expected registered model to appear in List(\"anthropic\")")
+	}
+
+	catalog.Deregister("internal-proxy-2")
+	if _, ok := catalog.Lookup("internal-proxy-2"); ok {
+		t.Error("expected Deregister to remove the model")
+	}
+
+	if err := catalog.Refresh(context.Background()); err != nil {
+		t.Errorf("expected DefaultCatalog.Refresh to be a no-op, got %v", err)
+	}
+}
+
+func TestFileCatalogRefresh(t *testing.T) {
+	resetUserModels(t)
+	dir := t.TempDir()
+
+	body := `- id: file-catalog-model
+  provider: openai
+  display_name: File Catalog Model
+  context_window: 128000
+`
+	if err := os.WriteFile(filepath.Join(dir, "models.yaml"), []byte(body), 0o644); err != nil {
+		t.Fatalf("write fixture: %v", err)
+	}
+
+	fc := NewFileCatalog(nil, dir)
+	if err := fc.Refresh(context.Background()); err != nil {
+		t.Fatalf("FileCatalog.Refresh: %v", err)
+	}
+
+	if GetModelInfo("file-catalog-model") == nil {
+		t.Fatal("expected FileCatalog.Refresh to register the model into the backing catalog")
+	}
+}
@@ -0,0 +1,138 @@
+package unifiedllm
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+)
+
+// userModels holds models registered at runtime via RegisterModel, layered
+// on top of the built-in Models catalog so ops teams can pin internal
+// proxy names (e.g. "azure-gpt5-eastus") as first-class models without
+// forking the repo.
+var (
+	userModels   []ModelInfo
+	userModelsMu sync.RWMutex
+)
+
+// RegisterModel adds m to the runtime model registry, consulted by
+// GetModelInfo, ListModels, and GetLatestModel alongside the built-in
+// Models catalog. Registering a model with an ID that already exists
+// (built-in or previously registered) replaces it.
+func RegisterModel(m ModelInfo) {
+	userModelsMu.Lock()
+	defer userModelsMu.Unlock()
+	for i, existing := range userModels {
+		if existing.ID == m.ID {
+			userModels[i] = m
+			return
+		}
+	}
+	userModels = append(userModels, m)
+}
+
+// DeregisterModel removes id from the runtime model registry, if present.
+// It only affects models added via RegisterModel (or the loaders below); it
+// cannot remove a built-in entry from Models.
+func DeregisterModel(id string) {
+	userModelsMu.Lock()
+	defer userModelsMu.Unlock()
+	for i, existing := range userModels {
+		if existing.ID == id {
+			userModels = append(userModels[:i], userModels[i+1:]...)
+			return
+		}
+	}
+}
+
+// registeredModels returns a snapshot of the runtime-registered models.
+func registeredModels() []ModelInfo {
+	userModelsMu.RLock()
+	defer userModelsMu.RUnlock()
+	result := make([]ModelInfo, len(userModels))
+	copy(result, userModels)
+	return result
+}
+
+// LoadModelsFromFile reads path (YAML, or JSON if its extension is .json)
+// and registers each entry via RegisterModel. The config format mirrors
+// ModelInfo. It returns the number of models registered.
+func LoadModelsFromFile(path string) (int, error) {
+	return loadModelsFromFileInto(DefaultCatalog, path)
+}
+
+// loadModelsFromFileInto is LoadModelsFromFile generalized over an
+// arbitrary ModelCatalog, so FileCatalog can reload into whatever catalog
+// it was constructed to back.
+func loadModelsFromFileInto(catalog ModelCatalog, path string) (int, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, fmt.Errorf("unifiedllm: read model config %s: %w", path, err)
+	}
+
+	var models []ModelInfo
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		if err := json.Unmarshal(data, &models); err != nil {
+			return 0, fmt.Errorf("unifiedllm: parse model config %s: %w", path, err)
+		}
+	} else {
+		if err := yaml.Unmarshal(data, &models); err != nil {
+			return 0, fmt.Errorf("unifiedllm: parse model config %s: %w", path, err)
+		}
+	}
+
+	for _, m := range models {
+		catalog.Register(m)
+	}
+	return len(models), nil
+}
+
+// LoadModelsFromDir registers every .yaml, .yml, and .json file directly
+// inside dir (non-recursive) via LoadModelsFromFile. It returns the total
+// number of models registered across all files.
+func LoadModelsFromDir(dir string) (int, error) {
+	return loadModelsFromDirInto(DefaultCatalog, dir)
+}
+
+// loadModelsFromDirInto is LoadModelsFromDir generalized over an arbitrary
+// ModelCatalog; see loadModelsFromFileInto.
+func loadModelsFromDirInto(catalog ModelCatalog, dir string) (int, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return 0, fmt.Errorf("unifiedllm: read model config dir %s: %w", dir, err)
+	}
+
+	total := 0
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		ext := strings.ToLower(filepath.Ext(entry.Name()))
+		if ext != ".yaml" && ext != ".yml" && ext != ".json" {
+			continue
+		}
+		n, err := loadModelsFromFileInto(catalog, filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return total, err
+		}
+		total += n
+	}
+	return total, nil
+}
+
+// LoadModelsFromEnv registers models from the directory named by the
+// ATTRACTOR_MODELS_DIR environment variable, if set. It is a no-op (0, nil)
+// when the variable is unset, so callers can invoke it unconditionally
+// during startup alongside NewClientFromEnv.
+func LoadModelsFromEnv() (int, error) {
+	dir := os.Getenv("ATTRACTOR_MODELS_DIR")
+	if dir == "" {
+		return 0, nil
+	}
+	return LoadModelsFromDir(dir)
+}
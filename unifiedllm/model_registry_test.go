@@ -0,0 +1,154 @@
+package unifiedllm
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// resetUserModels clears the runtime model registry after a test so later
+// tests (e.g. TestListModels) see only the built-in catalog.
+func resetUserModels(t *testing.T) {
+	t.Helper()
+	t.Cleanup(func() {
+		userModelsMu.Lock()
+		userModels = nil
+		userModelsMu.Unlock()
+	})
+}
+
+func TestRegisterModelAddsAndReplaces(t *testing.T) {
+	resetUserModels(t)
+
+	RegisterModel(ModelInfo{ID: "azure-gpt5-eastus", Provider: "openai", DisplayName: "Azure GPT-5 (East US)", ContextWindow: 128000, Aliases: []string{"azure-gpt5"}})
+
+	info := GetModelInfo("azure-gpt5-eastus")
+	if info == nil {
+		t.Fatal("expected to find registered model by ID")
+	}
+	if info.DisplayName != "Azure GPT-5 (East US)" {
+		t.Errorf("unexpected display name: %q", info.DisplayName)
+	}
+
+	info = GetModelInfo("azure-gpt5")
+	if info == nil || info.ID != "azure-gpt5-eastus" {
+		t.Fatalf("expected to find registered model by alias, got %+v", info)
+	}
+
+	RegisterModel(ModelInfo{ID: "azure-gpt5-eastus", Provider: "openai", DisplayName: "Azure GPT-5 (East US) v2", ContextWindow: 200000})
+	info = GetModelInfo("azure-gpt5-eastus")
+	if info.DisplayName != "Azure GPT-5 (East US) v2" {
+		t.Errorf("expected re-registering to replace the entry, got %+v", info)
+	}
+	if len(registeredModels()) != 1 {
+		t.Errorf("expected replacement not to grow the registry, got %d entries", len(registeredModels()))
+	}
+}
+
+func TestListModelsIncludesRegistered(t *testing.T) {
+	resetUserModels(t)
+
+	builtinCount := len(Models)
+	RegisterModel(ModelInfo{ID: "internal-proxy-1", Provider: "anthropic", DisplayName: "Internal Proxy 1", ContextWindow: 100000})
+
+	all := ListModels("")
+	if len(all) != builtinCount+1 {
+		t.Fatalf("expected %d models, got %d", builtinCount+1, len(all))
+	}
+
+	anthropic := ListModels("anthropic")
+	found := false
+	for _, m := range anthropic {
+		if m.ID == "internal-proxy-1" {
+			found = true
+		}
+	}
+	if !found {
+		t.Error("expected registered model to appear in provider-filtered list")
+	}
+}
+
+func TestGetLatestModelPrefersRegistered(t *testing.T) {
+	resetUserModels(t)
+
+	RegisterModel(ModelInfo{ID: "azure-gpt5-eastus", Provider: "openai", DisplayName: "Azure GPT-5 (East US)", ContextWindow: 128000})
+
+	info := GetLatestModel("openai", Capabilities{})
+	if info == nil || info.ID != "azure-gpt5-eastus" {
+		t.Fatalf("expected registered model to take precedence, got %+v", info)
+	}
+}
+
+func TestLoadModelsFromFileYAMLAndJSON(t *testing.T) {
+	resetUserModels(t)
+	dir := t.TempDir()
+
+	yamlPath := filepath.Join(dir, "models.yaml")
+	yamlBody := `
+- id: azure-gpt5-eastus
+  provider: openai
+  display_name: Azure GPT-5 (East US)
+  context_window: 128000
+  supports_tools: true
+  aliases: [azure-gpt5]
+`
+	if err := os.WriteFile(yamlPath, []byte(yamlBody), 0o644); err != nil {
+		t.Fatalf("write yaml fixture: %v", err)
+	}
+
+	n, err := LoadModelsFromFile(yamlPath)
+	if err != nil {
+		t.Fatalf("LoadModelsFromFile(yaml): %v", err)
+	}
+	if n != 1 {
+		t.Errorf("expected 1 model registered, got %d", n)
+	}
+	info := GetModelInfo("azure-gpt5")
+	if info == nil || info.ContextWindow != 128000 {
+		t.Fatalf("expected YAML-loaded model to be registered, got %+v", info)
+	}
+
+	jsonPath := filepath.Join(dir, "extra.json")
+	jsonBody := `[{"id": "on-prem-llama", "provider": "anthropic", "display_name": "On-Prem Llama", "context_window": 32000}]`
+	if err := os.WriteFile(jsonPath, []byte(jsonBody), 0o644); err != nil {
+		t.Fatalf("write json fixture: %v", err)
+	}
+	n, err = LoadModelsFromFile(jsonPath)
+	if err != nil {
+		t.Fatalf("LoadModelsFromFile(json): %v", err)
+	}
+	if n != 1 {
+		t.Errorf("expected 1 model registered, got %d", n)
+	}
+	if GetModelInfo("on-prem-llama") == nil {
+		t.Fatal("expected JSON-loaded model to be registered")
+	}
+}
+
+func TestLoadModelsFromEnv(t *testing.T) {
+	resetUserModels(t)
+	dir := t.TempDir()
+
+	body := `[{"id": "env-loaded-model", "provider": "gemini", "display_name": "Env Loaded", "context_window": 64000}]`
+	if err := os.WriteFile(filepath.Join(dir, "models.json"), []byte(body), 0o644); err != nil {
+		t.Fatalf("write fixture: %v", err)
+	}
+
+	t.Setenv("ATTRACTOR_MODELS_DIR", dir)
+	n, err := LoadModelsFromEnv()
+	if err != nil {
+		t.Fatalf("LoadModelsFromEnv: %v", err)
+	}
+	if n != 1 {
+		t.Errorf("expected 1 model registered, got %d", n)
+	}
+	if GetModelInfo("env-loaded-model") == nil {
+		t.Fatal("expected env-driven load to register the model")
+	}
+
+	t.Setenv("ATTRACTOR_MODELS_DIR", "")
+	n, err = LoadModelsFromEnv()
+	if err != nil || n != 0 {
+		t.Errorf("expected a no-op when ATTRACTOR_MODELS_DIR is unset, got (%d, %v)", n, err)
+	}
+}
@@ -0,0 +1,245 @@
+package unifiedllm
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// RouterObjective selects how Router ranks the candidates that satisfy a
+// RouteBudget.
+type RouterObjective string
+
+const (
+	// ObjectiveCheapest ranks by the sum of a model's per-million input and
+	// output rates, cheapest first.
+	ObjectiveCheapest RouterObjective = "cheapest"
+	// ObjectiveLargestContext ranks by ContextWindow, largest first.
+	ObjectiveLargestContext RouterObjective = "largest_context"
+	// ObjectiveLowestLatency ranks by an EWMA of latencies Router has
+	// observed for each model (see Router.Observe), lowest first. A model
+	// with no samples yet sorts before every model with samples, so every
+	// candidate gets a chance to build up a latency profile.
+	ObjectiveLowestLatency RouterObjective = "lowest_latency"
+)
+
+// RouteBudget bounds and shapes Router's candidate search. MaxInputTokens
+// and EstimatedOutputTokens are caller-supplied token estimates (Router has
+// no tokenizer of its own); they filter candidates by ContextWindow and
+// MaxCostUSD. Capabilities filters by required model features.
+// PreferredProviders, if non-empty, sorts candidates from those providers
+// ahead of every other candidate, in PreferredProviders order.
+type RouteBudget struct {
+	MaxInputTokens        int
+	EstimatedOutputTokens int
+	MaxCostUSD            float64
+	Capabilities          Capabilities
+	PreferredProviders    []string
+}
+
+// estimatedCost projects b's token estimate against m's catalog pricing.
+// Unpriced fields (nil) contribute 0, same convention as estimatedCostUSD.
+func (b RouteBudget) estimatedCost(m ModelInfo) float64 {
+	var cost float64
+	if m.InputCostPerMillion != nil {
+		cost += float64(b.MaxInputTokens) / 1_000_000 * *m.InputCostPerMillion
+	}
+	if m.OutputCostPerMillion != nil {
+		cost += float64(b.EstimatedOutputTokens) / 1_000_000 * *m.OutputCostPerMillion
+	}
+	return cost
+}
+
+// satisfies reports whether m fits within b: not deprecated, within the
+// context window, within the cost ceiling (if set), and capability-matched.
+func (b RouteBudget) satisfies(m ModelInfo) bool {
+	if m.Deprecated {
+		return false
+	}
+	if b.MaxInputTokens > 0 && m.ContextWindow > 0 && b.MaxInputTokens > m.ContextWindow {
+		return false
+	}
+	if b.MaxCostUSD > 0 && b.estimatedCost(m) > b.MaxCostUSD {
+		return false
+	}
+	return b.Capabilities.satisfiedBy(m)
+}
+
+// Router picks a model for a RouteBudget from a ModelCatalog, ranking
+// eligible candidates by a configurable RouterObjective. It turns the
+// catalog's hardcoded, single-provider GetLatestModel into something a
+// caller can hand a budget and a retry loop: see Select for one-shot
+// picking and CompleteWithFallback for a driven-to-completion call that
+// automatically moves to the next candidate on a rate-limit or
+// context-length error.
+type Router struct {
+	catalog   ModelCatalog
+	objective RouterObjective
+	metrics   *Metrics // optional; records router_fallbacks_total if non-nil
+
+	mu      sync.Mutex
+	latency map[string]time.Duration // modelID -> EWMA of observed latency
+}
+
+// NewRouter creates a Router over catalog, ranking eligible candidates by
+// objective. metrics may be nil, in which case fallbacks aren't recorded
+// anywhere.
+func NewRouter(catalog ModelCatalog, objective RouterObjective, metrics *Metrics) *Router {
+	return &Router{
+		catalog:   catalog,
+		objective: objective,
+		metrics:   metrics,
+		latency:   make(map[string]time.Duration),
+	}
+}
+
+// Candidates returns every model in r's catalog that satisfies budget,
+// ranked best-first by r.objective (after sorting budget.PreferredProviders
+// ahead of everything else).
+func (r *Router) Candidates(budget RouteBudget) []ModelInfo {
+	var eligible []ModelInfo
+	for _, m := range r.catalog.List("") {
+		if budget.satisfies(m) {
+			eligible = append(eligible, m)
+		}
+	}
+
+	preference := make(map[string]int, len(budget.PreferredProviders))
+	for i, p := range budget.PreferredProviders {
+		preference[p] = i
+	}
+
+	r.mu.Lock()
+	latency := make(map[string]time.Duration, len(r.latency))
+	for k, v := range r.latency {
+		latency[k] = v
+	}
+	r.mu.Unlock()
+
+	sort.SliceStable(eligible, func(i, j int) bool {
+		pi, oki := preference[eligible[i].Provider]
+		pj, okj := preference[eligible[j].Provider]
+		if oki != okj {
+			return oki
+		}
+		if oki && okj && pi != pj {
+			return pi < pj
+		}
+		return r.less(eligible[i], eligible[j], latency)
+	})
+	return eligible
+}
+
+// less implements r.objective's ordering between two already-eligible
+// candidates with equal provider preference.
+func (r *Router) less(a, b ModelInfo, latency map[string]time.Duration) bool {
+	switch r.objective {
+	case ObjectiveLargestContext:
+		return a.ContextWindow > b.ContextWindow
+	case ObjectiveLowestLatency:
+		la, oka := latency[a.ID]
+		lb, okb := latency[b.ID]
+		if oka != okb {
+			return !oka
+		}
+		return la < lb
+	default: // ObjectiveCheapest, and the fallback for an unrecognized value
+		return modelCost(a) < modelCost(b)
+	}
+}
+
+// modelCost sums a model's per-million input and output rates, for
+// relative cheapest-first comparison between candidates (as opposed to
+// RouteBudget.estimatedCost's budget-aware absolute estimate).
+func modelCost(m ModelInfo) float64 {
+	var cost float64
+	if m.InputCostPerMillion != nil {
+		cost += *m.InputCostPerMillion
+	}
+	if m.OutputCostPerMillion != nil {
+		cost += *m.OutputCostPerMillion
+	}
+	return cost
+}
+
+// Select returns the best candidate for budget, or an error if no model in
+// r's catalog satisfies it.
+func (r *Router) Select(budget RouteBudget) (*ModelInfo, error) {
+	candidates := r.Candidates(budget)
+	if len(candidates) == 0 {
+		return nil, fmt.Errorf("unifiedllm: no model satisfies the given budget")
+	}
+	return &candidates[0], nil
+}
+
+// ewmaAlpha weights Observe's latest sample against the running average.
+const ewmaAlpha = 0.3
+
+// Observe records latency as a new EWMA sample for modelID, feeding the
+// lowest_latency objective. Call it after every real completion attempt,
+// successful or not -- a slow failure is still useful latency signal for
+// that model.
+func (r *Router) Observe(modelID string, latency time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if prev, ok := r.latency[modelID]; ok {
+		r.latency[modelID] = time.Duration(ewmaAlpha*float64(latency) + (1-ewmaAlpha)*float64(prev))
+	} else {
+		r.latency[modelID] = latency
+	}
+}
+
+// isRouterFallbackError reports whether err is the kind of failure
+// CompleteWithFallback falls back from: a rate limit (the provider is
+// temporarily unavailable) or a context-length error (this particular
+// model can't fit the request, but a larger one might). Every other error
+// is returned to the caller immediately, same as a non-retryable error
+// would be from RetryPolicy.
+func isRouterFallbackError(err error) bool {
+	switch err.(type) {
+	case *RateLimitError, *ContextLengthError:
+		return true
+	}
+	return false
+}
+
+// CompleteWithFallback calls client.Complete against the best candidate for
+// budget, then -- if the attempt fails with a RateLimitError or
+// ContextLengthError -- retries against each remaining candidate in rank
+// order until one succeeds or every candidate has failed. req.Model and
+// req.Provider are overwritten per attempt with the candidate being tried;
+// every other Request field is left as the caller set it. Every attempt,
+// successful or not, feeds Observe; every fallback (a failed attempt with a
+// candidate still left to try) increments r.metrics' router_fallbacks_total
+// counter, if metrics is non-nil.
+func (r *Router) CompleteWithFallback(ctx context.Context, client *Client, req Request, budget RouteBudget) (*Response, error) {
+	candidates := r.Candidates(budget)
+	if len(candidates) == 0 {
+		return nil, fmt.Errorf("unifiedllm: no model satisfies the given budget")
+	}
+
+	var lastErr error
+	for i, model := range candidates {
+		attempt := req
+		attempt.Model = model.ID
+		attempt.Provider = model.Provider
+
+		start := time.Now()
+		resp, err := client.Complete(ctx, attempt)
+		r.Observe(model.ID, time.Since(start))
+
+		if err == nil {
+			return resp, nil
+		}
+		lastErr = err
+		if !isRouterFallbackError(err) {
+			return nil, err
+		}
+		if i < len(candidates)-1 && r.metrics != nil {
+			r.metrics.recordRouterFallback(model.Provider, model.ID)
+		}
+	}
+	return nil, lastErr
+}
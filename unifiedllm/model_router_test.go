@@ -0,0 +1,193 @@
+package unifiedllm
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	dto "github.com/prometheus/client_model/go"
+)
+
+// fakeCatalog is a minimal in-memory ModelCatalog for router tests, so they
+// don't depend on (or mutate) the package's built-in Models/RegisterModel
+// state.
+type fakeCatalog struct {
+	models map[string]ModelInfo
+}
+
+func newFakeCatalog(models ...ModelInfo) *fakeCatalog {
+	c := &fakeCatalog{models: make(map[string]ModelInfo)}
+	for _, m := range models {
+		c.models[m.ID] = m
+	}
+	return c
+}
+
+func (c *fakeCatalog) Register(m ModelInfo) { c.models[m.ID] = m }
+func (c *fakeCatalog) Deregister(id string) { delete(c.models, id) }
+func (c *fakeCatalog) Lookup(id string) (ModelInfo, bool) {
+	m, ok := c.models[id]
+	return m, ok
+}
+func (c *fakeCatalog) List(provider string) []ModelInfo {
+	var out []ModelInfo
+	for _, m := range c.models {
+		if provider == "" || m.Provider == provider {
+			out = append(out, m)
+		}
+	}
+	return out
+}
+func (c *fakeCatalog) Refresh(ctx context.Context) error { return nil }
+
+func testModels() []ModelInfo {
+	return []ModelInfo{
+		{ID: "cheap-small", Provider: "a", ContextWindow: 8000,
+			InputCostPerMillion: floatPtr(1.0), OutputCostPerMillion: floatPtr(2.0), SupportsTools: true},
+		{ID: "pricey-large", Provider: "b", ContextWindow: 1000000,
+			InputCostPerMillion: floatPtr(20.0), OutputCostPerMillion: floatPtr(60.0), SupportsTools: true},
+		{ID: "deprecated-model", Provider: "a", ContextWindow: 1000000,
+			InputCostPerMillion: floatPtr(0.1), OutputCostPerMillion: floatPtr(0.1), Deprecated: true},
+	}
+}
+
+func TestRouteBudgetSatisfiesFiltersByContextAndCost(t *testing.T) {
+	models := testModels()
+	small, large, deprecated := models[0], models[1], models[2]
+
+	budget := RouteBudget{MaxInputTokens: 500000}
+	if budget.satisfies(small) {
+		t.Error("expected small context window to be excluded by MaxInputTokens")
+	}
+	if !budget.satisfies(large) {
+		t.Error("expected large context window to satisfy the budget")
+	}
+	if budget.satisfies(deprecated) {
+		t.Error("expected a deprecated model to never satisfy a budget")
+	}
+
+	// MaxInputTokens here is kept well under every test model's
+	// ContextWindow so this isolates the cost check: a value large enough
+	// to also exercise the context-window filter (as in budget above)
+	// would reject small on context before its cost is ever considered.
+	costBudget := RouteBudget{MaxCostUSD: 1.0, MaxInputTokens: 1000, EstimatedOutputTokens: 400_000}
+	if !costBudget.satisfies(small) {
+		t.Error("expected the cheap model to fit a $1 budget")
+	}
+	if costBudget.satisfies(large) {
+		t.Error("expected the pricey model to exceed a $1 budget")
+	}
+}
+
+func TestRouterCandidatesCheapestObjective(t *testing.T) {
+	catalog := newFakeCatalog(testModels()...)
+	router := NewRouter(catalog, ObjectiveCheapest, nil)
+
+	candidates := router.Candidates(RouteBudget{})
+	if len(candidates) != 2 {
+		t.Fatalf("expected 2 eligible (non-deprecated) candidates, got %d", len(candidates))
+	}
+	if candidates[0].ID != "cheap-small" {
+		t.Errorf("expected cheap-small ranked first, got %s", candidates[0].ID)
+	}
+}
+
+func TestRouterCandidatesLargestContextObjective(t *testing.T) {
+	catalog := newFakeCatalog(testModels()...)
+	router := NewRouter(catalog, ObjectiveLargestContext, nil)
+
+	candidates := router.Candidates(RouteBudget{})
+	if candidates[0].ID != "pricey-large" {
+		t.Errorf("expected pricey-large ranked first, got %s", candidates[0].ID)
+	}
+}
+
+func TestRouterCandidatesPreferredProviders(t *testing.T) {
+	catalog := newFakeCatalog(testModels()...)
+	router := NewRouter(catalog, ObjectiveCheapest, nil)
+
+	candidates := router.Candidates(RouteBudget{PreferredProviders: []string{"b"}})
+	if candidates[0].Provider != "b" {
+		t.Errorf("expected provider b preferred regardless of cost, got %s", candidates[0].Provider)
+	}
+}
+
+func TestRouterCandidatesLowestLatencyObjective(t *testing.T) {
+	catalog := newFakeCatalog(testModels()...)
+	router := NewRouter(catalog, ObjectiveLowestLatency, nil)
+
+	router.Observe("cheap-small", 500*time.Millisecond)
+	router.Observe("pricey-large", 10*time.Millisecond)
+
+	candidates := router.Candidates(RouteBudget{})
+	if candidates[0].ID != "pricey-large" {
+		t.Errorf("expected the lower-latency model ranked first, got %s", candidates[0].ID)
+	}
+}
+
+func TestRouterSelectNoCandidates(t *testing.T) {
+	catalog := newFakeCatalog(testModels()...)
+	router := NewRouter(catalog, ObjectiveCheapest, nil)
+
+	// A context-window requirement beyond every test model's ContextWindow
+	// (the largest is 1,000,000) guarantees zero candidates regardless of
+	// cost -- a tiny token estimate, by contrast, makes every model look
+	// cheap rather than unsatisfiable.
+	_, err := router.Select(RouteBudget{MaxInputTokens: 2_000_000})
+	if err == nil {
+		t.Fatal("expected an error when no model satisfies the budget")
+	}
+}
+
+func TestRouterCompleteWithFallback(t *testing.T) {
+	catalog := newFakeCatalog(testModels()...)
+	router := NewRouter(catalog, ObjectiveCheapest, nil)
+
+	rateLimited := &mockAdapter{name: "a", err: &RateLimitError{ProviderError: ProviderError{SDKError: SDKError{Message: "slow down"}, Retryable: true}}}
+	ok := newMockAdapter("b", "fallback succeeded")
+	client := NewClient(WithProvider("a", rateLimited), WithProvider("b", ok))
+
+	resp, err := router.CompleteWithFallback(context.Background(), client, Request{}, RouteBudget{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resp.Provider != "b" {
+		t.Errorf("expected fallback to provider b, got %s", resp.Provider)
+	}
+}
+
+func TestRouterCompleteWithFallbackRecordsMetric(t *testing.T) {
+	catalog := newFakeCatalog(testModels()...)
+	m := NewMetrics()
+	router := NewRouter(catalog, ObjectiveCheapest, m)
+
+	rateLimited := &mockAdapter{name: "a", err: &RateLimitError{ProviderError: ProviderError{SDKError: SDKError{Message: "slow down"}, Retryable: true}}}
+	ok := newMockAdapter("b", "fallback succeeded")
+	client := NewClient(WithProvider("a", rateLimited), WithProvider("b", ok))
+
+	if _, err := router.CompleteWithFallback(context.Background(), client, Request{}, RouteBudget{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var metric dto.Metric
+	if err := m.routerFallbacks.WithLabelValues("a", "cheap-small").Write(&metric); err != nil {
+		t.Fatalf("write metric: %v", err)
+	}
+	if metric.GetCounter().GetValue() != 1 {
+		t.Errorf("expected 1 fallback recorded, got %v", metric.GetCounter().GetValue())
+	}
+}
+
+func TestRouterCompleteWithFallbackNonRetryableErrorStops(t *testing.T) {
+	catalog := newFakeCatalog(testModels()...)
+	router := NewRouter(catalog, ObjectiveCheapest, nil)
+
+	invalid := &mockAdapter{name: "a", err: &InvalidRequestError{ProviderError: ProviderError{SDKError: SDKError{Message: "bad request"}}}}
+	ok := newMockAdapter("b", "should not be reached")
+	client := NewClient(WithProvider("a", invalid), WithProvider("b", ok))
+
+	_, err := router.CompleteWithFallback(context.Background(), client, Request{}, RouteBudget{})
+	if err == nil {
+		t.Fatal("expected the non-retryable error to be returned without falling back")
+	}
+}
@@ -0,0 +1,89 @@
+// Package plugin implements the client and server halves of attractor's
+// reattach workflow for out-of-process ProviderAdapters: running a
+// ProviderAdapter in a separate, long-lived process and having the
+// in-process Client connect to it over gRPC instead of instantiating a
+// GollmAdapter in-process -- Terraform's TF_REATTACH_PROVIDERS pattern
+// applied to LLM providers. This enables debugger-attach workflows for
+// provider adapters, out-of-process credential isolation for API keys, and
+// language-agnostic providers, while leaving the in-process path untouched.
+//
+// It's a thin, named front door onto unifiedllm's GRPCAdapter/GRPCServer,
+// which already implement the wire protocol (see proto/llm_provider.proto)
+// and live in the parent package to avoid import cycles, the same reason
+// unifiedllm/providers/* live outside unifiedllm itself. See
+// unifiedllm.ReattachEnvVar for the env-var-driven auto-registration
+// NewClient performs without importing this package at all.
+package plugin
+
+import (
+	"fmt"
+	"net"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+
+	"github.com/martinemde/attractor/unifiedllm"
+)
+
+// ReattachConfig describes one already-running ProviderAdapter plugin
+// process to connect to.
+type ReattachConfig struct {
+	// Target is a grpc.Dial target: "host:port" for loopback TCP, or
+	// "unix:/path/to.sock" for a Unix domain socket.
+	Target string
+
+	// DialTimeout bounds how long NewReattachAdapter retries the
+	// Initialize handshake before giving up -- useful when the plugin
+	// process is still starting up. Defaults to 10s.
+	DialTimeout time.Duration
+
+	// DialOptions are passed to grpc.NewClient verbatim. Defaults to
+	// insecure.NewCredentials() transport credentials, appropriate for a
+	// local Unix socket or loopback TCP target.
+	DialOptions []grpc.DialOption
+}
+
+// NewReattachAdapter dials cfg.Target and performs the Name/Register
+// handshake (unifiedllm.GRPCAdapter.Initialize), retrying until it
+// succeeds or cfg.DialTimeout elapses, and returns a
+// unifiedllm.ProviderAdapter ready to hand to Client.RegisterProvider. The
+// plugin process on the other end must already be listening and serving
+// the LLMProvider gRPC service -- see Serve.
+func NewReattachAdapter(cfg ReattachConfig) (*unifiedllm.GRPCAdapter, error) {
+	if cfg.Target == "" {
+		return nil, fmt.Errorf("plugin: reattach config requires a target")
+	}
+	opts := cfg.DialOptions
+	if len(opts) == 0 {
+		opts = []grpc.DialOption{grpc.WithTransportCredentials(insecure.NewCredentials())}
+	}
+	timeout := cfg.DialTimeout
+	if timeout == 0 {
+		timeout = 10 * time.Second
+	}
+
+	adapter := unifiedllm.NewGRPCAdapter(cfg.Target, opts...)
+	deadline := time.Now().Add(timeout)
+	var lastErr error
+	for {
+		if err := adapter.Initialize(); err == nil {
+			return adapter, nil
+		} else {
+			lastErr = err
+		}
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("plugin: reattach %s: timed out: %w", cfg.Target, lastErr)
+		}
+		time.Sleep(200 * time.Millisecond)
+	}
+}
+
+// Serve exposes adapter over gRPC at lis and blocks until it stops or
+// returns an error -- the call a plugin process's main() makes before
+// serving forever, the process a ReattachConfig dials into. It's a direct
+// re-export of unifiedllm.Serve so a plugin author doesn't need to import
+// the parent package by name just to find it.
+func Serve(lis net.Listener, adapter unifiedllm.ProviderAdapter, opts ...grpc.ServerOption) error {
+	return unifiedllm.Serve(lis, adapter, opts...)
+}
@@ -31,3 +31,45 @@ type Initializer interface {
 type ToolChoiceSupporter interface {
 	SupportsToolChoice(mode string) bool
 }
+
+// ModelLister is implemented by adapters that can enumerate their own model
+// catalog, such as a GRPCAdapter backed by a local model runtime that knows
+// which models it serves without an entry in unifiedllm's built-in Models.
+type ModelLister interface {
+	Models() []ModelInfo
+}
+
+// ModelRefresher is implemented by adapters that can re-enumerate their
+// model catalog on demand, beyond the one-time handshake ModelLister
+// reports at startup -- e.g. a GRPCAdapter whose backend has just picked up
+// a newly downloaded model.
+type ModelRefresher interface {
+	ListModels(ctx context.Context) ([]ModelInfo, error)
+}
+
+// TokenCounter is implemented by adapters that can count tokens for a
+// request without performing a completion, so a caller can budget a
+// request (or decide whether to trim context) before sending it.
+type TokenCounter interface {
+	CountTokens(ctx context.Context, req Request) (int, error)
+}
+
+// Embedder is implemented by adapters that can produce vector embeddings,
+// separately from chat completion.
+type Embedder interface {
+	Embed(ctx context.Context, req EmbedRequest) (*EmbedResponse, error)
+}
+
+// EmbedRequest is the input to Embedder.Embed: a batch of texts to embed
+// with model.
+type EmbedRequest struct {
+	Model string
+	Input []string
+}
+
+// EmbedResponse is Embedder.Embed's result: one embedding vector per Input
+// entry, in the same order.
+type EmbedResponse struct {
+	Embeddings [][]float64
+	Usage      Usage
+}
@@ -0,0 +1,365 @@
+// Package anthropic implements a native unifiedllm.ProviderAdapter for the
+// Anthropic Messages API, using the native tools API (tool_use/tool_result
+// content blocks) rather than any XML/stop-sequence scheme.
+package anthropic
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/martinemde/attractor/unifiedllm"
+)
+
+const (
+	defaultBaseURL    = "https://api.anthropic.com/v1"
+	defaultAPIVersion = "2023-06-01"
+)
+
+// Adapter implements unifiedllm.ProviderAdapter against the Anthropic
+// Messages API.
+type Adapter struct {
+	apiKey               string
+	baseURL              string
+	version              string
+	model                string
+	http                 *http.Client
+	systemCacheThreshold int
+}
+
+// Option configures an Adapter.
+type Option func(*Adapter)
+
+// WithBaseURL overrides the default Anthropic API base URL (for proxies or
+// testing).
+func WithBaseURL(url string) Option {
+	return func(a *Adapter) { a.baseURL = strings.TrimRight(url, "/") }
+}
+
+// WithAPIVersion overrides the "anthropic-version" header value.
+func WithAPIVersion(version string) Option {
+	return func(a *Adapter) { a.version = version }
+}
+
+// WithModel sets the default model used when a Request doesn't specify one.
+func WithModel(model string) Option {
+	return func(a *Adapter) { a.model = model }
+}
+
+// WithHTTPClient overrides the default *http.Client.
+func WithHTTPClient(client *http.Client) Option {
+	return func(a *Adapter) { a.http = client }
+}
+
+// WithSystemCacheThreshold overrides the byte length above which a hoisted
+// system prompt is cached automatically even without an explicit CacheHint.
+// A threshold <= 0 disables auto-promotion.
+func WithSystemCacheThreshold(bytes int) Option {
+	return func(a *Adapter) { a.systemCacheThreshold = bytes }
+}
+
+// NewAdapter creates an Anthropic provider adapter authenticated with apiKey.
+func NewAdapter(apiKey string, opts ...Option) *Adapter {
+	a := &Adapter{
+		apiKey:               apiKey,
+		baseURL:              defaultBaseURL,
+		version:              defaultAPIVersion,
+		http:                 http.DefaultClient,
+		systemCacheThreshold: defaultSystemCacheThreshold,
+	}
+	for _, opt := range opts {
+		opt(a)
+	}
+	return a
+}
+
+// Name returns the provider identifier.
+func (a *Adapter) Name() string { return "anthropic" }
+
+// Complete sends a blocking Messages API request.
+func (a *Adapter) Complete(ctx context.Context, req unifiedllm.Request) (*unifiedllm.Response, error) {
+	ar := translateRequestWithCacheThreshold(req, a.systemCacheThreshold)
+	ar.Model = a.modelFor(req)
+
+	body, err := json.Marshal(ar)
+	if err != nil {
+		return nil, fmt.Errorf("anthropic: encode request: %w", err)
+	}
+
+	httpResp, err := a.doPost(ctx, a.baseURL+"/messages", body)
+	if err != nil {
+		return nil, err
+	}
+	defer httpResp.Body.Close()
+
+	data, err := io.ReadAll(httpResp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("anthropic: read response: %w", err)
+	}
+	if httpResp.StatusCode >= 400 {
+		return nil, translateHTTPError(httpResp.StatusCode, data)
+	}
+
+	var raw anthropicResponse
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("anthropic: decode response: %w", err)
+	}
+	return buildResponse(ar.Model, &raw), nil
+}
+
+// Stream sends a streaming Messages API request and translates each SSE
+// event into a unifiedllm.StreamEvent.
+func (a *Adapter) Stream(ctx context.Context, req unifiedllm.Request) (<-chan unifiedllm.StreamEvent, error) {
+	ar := translateRequestWithCacheThreshold(req, a.systemCacheThreshold)
+	ar.Model = a.modelFor(req)
+	ar.Stream = true
+
+	body, err := json.Marshal(ar)
+	if err != nil {
+		return nil, fmt.Errorf("anthropic: encode request: %w", err)
+	}
+
+	httpResp, err := a.doPost(ctx, a.baseURL+"/messages", body)
+	if err != nil {
+		return nil, err
+	}
+	if httpResp.StatusCode >= 400 {
+		defer httpResp.Body.Close()
+		data, _ := io.ReadAll(httpResp.Body)
+		return nil, translateHTTPError(httpResp.StatusCode, data)
+	}
+
+	ch := make(chan unifiedllm.StreamEvent, 16)
+	go a.consumeStream(httpResp.Body, ar.Model, ch)
+	return ch, nil
+}
+
+func (a *Adapter) doPost(ctx context.Context, url string, body []byte) (*http.Response, error) {
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("anthropic: build request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("x-api-key", a.apiKey)
+	httpReq.Header.Set("anthropic-version", a.version)
+
+	resp, err := a.http.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("anthropic: request: %w", err)
+	}
+	return resp, nil
+}
+
+func (a *Adapter) modelFor(req unifiedllm.Request) string {
+	if req.Model != "" {
+		return req.Model
+	}
+	return a.model
+}
+
+// sseEvent is the subset of Anthropic's streaming event envelope needed to
+// drive the block accumulator.
+type sseEvent struct {
+	Type  string `json:"type"`
+	Index int    `json:"index"`
+
+	ContentBlock *anthropicContentBlock `json:"content_block,omitempty"`
+
+	Delta struct {
+		Type        string `json:"type"`
+		Text        string `json:"text,omitempty"`
+		PartialJSON string `json:"partial_json,omitempty"`
+		StopReason  string `json:"stop_reason,omitempty"`
+	} `json:"delta,omitempty"`
+
+	Message *struct {
+		ID    string         `json:"id"`
+		Usage anthropicUsage `json:"usage"`
+	} `json:"message,omitempty"`
+
+	Usage *anthropicUsage `json:"usage,omitempty"`
+}
+
+// consumeStream reads "data: {...}" SSE lines, accumulates tool-use
+// input_json_delta fragments per content-block index, and emits
+// TextDelta/ToolCallStart/ToolCallDelta/ToolCallEnd/StreamFinish events.
+// It always closes ch and body.
+func (a *Adapter) consumeStream(body io.ReadCloser, model string, ch chan<- unifiedllm.StreamEvent) {
+	defer close(ch)
+	defer body.Close()
+
+	acc := newStreamAccumulator(model)
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	ch <- unifiedllm.StreamEvent{Type: unifiedllm.StreamStart}
+
+	for scanner.Scan() {
+		line := scanner.Text()
+		if !strings.HasPrefix(line, "data: ") {
+			continue
+		}
+		payload := strings.TrimPrefix(line, "data: ")
+
+		var evt sseEvent
+		if err := json.Unmarshal([]byte(payload), &evt); err != nil {
+			ch <- unifiedllm.StreamEvent{Type: unifiedllm.StreamError, Error: fmt.Errorf("anthropic: decode stream event: %w", err)}
+			return
+		}
+
+		switch evt.Type {
+		case "message_start":
+			if evt.Message != nil {
+				acc.id = evt.Message.ID
+				acc.usage.InputTokens = evt.Message.Usage.InputTokens
+			}
+		case "content_block_start":
+			if evt.ContentBlock != nil {
+				acc.startBlock(evt.Index, *evt.ContentBlock)
+				if evt.ContentBlock.Type == "tool_use" {
+					ch <- unifiedllm.StreamEvent{
+						Type:     unifiedllm.ToolCallStart,
+						ToolCall: &unifiedllm.ToolCall{ID: evt.ContentBlock.ID, Name: evt.ContentBlock.Name},
+					}
+				}
+			}
+		case "content_block_delta":
+			switch evt.Delta.Type {
+			case "text_delta":
+				acc.appendText(evt.Index, evt.Delta.Text)
+				if evt.Delta.Text != "" {
+					ch <- unifiedllm.StreamEvent{Type: unifiedllm.TextDelta, Delta: evt.Delta.Text}
+				}
+			case "input_json_delta":
+				acc.appendJSON(evt.Index, evt.Delta.PartialJSON)
+				if id, ok := acc.blockID(evt.Index); ok && evt.Delta.PartialJSON != "" {
+					ch <- unifiedllm.StreamEvent{
+						Type:     unifiedllm.ToolCallDelta,
+						ToolCall: &unifiedllm.ToolCall{ID: id},
+						Delta:    evt.Delta.PartialJSON,
+					}
+				}
+			}
+		case "content_block_stop":
+			if tc := acc.finishBlock(evt.Index); tc != nil {
+				ch <- unifiedllm.StreamEvent{Type: unifiedllm.ToolCallEnd, ToolCall: tc}
+			}
+		case "message_delta":
+			if evt.Delta.StopReason != "" {
+				acc.stopReason = evt.Delta.StopReason
+			}
+			if evt.Usage != nil {
+				acc.usage.OutputTokens = evt.Usage.OutputTokens
+			}
+		case "message_stop":
+			// Terminal event; StreamFinish is emitted after the loop exits.
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		ch <- unifiedllm.StreamEvent{Type: unifiedllm.StreamError, Error: fmt.Errorf("anthropic: read stream: %w", err)}
+		return
+	}
+
+	resp := buildResponse(model, acc.response())
+	ch <- unifiedllm.StreamEvent{
+		Type:         unifiedllm.StreamFinish,
+		FinishReason: &resp.FinishReason,
+		Usage:        &resp.Usage,
+		Response:     resp,
+	}
+}
+
+// streamAccumulator tracks content blocks by index as they are streamed in,
+// buffering tool_use input_json_delta fragments until content_block_stop.
+type streamAccumulator struct {
+	model      string
+	id         string
+	stopReason string
+	usage      anthropicUsage
+	blocks     map[int]*anthropicContentBlock
+	jsonBuf    map[int]*strings.Builder
+}
+
+func newStreamAccumulator(model string) *streamAccumulator {
+	return &streamAccumulator{
+		model:   model,
+		blocks:  make(map[int]*anthropicContentBlock),
+		jsonBuf: make(map[int]*strings.Builder),
+	}
+}
+
+func (a *streamAccumulator) startBlock(index int, block anthropicContentBlock) {
+	b := block
+	a.blocks[index] = &b
+	if block.Type == "tool_use" {
+		a.jsonBuf[index] = &strings.Builder{}
+	}
+}
+
+func (a *streamAccumulator) appendText(index int, delta string) {
+	if b, ok := a.blocks[index]; ok {
+		b.Text += delta
+	}
+}
+
+func (a *streamAccumulator) appendJSON(index int, delta string) {
+	if buf, ok := a.jsonBuf[index]; ok {
+		buf.WriteString(delta)
+	}
+}
+
+// blockID reports the tool_use block ID at index, for tagging
+// ToolCallDelta events as they stream in.
+func (a *streamAccumulator) blockID(index int) (string, bool) {
+	b, ok := a.blocks[index]
+	if !ok || b.Type != "tool_use" {
+		return "", false
+	}
+	return b.ID, true
+}
+
+// finishBlock finalizes the block at index, parsing any buffered tool-use
+// input JSON. It returns a ToolCall for tool_use blocks, or nil otherwise.
+func (a *streamAccumulator) finishBlock(index int) *unifiedllm.ToolCall {
+	b, ok := a.blocks[index]
+	if !ok || b.Type != "tool_use" {
+		return nil
+	}
+	buf := a.jsonBuf[index]
+	raw := "{}"
+	if buf != nil && buf.Len() > 0 {
+		raw = buf.String()
+	}
+	var input map[string]interface{}
+	_ = json.Unmarshal([]byte(raw), &input)
+	b.Input = input
+
+	args, _ := json.Marshal(input)
+	return &unifiedllm.ToolCall{
+		ID:        b.ID,
+		Name:      b.Name,
+		Arguments: args,
+	}
+}
+
+// response assembles the blocks accumulated so far into an anthropicResponse
+// suitable for buildResponse.
+func (a *streamAccumulator) response() *anthropicResponse {
+	resp := &anthropicResponse{
+		ID:         a.id,
+		Model:      a.model,
+		StopReason: a.stopReason,
+		Usage:      a.usage,
+	}
+	for i := 0; i < len(a.blocks); i++ {
+		if b, ok := a.blocks[i]; ok {
+			resp.Content = append(resp.Content, *b)
+		}
+	}
+	return resp
+}
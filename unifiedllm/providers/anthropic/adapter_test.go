@@ -0,0 +1,31 @@
+package anthropic
+
+import "testing"
+
+func TestStreamAccumulatorFlushesToolCallOnBlockStop(t *testing.T) {
+	acc := newStreamAccumulator("claude-sonnet-4-5")
+	acc.startBlock(0, anthropicContentBlock{Type: "tool_use", ID: "toolu_1", Name: "get_weather"})
+	acc.appendJSON(0, `{"city":`)
+	acc.appendJSON(0, `"Paris"}`)
+
+	tc := acc.finishBlock(0)
+	if tc == nil {
+		t.Fatal("expected a tool call, got nil")
+	}
+	if tc.ID != "toolu_1" || tc.Name != "get_weather" {
+		t.Errorf("unexpected tool call: %+v", tc)
+	}
+	if string(tc.Arguments) != `{"city":"Paris"}` {
+		t.Errorf("expected reassembled arguments, got %q", tc.Arguments)
+	}
+}
+
+func TestStreamAccumulatorIgnoresTextBlockOnStop(t *testing.T) {
+	acc := newStreamAccumulator("claude-sonnet-4-5")
+	acc.startBlock(0, anthropicContentBlock{Type: "text"})
+	acc.appendText(0, "hello")
+
+	if tc := acc.finishBlock(0); tc != nil {
+		t.Errorf("expected nil tool call for text block, got %+v", tc)
+	}
+}
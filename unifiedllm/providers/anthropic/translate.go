@@ -0,0 +1,367 @@
+package anthropic
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+
+	"github.com/martinemde/attractor/unifiedllm"
+)
+
+// anthropicMessage is one turn of an Anthropic Messages API request/response.
+type anthropicMessage struct {
+	Role    string                  `json:"role"`
+	Content []anthropicContentBlock `json:"content"`
+}
+
+// anthropicContentBlock mirrors Anthropic's content block union: the Type
+// field discriminates which of the other fields is populated.
+type anthropicContentBlock struct {
+	Type         string                  `json:"type"`
+	Text         string                  `json:"text,omitempty"`
+	ID           string                  `json:"id,omitempty"`
+	Name         string                  `json:"name,omitempty"`
+	Input        map[string]interface{}  `json:"input,omitempty"`
+	ToolUseID    string                  `json:"tool_use_id,omitempty"`
+	Content      []anthropicContentBlock `json:"content,omitempty"`
+	IsError      bool                    `json:"is_error,omitempty"`
+	Source       *anthropicImageSource   `json:"source,omitempty"`
+	CacheControl *anthropicCacheControl  `json:"cache_control,omitempty"`
+}
+
+// anthropicCacheControl marks a content block for Anthropic's prompt-caching,
+// mirroring unifiedllm.CacheHint.
+type anthropicCacheControl struct {
+	Type string `json:"type"`          // "ephemeral"
+	TTL  string `json:"ttl,omitempty"` // e.g. "5m", "1h"
+}
+
+type anthropicImageSource struct {
+	Type      string `json:"type"` // "base64"
+	MediaType string `json:"media_type"`
+	Data      string `json:"data"`
+}
+
+type anthropicTool struct {
+	Name        string                 `json:"name"`
+	Description string                 `json:"description,omitempty"`
+	InputSchema map[string]interface{} `json:"input_schema,omitempty"`
+}
+
+type anthropicRequest struct {
+	Model         string             `json:"model"`
+	MaxTokens     int                `json:"max_tokens"`
+	Messages      []anthropicMessage `json:"messages"`
+	System        string             `json:"-"`
+	Tools         []anthropicTool    `json:"tools,omitempty"`
+	Temperature   *float64           `json:"temperature,omitempty"`
+	TopP          *float64           `json:"top_p,omitempty"`
+	StopSequences []string           `json:"stop_sequences,omitempty"`
+	Stream        bool               `json:"stream,omitempty"`
+
+	// SystemCacheControl marks System for prompt-caching; when set, System is
+	// encoded as a single cache-annotated text block instead of a bare string,
+	// since that's the only shape Anthropic accepts cache_control on.
+	SystemCacheControl *anthropicCacheControl `json:"-"`
+}
+
+// anthropicRequestWire is the JSON shape sent over the wire; it exists
+// separately from anthropicRequest so System can stay a plain Go string for
+// callers and tests while still supporting the cache-annotated array form.
+type anthropicRequestWire struct {
+	Model         string             `json:"model"`
+	MaxTokens     int                `json:"max_tokens"`
+	Messages      []anthropicMessage `json:"messages"`
+	System        interface{}        `json:"system,omitempty"`
+	Tools         []anthropicTool    `json:"tools,omitempty"`
+	Temperature   *float64           `json:"temperature,omitempty"`
+	TopP          *float64           `json:"top_p,omitempty"`
+	StopSequences []string           `json:"stop_sequences,omitempty"`
+	Stream        bool               `json:"stream,omitempty"`
+}
+
+// MarshalJSON encodes System as a bare string, or as a single cache-annotated
+// text block when SystemCacheControl is set.
+func (ar anthropicRequest) MarshalJSON() ([]byte, error) {
+	wire := anthropicRequestWire{
+		Model:         ar.Model,
+		MaxTokens:     ar.MaxTokens,
+		Messages:      ar.Messages,
+		Tools:         ar.Tools,
+		Temperature:   ar.Temperature,
+		TopP:          ar.TopP,
+		StopSequences: ar.StopSequences,
+		Stream:        ar.Stream,
+	}
+	if ar.System != "" {
+		if ar.SystemCacheControl != nil {
+			wire.System = []anthropicContentBlock{{
+				Type:         "text",
+				Text:         ar.System,
+				CacheControl: ar.SystemCacheControl,
+			}}
+		} else {
+			wire.System = ar.System
+		}
+	}
+	return json.Marshal(wire)
+}
+
+type anthropicUsage struct {
+	InputTokens  int `json:"input_tokens"`
+	OutputTokens int `json:"output_tokens"`
+}
+
+type anthropicResponse struct {
+	ID         string                  `json:"id"`
+	Model      string                  `json:"model"`
+	Content    []anthropicContentBlock `json:"content"`
+	StopReason string                  `json:"stop_reason"`
+	Usage      anthropicUsage          `json:"usage"`
+}
+
+// defaultMaxTokens is sent when the request doesn't specify one, since
+// Anthropic's Messages API requires max_tokens on every call.
+const defaultMaxTokens = 4096
+
+// defaultSystemCacheThreshold is the byte length above which a hoisted system
+// prompt is cached automatically, even without an explicit CacheHint. It
+// approximates Anthropic's documented minimum cacheable prompt size.
+const defaultSystemCacheThreshold = 4096
+
+// translateRequest maps a unifiedllm.Request onto Anthropic's Messages API
+// schema: RoleSystem/RoleDeveloper messages are hoisted into the top-level
+// System string rather than appearing in messages.
+func translateRequest(req unifiedllm.Request) anthropicRequest {
+	return translateRequestWithCacheThreshold(req, defaultSystemCacheThreshold)
+}
+
+// translateRequestWithCacheThreshold is translateRequest with the
+// system-prompt auto-cache byte threshold configurable, so callers such as
+// Adapter can tune it via an Option.
+func translateRequestWithCacheThreshold(req unifiedllm.Request, systemCacheThreshold int) anthropicRequest {
+	ar := anthropicRequest{
+		Model:         req.Model,
+		MaxTokens:     defaultMaxTokens,
+		Temperature:   req.Temperature,
+		TopP:          req.TopP,
+		StopSequences: req.StopSequences,
+	}
+	if req.MaxTokens != nil {
+		ar.MaxTokens = *req.MaxTokens
+	}
+
+	var system string
+	var systemCache *unifiedllm.CacheHint
+	for _, msg := range req.Messages {
+		if msg.Role == unifiedllm.RoleSystem || msg.Role == unifiedllm.RoleDeveloper {
+			if system != "" {
+				system += "\n"
+			}
+			system += msg.TextContent()
+			if msg.CacheControl != nil {
+				systemCache = msg.CacheControl
+			}
+			continue
+		}
+		blocks := translateContentParts(msg)
+		if len(blocks) == 0 {
+			continue
+		}
+		ar.Messages = append(ar.Messages, anthropicMessage{
+			Role:    anthropicRole(msg.Role),
+			Content: blocks,
+		})
+	}
+	ar.System = system
+	if systemCache == nil && systemCacheThreshold > 0 && len(system) >= systemCacheThreshold {
+		systemCache = &unifiedllm.CacheHint{Type: "ephemeral"}
+	}
+	ar.SystemCacheControl = anthropicCacheControlFrom(systemCache)
+
+	if len(req.ToolDefs) > 0 {
+		tools := make([]anthropicTool, len(req.ToolDefs))
+		for i, td := range req.ToolDefs {
+			tools[i] = anthropicTool{
+				Name:        td.Name,
+				Description: td.Description,
+				InputSchema: td.Parameters,
+			}
+		}
+		ar.Tools = tools
+	}
+
+	return ar
+}
+
+// anthropicRole maps a unifiedllm.Role onto Anthropic's two-role scheme.
+// Anthropic has no separate tool role; tool results travel as user-role
+// blocks alongside the rest of the turn.
+func anthropicRole(role unifiedllm.Role) string {
+	if role == unifiedllm.RoleAssistant {
+		return "assistant"
+	}
+	return "user"
+}
+
+// translateContentParts maps a Message's ContentPart slice onto Anthropic
+// content blocks. ContentToolCall becomes a tool_use block; ContentToolResult
+// becomes a tool_result block whose content may carry a nested image block
+// when ToolResultData.ImageData is set. A ContentPart's own CacheControl is
+// attached to the block it produces; if msg.CacheControl is set, it's
+// attached to the last block regardless, since Anthropic only honors
+// cache_control on the final block of a cached prefix.
+func translateContentParts(msg unifiedllm.Message) []anthropicContentBlock {
+	var blocks []anthropicContentBlock
+	for _, cp := range msg.Content {
+		before := len(blocks)
+		switch cp.Kind {
+		case unifiedllm.ContentText:
+			if cp.Text != "" {
+				blocks = append(blocks, anthropicContentBlock{Type: "text", Text: cp.Text})
+			}
+		case unifiedllm.ContentToolCall:
+			if cp.ToolCall == nil {
+				continue
+			}
+			var input map[string]interface{}
+			_ = json.Unmarshal(cp.ToolCall.Arguments, &input)
+			blocks = append(blocks, anthropicContentBlock{
+				Type:  "tool_use",
+				ID:    cp.ToolCall.ID,
+				Name:  cp.ToolCall.Name,
+				Input: input,
+			})
+		case unifiedllm.ContentToolResult:
+			if cp.ToolResult == nil {
+				continue
+			}
+			blocks = append(blocks, toolResultBlock(cp.ToolResult))
+		case unifiedllm.ContentImage:
+			if cp.Image != nil && len(cp.Image.Data) > 0 {
+				blocks = append(blocks, imageBlock(cp.Image.Data, cp.Image.MediaType))
+			}
+		}
+		if len(blocks) > before && cp.CacheControl != nil {
+			blocks[len(blocks)-1].CacheControl = anthropicCacheControlFrom(cp.CacheControl)
+		}
+	}
+	if msg.CacheControl != nil && len(blocks) > 0 {
+		blocks[len(blocks)-1].CacheControl = anthropicCacheControlFrom(msg.CacheControl)
+	}
+	return blocks
+}
+
+// anthropicCacheControlFrom maps a unifiedllm.CacheHint onto Anthropic's
+// cache_control block annotation, defaulting Type to "ephemeral".
+func anthropicCacheControlFrom(hint *unifiedllm.CacheHint) *anthropicCacheControl {
+	if hint == nil {
+		return nil
+	}
+	cc := &anthropicCacheControl{Type: hint.Type}
+	if cc.Type == "" {
+		cc.Type = "ephemeral"
+	}
+	if hint.TTL > 0 {
+		cc.TTL = hint.TTL.String()
+	}
+	return cc
+}
+
+func toolResultBlock(tr *unifiedllm.ToolResultData) anthropicContentBlock {
+	var content []anthropicContentBlock
+	var text string
+	if err := json.Unmarshal(tr.Content, &text); err != nil {
+		text = string(tr.Content)
+	}
+	if text != "" {
+		content = append(content, anthropicContentBlock{Type: "text", Text: text})
+	}
+	if len(tr.ImageData) > 0 {
+		content = append(content, imageBlock(tr.ImageData, tr.ImageMediaType))
+	}
+	return anthropicContentBlock{
+		Type:      "tool_result",
+		ToolUseID: tr.ToolCallID,
+		Content:   content,
+		IsError:   tr.IsError,
+	}
+}
+
+func imageBlock(data []byte, mediaType string) anthropicContentBlock {
+	if mediaType == "" {
+		mediaType = "image/png"
+	}
+	return anthropicContentBlock{
+		Type: "image",
+		Source: &anthropicImageSource{
+			Type:      "base64",
+			MediaType: mediaType,
+			Data:      base64.StdEncoding.EncodeToString(data),
+		},
+	}
+}
+
+// buildResponse translates an Anthropic response into the unified Response
+// type, populating Usage from usage and mapping stop_reason to FinishReason.
+func buildResponse(model string, raw *anthropicResponse) *unifiedllm.Response {
+	var content []unifiedllm.ContentPart
+	for _, block := range raw.Content {
+		switch block.Type {
+		case "text":
+			content = append(content, unifiedllm.TextPart(block.Text))
+		case "tool_use":
+			args, _ := json.Marshal(block.Input)
+			content = append(content, unifiedllm.ToolCallPart(block.ID, block.Name, args))
+		}
+	}
+
+	return &unifiedllm.Response{
+		ID:       raw.ID,
+		Model:    model,
+		Provider: "anthropic",
+		Message: unifiedllm.Message{
+			Role:    unifiedllm.RoleAssistant,
+			Content: content,
+		},
+		FinishReason: translateStopReason(raw.StopReason),
+		Usage: unifiedllm.Usage{
+			InputTokens:  raw.Usage.InputTokens,
+			OutputTokens: raw.Usage.OutputTokens,
+			TotalTokens:  raw.Usage.InputTokens + raw.Usage.OutputTokens,
+		},
+	}
+}
+
+// translateStopReason maps Anthropic's stop_reason onto the unified
+// FinishReason, in particular translating "tool_use" to "tool_calls".
+func translateStopReason(raw string) unifiedllm.FinishReason {
+	switch raw {
+	case "tool_use":
+		return unifiedllm.FinishReason{Reason: "tool_calls", Raw: raw}
+	case "max_tokens":
+		return unifiedllm.FinishReason{Reason: "length", Raw: raw}
+	case "stop_sequence", "end_turn", "":
+		return unifiedllm.FinishReason{Reason: "stop", Raw: raw}
+	default:
+		return unifiedllm.FinishReason{Reason: "other", Raw: raw}
+	}
+}
+
+// translateHTTPError maps an Anthropic HTTP error response onto the unified
+// error hierarchy via unifiedllm.ErrorFromStatusCode.
+func translateHTTPError(statusCode int, body []byte) error {
+	var parsed struct {
+		Error struct {
+			Type    string `json:"type"`
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	_ = json.Unmarshal(body, &parsed)
+
+	message := parsed.Error.Message
+	if message == "" {
+		message = fmt.Sprintf("anthropic: request failed with status %d", statusCode)
+	}
+	return unifiedllm.ErrorFromStatusCode(statusCode, message, "anthropic", parsed.Error.Type, nil, nil)
+}
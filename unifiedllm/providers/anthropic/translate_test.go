@@ -0,0 +1,168 @@
+package anthropic
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/martinemde/attractor/unifiedllm"
+)
+
+func TestTranslateRequestHoistsSystemMessage(t *testing.T) {
+	req := unifiedllm.Request{
+		Messages: []unifiedllm.Message{
+			unifiedllm.SystemMessage("be concise"),
+			unifiedllm.UserMessage("hello"),
+		},
+	}
+
+	ar := translateRequest(req)
+	if ar.System != "be concise" {
+		t.Fatalf("expected system to be hoisted, got %q", ar.System)
+	}
+	if len(ar.Messages) != 1 || ar.Messages[0].Role != "user" {
+		t.Fatalf("expected one user message, got %+v", ar.Messages)
+	}
+}
+
+func TestTranslateContentPartsEmitsToolUseBlock(t *testing.T) {
+	msg := unifiedllm.Message{
+		Role: unifiedllm.RoleAssistant,
+		Content: []unifiedllm.ContentPart{
+			unifiedllm.ToolCallPart("toolu_1", "get_weather", json.RawMessage(`{"city":"Paris"}`)),
+		},
+	}
+
+	blocks := translateContentParts(msg)
+	if len(blocks) != 1 || blocks[0].Type != "tool_use" {
+		t.Fatalf("expected one tool_use block, got %+v", blocks)
+	}
+	if blocks[0].ID != "toolu_1" || blocks[0].Name != "get_weather" {
+		t.Errorf("unexpected tool_use block: %+v", blocks[0])
+	}
+	if blocks[0].Input["city"] != "Paris" {
+		t.Errorf("expected input city Paris, got %v", blocks[0].Input)
+	}
+}
+
+func TestTranslateContentPartsEmitsToolResultWithImage(t *testing.T) {
+	raw, _ := json.Marshal("32 degrees")
+	msg := unifiedllm.Message{
+		Role: unifiedllm.RoleTool,
+		Content: []unifiedllm.ContentPart{
+			{
+				Kind: unifiedllm.ContentToolResult,
+				ToolResult: &unifiedllm.ToolResultData{
+					ToolCallID:     "toolu_1",
+					Content:        raw,
+					ImageData:      []byte{1, 2, 3},
+					ImageMediaType: "image/png",
+				},
+			},
+		},
+	}
+
+	blocks := translateContentParts(msg)
+	if len(blocks) != 1 || blocks[0].Type != "tool_result" {
+		t.Fatalf("expected one tool_result block, got %+v", blocks)
+	}
+	if blocks[0].ToolUseID != "toolu_1" {
+		t.Errorf("expected tool_use_id toolu_1, got %q", blocks[0].ToolUseID)
+	}
+	if len(blocks[0].Content) != 2 || blocks[0].Content[1].Type != "image" {
+		t.Fatalf("expected nested text and image blocks, got %+v", blocks[0].Content)
+	}
+}
+
+func TestBuildResponseMapsToolUseStopReason(t *testing.T) {
+	raw := &anthropicResponse{
+		ID: "msg_1",
+		Content: []anthropicContentBlock{
+			{Type: "tool_use", ID: "toolu_1", Name: "get_weather", Input: map[string]interface{}{"city": "Paris"}},
+		},
+		StopReason: "tool_use",
+		Usage:      anthropicUsage{InputTokens: 10, OutputTokens: 5},
+	}
+
+	resp := buildResponse("claude-sonnet-4-5", raw)
+	if resp.FinishReason.Reason != "tool_calls" {
+		t.Errorf("expected finish reason tool_calls, got %q", resp.FinishReason.Reason)
+	}
+	calls := resp.ToolCallsFromResponse()
+	if len(calls) != 1 || calls[0].Name != "get_weather" {
+		t.Fatalf("expected one get_weather tool call, got %+v", calls)
+	}
+	if resp.Usage.TotalTokens != 15 {
+		t.Errorf("expected total tokens 15, got %d", resp.Usage.TotalTokens)
+	}
+}
+
+func TestTranslateContentPartsAttachesCacheControlToLastBlock(t *testing.T) {
+	msg := unifiedllm.Message{
+		Role: unifiedllm.RoleUser,
+		Content: []unifiedllm.ContentPart{
+			unifiedllm.TextPart("first"),
+			unifiedllm.TextPart("second"),
+		},
+		CacheControl: &unifiedllm.CacheHint{Type: "ephemeral"},
+	}
+
+	blocks := translateContentParts(msg)
+	if len(blocks) != 2 {
+		t.Fatalf("expected two blocks, got %d", len(blocks))
+	}
+	if blocks[0].CacheControl != nil {
+		t.Errorf("expected no cache_control on the first block, got %+v", blocks[0].CacheControl)
+	}
+	if blocks[1].CacheControl == nil || blocks[1].CacheControl.Type != "ephemeral" {
+		t.Fatalf("expected cache_control ephemeral on the last block, got %+v", blocks[1].CacheControl)
+	}
+}
+
+func TestTranslateRequestMarshalsCachedSystemAsBlockArray(t *testing.T) {
+	req := unifiedllm.Request{
+		Messages: []unifiedllm.Message{
+			unifiedllm.SystemMessageCached("be concise", 0),
+			unifiedllm.UserMessage("hello"),
+		},
+	}
+
+	ar := translateRequestWithCacheThreshold(req, defaultSystemCacheThreshold)
+	if ar.System != "be concise" {
+		t.Fatalf("expected system to be hoisted, got %q", ar.System)
+	}
+
+	body, err := json.Marshal(ar)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	if !strings.Contains(string(body), `"system":[{"type":"text","text":"be concise","cache_control":{"type":"ephemeral"}}]`) {
+		t.Fatalf("expected system to be encoded as a cache-annotated block array, got %s", body)
+	}
+}
+
+func TestTranslateRequestAutoPromotesLongSystemPrompt(t *testing.T) {
+	req := unifiedllm.Request{
+		Messages: []unifiedllm.Message{
+			unifiedllm.SystemMessage(strings.Repeat("x", 10)),
+		},
+	}
+
+	ar := translateRequestWithCacheThreshold(req, 5)
+	if ar.SystemCacheControl == nil {
+		t.Fatal("expected a long system prompt to be auto-promoted for caching")
+	}
+
+	ar = translateRequestWithCacheThreshold(req, 0)
+	if ar.SystemCacheControl != nil {
+		t.Errorf("expected auto-promotion disabled when threshold is 0, got %+v", ar.SystemCacheControl)
+	}
+}
+
+func TestTranslateHTTPError(t *testing.T) {
+	body := []byte(`{"error":{"type":"authentication_error","message":"invalid x-api-key"}}`)
+	err := translateHTTPError(401, body)
+	if _, ok := err.(*unifiedllm.AuthenticationError); !ok {
+		t.Errorf("expected AuthenticationError, got %T", err)
+	}
+}
@@ -0,0 +1,243 @@
+// Package google implements a native unifiedllm.ProviderAdapter for the
+// Google Gemini API, talking directly to the generateContent/
+// streamGenerateContent REST endpoints instead of going through gollm's
+// text-scraping translation layer.
+package google
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/martinemde/attractor/unifiedllm"
+)
+
+const defaultBaseURL = "https://generativelanguage.googleapis.com/v1beta"
+
+// Adapter implements unifiedllm.ProviderAdapter against the Gemini
+// generateContent API.
+type Adapter struct {
+	apiKey  string
+	baseURL string
+	model   string
+	http    *http.Client
+}
+
+// Option configures an Adapter.
+type Option func(*Adapter)
+
+// WithBaseURL overrides the default Gemini API base URL (for proxies or
+// testing).
+func WithBaseURL(url string) Option {
+	return func(a *Adapter) { a.baseURL = strings.TrimRight(url, "/") }
+}
+
+// WithModel sets the default model used when a Request doesn't specify one.
+func WithModel(model string) Option {
+	return func(a *Adapter) { a.model = model }
+}
+
+// WithHTTPClient overrides the default *http.Client.
+func WithHTTPClient(client *http.Client) Option {
+	return func(a *Adapter) { a.http = client }
+}
+
+// NewAdapter creates a Gemini provider adapter authenticated with apiKey.
+func NewAdapter(apiKey string, opts ...Option) *Adapter {
+	a := &Adapter{
+		apiKey:  apiKey,
+		baseURL: defaultBaseURL,
+		http:    http.DefaultClient,
+	}
+	for _, opt := range opts {
+		opt(a)
+	}
+	return a
+}
+
+// Name returns the provider identifier.
+func (a *Adapter) Name() string { return "gemini" }
+
+// Complete sends a blocking generateContent request.
+func (a *Adapter) Complete(ctx context.Context, req unifiedllm.Request) (*unifiedllm.Response, error) {
+	model := a.modelFor(req)
+	body, err := json.Marshal(translateRequest(req))
+	if err != nil {
+		return nil, fmt.Errorf("gemini: encode request: %w", err)
+	}
+
+	url := fmt.Sprintf("%s/models/%s:generateContent?key=%s", a.baseURL, model, a.apiKey)
+	httpResp, err := a.doPost(ctx, url, body)
+	if err != nil {
+		return nil, err
+	}
+	defer httpResp.Body.Close()
+
+	data, err := io.ReadAll(httpResp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("gemini: read response: %w", err)
+	}
+	if httpResp.StatusCode >= 400 {
+		return nil, translateHTTPError(httpResp.StatusCode, data)
+	}
+
+	var raw geminiResponse
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("gemini: decode response: %w", err)
+	}
+	resp := buildResponse(model, &raw)
+	resp.Warnings = append(resp.Warnings, collectCacheWarnings(req)...)
+	return resp, nil
+}
+
+// Stream sends a streamGenerateContent request and translates each SSE
+// chunk into a unifiedllm.StreamEvent.
+func (a *Adapter) Stream(ctx context.Context, req unifiedllm.Request) (<-chan unifiedllm.StreamEvent, error) {
+	model := a.modelFor(req)
+	body, err := json.Marshal(translateRequest(req))
+	if err != nil {
+		return nil, fmt.Errorf("gemini: encode request: %w", err)
+	}
+
+	url := fmt.Sprintf("%s/models/%s:streamGenerateContent?alt=sse&key=%s", a.baseURL, model, a.apiKey)
+	httpResp, err := a.doPost(ctx, url, body)
+	if err != nil {
+		return nil, err
+	}
+	if httpResp.StatusCode >= 400 {
+		defer httpResp.Body.Close()
+		data, _ := io.ReadAll(httpResp.Body)
+		return nil, translateHTTPError(httpResp.StatusCode, data)
+	}
+
+	ch := make(chan unifiedllm.StreamEvent, 16)
+	go a.consumeStream(httpResp.Body, model, collectCacheWarnings(req), ch)
+	return ch, nil
+}
+
+func (a *Adapter) doPost(ctx context.Context, url string, body []byte) (*http.Response, error) {
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("gemini: build request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	resp, err := a.http.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("gemini: request: %w", err)
+	}
+	return resp, nil
+}
+
+func (a *Adapter) modelFor(req unifiedllm.Request) string {
+	if req.Model != "" {
+		return req.Model
+	}
+	return a.model
+}
+
+// consumeStream reads "data: {...}" SSE lines and emits
+// TextDelta/ToolCallStart/ToolCallDelta/ToolCallEnd/StreamFinish events.
+// It always closes ch and body.
+func (a *Adapter) consumeStream(body io.ReadCloser, model string, warnings []unifiedllm.Warning, ch chan<- unifiedllm.StreamEvent) {
+	defer close(ch)
+	defer body.Close()
+
+	acc := &streamAccumulator{}
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	ch <- unifiedllm.StreamEvent{Type: unifiedllm.StreamStart}
+
+	for scanner.Scan() {
+		line := scanner.Text()
+		if !strings.HasPrefix(line, "data: ") {
+			continue
+		}
+		payload := strings.TrimPrefix(line, "data: ")
+		if payload == "[DONE]" {
+			break
+		}
+
+		var chunk geminiResponse
+		if err := json.Unmarshal([]byte(payload), &chunk); err != nil {
+			ch <- unifiedllm.StreamEvent{Type: unifiedllm.StreamError, Error: fmt.Errorf("gemini: decode stream chunk: %w", err)}
+			return
+		}
+		acc.ingest(&chunk)
+
+		for _, part := range latestCandidateParts(&chunk) {
+			if part.Text != "" {
+				ch <- unifiedllm.StreamEvent{Type: unifiedllm.TextDelta, Delta: part.Text}
+			}
+			if part.FunctionCall != nil {
+				argsJSON, _ := encodeFunctionArgs(part.FunctionCall.Args)
+				id := "call_" + uuid.New().String()[:8]
+				// Gemini delivers a functionCall whole within one chunk rather
+				// than fragmenting its arguments, so Start and End bracket a
+				// single Delta instead of many incremental ones.
+				ch <- unifiedllm.StreamEvent{
+					Type:     unifiedllm.ToolCallStart,
+					ToolCall: &unifiedllm.ToolCall{ID: id, Name: part.FunctionCall.Name},
+				}
+				ch <- unifiedllm.StreamEvent{
+					Type:     unifiedllm.ToolCallDelta,
+					ToolCall: &unifiedllm.ToolCall{ID: id},
+					Delta:    string(argsJSON),
+				}
+				ch <- unifiedllm.StreamEvent{
+					Type: unifiedllm.ToolCallEnd,
+					ToolCall: &unifiedllm.ToolCall{
+						ID:        id,
+						Name:      part.FunctionCall.Name,
+						Arguments: argsJSON,
+					},
+				}
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		ch <- unifiedllm.StreamEvent{Type: unifiedllm.StreamError, Error: fmt.Errorf("gemini: read stream: %w", err)}
+		return
+	}
+
+	resp := buildResponse(model, acc.final())
+	resp.Warnings = append(resp.Warnings, warnings...)
+	ch <- unifiedllm.StreamEvent{
+		Type:         unifiedllm.StreamFinish,
+		FinishReason: &resp.FinishReason,
+		Usage:        &resp.Usage,
+		Response:     resp,
+	}
+}
+
+// streamAccumulator tracks the most recently seen usageMetadata and finish
+// reason across SSE chunks, since Gemini repeats them on every chunk rather
+// than sending a single terminal summary.
+type streamAccumulator struct {
+	last *geminiResponse
+}
+
+func (a *streamAccumulator) ingest(chunk *geminiResponse) {
+	a.last = chunk
+}
+
+func (a *streamAccumulator) final() *geminiResponse {
+	if a.last == nil {
+		return &geminiResponse{}
+	}
+	return a.last
+}
+
+func latestCandidateParts(chunk *geminiResponse) []geminiPart {
+	if len(chunk.Candidates) == 0 {
+		return nil
+	}
+	return chunk.Candidates[0].Content.Parts
+}
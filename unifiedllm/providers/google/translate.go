@@ -0,0 +1,298 @@
+package google
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/martinemde/attractor/unifiedllm"
+)
+
+// geminiContent is one turn of a Gemini generateContent request/response.
+type geminiContent struct {
+	Role  string       `json:"role,omitempty"`
+	Parts []geminiPart `json:"parts"`
+}
+
+// geminiPart mirrors Gemini's Part union: exactly one field is set.
+type geminiPart struct {
+	Text             string                `json:"text,omitempty"`
+	FunctionCall     *geminiFunctionCall   `json:"functionCall,omitempty"`
+	FunctionResponse *geminiFunctionResult `json:"functionResponse,omitempty"`
+	InlineData       *geminiInlineData     `json:"inlineData,omitempty"`
+}
+
+type geminiFunctionCall struct {
+	Name string                 `json:"name"`
+	Args map[string]interface{} `json:"args"`
+}
+
+type geminiFunctionResult struct {
+	Name     string                 `json:"name"`
+	Response map[string]interface{} `json:"response"`
+}
+
+type geminiInlineData struct {
+	MimeType string `json:"mimeType"`
+	Data     string `json:"data"` // base64
+}
+
+type geminiFunctionDeclaration struct {
+	Name        string                 `json:"name"`
+	Description string                 `json:"description,omitempty"`
+	Parameters  map[string]interface{} `json:"parameters,omitempty"`
+}
+
+type geminiTool struct {
+	FunctionDeclarations []geminiFunctionDeclaration `json:"functionDeclarations"`
+}
+
+type geminiGenerationConfig struct {
+	Temperature     *float64 `json:"temperature,omitempty"`
+	TopP            *float64 `json:"topP,omitempty"`
+	MaxOutputTokens *int     `json:"maxOutputTokens,omitempty"`
+	StopSequences   []string `json:"stopSequences,omitempty"`
+}
+
+type geminiRequest struct {
+	Contents          []geminiContent         `json:"contents"`
+	SystemInstruction *geminiContent          `json:"systemInstruction,omitempty"`
+	Tools             []geminiTool            `json:"tools,omitempty"`
+	GenerationConfig  *geminiGenerationConfig `json:"generationConfig,omitempty"`
+}
+
+type geminiUsageMetadata struct {
+	PromptTokenCount     int `json:"promptTokenCount"`
+	CandidatesTokenCount int `json:"candidatesTokenCount"`
+	TotalTokenCount      int `json:"totalTokenCount"`
+}
+
+type geminiCandidate struct {
+	Content      geminiContent `json:"content"`
+	FinishReason string        `json:"finishReason"`
+}
+
+type geminiResponse struct {
+	Candidates    []geminiCandidate    `json:"candidates"`
+	UsageMetadata *geminiUsageMetadata `json:"usageMetadata,omitempty"`
+}
+
+// translateRequest maps a unifiedllm.Request onto Gemini's generateContent
+// schema: RoleSystem messages are hoisted into systemInstruction rather
+// than appearing in contents, and assistant/user roles become "model"/"user".
+func translateRequest(req unifiedllm.Request) geminiRequest {
+	var gr geminiRequest
+
+	for _, msg := range req.Messages {
+		parts := translateContentParts(msg)
+		if len(parts) == 0 {
+			continue
+		}
+		if msg.Role == unifiedllm.RoleSystem {
+			gr.SystemInstruction = mergeSystemInstruction(gr.SystemInstruction, parts)
+			continue
+		}
+		gr.Contents = append(gr.Contents, geminiContent{
+			Role:  geminiRole(msg.Role),
+			Parts: parts,
+		})
+	}
+
+	if len(req.ToolDefs) > 0 {
+		decls := make([]geminiFunctionDeclaration, len(req.ToolDefs))
+		for i, td := range req.ToolDefs {
+			decls[i] = geminiFunctionDeclaration{
+				Name:        td.Name,
+				Description: td.Description,
+				Parameters:  td.Parameters,
+			}
+		}
+		gr.Tools = []geminiTool{{FunctionDeclarations: decls}}
+	}
+
+	if req.Temperature != nil || req.TopP != nil || req.MaxTokens != nil || len(req.StopSequences) > 0 {
+		gr.GenerationConfig = &geminiGenerationConfig{
+			Temperature:     req.Temperature,
+			TopP:            req.TopP,
+			MaxOutputTokens: req.MaxTokens,
+			StopSequences:   req.StopSequences,
+		}
+	}
+
+	return gr
+}
+
+// collectCacheWarnings scans req for CacheControl hints. Gemini's caching
+// model is a separate cached-content resource rather than an inline request
+// annotation, so this adapter doesn't create one on the fly; any requested
+// hint is reported back as a Warning instead of silently doing nothing.
+func collectCacheWarnings(req unifiedllm.Request) []unifiedllm.Warning {
+	var warnings []unifiedllm.Warning
+	seen := false
+	for _, msg := range req.Messages {
+		if msg.CacheControl != nil {
+			seen = true
+		}
+		for _, cp := range msg.Content {
+			if cp.CacheControl != nil {
+				seen = true
+			}
+		}
+	}
+	if seen {
+		warnings = append(warnings, unifiedllm.Warning{
+			Message: "gemini: CacheControl hints are not supported by this adapter and were ignored; use Gemini's cached-content API directly",
+			Code:    "cache_control_unsupported",
+		})
+	}
+	return warnings
+}
+
+func mergeSystemInstruction(existing *geminiContent, parts []geminiPart) *geminiContent {
+	if existing == nil {
+		return &geminiContent{Parts: parts}
+	}
+	existing.Parts = append(existing.Parts, parts...)
+	return existing
+}
+
+func geminiRole(role unifiedllm.Role) string {
+	if role == unifiedllm.RoleAssistant {
+		return "model"
+	}
+	return "user"
+}
+
+// translateContentParts maps a Message's ContentPart slice onto Gemini
+// parts. ContentToolResult becomes a functionResponse part; Gemini has no
+// separate tool role, so tool results travel as user-role parts.
+func translateContentParts(msg unifiedllm.Message) []geminiPart {
+	var parts []geminiPart
+	for _, cp := range msg.Content {
+		switch cp.Kind {
+		case unifiedllm.ContentText:
+			if cp.Text != "" {
+				parts = append(parts, geminiPart{Text: cp.Text})
+			}
+		case unifiedllm.ContentToolCall:
+			if cp.ToolCall == nil {
+				continue
+			}
+			var args map[string]interface{}
+			_ = json.Unmarshal(cp.ToolCall.Arguments, &args)
+			parts = append(parts, geminiPart{FunctionCall: &geminiFunctionCall{
+				Name: cp.ToolCall.Name,
+				Args: args,
+			}})
+		case unifiedllm.ContentToolResult:
+			if cp.ToolResult == nil {
+				continue
+			}
+			var response map[string]interface{}
+			if err := json.Unmarshal(cp.ToolResult.Content, &response); err != nil {
+				response = map[string]interface{}{"result": string(cp.ToolResult.Content)}
+			}
+			parts = append(parts, geminiPart{FunctionResponse: &geminiFunctionResult{
+				Name:     msg.Name,
+				Response: response,
+			}})
+		case unifiedllm.ContentImage:
+			if cp.Image != nil && len(cp.Image.Data) > 0 {
+				parts = append(parts, geminiPart{InlineData: &geminiInlineData{
+					MimeType: cp.Image.MediaType,
+					Data:     base64.StdEncoding.EncodeToString(cp.Image.Data),
+				}})
+			}
+		}
+	}
+	return parts
+}
+
+// encodeFunctionArgs re-encodes Gemini's map[string]interface{} function
+// call arguments into the json.RawMessage shape ToolCallData.Arguments
+// expects.
+func encodeFunctionArgs(args map[string]interface{}) (json.RawMessage, error) {
+	if args == nil {
+		return json.RawMessage("{}"), nil
+	}
+	return json.Marshal(args)
+}
+
+// buildResponse translates a Gemini response into the unified Response
+// type, populating Usage from usageMetadata.
+func buildResponse(model string, raw *geminiResponse) *unifiedllm.Response {
+	var content []unifiedllm.ContentPart
+	finishReason := unifiedllm.FinishReason{Reason: "stop"}
+
+	if len(raw.Candidates) > 0 {
+		cand := raw.Candidates[0]
+		for _, part := range cand.Content.Parts {
+			switch {
+			case part.Text != "":
+				content = append(content, unifiedllm.TextPart(part.Text))
+			case part.FunctionCall != nil:
+				argsJSON, _ := encodeFunctionArgs(part.FunctionCall.Args)
+				content = append(content, unifiedllm.ToolCallPart("call_"+uuid.New().String()[:8], part.FunctionCall.Name, argsJSON))
+			}
+		}
+		finishReason = translateFinishReason(cand.FinishReason, content)
+	}
+
+	usage := unifiedllm.Usage{}
+	if raw.UsageMetadata != nil {
+		usage = unifiedllm.Usage{
+			InputTokens:  raw.UsageMetadata.PromptTokenCount,
+			OutputTokens: raw.UsageMetadata.CandidatesTokenCount,
+			TotalTokens:  raw.UsageMetadata.TotalTokenCount,
+		}
+	}
+
+	return &unifiedllm.Response{
+		ID:       "resp_" + uuid.New().String()[:8],
+		Model:    model,
+		Provider: "gemini",
+		Message: unifiedllm.Message{
+			Role:    unifiedllm.RoleAssistant,
+			Content: content,
+		},
+		FinishReason: finishReason,
+		Usage:        usage,
+	}
+}
+
+func translateFinishReason(raw string, content []unifiedllm.ContentPart) unifiedllm.FinishReason {
+	for _, part := range content {
+		if part.Kind == unifiedllm.ContentToolCall {
+			return unifiedllm.FinishReason{Reason: "tool_calls", Raw: raw}
+		}
+	}
+	switch raw {
+	case "MAX_TOKENS":
+		return unifiedllm.FinishReason{Reason: "length", Raw: raw}
+	case "SAFETY", "RECITATION":
+		return unifiedllm.FinishReason{Reason: "content_filter", Raw: raw}
+	case "STOP", "":
+		return unifiedllm.FinishReason{Reason: "stop", Raw: raw}
+	default:
+		return unifiedllm.FinishReason{Reason: "other", Raw: raw}
+	}
+}
+
+// translateHTTPError maps a Gemini HTTP error response onto the unified
+// error hierarchy via unifiedllm.ErrorFromStatusCode.
+func translateHTTPError(statusCode int, body []byte) error {
+	var parsed struct {
+		Error struct {
+			Message string `json:"message"`
+			Status  string `json:"status"`
+		} `json:"error"`
+	}
+	_ = json.Unmarshal(body, &parsed)
+
+	message := parsed.Error.Message
+	if message == "" {
+		message = fmt.Sprintf("gemini: request failed with status %d", statusCode)
+	}
+	return unifiedllm.ErrorFromStatusCode(statusCode, message, "gemini", parsed.Error.Status, nil, nil)
+}
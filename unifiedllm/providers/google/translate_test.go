@@ -0,0 +1,144 @@
+package google
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/martinemde/attractor/unifiedllm"
+)
+
+func TestTranslateRequestHoistsSystemMessage(t *testing.T) {
+	req := unifiedllm.Request{
+		Messages: []unifiedllm.Message{
+			unifiedllm.SystemMessage("be concise"),
+			unifiedllm.UserMessage("hello"),
+		},
+	}
+
+	gr := translateRequest(req)
+	if gr.SystemInstruction == nil || len(gr.SystemInstruction.Parts) != 1 || gr.SystemInstruction.Parts[0].Text != "be concise" {
+		t.Fatalf("expected system instruction to be hoisted, got %+v", gr.SystemInstruction)
+	}
+	if len(gr.Contents) != 1 || gr.Contents[0].Role != "user" {
+		t.Fatalf("expected one user content, got %+v", gr.Contents)
+	}
+}
+
+func TestTranslateRequestMapsAssistantRoleToModel(t *testing.T) {
+	req := unifiedllm.Request{
+		Messages: []unifiedllm.Message{
+			unifiedllm.UserMessage("hi"),
+			unifiedllm.AssistantMessage("hello"),
+		},
+	}
+
+	gr := translateRequest(req)
+	if len(gr.Contents) != 2 || gr.Contents[1].Role != "model" {
+		t.Fatalf("expected assistant role translated to \"model\", got %+v", gr.Contents)
+	}
+}
+
+func TestTranslateRequestMapsTools(t *testing.T) {
+	req := unifiedllm.Request{
+		ToolDefs: []unifiedllm.ToolDefinition{
+			{Name: "get_weather", Description: "looks up weather", Parameters: map[string]interface{}{"type": "object"}},
+		},
+	}
+
+	gr := translateRequest(req)
+	if len(gr.Tools) != 1 || len(gr.Tools[0].FunctionDeclarations) != 1 {
+		t.Fatalf("expected one function declaration, got %+v", gr.Tools)
+	}
+	if gr.Tools[0].FunctionDeclarations[0].Name != "get_weather" {
+		t.Errorf("expected name get_weather, got %q", gr.Tools[0].FunctionDeclarations[0].Name)
+	}
+}
+
+func TestEncodeFunctionArgsReencodesStringlyTypedArgs(t *testing.T) {
+	args := map[string]interface{}{"city": "Paris", "days": float64(3)}
+	raw, err := encodeFunctionArgs(args)
+	if err != nil {
+		t.Fatalf("encodeFunctionArgs: %v", err)
+	}
+
+	var decoded map[string]interface{}
+	if err := json.Unmarshal(raw, &decoded); err != nil {
+		t.Fatalf("decode re-encoded args: %v", err)
+	}
+	if decoded["city"] != "Paris" {
+		t.Errorf("expected city Paris, got %v", decoded["city"])
+	}
+}
+
+func TestBuildResponseMapsUsage(t *testing.T) {
+	raw := &geminiResponse{
+		Candidates: []geminiCandidate{
+			{Content: geminiContent{Parts: []geminiPart{{Text: "hi there"}}}, FinishReason: "STOP"},
+		},
+		UsageMetadata: &geminiUsageMetadata{PromptTokenCount: 10, CandidatesTokenCount: 5, TotalTokenCount: 15},
+	}
+
+	resp := buildResponse("gemini-3-pro-preview", raw)
+	if resp.Text() != "hi there" {
+		t.Errorf("expected text %q, got %q", "hi there", resp.Text())
+	}
+	if resp.Usage.InputTokens != 10 || resp.Usage.OutputTokens != 5 || resp.Usage.TotalTokens != 15 {
+		t.Errorf("unexpected usage: %+v", resp.Usage)
+	}
+	if resp.FinishReason.Reason != "stop" {
+		t.Errorf("expected finish reason stop, got %q", resp.FinishReason.Reason)
+	}
+}
+
+func TestBuildResponseDetectsToolCalls(t *testing.T) {
+	raw := &geminiResponse{
+		Candidates: []geminiCandidate{
+			{
+				Content: geminiContent{Parts: []geminiPart{
+					{FunctionCall: &geminiFunctionCall{Name: "get_weather", Args: map[string]interface{}{"city": "Paris"}}},
+				}},
+				FinishReason: "STOP",
+			},
+		},
+	}
+
+	resp := buildResponse("gemini-3-pro-preview", raw)
+	if resp.FinishReason.Reason != "tool_calls" {
+		t.Errorf("expected finish reason tool_calls, got %q", resp.FinishReason.Reason)
+	}
+	calls := resp.ToolCallsFromResponse()
+	if len(calls) != 1 || calls[0].Name != "get_weather" {
+		t.Fatalf("expected one get_weather tool call, got %+v", calls)
+	}
+}
+
+func TestCollectCacheWarningsFlagsUnsupportedHint(t *testing.T) {
+	req := unifiedllm.Request{
+		Messages: []unifiedllm.Message{
+			unifiedllm.SystemMessageCached("be concise", 0),
+		},
+	}
+
+	warnings := collectCacheWarnings(req)
+	if len(warnings) != 1 || warnings[0].Code != "cache_control_unsupported" {
+		t.Fatalf("expected one cache_control_unsupported warning, got %+v", warnings)
+	}
+}
+
+func TestCollectCacheWarningsIsEmptyWithoutHints(t *testing.T) {
+	req := unifiedllm.Request{
+		Messages: []unifiedllm.Message{unifiedllm.UserMessage("hi")},
+	}
+
+	if warnings := collectCacheWarnings(req); len(warnings) != 0 {
+		t.Errorf("expected no warnings, got %+v", warnings)
+	}
+}
+
+func TestTranslateHTTPError(t *testing.T) {
+	body := []byte(`{"error":{"message":"API key invalid","status":"UNAUTHENTICATED"}}`)
+	err := translateHTTPError(401, body)
+	if _, ok := err.(*unifiedllm.AuthenticationError); !ok {
+		t.Errorf("expected AuthenticationError, got %T", err)
+	}
+}
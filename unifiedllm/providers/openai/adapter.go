@@ -0,0 +1,348 @@
+// Package openai implements a native unifiedllm.ProviderAdapter for the
+// OpenAI Chat Completions API, using its native tools array (tool_calls)
+// rather than text-scraping a gollm completion.
+package openai
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/martinemde/attractor/unifiedllm"
+)
+
+const defaultBaseURL = "https://api.openai.com/v1"
+
+// Adapter implements unifiedllm.ProviderAdapter against the OpenAI Chat
+// Completions API.
+type Adapter struct {
+	apiKey  string
+	baseURL string
+	model   string
+	http    *http.Client
+}
+
+// Option configures an Adapter.
+type Option func(*Adapter)
+
+// WithBaseURL overrides the default OpenAI API base URL (for proxies,
+// Azure OpenAI, or testing).
+func WithBaseURL(url string) Option {
+	return func(a *Adapter) { a.baseURL = strings.TrimRight(url, "/") }
+}
+
+// WithModel sets the default model used when a Request doesn't specify one.
+func WithModel(model string) Option {
+	return func(a *Adapter) { a.model = model }
+}
+
+// WithHTTPClient overrides the default *http.Client.
+func WithHTTPClient(client *http.Client) Option {
+	return func(a *Adapter) { a.http = client }
+}
+
+// NewAdapter creates an OpenAI provider adapter authenticated with apiKey.
+func NewAdapter(apiKey string, opts ...Option) *Adapter {
+	a := &Adapter{
+		apiKey:  apiKey,
+		baseURL: defaultBaseURL,
+		http:    http.DefaultClient,
+	}
+	for _, opt := range opts {
+		opt(a)
+	}
+	return a
+}
+
+// Name returns the provider identifier.
+func (a *Adapter) Name() string { return "openai" }
+
+// Complete sends a blocking Chat Completions request.
+func (a *Adapter) Complete(ctx context.Context, req unifiedllm.Request) (*unifiedllm.Response, error) {
+	or := translateRequest(req)
+	or.Model = a.modelFor(req)
+
+	body, err := json.Marshal(or)
+	if err != nil {
+		return nil, fmt.Errorf("openai: encode request: %w", err)
+	}
+
+	httpResp, err := a.doPost(ctx, a.baseURL+"/chat/completions", body)
+	if err != nil {
+		return nil, err
+	}
+	defer httpResp.Body.Close()
+
+	data, err := io.ReadAll(httpResp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("openai: read response: %w", err)
+	}
+	if httpResp.StatusCode >= 400 {
+		return nil, translateHTTPError(httpResp.StatusCode, data)
+	}
+
+	var raw openaiResponse
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("openai: decode response: %w", err)
+	}
+	return buildResponse(or.Model, &raw), nil
+}
+
+// Stream sends a streaming Chat Completions request and translates each SSE
+// chunk into a unifiedllm.StreamEvent.
+func (a *Adapter) Stream(ctx context.Context, req unifiedllm.Request) (<-chan unifiedllm.StreamEvent, error) {
+	or := translateRequest(req)
+	or.Model = a.modelFor(req)
+	or.Stream = true
+
+	body, err := json.Marshal(streamRequest{openaiRequest: or, StreamOptions: &streamOptions{IncludeUsage: true}})
+	if err != nil {
+		return nil, fmt.Errorf("openai: encode request: %w", err)
+	}
+
+	httpResp, err := a.doPost(ctx, a.baseURL+"/chat/completions", body)
+	if err != nil {
+		return nil, err
+	}
+	if httpResp.StatusCode >= 400 {
+		defer httpResp.Body.Close()
+		data, _ := io.ReadAll(httpResp.Body)
+		return nil, translateHTTPError(httpResp.StatusCode, data)
+	}
+
+	ch := make(chan unifiedllm.StreamEvent, 16)
+	go a.consumeStream(httpResp.Body, or.Model, ch)
+	return ch, nil
+}
+
+func (a *Adapter) doPost(ctx context.Context, url string, body []byte) (*http.Response, error) {
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("openai: build request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("Authorization", "Bearer "+a.apiKey)
+
+	resp, err := a.http.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("openai: request: %w", err)
+	}
+	return resp, nil
+}
+
+func (a *Adapter) modelFor(req unifiedllm.Request) string {
+	if req.Model != "" {
+		return req.Model
+	}
+	return a.model
+}
+
+// streamOptions requests usage totals on the final streaming chunk, since
+// OpenAI omits usage from chunk deltas unless asked for it.
+type streamOptions struct {
+	IncludeUsage bool `json:"include_usage"`
+}
+
+// streamRequest embeds openaiRequest with stream_options, kept separate
+// from openaiRequest so Complete's request body doesn't carry it.
+type streamRequest struct {
+	openaiRequest
+	StreamOptions *streamOptions `json:"stream_options,omitempty"`
+}
+
+// streamDelta is one choice's incremental content in a streaming chunk.
+type streamDelta struct {
+	Content   string               `json:"content,omitempty"`
+	ToolCalls []streamToolCallDiff `json:"tool_calls,omitempty"`
+}
+
+// streamFunctionDiff is the function half of a streamToolCallDiff.
+type streamFunctionDiff struct {
+	Name      string `json:"name,omitempty"`
+	Arguments string `json:"arguments,omitempty"`
+}
+
+// streamToolCallDiff is a single tool_calls fragment: Index identifies
+// which tool call this fragment belongs to, since OpenAI may interleave
+// fragments for multiple parallel tool calls in one stream.
+type streamToolCallDiff struct {
+	Index    int                `json:"index"`
+	ID       string             `json:"id,omitempty"`
+	Function streamFunctionDiff `json:"function,omitempty"`
+}
+
+type streamChoice struct {
+	Delta        streamDelta `json:"delta"`
+	FinishReason *string     `json:"finish_reason"`
+}
+
+type streamChunk struct {
+	ID      string         `json:"id"`
+	Choices []streamChoice `json:"choices"`
+	Usage   *openaiUsage   `json:"usage"`
+}
+
+// consumeStream reads "data: {...}" SSE lines, accumulates tool_calls
+// argument fragments by index, and emits
+// TextDelta/ToolCallStart/ToolCallDelta/ToolCallEnd/StreamFinish events.
+// It always closes ch and body.
+func (a *Adapter) consumeStream(body io.ReadCloser, model string, ch chan<- unifiedllm.StreamEvent) {
+	defer close(ch)
+	defer body.Close()
+
+	acc := newStreamToolAccumulator()
+	var id, finishReason string
+	var usage openaiUsage
+
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	ch <- unifiedllm.StreamEvent{Type: unifiedllm.StreamStart}
+
+	for scanner.Scan() {
+		line := scanner.Text()
+		if !strings.HasPrefix(line, "data: ") {
+			continue
+		}
+		payload := strings.TrimPrefix(line, "data: ")
+		if payload == "[DONE]" {
+			break
+		}
+
+		var chunk streamChunk
+		if err := json.Unmarshal([]byte(payload), &chunk); err != nil {
+			ch <- unifiedllm.StreamEvent{Type: unifiedllm.StreamError, Error: fmt.Errorf("openai: decode stream chunk: %w", err)}
+			return
+		}
+		if chunk.ID != "" {
+			id = chunk.ID
+		}
+		if chunk.Usage != nil {
+			usage = *chunk.Usage
+		}
+		if len(chunk.Choices) == 0 {
+			continue
+		}
+		choice := chunk.Choices[0]
+		if choice.FinishReason != nil {
+			finishReason = *choice.FinishReason
+		}
+		if choice.Delta.Content != "" {
+			acc.text += choice.Delta.Content
+			ch <- unifiedllm.StreamEvent{Type: unifiedllm.TextDelta, Delta: choice.Delta.Content}
+		}
+		for _, diff := range choice.Delta.ToolCalls {
+			acc.apply(diff, ch)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		ch <- unifiedllm.StreamEvent{Type: unifiedllm.StreamError, Error: fmt.Errorf("openai: read stream: %w", err)}
+		return
+	}
+
+	toolCalls := acc.finish(ch)
+
+	resp := buildResponse(model, &openaiResponse{
+		ID: id,
+		Choices: []openaiChoice{{
+			Message:      openaiMessage{Role: "assistant", Content: acc.text, ToolCalls: toolCalls},
+			FinishReason: finishReason,
+		}},
+		Usage: usage,
+	})
+
+	ch <- unifiedllm.StreamEvent{
+		Type:         unifiedllm.StreamFinish,
+		FinishReason: &resp.FinishReason,
+		Usage:        &resp.Usage,
+		Response:     resp,
+	}
+}
+
+// streamToolCallPending tracks one tool call's fragments as they arrive.
+type streamToolCallPending struct {
+	id        string
+	name      string
+	arguments strings.Builder
+	startSent bool
+}
+
+// streamToolAccumulator assembles tool_calls deltas by index into complete
+// calls, tracking accumulated text separately so the final Response can be
+// assembled once the stream ends.
+type streamToolAccumulator struct {
+	text    string
+	pending map[int]*streamToolCallPending
+	order   []int
+}
+
+func newStreamToolAccumulator() *streamToolAccumulator {
+	return &streamToolAccumulator{pending: make(map[int]*streamToolCallPending)}
+}
+
+// apply folds one tool_calls fragment into the accumulator by index, since
+// OpenAI may interleave argument fragments for multiple parallel tool
+// calls in one stream. It emits ToolCallStart as soon as both an ID and a
+// name are known, and a ToolCallDelta for each non-empty arguments
+// fragment that arrives after that.
+func (a *streamToolAccumulator) apply(diff streamToolCallDiff, ch chan<- unifiedllm.StreamEvent) {
+	p, ok := a.pending[diff.Index]
+	if !ok {
+		p = &streamToolCallPending{}
+		a.pending[diff.Index] = p
+		a.order = append(a.order, diff.Index)
+	}
+	if diff.ID != "" {
+		p.id = diff.ID
+	}
+	if diff.Function.Name != "" {
+		p.name = diff.Function.Name
+	}
+	if !p.startSent && p.id != "" && p.name != "" {
+		p.startSent = true
+		ch <- unifiedllm.StreamEvent{Type: unifiedllm.ToolCallStart, ToolCall: &unifiedllm.ToolCall{ID: p.id, Name: p.name}}
+	}
+	if diff.Function.Arguments != "" {
+		p.arguments.WriteString(diff.Function.Arguments)
+		if p.startSent {
+			ch <- unifiedllm.StreamEvent{Type: unifiedllm.ToolCallDelta, ToolCall: &unifiedllm.ToolCall{ID: p.id}, Delta: diff.Function.Arguments}
+		}
+	}
+}
+
+// finish emits a ToolCallEnd for every tool call the accumulator saw, in
+// the order their index first appeared (emitting a deferred ToolCallStart
+// first for any call whose name never arrived in time to fire one during
+// apply), and returns them as openaiToolCall values for buildResponse.
+func (a *streamToolAccumulator) finish(ch chan<- unifiedllm.StreamEvent) []openaiToolCall {
+	var calls []openaiToolCall
+	for _, idx := range a.order {
+		p := a.pending[idx]
+		args := p.arguments.String()
+		if !p.startSent {
+			ch <- unifiedllm.StreamEvent{Type: unifiedllm.ToolCallStart, ToolCall: &unifiedllm.ToolCall{ID: p.id, Name: p.name}}
+		}
+		ch <- unifiedllm.StreamEvent{
+			Type: unifiedllm.ToolCallEnd,
+			ToolCall: &unifiedllm.ToolCall{
+				ID:        p.id,
+				Name:      p.name,
+				Arguments: json.RawMessage(args),
+			},
+		}
+		calls = append(calls, openaiToolCall{
+			ID:   p.id,
+			Type: "function",
+			Function: openaiFunctionCall{
+				Name:      p.name,
+				Arguments: args,
+			},
+		})
+	}
+	return calls
+}
@@ -0,0 +1,77 @@
+package openai
+
+import (
+	"testing"
+
+	"github.com/martinemde/attractor/unifiedllm"
+)
+
+func TestStreamToolAccumulatorAssemblesInterleavedFragments(t *testing.T) {
+	acc := newStreamToolAccumulator()
+	ch := make(chan unifiedllm.StreamEvent, 8)
+	acc.apply(streamToolCallDiff{Index: 0, ID: "call_1", Function: streamFunctionDiff{Name: "get_weather"}}, ch)
+	acc.apply(streamToolCallDiff{Index: 0, Function: streamFunctionDiff{Arguments: `{"city":`}}, ch)
+	acc.apply(streamToolCallDiff{Index: 0, Function: streamFunctionDiff{Arguments: `"Paris"}`}}, ch)
+
+	calls := acc.finish(ch)
+	close(ch)
+
+	if len(calls) != 1 {
+		t.Fatalf("expected one assembled tool call, got %d", len(calls))
+	}
+	if calls[0].ID != "call_1" || calls[0].Function.Name != "get_weather" {
+		t.Errorf("unexpected tool call: %+v", calls[0])
+	}
+	if calls[0].Function.Arguments != `{"city":"Paris"}` {
+		t.Errorf("expected reassembled arguments, got %q", calls[0].Function.Arguments)
+	}
+
+	var types []unifiedllm.StreamEventType
+	var deltas string
+	var end *unifiedllm.ToolCall
+	for evt := range ch {
+		types = append(types, evt.Type)
+		switch evt.Type {
+		case unifiedllm.ToolCallDelta:
+			deltas += evt.Delta
+		case unifiedllm.ToolCallEnd:
+			end = evt.ToolCall
+		}
+	}
+	if len(types) < 3 || types[0] != unifiedllm.ToolCallStart {
+		t.Fatalf("expected a leading ToolCallStart event, got %v", types)
+	}
+	if deltas != `{"city":"Paris"}` {
+		t.Errorf("expected deltas to reconstruct the arguments, got %q", deltas)
+	}
+	if end == nil || end.ID != "call_1" {
+		t.Fatalf("expected a ToolCallEnd event for call_1, got %+v", end)
+	}
+}
+
+func TestStreamToolAccumulatorHandlesParallelCalls(t *testing.T) {
+	acc := newStreamToolAccumulator()
+	ch := make(chan unifiedllm.StreamEvent, 8)
+	acc.apply(streamToolCallDiff{Index: 0, ID: "call_1", Function: streamFunctionDiff{Name: "get_weather", Arguments: `{}`}}, ch)
+	acc.apply(streamToolCallDiff{Index: 1, ID: "call_2", Function: streamFunctionDiff{Name: "get_time", Arguments: `{}`}}, ch)
+
+	calls := acc.finish(ch)
+	close(ch)
+
+	if len(calls) != 2 {
+		t.Fatalf("expected two assembled tool calls, got %d", len(calls))
+	}
+	if calls[0].ID != "call_1" || calls[1].ID != "call_2" {
+		t.Errorf("expected calls in index order, got %+v", calls)
+	}
+
+	starts := 0
+	for evt := range ch {
+		if evt.Type == unifiedllm.ToolCallStart {
+			starts++
+		}
+	}
+	if starts != 2 {
+		t.Errorf("expected a ToolCallStart per parallel call, got %d", starts)
+	}
+}
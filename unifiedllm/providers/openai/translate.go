@@ -0,0 +1,250 @@
+package openai
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/martinemde/attractor/unifiedllm"
+)
+
+// openaiMessage is one turn of an OpenAI Chat Completions request/response.
+// Unlike Anthropic/Gemini, OpenAI keeps system messages inline in the array
+// instead of hoisting them into a separate field.
+type openaiMessage struct {
+	Role       string           `json:"role"`
+	Content    string           `json:"content,omitempty"`
+	ToolCalls  []openaiToolCall `json:"tool_calls,omitempty"`
+	ToolCallID string           `json:"tool_call_id,omitempty"`
+}
+
+type openaiFunctionCall struct {
+	Name      string `json:"name"`
+	Arguments string `json:"arguments"`
+}
+
+type openaiToolCall struct {
+	ID       string             `json:"id"`
+	Type     string             `json:"type"` // "function"
+	Function openaiFunctionCall `json:"function"`
+}
+
+type openaiFunctionDef struct {
+	Name        string                 `json:"name"`
+	Description string                 `json:"description,omitempty"`
+	Parameters  map[string]interface{} `json:"parameters,omitempty"`
+}
+
+type openaiToolDef struct {
+	Type     string            `json:"type"` // "function"
+	Function openaiFunctionDef `json:"function"`
+}
+
+type openaiRequest struct {
+	Model       string          `json:"model"`
+	Messages    []openaiMessage `json:"messages"`
+	Tools       []openaiToolDef `json:"tools,omitempty"`
+	Temperature *float64        `json:"temperature,omitempty"`
+	TopP        *float64        `json:"top_p,omitempty"`
+	Stop        []string        `json:"stop,omitempty"`
+	MaxTokens   *int            `json:"max_tokens,omitempty"`
+	Stream      bool            `json:"stream,omitempty"`
+}
+
+type openaiUsage struct {
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
+	TotalTokens      int `json:"total_tokens"`
+}
+
+type openaiChoice struct {
+	Message      openaiMessage `json:"message"`
+	FinishReason string        `json:"finish_reason"`
+}
+
+type openaiResponse struct {
+	ID      string         `json:"id"`
+	Model   string         `json:"model"`
+	Choices []openaiChoice `json:"choices"`
+	Usage   openaiUsage    `json:"usage"`
+}
+
+// translateRequest maps a unifiedllm.Request onto OpenAI's Chat Completions
+// schema: one unifiedllm.Message with both text and tool_call content parts
+// becomes a single assistant message with content plus a tool_calls array,
+// and each ContentToolResult becomes its own "tool"-role message, since
+// OpenAI requires one message per tool_call_id.
+func translateRequest(req unifiedllm.Request) openaiRequest {
+	or := openaiRequest{
+		Model:       req.Model,
+		Temperature: req.Temperature,
+		TopP:        req.TopP,
+		Stop:        req.StopSequences,
+		MaxTokens:   req.MaxTokens,
+	}
+
+	for _, msg := range req.Messages {
+		or.Messages = append(or.Messages, translateMessage(msg)...)
+	}
+
+	if len(req.ToolDefs) > 0 {
+		tools := make([]openaiToolDef, len(req.ToolDefs))
+		for i, td := range req.ToolDefs {
+			tools[i] = openaiToolDef{
+				Type: "function",
+				Function: openaiFunctionDef{
+					Name:        td.Name,
+					Description: td.Description,
+					Parameters:  td.Parameters,
+				},
+			}
+		}
+		or.Tools = tools
+	}
+
+	return or
+}
+
+// translateMessage maps a single unifiedllm.Message onto zero or more
+// OpenAI messages: a ContentToolResult part always becomes its own
+// "tool"-role message; text and tool_call parts are merged into one
+// message carrying the original role.
+func translateMessage(msg unifiedllm.Message) []openaiMessage {
+	role := openaiRole(msg.Role)
+	var out []openaiMessage
+	var text string
+	var toolCalls []openaiToolCall
+
+	for _, cp := range msg.Content {
+		switch cp.Kind {
+		case unifiedllm.ContentText:
+			text += cp.Text
+		case unifiedllm.ContentToolCall:
+			if cp.ToolCall == nil {
+				continue
+			}
+			toolCalls = append(toolCalls, openaiToolCall{
+				ID:   cp.ToolCall.ID,
+				Type: "function",
+				Function: openaiFunctionCall{
+					Name:      cp.ToolCall.Name,
+					Arguments: string(cp.ToolCall.Arguments),
+				},
+			})
+		case unifiedllm.ContentToolResult:
+			if cp.ToolResult == nil {
+				continue
+			}
+			var resultText string
+			if err := json.Unmarshal(cp.ToolResult.Content, &resultText); err != nil {
+				resultText = string(cp.ToolResult.Content)
+			}
+			out = append(out, openaiMessage{
+				Role:       "tool",
+				Content:    resultText,
+				ToolCallID: cp.ToolResult.ToolCallID,
+			})
+		}
+	}
+
+	if text != "" || len(toolCalls) > 0 {
+		out = append([]openaiMessage{{
+			Role:      role,
+			Content:   text,
+			ToolCalls: toolCalls,
+		}}, out...)
+	}
+
+	return out
+}
+
+// openaiRole maps a unifiedllm.Role onto OpenAI's role scheme. RoleDeveloper
+// maps to "developer", the role OpenAI's newer reasoning models expect in
+// place of "system".
+func openaiRole(role unifiedllm.Role) string {
+	switch role {
+	case unifiedllm.RoleAssistant:
+		return "assistant"
+	case unifiedllm.RoleSystem:
+		return "system"
+	case unifiedllm.RoleDeveloper:
+		return "developer"
+	case unifiedllm.RoleTool:
+		return "tool"
+	default:
+		return "user"
+	}
+}
+
+// buildResponse translates an OpenAI response into the unified Response
+// type, populating Usage from usage and mapping finish_reason to
+// FinishReason.
+func buildResponse(model string, raw *openaiResponse) *unifiedllm.Response {
+	var content []unifiedllm.ContentPart
+	var finishReason string
+	if len(raw.Choices) > 0 {
+		choice := raw.Choices[0]
+		finishReason = choice.FinishReason
+		if choice.Message.Content != "" {
+			content = append(content, unifiedllm.TextPart(choice.Message.Content))
+		}
+		for _, tc := range choice.Message.ToolCalls {
+			content = append(content, unifiedllm.ToolCallPart(tc.ID, tc.Function.Name, json.RawMessage(tc.Function.Arguments)))
+		}
+	}
+
+	return &unifiedllm.Response{
+		ID:       raw.ID,
+		Model:    model,
+		Provider: "openai",
+		Message: unifiedllm.Message{
+			Role:    unifiedllm.RoleAssistant,
+			Content: content,
+		},
+		FinishReason: translateFinishReason(finishReason),
+		Usage: unifiedllm.Usage{
+			InputTokens:  raw.Usage.PromptTokens,
+			OutputTokens: raw.Usage.CompletionTokens,
+			TotalTokens:  raw.Usage.TotalTokens,
+		},
+	}
+}
+
+// translateFinishReason maps OpenAI's finish_reason onto the unified
+// FinishReason, in particular translating "tool_calls" through unchanged.
+func translateFinishReason(raw string) unifiedllm.FinishReason {
+	switch raw {
+	case "tool_calls", "function_call":
+		return unifiedllm.FinishReason{Reason: "tool_calls", Raw: raw}
+	case "length":
+		return unifiedllm.FinishReason{Reason: "length", Raw: raw}
+	case "stop", "":
+		return unifiedllm.FinishReason{Reason: "stop", Raw: raw}
+	case "content_filter":
+		return unifiedllm.FinishReason{Reason: "content_filter", Raw: raw}
+	default:
+		return unifiedllm.FinishReason{Reason: "other", Raw: raw}
+	}
+}
+
+// translateHTTPError maps an OpenAI HTTP error response onto the unified
+// error hierarchy via unifiedllm.ErrorFromStatusCode.
+func translateHTTPError(statusCode int, body []byte) error {
+	var parsed struct {
+		Error struct {
+			Type    string `json:"type"`
+			Message string `json:"message"`
+			Code    string `json:"code"`
+		} `json:"error"`
+	}
+	_ = json.Unmarshal(body, &parsed)
+
+	message := parsed.Error.Message
+	if message == "" {
+		message = fmt.Sprintf("openai: request failed with status %d", statusCode)
+	}
+	errorCode := parsed.Error.Code
+	if errorCode == "" {
+		errorCode = parsed.Error.Type
+	}
+	return unifiedllm.ErrorFromStatusCode(statusCode, message, "openai", errorCode, nil, nil)
+}
@@ -0,0 +1,92 @@
+package openai
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/martinemde/attractor/unifiedllm"
+)
+
+func TestTranslateMessageMergesTextAndToolCalls(t *testing.T) {
+	msg := unifiedllm.Message{
+		Role: unifiedllm.RoleAssistant,
+		Content: []unifiedllm.ContentPart{
+			unifiedllm.TextPart("checking the weather"),
+			unifiedllm.ToolCallPart("call_1", "get_weather", json.RawMessage(`{"city":"Paris"}`)),
+		},
+	}
+
+	msgs := translateMessage(msg)
+	if len(msgs) != 1 {
+		t.Fatalf("expected one merged message, got %d", len(msgs))
+	}
+	if msgs[0].Role != "assistant" || msgs[0].Content != "checking the weather" {
+		t.Fatalf("unexpected message: %+v", msgs[0])
+	}
+	if len(msgs[0].ToolCalls) != 1 || msgs[0].ToolCalls[0].Function.Name != "get_weather" {
+		t.Errorf("unexpected tool calls: %+v", msgs[0].ToolCalls)
+	}
+}
+
+func TestTranslateMessageSplitsToolResultsIntoOwnMessages(t *testing.T) {
+	raw, _ := json.Marshal("32 degrees")
+	msg := unifiedllm.Message{
+		Role: unifiedllm.RoleTool,
+		Content: []unifiedllm.ContentPart{
+			unifiedllm.ToolResultPart("call_1", raw, false),
+		},
+	}
+
+	msgs := translateMessage(msg)
+	if len(msgs) != 1 || msgs[0].Role != "tool" {
+		t.Fatalf("expected one tool message, got %+v", msgs)
+	}
+	if msgs[0].ToolCallID != "call_1" || msgs[0].Content != "32 degrees" {
+		t.Errorf("unexpected tool message: %+v", msgs[0])
+	}
+}
+
+func TestTranslateRequestIncludesToolDefs(t *testing.T) {
+	req := unifiedllm.Request{
+		Messages: []unifiedllm.Message{unifiedllm.UserMessage("hi")},
+		ToolDefs: []unifiedllm.ToolDefinition{
+			{Name: "get_weather", Description: "current weather", Parameters: map[string]interface{}{"type": "object"}},
+		},
+	}
+
+	or := translateRequest(req)
+	if len(or.Tools) != 1 || or.Tools[0].Function.Name != "get_weather" {
+		t.Fatalf("expected one tool def, got %+v", or.Tools)
+	}
+	if or.Tools[0].Type != "function" {
+		t.Errorf("expected function tool type, got %q", or.Tools[0].Type)
+	}
+}
+
+func TestBuildResponseMapsToolCallFinishReason(t *testing.T) {
+	raw := &openaiResponse{
+		ID: "chatcmpl_1",
+		Choices: []openaiChoice{{
+			Message: openaiMessage{
+				Role: "assistant",
+				ToolCalls: []openaiToolCall{
+					{ID: "call_1", Type: "function", Function: openaiFunctionCall{Name: "get_weather", Arguments: `{"city":"Paris"}`}},
+				},
+			},
+			FinishReason: "tool_calls",
+		}},
+		Usage: openaiUsage{PromptTokens: 10, CompletionTokens: 5, TotalTokens: 15},
+	}
+
+	resp := buildResponse("gpt-5.2", raw)
+	if resp.FinishReason.Reason != "tool_calls" {
+		t.Errorf("expected tool_calls finish reason, got %+v", resp.FinishReason)
+	}
+	if resp.Usage.InputTokens != 10 || resp.Usage.OutputTokens != 5 || resp.Usage.TotalTokens != 15 {
+		t.Errorf("unexpected usage: %+v", resp.Usage)
+	}
+	calls := resp.ToolCallsFromResponse()
+	if len(calls) != 1 || calls[0].Name != "get_weather" {
+		t.Errorf("unexpected tool calls: %+v", calls)
+	}
+}
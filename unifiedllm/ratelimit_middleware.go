@@ -0,0 +1,94 @@
+package unifiedllm
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// RateLimiter is a token-bucket limiter safe for concurrent use by multiple
+// goroutines, e.g. shared across every call RateLimitMiddleware intercepts.
+// It holds at most Burst tokens and refills at RatePerSecond tokens/second.
+type RateLimiter struct {
+	mu            sync.Mutex
+	ratePerSecond float64
+	burst         float64
+	tokens        float64
+	last          time.Time
+	now           func() time.Time
+}
+
+// NewRateLimiter creates a RateLimiter that allows ratePerSecond calls/sec
+// on average, bursting up to burst calls before it starts delaying. It
+// starts with a full bucket.
+func NewRateLimiter(ratePerSecond float64, burst int) *RateLimiter {
+	return &RateLimiter{
+		ratePerSecond: ratePerSecond,
+		burst:         float64(burst),
+		tokens:        float64(burst),
+		last:          time.Now(),
+		now:           time.Now,
+	}
+}
+
+// reserve removes one token from the bucket, refilling first for elapsed
+// time, and reports how long the caller must wait before that token is
+// available if the bucket is currently empty.
+func (r *RateLimiter) reserve() time.Duration {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := r.now()
+	elapsed := now.Sub(r.last)
+	r.last = now
+	r.tokens += elapsed.Seconds() * r.ratePerSecond
+	if r.tokens > r.burst {
+		r.tokens = r.burst
+	}
+
+	if r.tokens >= 1 {
+		r.tokens--
+		return 0
+	}
+
+	deficit := 1 - r.tokens
+	r.tokens -= 1 // goes negative; the next reserve's refill must earn it back
+	return time.Duration(deficit / r.ratePerSecond * float64(time.Second))
+}
+
+// Wait blocks until a token is available or ctx is done.
+func (r *RateLimiter) Wait(ctx context.Context) error {
+	delay := r.reserve()
+	if delay <= 0 {
+		return nil
+	}
+	select {
+	case <-ctx.Done():
+		return &AbortError{SDKError: SDKError{Message: "request cancelled waiting for rate limiter", Cause: ctx.Err()}}
+	case <-time.After(delay):
+		return nil
+	}
+}
+
+// RateLimitMiddleware returns a Middleware that blocks until limiter grants
+// a token before calling next. Share one *RateLimiter across every
+// Middleware/StreamMiddleware instance that should draw from the same
+// budget (e.g. a provider-wide requests/sec cap).
+func RateLimitMiddleware(limiter *RateLimiter) Middleware {
+	return func(ctx context.Context, req Request, next func(context.Context, Request) (*Response, error)) (*Response, error) {
+		if err := limiter.Wait(ctx); err != nil {
+			return nil, err
+		}
+		return next(ctx, req)
+	}
+}
+
+// StreamRateLimitMiddleware is RateLimitMiddleware's Stream counterpart.
+func StreamRateLimitMiddleware(limiter *RateLimiter) StreamMiddleware {
+	return func(ctx context.Context, req Request, next func(context.Context, Request) (<-chan StreamEvent, error)) (<-chan StreamEvent, error) {
+		if err := limiter.Wait(ctx); err != nil {
+			return nil, err
+		}
+		return next(ctx, req)
+	}
+}
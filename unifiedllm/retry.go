@@ -2,8 +2,11 @@ package unifiedllm
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"math"
 	"math/rand"
+	"strings"
 	"time"
 )
 
@@ -14,7 +17,170 @@ type RetryPolicy struct {
 	MaxDelay          float64 // maximum delay between retries
 	BackoffMultiplier float64 // exponential backoff factor
 	Jitter            bool    // add random jitter to prevent thundering herd
-	OnRetry           func(err error, attempt int, delay time.Duration)
+
+	// DecorrelatedJitter, if set, replaces Delay's exponential-plus-jitter
+	// formula with decorrelated-jitter backoff (sleep = min(MaxDelay,
+	// random_between(BaseDelay, prevDelay*3))) when computing delays via
+	// DecorrelatedDelay. ServerError and RequestTimeoutError retries use it.
+	DecorrelatedJitter bool
+
+	// MaxElapsed caps the total wall time, in seconds, spent sleeping
+	// between retries across the whole Retry call. 0 means unbounded. The
+	// error from the most recent attempt is returned once a further retry
+	// would cross the cap.
+	MaxElapsed float64
+
+	// PerErrorType overrides the whole policy for a translated error type,
+	// keyed by its unqualified Go type name (e.g. "RateLimitError",
+	// "ServerError"). See errorTypeName.
+	PerErrorType map[string]RetryPolicy
+
+	OnRetry func(err error, attempt int, delay time.Duration)
+
+	// DelayFunc, if set, overrides Delay/DecorrelatedDelay entirely: Retry
+	// calls it with the zero-based attempt number and the error that
+	// triggered the retry and uses the returned duration directly (still
+	// subject to the Retry-After override and MaxElapsed below). This is
+	// the seam for backoff strategies beyond the built-in
+	// exponential/decorrelated-jitter ones; see FixedDelayFunc,
+	// LinearBackoffFunc, and FibonacciBackoffFunc.
+	DelayFunc func(attempt int, err error) time.Duration
+
+	// RetryIf, if set, overrides the package's built-in IsRetryable check
+	// for this policy: only errors for which it returns true are retried.
+	RetryIf func(error) bool
+
+	// OnError, if set, fires for every failed attempt, including the
+	// final one that ends the retry loop -- unlike OnRetry, which only
+	// fires before a retry sleep.
+	OnError func(err error, attempt int)
+
+	// LastErrorOnly makes Retry return just the final attempt's error.
+	// The default collects every attempt's error into a *RetryError so a
+	// caller can inspect the whole sequence.
+	LastErrorOnly bool
+
+	// TreatContextDeadlineAsFatal, if set, treats a context.DeadlineExceeded
+	// or context.Canceled returned by fn as non-retryable even when the
+	// outer ctx passed to Retry is still live -- e.g. fn derived its own
+	// sub-context with a tighter deadline than ctx's.
+	TreatContextDeadlineAsFatal bool
+
+	// Clock, if set, is the source of time Delay, DecorrelatedDelay, and
+	// Retry use in place of the real clock -- inject a fake (see
+	// unifiedllmtest.NewFakeClock) to assert exact backoff sequences or
+	// simulate hours of retries in microseconds. Defaults to the real
+	// clock via effectiveClock.
+	Clock Clock
+
+	// Rand, if set, is the source of jitter Delay and DecorrelatedDelay use
+	// in place of math/rand -- inject a fake (see unifiedllmtest.FakeRand)
+	// to make jitter deterministic in tests. Defaults to the real
+	// math/rand-backed source via effectiveRand.
+	Rand Rand
+}
+
+// Clock abstracts time so Delay, DecorrelatedDelay, and Retry can be driven
+// by a fake in tests instead of real wall-clock time, mirroring Temporal's
+// move from a hard-coded system clock to an injectable clock.TimeSource.
+type Clock interface {
+	Now() time.Time
+	After(d time.Duration) <-chan time.Time
+	Sleep(d time.Duration)
+}
+
+// Rand abstracts the single source of randomness Delay and DecorrelatedDelay
+// need: a float uniformly distributed in [0, 1).
+type Rand interface {
+	Float64() float64
+}
+
+// systemClock is the real Clock, backed by the time package.
+type systemClock struct{}
+
+func (systemClock) Now() time.Time                         { return time.Now() }
+func (systemClock) After(d time.Duration) <-chan time.Time { return time.After(d) }
+func (systemClock) Sleep(d time.Duration)                  { time.Sleep(d) }
+
+// systemRand is the real Rand, backed by math/rand's global source.
+type systemRand struct{}
+
+func (systemRand) Float64() float64 { return rand.Float64() }
+
+// effectiveClock returns p.Clock, or the real system clock if unset.
+func (p RetryPolicy) effectiveClock() Clock {
+	if p.Clock != nil {
+		return p.Clock
+	}
+	return systemClock{}
+}
+
+// effectiveRand returns p.Rand, or the real math/rand-backed source if unset.
+func (p RetryPolicy) effectiveRand() Rand {
+	if p.Rand != nil {
+		return p.Rand
+	}
+	return systemRand{}
+}
+
+// RetryError wraps every attempt's error from a Retry call that did not set
+// LastErrorOnly. Unwrap() []error makes errors.Is/errors.As check every
+// attempt; Last returns the terminal attempt's error, the one that decided
+// the loop should stop.
+type RetryError struct {
+	Attempts []error
+}
+
+func (e *RetryError) Error() string {
+	if len(e.Attempts) == 0 {
+		return "retry: no attempts recorded"
+	}
+	msgs := make([]string, len(e.Attempts))
+	for i, err := range e.Attempts {
+		msgs[i] = fmt.Sprintf("attempt %d: %v", i+1, err)
+	}
+	return "retry: " + strings.Join(msgs, "; ")
+}
+
+// Unwrap exposes every attempt's error to errors.Is/errors.As (Go 1.20+
+// multi-error unwrapping).
+func (e *RetryError) Unwrap() []error {
+	return e.Attempts
+}
+
+// Last returns the terminal attempt's error, or nil if none were recorded.
+func (e *RetryError) Last() error {
+	if len(e.Attempts) == 0 {
+		return nil
+	}
+	return e.Attempts[len(e.Attempts)-1]
+}
+
+// FixedDelayFunc returns a RetryPolicy.DelayFunc that waits d before every
+// retry, regardless of attempt number.
+func FixedDelayFunc(d time.Duration) func(attempt int, err error) time.Duration {
+	return func(attempt int, err error) time.Duration { return d }
+}
+
+// LinearBackoffFunc returns a RetryPolicy.DelayFunc that waits
+// base*(attempt+1) before each retry: base, 2*base, 3*base, ...
+func LinearBackoffFunc(base time.Duration) func(attempt int, err error) time.Duration {
+	return func(attempt int, err error) time.Duration {
+		return base * time.Duration(attempt+1)
+	}
+}
+
+// FibonacciBackoffFunc returns a RetryPolicy.DelayFunc that waits
+// base*fib(attempt+1) before each retry (fib(1)=1, fib(2)=1, fib(3)=2, ...),
+// growing more gently than exponential backoff in later attempts.
+func FibonacciBackoffFunc(base time.Duration) func(attempt int, err error) time.Duration {
+	return func(attempt int, err error) time.Duration {
+		a, b := 1, 1
+		for i := 0; i < attempt; i++ {
+			a, b = b, a+b
+		}
+		return base * time.Duration(a)
+	}
 }
 
 // DefaultRetryPolicy returns the spec-default retry policy.
@@ -33,44 +199,206 @@ func (p RetryPolicy) Delay(attempt int) time.Duration {
 	delay := math.Min(p.BaseDelay*math.Pow(p.BackoffMultiplier, float64(attempt)), p.MaxDelay)
 	if p.Jitter {
 		// +/- 50% jitter
-		delay = delay * (0.5 + rand.Float64()) // rand in [0,1) -> [0.5, 1.5)
+		delay = delay * (0.5 + p.effectiveRand().Float64()) // rand in [0,1) -> [0.5, 1.5)
+	}
+	return time.Duration(delay * float64(time.Second))
+}
+
+// DecorrelatedDelay computes the next delay using decorrelated-jitter
+// backoff: min(MaxDelay, random_between(BaseDelay, prevDelay*3)). prev is
+// the previous delay returned by DecorrelatedDelay (or 0 for the first
+// retry, which seeds off BaseDelay).
+func (p RetryPolicy) DecorrelatedDelay(prev time.Duration) time.Duration {
+	prevSeconds := prev.Seconds()
+	if prevSeconds < p.BaseDelay {
+		prevSeconds = p.BaseDelay
 	}
+	hi := prevSeconds * 3
+	delay := p.BaseDelay + p.effectiveRand().Float64()*(hi-p.BaseDelay)
+	delay = math.Min(delay, p.MaxDelay)
 	return time.Duration(delay * float64(time.Second))
 }
 
-// Retry executes fn with the configured retry policy.
-// Only retryable errors are retried.
+// errorTypeName returns the translated error type's unqualified name, for
+// keying RetryPolicy.PerErrorType, e.g. "RateLimitError" for a
+// *RateLimitError. Unrecognized error types return "".
+func errorTypeName(err error) string {
+	switch err.(type) {
+	case *AuthenticationError:
+		return "AuthenticationError"
+	case *AccessDeniedError:
+		return "AccessDeniedError"
+	case *NotFoundError:
+		return "NotFoundError"
+	case *InvalidRequestError:
+		return "InvalidRequestError"
+	case *RateLimitError:
+		return "RateLimitError"
+	case *ServerError:
+		return "ServerError"
+	case *ContentFilterError:
+		return "ContentFilterError"
+	case *ContextLengthError:
+		return "ContextLengthError"
+	case *QuotaExceededError:
+		return "QuotaExceededError"
+	case *RequestTimeoutError:
+		return "RequestTimeoutError"
+	case *AbortError:
+		return "AbortError"
+	case *NetworkError:
+		return "NetworkError"
+	default:
+		return ""
+	}
+}
+
+// policyForError returns p, with any PerErrorType override for err's
+// translated type applied in full. An override that leaves OnRetry nil
+// inherits p.OnRetry, so a caller only has to set it once on the base policy.
+func (p RetryPolicy) policyForError(err error) RetryPolicy {
+	if p.PerErrorType == nil {
+		return p
+	}
+	override, ok := p.PerErrorType[errorTypeName(err)]
+	if !ok {
+		return p
+	}
+	if override.OnRetry == nil {
+		override.OnRetry = p.OnRetry
+	}
+	if override.Clock == nil {
+		override.Clock = p.Clock
+	}
+	if override.Rand == nil {
+		override.Rand = p.Rand
+	}
+	return override
+}
+
+// retryAfterFor returns the Retry-After hint carried by a RateLimitError or
+// ServerError, or nil if err is neither or carries none. Shared by Retry and
+// AsyncRetryer so both read a provider's Retry-After header the same way.
+// Retry gives up when the hint exceeds MaxDelay; AsyncRetryer gives up when
+// it exceeds the task's remaining budget.
+func retryAfterFor(err error) *float64 {
+	switch e := err.(type) {
+	case *RateLimitError:
+		return e.RetryAfter
+	case *ServerError:
+		return e.RetryAfter
+	default:
+		return nil
+	}
+}
+
+// isRetryableFor reports whether err should be retried under policy:
+// policy.RetryIf if set, otherwise the package's built-in IsRetryable. Either
+// way, a fn-internal context error is treated as fatal when
+// policy.TreatContextDeadlineAsFatal is set, even if the outer ctx is fine.
+func isRetryableFor(policy RetryPolicy, err error) bool {
+	if policy.TreatContextDeadlineAsFatal &&
+		(errors.Is(err, context.DeadlineExceeded) || errors.Is(err, context.Canceled)) {
+		return false
+	}
+	if policy.RetryIf != nil {
+		return policy.RetryIf(err)
+	}
+	return IsRetryable(err)
+}
+
+// Retry executes fn with the configured retry policy. Only retryable errors
+// (IsRetryable, or policy.RetryIf if set) are retried. Each error is
+// reclassified against policy.PerErrorType, so e.g. ServerError and
+// RequestTimeoutError can use decorrelated-jitter backoff while
+// RateLimitError and ServerError keep honoring Retry-After regardless of
+// whichever backoff strategy (Delay, DecorrelatedDelay, or a custom
+// DelayFunc) produced the candidate delay. If policy.MaxElapsed is set,
+// Retry stops before a sleep that would push total elapsed retry time past
+// it. The returned error is a *RetryError wrapping every attempt unless
+// policy.LastErrorOnly is set, in which case it's just the terminal error.
 func Retry[T any](ctx context.Context, policy RetryPolicy, fn func(ctx context.Context) (T, error)) (T, error) {
 	var zero T
+	var attempts []error
+
+	finish := func(err error) (T, error) {
+		attempts = append(attempts, err)
+		if policy.OnError != nil {
+			policy.OnError(err, len(attempts))
+		}
+		if policy.LastErrorOnly {
+			return zero, err
+		}
+		return zero, &RetryError{Attempts: attempts}
+	}
+
 	result, err := fn(ctx)
 	if err == nil {
 		return result, nil
 	}
 
+	clock := policy.effectiveClock()
+	start := clock.Now()
+	var prevDelay time.Duration
+
 	for attempt := 0; attempt < policy.MaxRetries; attempt++ {
-		if !IsRetryable(err) {
-			return zero, err
+		attempts = append(attempts, err)
+		if policy.OnError != nil {
+			policy.OnError(err, len(attempts))
 		}
 
-		// Check for Retry-After on rate limit errors.
-		delay := policy.Delay(attempt)
-		if rl, ok := err.(*RateLimitError); ok && rl.RetryAfter != nil {
-			retryDelay := time.Duration(*rl.RetryAfter * float64(time.Second))
-			if retryDelay > time.Duration(policy.MaxDelay*float64(time.Second)) {
-				// Retry-After exceeds max_delay; raise immediately.
+		if !isRetryableFor(policy, err) {
+			if policy.LastErrorOnly {
 				return zero, err
 			}
+			return zero, &RetryError{Attempts: attempts}
+		}
+
+		effective := policy.policyForError(err)
+
+		var delay time.Duration
+		switch {
+		case effective.DelayFunc != nil:
+			delay = effective.DelayFunc(attempt, err)
+		case effective.DecorrelatedJitter:
+			delay = effective.DecorrelatedDelay(prevDelay)
+		default:
+			delay = effective.Delay(attempt)
+		}
+
+		// Check for Retry-After on rate limit and server errors.
+		retryAfter := retryAfterFor(err)
+		if retryAfter != nil {
+			retryDelay := time.Duration(*retryAfter * float64(time.Second))
+			if retryDelay > time.Duration(effective.MaxDelay*float64(time.Second)) {
+				// Retry-After exceeds max_delay; raise immediately.
+				if policy.LastErrorOnly {
+					return zero, err
+				}
+				return zero, &RetryError{Attempts: attempts}
+			}
 			delay = retryDelay
 		}
 
+		if policy.MaxElapsed > 0 {
+			elapsed := clock.Now().Sub(start)
+			if elapsed+delay > time.Duration(policy.MaxElapsed*float64(time.Second)) {
+				if policy.LastErrorOnly {
+					return zero, err
+				}
+				return zero, &RetryError{Attempts: attempts}
+			}
+		}
+		prevDelay = delay
+
 		if policy.OnRetry != nil {
 			policy.OnRetry(err, attempt+1, delay)
 		}
 
 		select {
 		case <-ctx.Done():
-			return zero, &AbortError{SDKError: SDKError{Message: "request cancelled during retry", Cause: ctx.Err()}}
-		case <-time.After(delay):
+			return finish(&AbortError{SDKError: SDKError{Message: "request cancelled during retry", Cause: ctx.Err()}})
+		case <-effective.effectiveClock().After(delay):
 		}
 
 		result, err = fn(ctx)
@@ -79,5 +407,5 @@ func Retry[T any](ctx context.Context, policy RetryPolicy, fn func(ctx context.C
 		}
 	}
 
-	return zero, err
+	return finish(err)
 }
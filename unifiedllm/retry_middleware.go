@@ -0,0 +1,183 @@
+package unifiedllm
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"time"
+)
+
+// RetryOption configures RetryMiddleware and StreamRetryMiddleware.
+type RetryOption func(*retryConfig)
+
+type retryConfig struct {
+	maxAttempts     int
+	baseDelay       time.Duration
+	maxDelay        time.Duration
+	jitter          bool
+	retryableErrors func(error) bool
+}
+
+// WithMaxAttempts sets the total number of attempts (including the first),
+// not counting retries. The default is 3.
+func WithMaxAttempts(n int) RetryOption {
+	return func(c *retryConfig) { c.maxAttempts = n }
+}
+
+// WithBackoff sets the base and max delay for exponential backoff. The
+// default is a 1s base and a 60s cap.
+func WithBackoff(base, max time.Duration) RetryOption {
+	return func(c *retryConfig) { c.baseDelay, c.maxDelay = base, max }
+}
+
+// WithJitter enables or disables full-jitter backoff. It's enabled by
+// default.
+func WithJitter(enabled bool) RetryOption {
+	return func(c *retryConfig) { c.jitter = enabled }
+}
+
+// WithRetryableErrors overrides which errors are retried. The default is
+// IsRetryable.
+func WithRetryableErrors(fn func(error) bool) RetryOption {
+	return func(c *retryConfig) { c.retryableErrors = fn }
+}
+
+func newRetryConfig(opts ...RetryOption) retryConfig {
+	c := retryConfig{
+		maxAttempts:     3,
+		baseDelay:       time.Second,
+		maxDelay:        60 * time.Second,
+		jitter:          true,
+		retryableErrors: IsRetryable,
+	}
+	for _, opt := range opts {
+		opt(&c)
+	}
+	return c
+}
+
+// delay computes the backoff for the n'th retry (0-indexed) using full
+// jitter: sleep = rand(0, min(max, base*2^n)).
+func (c retryConfig) delay(attempt int) time.Duration {
+	capped := time.Duration(math.Min(
+		float64(c.baseDelay)*math.Pow(2, float64(attempt)),
+		float64(c.maxDelay),
+	))
+	if !c.jitter {
+		return capped
+	}
+	if capped <= 0 {
+		return 0
+	}
+	return time.Duration(rand.Int63n(int64(capped) + 1))
+}
+
+// RetryPolicyMiddleware returns a Middleware that retries next using
+// Retry[T] and policy -- the same retry engine Client.Complete applies via
+// WithRetryPolicy/Request.RetryPolicy, exposed as a stock interceptor so
+// retries compose with the rest of the chain (logging, metrics, tracing)
+// instead of wrapping it from outside. Register it first via WithMiddleware
+// so it's outermost and every attempt re-runs the full chain.
+func RetryPolicyMiddleware(policy RetryPolicy) Middleware {
+	return func(ctx context.Context, req Request, next func(context.Context, Request) (*Response, error)) (*Response, error) {
+		return Retry(ctx, policy, func(ctx context.Context) (*Response, error) {
+			return next(ctx, req)
+		})
+	}
+}
+
+// RetryMiddleware returns a Middleware that retries a failed Complete call
+// with full-jitter exponential backoff, honoring ctx.Done() between sleeps.
+// Only errors accepted by the configured retryableErrors predicate (see
+// WithRetryableErrors) are retried; anything else is returned immediately.
+// On success, the returned Response's RetryCount reports how many retries
+// it took.
+func RetryMiddleware(opts ...RetryOption) Middleware {
+	cfg := newRetryConfig(opts...)
+	return func(ctx context.Context, req Request, next func(context.Context, Request) (*Response, error)) (*Response, error) {
+		var lastErr error
+		for attempt := 0; attempt < cfg.maxAttempts; attempt++ {
+			resp, err := next(ctx, req)
+			if err == nil {
+				resp.RetryCount = attempt
+				return resp, nil
+			}
+			lastErr = err
+			if !cfg.retryableErrors(err) || attempt == cfg.maxAttempts-1 {
+				return nil, err
+			}
+			if err := sleepOrAbort(ctx, cfg.delay(attempt)); err != nil {
+				return nil, err
+			}
+		}
+		return nil, lastErr
+	}
+}
+
+// StreamRetryMiddleware returns a StreamMiddleware that retries a failed
+// Stream call the same way RetryMiddleware retries Complete. Because a
+// partially-streamed response can't be safely replayed, it only retries
+// before the first StreamEvent reaches the caller: once an event has been
+// forwarded, any later StreamError event is passed through as-is rather
+// than triggering a retry.
+func StreamRetryMiddleware(opts ...RetryOption) StreamMiddleware {
+	cfg := newRetryConfig(opts...)
+	return func(ctx context.Context, req Request, next func(context.Context, Request) (<-chan StreamEvent, error)) (<-chan StreamEvent, error) {
+		var lastErr error
+		for attempt := 0; attempt < cfg.maxAttempts; attempt++ {
+			events, err := next(ctx, req)
+			if err != nil {
+				lastErr = err
+				if !cfg.retryableErrors(err) || attempt == cfg.maxAttempts-1 {
+					return nil, err
+				}
+				if err := sleepOrAbort(ctx, cfg.delay(attempt)); err != nil {
+					return nil, err
+				}
+				continue
+			}
+
+			first, ok := <-events
+			if !ok {
+				return events, nil // closed with no events; nothing to retry
+			}
+			if first.Type == StreamError && cfg.retryableErrors(first.Error) {
+				lastErr = first.Error
+				if attempt == cfg.maxAttempts-1 {
+					return nil, lastErr
+				}
+				if err := sleepOrAbort(ctx, cfg.delay(attempt)); err != nil {
+					return nil, err
+				}
+				continue
+			}
+			return prependEvent(first, events), nil
+		}
+		return nil, lastErr
+	}
+}
+
+// sleepOrAbort sleeps for delay, returning an *AbortError if ctx is
+// cancelled first.
+func sleepOrAbort(ctx context.Context, delay time.Duration) error {
+	select {
+	case <-ctx.Done():
+		return &AbortError{SDKError: SDKError{Message: "request cancelled during retry", Cause: ctx.Err()}}
+	case <-time.After(delay):
+		return nil
+	}
+}
+
+// prependEvent returns a channel that yields first followed by the
+// remainder of rest.
+func prependEvent(first StreamEvent, rest <-chan StreamEvent) <-chan StreamEvent {
+	ch := make(chan StreamEvent)
+	go func() {
+		defer close(ch)
+		ch <- first
+		for event := range rest {
+			ch <- event
+		}
+	}()
+	return ch
+}
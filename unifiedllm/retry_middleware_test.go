@@ -0,0 +1,141 @@
+package unifiedllm
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func serverErr() error {
+	return &ServerError{ProviderError: ProviderError{SDKError: SDKError{Message: "server error"}, Retryable: true}}
+}
+
+func TestRetryMiddlewareSuccess(t *testing.T) {
+	mw := RetryMiddleware(WithMaxAttempts(3), WithBackoff(time.Millisecond, time.Millisecond), WithJitter(false))
+
+	callCount := 0
+	resp, err := mw(context.Background(), Request{}, func(ctx context.Context, r Request) (*Response, error) {
+		callCount++
+		if callCount < 3 {
+			return nil, serverErr()
+		}
+		return &Response{ID: "ok"}, nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if callCount != 3 {
+		t.Errorf("expected 3 calls, got %d", callCount)
+	}
+	if resp.RetryCount != 2 {
+		t.Errorf("expected RetryCount 2, got %d", resp.RetryCount)
+	}
+}
+
+func TestRetryMiddlewareNonRetryable(t *testing.T) {
+	mw := RetryMiddleware(WithMaxAttempts(3), WithBackoff(time.Millisecond, time.Millisecond))
+
+	callCount := 0
+	_, err := mw(context.Background(), Request{}, func(ctx context.Context, r Request) (*Response, error) {
+		callCount++
+		return nil, &AuthenticationError{ProviderError: ProviderError{SDKError: SDKError{Message: "invalid key"}}}
+	})
+	if err == nil {
+		t.Fatal("expected error")
+	}
+	if callCount != 1 {
+		t.Errorf("expected 1 call (no retries for non-retryable), got %d", callCount)
+	}
+}
+
+func TestRetryMiddlewareExhausted(t *testing.T) {
+	mw := RetryMiddleware(WithMaxAttempts(2), WithBackoff(time.Millisecond, time.Millisecond))
+
+	callCount := 0
+	_, err := mw(context.Background(), Request{}, func(ctx context.Context, r Request) (*Response, error) {
+		callCount++
+		return nil, serverErr()
+	})
+	if err == nil {
+		t.Fatal("expected error after attempts exhausted")
+	}
+	if callCount != 2 {
+		t.Errorf("expected 2 calls, got %d", callCount)
+	}
+}
+
+func TestRetryMiddlewareCustomRetryableErrors(t *testing.T) {
+	mw := RetryMiddleware(
+		WithMaxAttempts(3),
+		WithBackoff(time.Millisecond, time.Millisecond),
+		WithRetryableErrors(func(err error) bool { return true }),
+	)
+
+	callCount := 0
+	_, err := mw(context.Background(), Request{}, func(ctx context.Context, r Request) (*Response, error) {
+		callCount++
+		return nil, &AuthenticationError{ProviderError: ProviderError{SDKError: SDKError{Message: "invalid key"}}}
+	})
+	if err == nil {
+		t.Fatal("expected error")
+	}
+	if callCount != 3 {
+		t.Errorf("expected 3 calls with a permissive retryableErrors override, got %d", callCount)
+	}
+}
+
+func TestStreamRetryMiddlewareRetriesBeforeFirstEvent(t *testing.T) {
+	mw := StreamRetryMiddleware(WithMaxAttempts(3), WithBackoff(time.Millisecond, time.Millisecond))
+
+	callCount := 0
+	ch, err := mw(context.Background(), Request{}, func(ctx context.Context, r Request) (<-chan StreamEvent, error) {
+		callCount++
+		out := make(chan StreamEvent, 1)
+		if callCount < 3 {
+			out <- StreamEvent{Type: StreamError, Error: serverErr()}
+		} else {
+			out <- StreamEvent{Type: TextDelta, Delta: "hi"}
+		}
+		close(out)
+		return out, nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	event := <-ch
+	if event.Type != TextDelta || event.Delta != "hi" {
+		t.Errorf("expected forwarded text delta, got %+v", event)
+	}
+	if callCount != 3 {
+		t.Errorf("expected 3 attempts, got %d", callCount)
+	}
+}
+
+func TestStreamRetryMiddlewareDoesNotRetryAfterFirstEvent(t *testing.T) {
+	mw := StreamRetryMiddleware(WithMaxAttempts(3), WithBackoff(time.Millisecond, time.Millisecond))
+
+	callCount := 0
+	ch, err := mw(context.Background(), Request{}, func(ctx context.Context, r Request) (<-chan StreamEvent, error) {
+		callCount++
+		out := make(chan StreamEvent, 2)
+		out <- StreamEvent{Type: TextDelta, Delta: "partial"}
+		out <- StreamEvent{Type: StreamError, Error: serverErr()}
+		close(out)
+		return out, nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	first := <-ch
+	if first.Type != TextDelta {
+		t.Fatalf("expected first event forwarded, got %+v", first)
+	}
+	second := <-ch
+	if second.Type != StreamError {
+		t.Fatalf("expected the later error passed through, got %+v", second)
+	}
+	if callCount != 1 {
+		t.Errorf("expected only 1 attempt once an event has been forwarded, got %d", callCount)
+	}
+}
@@ -1,14 +1,24 @@
-package unifiedllm
+// Package unifiedllm_test is external so it can import unifiedllmtest
+// (which itself imports unifiedllm, for its Clock/Rand compile-time
+// assertions) without creating an import cycle: an internal test file
+// (package unifiedllm) importing a package that imports unifiedllm back is
+// rejected by the Go toolchain ("import cycle not allowed in test"), since
+// there's only one build identity for package unifiedllm per test binary,
+// test-augmented or not.
+package unifiedllm_test
 
 import (
 	"context"
 	"errors"
 	"testing"
 	"time"
+
+	"github.com/martinemde/attractor/unifiedllm"
+	"github.com/martinemde/attractor/unifiedllm/unifiedllmtest"
 )
 
 func TestRetryPolicyDelay(t *testing.T) {
-	policy := RetryPolicy{
+	policy := unifiedllm.RetryPolicy{
 		BaseDelay:         1.0,
 		BackoffMultiplier: 2.0,
 		MaxDelay:          60.0,
@@ -32,7 +42,7 @@ func TestRetryPolicyDelay(t *testing.T) {
 }
 
 func TestRetryPolicyDelayWithMaxCap(t *testing.T) {
-	policy := RetryPolicy{
+	policy := unifiedllm.RetryPolicy{
 		BaseDelay:         1.0,
 		BackoffMultiplier: 2.0,
 		MaxDelay:          5.0,
@@ -47,7 +57,7 @@ func TestRetryPolicyDelayWithMaxCap(t *testing.T) {
 }
 
 func TestRetryPolicyDelayWithJitter(t *testing.T) {
-	policy := RetryPolicy{
+	policy := unifiedllm.RetryPolicy{
 		BaseDelay:         1.0,
 		BackoffMultiplier: 2.0,
 		MaxDelay:          60.0,
@@ -64,14 +74,14 @@ func TestRetryPolicyDelayWithJitter(t *testing.T) {
 }
 
 func TestRetrySuccess(t *testing.T) {
-	policy := RetryPolicy{MaxRetries: 3, BaseDelay: 0.001, BackoffMultiplier: 1, MaxDelay: 0.001, Jitter: false}
+	policy := unifiedllm.RetryPolicy{MaxRetries: 3, BaseDelay: 0.001, BackoffMultiplier: 1, MaxDelay: 0.001, Jitter: false}
 
 	callCount := 0
-	result, err := Retry(context.Background(), policy, func(ctx context.Context) (string, error) {
+	result, err := unifiedllm.Retry(context.Background(), policy, func(ctx context.Context) (string, error) {
 		callCount++
 		if callCount < 3 {
-			return "", &ServerError{ProviderError: ProviderError{
-				SDKError: SDKError{Message: "server error"}, Retryable: true,
+			return "", &unifiedllm.ServerError{ProviderError: unifiedllm.ProviderError{
+				SDKError: unifiedllm.SDKError{Message: "server error"}, Retryable: true,
 			}}
 		}
 		return "success", nil
@@ -88,13 +98,13 @@ func TestRetrySuccess(t *testing.T) {
 }
 
 func TestRetryNonRetryableError(t *testing.T) {
-	policy := RetryPolicy{MaxRetries: 3, BaseDelay: 0.001, BackoffMultiplier: 1, MaxDelay: 0.001, Jitter: false}
+	policy := unifiedllm.RetryPolicy{MaxRetries: 3, BaseDelay: 0.001, BackoffMultiplier: 1, MaxDelay: 0.001, Jitter: false}
 
 	callCount := 0
-	_, err := Retry(context.Background(), policy, func(ctx context.Context) (string, error) {
+	_, err := unifiedllm.Retry(context.Background(), policy, func(ctx context.Context) (string, error) {
 		callCount++
-		return "", &AuthenticationError{ProviderError: ProviderError{
-			SDKError: SDKError{Message: "invalid key"},
+		return "", &unifiedllm.AuthenticationError{ProviderError: unifiedllm.ProviderError{
+			SDKError: unifiedllm.SDKError{Message: "invalid key"},
 		}}
 	})
 	if err == nil {
@@ -106,13 +116,13 @@ func TestRetryNonRetryableError(t *testing.T) {
 }
 
 func TestRetryExhausted(t *testing.T) {
-	policy := RetryPolicy{MaxRetries: 2, BaseDelay: 0.001, BackoffMultiplier: 1, MaxDelay: 0.001, Jitter: false}
+	policy := unifiedllm.RetryPolicy{MaxRetries: 2, BaseDelay: 0.001, BackoffMultiplier: 1, MaxDelay: 0.001, Jitter: false}
 
 	callCount := 0
-	_, err := Retry(context.Background(), policy, func(ctx context.Context) (string, error) {
+	_, err := unifiedllm.Retry(context.Background(), policy, func(ctx context.Context) (string, error) {
 		callCount++
-		return "", &ServerError{ProviderError: ProviderError{
-			SDKError: SDKError{Message: "server error"}, Retryable: true,
+		return "", &unifiedllm.ServerError{ProviderError: unifiedllm.ProviderError{
+			SDKError: unifiedllm.SDKError{Message: "server error"}, Retryable: true,
 		}}
 	})
 	if err == nil {
@@ -124,31 +134,31 @@ func TestRetryExhausted(t *testing.T) {
 }
 
 func TestRetryCancelled(t *testing.T) {
-	policy := RetryPolicy{MaxRetries: 5, BaseDelay: 1.0, BackoffMultiplier: 1, MaxDelay: 1.0, Jitter: false}
+	clock := unifiedllmtest.NewFakeClock()
+	policy := unifiedllm.RetryPolicy{MaxRetries: 5, BaseDelay: 1.0, BackoffMultiplier: 1, MaxDelay: 1.0, Jitter: false, Clock: clock}
 
+	// Cancel before the first retry's delay, so the select between
+	// ctx.Done() and clock.After(delay) deterministically picks ctx.Done():
+	// the fake clock's virtual time never advances, so After never fires.
 	ctx, cancel := context.WithCancel(context.Background())
-	callCount := 0
-	go func() {
-		time.Sleep(50 * time.Millisecond)
-		cancel()
-	}()
+	cancel()
 
-	_, err := Retry(ctx, policy, func(ctx context.Context) (string, error) {
+	callCount := 0
+	_, err := unifiedllm.Retry(ctx, policy, func(ctx context.Context) (string, error) {
 		callCount++
 		return "", errors.New("always fails")
 	})
 	if err == nil {
 		t.Fatal("expected error")
 	}
-	// Should have been cancelled before all retries completed.
-	if callCount > 3 {
-		t.Errorf("expected fewer calls due to cancellation, got %d", callCount)
+	if callCount != 1 {
+		t.Errorf("expected exactly 1 call before cancellation stopped retries, got %d", callCount)
 	}
 }
 
 func TestRetryNoError(t *testing.T) {
-	policy := DefaultRetryPolicy()
-	result, err := Retry(context.Background(), policy, func(ctx context.Context) (string, error) {
+	policy := unifiedllm.DefaultRetryPolicy()
+	result, err := unifiedllm.Retry(context.Background(), policy, func(ctx context.Context) (string, error) {
 		return "immediate", nil
 	})
 	if err != nil {
@@ -159,8 +169,68 @@ func TestRetryNoError(t *testing.T) {
 	}
 }
 
+func TestRetryPolicyDelayWithFakeRand(t *testing.T) {
+	policy := unifiedllm.RetryPolicy{
+		BaseDelay:         1.0,
+		BackoffMultiplier: 2.0,
+		MaxDelay:          60.0,
+		Jitter:            true,
+		Rand:              unifiedllmtest.NewFakeRand(0, 1),
+	}
+
+	// FakeRand(0, 1) cycles 0 then 1, so jitter pins the result to the
+	// formula's exact low and high bounds instead of a random draw.
+	if got, want := policy.Delay(0), 500*time.Millisecond; got != want {
+		t.Errorf("attempt 0 with rand=0: expected %v, got %v", want, got)
+	}
+	if got, want := policy.Delay(0), 1500*time.Millisecond; got != want {
+		t.Errorf("attempt 0 with rand=1: expected %v, got %v", want, got)
+	}
+}
+
+func TestRetryUsesFakeClockForBackoff(t *testing.T) {
+	clock := unifiedllmtest.NewFakeClock()
+	policy := unifiedllm.RetryPolicy{MaxRetries: 3, BaseDelay: 1.0, BackoffMultiplier: 2.0, MaxDelay: 60.0, Jitter: false, Clock: clock}
+
+	done := make(chan struct{})
+	callCount := 0
+	var result string
+	var err error
+	go func() {
+		result, err = unifiedllm.Retry(context.Background(), policy, func(ctx context.Context) (string, error) {
+			callCount++
+			if callCount < 3 {
+				return "", &unifiedllm.ServerError{ProviderError: unifiedllm.ProviderError{
+					SDKError: unifiedllm.SDKError{Message: "server error"}, Retryable: true,
+				}}
+			}
+			return "success", nil
+		})
+		close(done)
+	}()
+
+	// Advance past each expected backoff (1s, then 2s) without sleeping in
+	// real time; a stuck select on clock.After would leave done unclosed
+	// and the final receive below would hang forever instead of passing.
+	for _, step := range []time.Duration{time.Second, 2 * time.Second} {
+		time.Sleep(time.Millisecond) // let the goroutine reach its select
+		clock.Advance(step)
+	}
+
+	<-done
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result != "success" {
+		t.Errorf("expected %q, got %q", "success", result)
+	}
+	if callCount != 3 {
+		t.Errorf("expected 3 calls, got %d", callCount)
+	}
+}
+
 func TestDefaultRetryPolicy(t *testing.T) {
-	p := DefaultRetryPolicy()
+	p := unifiedllm.DefaultRetryPolicy()
 	if p.MaxRetries != 2 {
 		t.Errorf("expected max_retries 2, got %d", p.MaxRetries)
 	}
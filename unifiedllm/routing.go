@@ -0,0 +1,238 @@
+package unifiedllm
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"sync"
+	"time"
+)
+
+// RoutingPolicy picks which registered provider Client should try for req,
+// given the provider names currently eligible to serve it. Select is called
+// again, with the failed provider removed from candidates, each time a
+// chosen provider returns a retryable error -- see WithRoutingPolicy.
+type RoutingPolicy interface {
+	Select(req Request, candidates []string) (string, error)
+}
+
+// RoutingObserver is implemented by policies that want to see the outcome
+// of every attempt (e.g. to maintain rolling latency stats). Client calls
+// Observe after each attempt a routing policy directs, successful or not.
+type RoutingObserver interface {
+	Observe(provider string, latency time.Duration, err error)
+}
+
+// WeightedRoutingPolicy selects a candidate at random, proportional to its
+// configured weight. Candidates with no configured weight default to weight
+// 1; a candidate configured with a weight <= 0 is never picked, unless every
+// candidate weighs 0, in which case one is picked uniformly at random.
+type WeightedRoutingPolicy struct {
+	Weights map[string]int
+}
+
+// NewWeightedRoutingPolicy creates a WeightedRoutingPolicy from a map of
+// provider name to weight.
+func NewWeightedRoutingPolicy(weights map[string]int) *WeightedRoutingPolicy {
+	return &WeightedRoutingPolicy{Weights: weights}
+}
+
+func (p *WeightedRoutingPolicy) Select(req Request, candidates []string) (string, error) {
+	if len(candidates) == 0 {
+		return "", fmt.Errorf("unifiedllm: no candidate providers to route to")
+	}
+	total := 0
+	for _, name := range candidates {
+		total += p.weightOf(name)
+	}
+	if total <= 0 {
+		// Every candidate was explicitly weighted to 0 (or negative):
+		// there's no weighted preference left to apply, so fall back to a
+		// uniform pick rather than panicking on rand.Intn(0).
+		return candidates[rand.Intn(len(candidates))], nil
+	}
+	r := rand.Intn(total)
+	for _, name := range candidates {
+		w := p.weightOf(name)
+		if r < w {
+			return name, nil
+		}
+		r -= w
+	}
+	return candidates[len(candidates)-1], nil
+}
+
+// weightOf returns name's configured weight, defaulting to 1 only when name
+// has no entry in Weights at all. A weight explicitly configured to <= 0 is
+// returned as 0 -- excluded from selection -- rather than falling back to
+// the default, so callers can turn a candidate off without removing it.
+func (p *WeightedRoutingPolicy) weightOf(name string) int {
+	w, ok := p.Weights[name]
+	if !ok {
+		return 1
+	}
+	if w < 0 {
+		return 0
+	}
+	return w
+}
+
+// FailoverRoutingPolicy always prefers the first eligible candidate in
+// Order, falling through to the next when an earlier one has already
+// failed (and so is absent from candidates). Any candidate not listed in
+// Order is treated as lowest priority, tried in candidates order only after
+// every listed provider has been excluded.
+type FailoverRoutingPolicy struct {
+	Order []string
+}
+
+// NewFailoverRoutingPolicy creates a FailoverRoutingPolicy that tries
+// providers in the given order, primary first.
+func NewFailoverRoutingPolicy(order ...string) *FailoverRoutingPolicy {
+	return &FailoverRoutingPolicy{Order: order}
+}
+
+func (p *FailoverRoutingPolicy) Select(req Request, candidates []string) (string, error) {
+	if len(candidates) == 0 {
+		return "", fmt.Errorf("unifiedllm: no candidate providers to route to")
+	}
+	eligible := make(map[string]bool, len(candidates))
+	for _, name := range candidates {
+		eligible[name] = true
+	}
+	for _, name := range p.Order {
+		if eligible[name] {
+			return name, nil
+		}
+	}
+	return candidates[0], nil
+}
+
+// LatencyRoutingPolicy selects the candidate with the lowest rolling p50
+// latency observed over its last window successful attempts. A candidate
+// never attempted yet is preferred over any candidate with samples, so
+// every provider gets a chance to build up a latency profile. A candidate
+// whose every attempt failed has no samples and is skipped; it is returned
+// only as the first candidate when no candidate has samples.
+type LatencyRoutingPolicy struct {
+	window int
+
+	mu      sync.Mutex
+	samples map[string][]time.Duration
+	// failed counts attempts that errored, so Select can tell "never
+	// attempted" (prefer it, build up a profile) apart from "failed every
+	// attempt" (no latency samples either, but not untested).
+	failed map[string]int
+}
+
+// NewLatencyRoutingPolicy creates a LatencyRoutingPolicy tracking up to
+// window recent latencies per provider (20 if window <= 0).
+func NewLatencyRoutingPolicy(window int) *LatencyRoutingPolicy {
+	if window <= 0 {
+		window = 20
+	}
+	return &LatencyRoutingPolicy{window: window, samples: make(map[string][]time.Duration), failed: make(map[string]int)}
+}
+
+func (p *LatencyRoutingPolicy) Select(req Request, candidates []string) (string, error) {
+	if len(candidates) == 0 {
+		return "", fmt.Errorf("unifiedllm: no candidate providers to route to")
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	best := candidates[0]
+	bestP50 := time.Duration(-1)
+	for _, name := range candidates {
+		samples := p.samples[name]
+		if len(samples) == 0 {
+			if p.failed[name] == 0 {
+				// Never attempted at all (as opposed to attempted and
+				// failed every time): give it a chance to build a profile
+				// before any latency-based comparison kicks in.
+				return name, nil
+			}
+			continue
+		}
+		p50 := medianDuration(samples)
+		if bestP50 < 0 || p50 < bestP50 {
+			bestP50 = p50
+			best = name
+		}
+	}
+	return best, nil
+}
+
+// Observe records latency as a new sample for provider on success. A failed
+// attempt isn't recorded as a latency sample -- a fast failure shouldn't
+// make a provider look attractive -- but is still counted, so Select can
+// tell a provider that has failed every attempt apart from one that's
+// never been tried.
+func (p *LatencyRoutingPolicy) Observe(provider string, latency time.Duration, err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if err != nil {
+		p.failed[provider]++
+		return
+	}
+	samples := append(p.samples[provider], latency)
+	if len(samples) > p.window {
+		samples = samples[len(samples)-p.window:]
+	}
+	p.samples[provider] = samples
+}
+
+func medianDuration(samples []time.Duration) time.Duration {
+	sorted := append([]time.Duration(nil), samples...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	return sorted[len(sorted)/2]
+}
+
+// CostRoutingPolicy selects the candidate whose catalog entry is cheapest.
+// Since providers rarely share a model ID, ModelFor maps each candidate
+// provider name to the model ID it would actually serve the request with;
+// a candidate missing from ModelFor is assumed to serve req.Model as-is.
+// Candidates with no catalog entry are never preferred over one with a
+// known cost.
+type CostRoutingPolicy struct {
+	ModelFor map[string]string
+}
+
+// NewCostRoutingPolicy creates a CostRoutingPolicy using modelFor to resolve
+// each candidate's model ID for a catalog lookup.
+func NewCostRoutingPolicy(modelFor map[string]string) *CostRoutingPolicy {
+	return &CostRoutingPolicy{ModelFor: modelFor}
+}
+
+func (p *CostRoutingPolicy) Select(req Request, candidates []string) (string, error) {
+	if len(candidates) == 0 {
+		return "", fmt.Errorf("unifiedllm: no candidate providers to route to")
+	}
+
+	best := candidates[0]
+	bestCost := math.Inf(1)
+	for _, name := range candidates {
+		modelID := p.ModelFor[name]
+		if modelID == "" {
+			modelID = req.Model
+		}
+		info := GetModelInfo(modelID)
+		if info == nil {
+			continue
+		}
+		cost := 0.0
+		if info.InputCostPerMillion != nil {
+			cost += *info.InputCostPerMillion
+		}
+		if info.OutputCostPerMillion != nil {
+			cost += *info.OutputCostPerMillion
+		}
+		if cost < bestCost {
+			bestCost = cost
+			best = name
+		}
+	}
+	return best, nil
+}
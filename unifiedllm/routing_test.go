@@ -0,0 +1,136 @@
+package unifiedllm
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestWeightedRoutingPolicyRespectsWeights(t *testing.T) {
+	policy := NewWeightedRoutingPolicy(map[string]int{"a": 1, "b": 0})
+	candidates := []string{"a", "b"}
+
+	for i := 0; i < 50; i++ {
+		name, err := policy.Select(Request{}, candidates)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if name != "a" {
+			t.Fatalf("expected only weighted candidate %q, got %q", "a", name)
+		}
+	}
+}
+
+func TestFailoverRoutingPolicyPrefersOrder(t *testing.T) {
+	policy := NewFailoverRoutingPolicy("primary", "secondary")
+
+	name, err := policy.Select(Request{}, []string{"secondary", "primary"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if name != "primary" {
+		t.Errorf("expected primary to be preferred, got %q", name)
+	}
+
+	name, err = policy.Select(Request{}, []string{"secondary"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if name != "secondary" {
+		t.Errorf("expected secondary once primary is excluded, got %q", name)
+	}
+}
+
+func TestLatencyRoutingPolicyPrefersFasterProvider(t *testing.T) {
+	policy := NewLatencyRoutingPolicy(5)
+	policy.Observe("slow", 100*time.Millisecond, nil)
+	policy.Observe("fast", 10*time.Millisecond, nil)
+
+	name, err := policy.Select(Request{}, []string{"slow", "fast"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if name != "fast" {
+		t.Errorf("expected the lower-latency provider, got %q", name)
+	}
+}
+
+func TestLatencyRoutingPolicyPrefersUntestedCandidate(t *testing.T) {
+	policy := NewLatencyRoutingPolicy(5)
+	policy.Observe("known", 10*time.Millisecond, nil)
+
+	name, err := policy.Select(Request{}, []string{"known", "unknown"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if name != "unknown" {
+		t.Errorf("expected the untested candidate to be preferred, got %q", name)
+	}
+}
+
+func TestLatencyRoutingPolicyIgnoresFailedAttempts(t *testing.T) {
+	policy := NewLatencyRoutingPolicy(5)
+	policy.Observe("a", 5*time.Millisecond, context.DeadlineExceeded)
+
+	name, err := policy.Select(Request{}, []string{"a", "b"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if name != "b" {
+		t.Errorf("expected untested %q to be preferred over a failed sample, got %q", "b", name)
+	}
+}
+
+func TestCostRoutingPolicyPrefersCheaperModel(t *testing.T) {
+	policy := NewCostRoutingPolicy(map[string]string{
+		"anthropic": "claude-opus-4-6",
+		"gemini":    "gemini-3-flash-preview",
+	})
+
+	name, err := policy.Select(Request{}, []string{"anthropic", "gemini"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if name != "gemini" {
+		t.Errorf("expected the cheaper gemini model to be preferred, got %q", name)
+	}
+}
+
+func TestClientCompleteRoutesAndFailsOver(t *testing.T) {
+	failing := &mockAdapter{name: "failing", err: &ServerError{
+		ProviderError: ProviderError{SDKError: SDKError{Message: "down"}, Retryable: true},
+	}}
+	healthy := newMockAdapter("healthy", "ok")
+
+	client := NewClient(
+		WithProvider("failing", failing),
+		WithProvider("healthy", healthy),
+		WithRoutingPolicy(NewFailoverRoutingPolicy("failing", "healthy")),
+	)
+
+	resp, err := client.Complete(context.Background(), Request{Model: "test-model"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resp.Provider != "healthy" {
+		t.Errorf("expected failover to land on %q, got %q", "healthy", resp.Provider)
+	}
+}
+
+func TestClientCompleteRoutingStopsOnNonRetryableError(t *testing.T) {
+	failing := &mockAdapter{name: "failing", err: &AuthenticationError{
+		ProviderError: ProviderError{SDKError: SDKError{Message: "bad key"}},
+	}}
+	healthy := newMockAdapter("healthy", "ok")
+
+	client := NewClient(
+		WithProvider("failing", failing),
+		WithProvider("healthy", healthy),
+		WithRoutingPolicy(NewFailoverRoutingPolicy("failing", "healthy")),
+	)
+
+	_, err := client.Complete(context.Background(), Request{Model: "test-model"})
+	if err == nil {
+		t.Fatal("expected a non-retryable error to abort routing")
+	}
+}
@@ -0,0 +1,54 @@
+package unifiedllm
+
+// MaxSteps returns a StopCondition that fires once at least n steps have
+// been accumulated.
+func MaxSteps(n int) StopCondition {
+	return func(steps []StepResult) bool {
+		return len(steps) >= n
+	}
+}
+
+// NoToolCalls returns a StopCondition that fires when the most recent step
+// produced no tool calls.
+func NoToolCalls() StopCondition {
+	return func(steps []StepResult) bool {
+		if len(steps) == 0 {
+			return false
+		}
+		return len(steps[len(steps)-1].ToolCalls) == 0
+	}
+}
+
+// TokensExceeded returns a StopCondition that fires once the usage
+// accumulated across all steps exceeds n total tokens.
+func TokensExceeded(n int) StopCondition {
+	return func(steps []StepResult) bool {
+		var total int
+		for _, s := range steps {
+			total += s.Usage.TotalTokens
+		}
+		return total > n
+	}
+}
+
+// HasFinishReason returns a StopCondition that fires when the most recent
+// step's FinishReason matches reason.
+func HasFinishReason(reason string) StopCondition {
+	return func(steps []StepResult) bool {
+		if len(steps) == 0 {
+			return false
+		}
+		return steps[len(steps)-1].FinishReason.Reason == reason
+	}
+}
+
+// IsAssistantContinuation reports whether messages ends with an assistant
+// message, meaning the caller wants Generate to continue generation from an
+// existing assistant turn rather than starting a fresh round from a new
+// user message.
+func IsAssistantContinuation(messages []Message) bool {
+	if len(messages) == 0 {
+		return false
+	}
+	return messages[len(messages)-1].Role == RoleAssistant
+}
@@ -0,0 +1,69 @@
+package unifiedllm
+
+import "testing"
+
+func TestMaxSteps(t *testing.T) {
+	cond := MaxSteps(2)
+	if cond(nil) {
+		t.Error("expected false for zero steps")
+	}
+	if cond([]StepResult{{}}) {
+		t.Error("expected false for one step")
+	}
+	if !cond([]StepResult{{}, {}}) {
+		t.Error("expected true for two steps")
+	}
+}
+
+func TestNoToolCalls(t *testing.T) {
+	cond := NoToolCalls()
+	if cond(nil) {
+		t.Error("expected false for zero steps")
+	}
+	steps := []StepResult{{ToolCalls: []ToolCall{{Name: "x"}}}}
+	if cond(steps) {
+		t.Error("expected false when the last step has tool calls")
+	}
+	steps = append(steps, StepResult{})
+	if !cond(steps) {
+		t.Error("expected true when the last step has no tool calls")
+	}
+}
+
+func TestTokensExceeded(t *testing.T) {
+	cond := TokensExceeded(100)
+	steps := []StepResult{{Usage: Usage{TotalTokens: 60}}, {Usage: Usage{TotalTokens: 50}}}
+	if !cond(steps) {
+		t.Error("expected true once accumulated usage exceeds the limit")
+	}
+	if cond(steps[:1]) {
+		t.Error("expected false while under the limit")
+	}
+}
+
+func TestHasFinishReason(t *testing.T) {
+	cond := HasFinishReason("stop")
+	if cond(nil) {
+		t.Error("expected false for zero steps")
+	}
+	steps := []StepResult{{FinishReason: FinishReason{Reason: "tool_calls"}}}
+	if cond(steps) {
+		t.Error("expected false when the last finish reason doesn't match")
+	}
+	steps = append(steps, StepResult{FinishReason: FinishReason{Reason: "stop"}})
+	if !cond(steps) {
+		t.Error("expected true when the last finish reason matches")
+	}
+}
+
+func TestIsAssistantContinuation(t *testing.T) {
+	if IsAssistantContinuation(nil) {
+		t.Error("expected false for empty messages")
+	}
+	if IsAssistantContinuation([]Message{UserMessage("hi")}) {
+		t.Error("expected false when the last message is from the user")
+	}
+	if !IsAssistantContinuation([]Message{UserMessage("hi"), AssistantMessage("ok")}) {
+		t.Error("expected true when the last message is from the assistant")
+	}
+}
@@ -0,0 +1,140 @@
+package unifiedllm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// ToolApprovalAction is the decision GenerateOptions.ApproveToolCall returns
+// for one proposed tool call.
+type ToolApprovalAction int
+
+const (
+	// ApprovalActionApprove runs the call as the model proposed it.
+	ApprovalActionApprove ToolApprovalAction = iota
+	// ApprovalActionDeny skips execution; ToolApprovalDecision.Reason (or a
+	// generic message if empty) becomes the ToolResult content, IsError true.
+	ApprovalActionDeny
+	// ApprovalActionModify runs the call with
+	// ToolApprovalDecision.Arguments in place of the model's own.
+	ApprovalActionModify
+	// ApprovalActionAbortRun stops Generate's tool-round loop after this
+	// round's results are folded in, without executing this call or any
+	// later call in the same round.
+	ApprovalActionAbortRun
+)
+
+// ToolApprovalDecision is returned by GenerateOptions.ApproveToolCall for a
+// single proposed tool call.
+type ToolApprovalDecision struct {
+	Action ToolApprovalAction
+
+	// Reason is surfaced as the ToolResult content when Action is
+	// ApprovalActionDeny.
+	Reason string
+
+	// Arguments replaces the model's own arguments when Action is
+	// ApprovalActionModify.
+	Arguments json.RawMessage
+}
+
+// ApproveTool returns a ToolApprovalDecision approving a call unmodified,
+// for a frontend's "allow" path.
+func ApproveTool() ToolApprovalDecision {
+	return ToolApprovalDecision{Action: ApprovalActionApprove}
+}
+
+// DenyToolCall returns a ToolApprovalDecision denying a call, surfacing
+// reason to the model as the ToolResult content.
+func DenyToolCall(reason string) ToolApprovalDecision {
+	return ToolApprovalDecision{Action: ApprovalActionDeny, Reason: reason}
+}
+
+// ModifyToolCall returns a ToolApprovalDecision running a call with
+// arguments in place of the model's own.
+func ModifyToolCall(arguments json.RawMessage) ToolApprovalDecision {
+	return ToolApprovalDecision{Action: ApprovalActionModify, Arguments: arguments}
+}
+
+// AbortToolRun returns a ToolApprovalDecision that stops Generate's
+// tool-round loop cleanly once the current round's results are folded in.
+func AbortToolRun() ToolApprovalDecision {
+	return ToolApprovalDecision{Action: ApprovalActionAbortRun}
+}
+
+// gateToolCalls applies approve to each of calls in order, separating those
+// Generate should actually execute (pending, with ApprovalActionModify's
+// Arguments substituted in) from those already decided without running
+// (decided, keyed by ToolCallID -- denials only). The call that returned
+// ApprovalActionAbortRun, and every call after it, is in neither;
+// mergeToolResults fills those in. abort reports whether Generate's loop
+// should stop once this round's results are folded in.
+func gateToolCalls(ctx context.Context, approve func(ctx context.Context, call ToolCall) (ToolApprovalDecision, error), calls []ToolCall) (pending []ToolCall, decided map[string]ToolResult, abort bool, err error) {
+	decided = make(map[string]ToolResult)
+	for _, call := range calls {
+		if abort {
+			// A prior call in this round already triggered AbortRun; later
+			// calls are left undecided (see mergeToolResults) rather than
+			// proposed for approval at all.
+			continue
+		}
+
+		decision, derr := approve(ctx, call)
+		if derr != nil {
+			return nil, nil, false, fmt.Errorf("unifiedllm: tool approval for %q: %w", call.Name, derr)
+		}
+
+		switch decision.Action {
+		case ApprovalActionApprove:
+			pending = append(pending, call)
+		case ApprovalActionModify:
+			modified := call
+			modified.Arguments = decision.Arguments
+			pending = append(pending, modified)
+		case ApprovalActionDeny:
+			reason := decision.Reason
+			if reason == "" {
+				reason = fmt.Sprintf("tool call %q was denied", call.Name)
+			}
+			decided[call.ID] = ToolResult{ToolCallID: call.ID, Content: reason, IsError: true}
+		case ApprovalActionAbortRun:
+			abort = true
+		default:
+			return nil, nil, false, fmt.Errorf("unifiedllm: tool approval for %q: unknown action %d", call.Name, decision.Action)
+		}
+	}
+	return pending, decided, abort, nil
+}
+
+// mergeToolResults reassembles executed (the results of running pending,
+// see gateToolCalls) and decided (results gateToolCalls already settled
+// without executing) back into calls' original order, so a round's
+// StepResult.ToolResults stays aligned 1:1 with StepResult.ToolCalls
+// regardless of which calls actually ran.
+func mergeToolResults(calls []ToolCall, executed []ToolResult, decided map[string]ToolResult) []ToolResult {
+	executedByID := make(map[string]ToolResult, len(executed))
+	for _, r := range executed {
+		executedByID[r.ToolCallID] = r
+	}
+
+	merged := make([]ToolResult, len(calls))
+	for i, call := range calls {
+		if r, ok := executedByID[call.ID]; ok {
+			merged[i] = r
+			continue
+		}
+		if r, ok := decided[call.ID]; ok {
+			merged[i] = r
+			continue
+		}
+		// Left undecided because an earlier call in this round triggered
+		// AbortRun.
+		merged[i] = ToolResult{
+			ToolCallID: call.ID,
+			Content:    fmt.Sprintf("tool call %q was not run: run aborted", call.Name),
+			IsError:    true,
+		}
+	}
+	return merged
+}
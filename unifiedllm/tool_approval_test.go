@@ -0,0 +1,221 @@
+package unifiedllm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func weatherToolCallResponse() *Response {
+	return &Response{
+		ID:       "resp_tool",
+		Model:    "test-model",
+		Provider: "test",
+		Message: Message{
+			Role: RoleAssistant,
+			Content: []ContentPart{
+				ToolCallPart("call_1", "get_weather", json.RawMessage(`{"city":"SF"}`)),
+			},
+		},
+		FinishReason: FinishReason{Reason: "tool_calls"},
+		Usage:        Usage{InputTokens: 10, OutputTokens: 5, TotalTokens: 15},
+	}
+}
+
+func newWeatherTool(execute func(args json.RawMessage) (interface{}, error)) Tool {
+	return Tool{
+		Name:        "get_weather",
+		Description: "Get weather",
+		Parameters: map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"city": map[string]interface{}{"type": "string"},
+			},
+		},
+		Execute: execute,
+	}
+}
+
+func TestGenerateApproveToolCallApprove(t *testing.T) {
+	var gotArgs json.RawMessage
+	weatherTool := newWeatherTool(func(args json.RawMessage) (interface{}, error) {
+		gotArgs = args
+		return "72F and sunny", nil
+	})
+
+	adapter := &sequenceAdapter{
+		name:      "test",
+		responses: []*Response{weatherToolCallResponse(), newMockAdapter("test", "It's 72F in SF").response},
+	}
+	client := NewClient(WithProvider("test", adapter))
+
+	approveCalls := 0
+	result, err := Generate(context.Background(), GenerateOptions{
+		Model:         "test-model",
+		Prompt:        "What's the weather in SF?",
+		Tools:         []Tool{weatherTool},
+		MaxToolRounds: 3,
+		Provider:      "test",
+		Client:        client,
+		MaxRetries:    0,
+		ApproveToolCall: func(ctx context.Context, call ToolCall) (ToolApprovalDecision, error) {
+			approveCalls++
+			return ApproveTool(), nil
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if approveCalls != 1 {
+		t.Errorf("expected ApproveToolCall to be invoked once, got %d", approveCalls)
+	}
+	if string(gotArgs) != `{"city":"SF"}` {
+		t.Errorf("expected unmodified arguments, got %s", gotArgs)
+	}
+	if result.Text != "It's 72F in SF" {
+		t.Errorf("expected final text %q, got %q", "It's 72F in SF", result.Text)
+	}
+}
+
+func TestGenerateApproveToolCallDeny(t *testing.T) {
+	called := false
+	weatherTool := newWeatherTool(func(args json.RawMessage) (interface{}, error) {
+		called = true
+		return "72F and sunny", nil
+	})
+
+	adapter := &sequenceAdapter{
+		name:      "test",
+		responses: []*Response{weatherToolCallResponse(), newMockAdapter("test", "ok").response},
+	}
+	client := NewClient(WithProvider("test", adapter))
+
+	result, err := Generate(context.Background(), GenerateOptions{
+		Model:         "test-model",
+		Prompt:        "What's the weather in SF?",
+		Tools:         []Tool{weatherTool},
+		MaxToolRounds: 3,
+		Provider:      "test",
+		Client:        client,
+		MaxRetries:    0,
+		ApproveToolCall: func(ctx context.Context, call ToolCall) (ToolApprovalDecision, error) {
+			return DenyToolCall("not allowed"), nil
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if called {
+		t.Error("expected the tool not to execute when denied")
+	}
+	if len(result.Steps) == 0 || len(result.Steps[0].ToolResults) != 1 {
+		t.Fatalf("expected one tool result in the first step, got %+v", result.Steps)
+	}
+	toolResult := result.Steps[0].ToolResults[0]
+	if !toolResult.IsError || toolResult.Content != "not allowed" {
+		t.Errorf("expected denial result with reason, got %+v", toolResult)
+	}
+}
+
+func TestGenerateApproveToolCallModify(t *testing.T) {
+	var gotArgs json.RawMessage
+	weatherTool := newWeatherTool(func(args json.RawMessage) (interface{}, error) {
+		gotArgs = args
+		return "10F and snowing", nil
+	})
+
+	adapter := &sequenceAdapter{
+		name:      "test",
+		responses: []*Response{weatherToolCallResponse(), newMockAdapter("test", "ok").response},
+	}
+	client := NewClient(WithProvider("test", adapter))
+
+	_, err := Generate(context.Background(), GenerateOptions{
+		Model:         "test-model",
+		Prompt:        "What's the weather in SF?",
+		Tools:         []Tool{weatherTool},
+		MaxToolRounds: 3,
+		Provider:      "test",
+		Client:        client,
+		MaxRetries:    0,
+		ApproveToolCall: func(ctx context.Context, call ToolCall) (ToolApprovalDecision, error) {
+			return ModifyToolCall(json.RawMessage(`{"city":"Anchorage"}`)), nil
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if string(gotArgs) != `{"city":"Anchorage"}` {
+		t.Errorf("expected modified arguments, got %s", gotArgs)
+	}
+}
+
+func TestGenerateApproveToolCallAbortRun(t *testing.T) {
+	called := false
+	weatherTool := newWeatherTool(func(args json.RawMessage) (interface{}, error) {
+		called = true
+		return "72F and sunny", nil
+	})
+
+	adapter := &sequenceAdapter{
+		name:      "test",
+		responses: []*Response{weatherToolCallResponse(), newMockAdapter("test", "unreachable").response},
+	}
+	client := NewClient(WithProvider("test", adapter))
+
+	result, err := Generate(context.Background(), GenerateOptions{
+		Model:         "test-model",
+		Prompt:        "What's the weather in SF?",
+		Tools:         []Tool{weatherTool},
+		MaxToolRounds: 3,
+		Provider:      "test",
+		Client:        client,
+		MaxRetries:    0,
+		ApproveToolCall: func(ctx context.Context, call ToolCall) (ToolApprovalDecision, error) {
+			return AbortToolRun(), nil
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if called {
+		t.Error("expected the tool not to execute after AbortRun")
+	}
+	if len(result.Steps) != 1 {
+		t.Fatalf("expected the loop to stop after the aborted round, got %d steps", len(result.Steps))
+	}
+	toolResult := result.Steps[0].ToolResults[0]
+	if !toolResult.IsError {
+		t.Errorf("expected an error result for the unreached call, got %+v", toolResult)
+	}
+}
+
+func TestGenerateApproveToolCallError(t *testing.T) {
+	weatherTool := newWeatherTool(func(args json.RawMessage) (interface{}, error) {
+		return "72F and sunny", nil
+	})
+
+	adapter := &sequenceAdapter{
+		name:      "test",
+		responses: []*Response{weatherToolCallResponse()},
+	}
+	client := NewClient(WithProvider("test", adapter))
+
+	wantErr := errors.New("approval backend unavailable")
+	_, err := Generate(context.Background(), GenerateOptions{
+		Model:         "test-model",
+		Prompt:        "What's the weather in SF?",
+		Tools:         []Tool{weatherTool},
+		MaxToolRounds: 3,
+		Provider:      "test",
+		Client:        client,
+		MaxRetries:    0,
+		ApproveToolCall: func(ctx context.Context, call ToolCall) (ToolApprovalDecision, error) {
+			return ToolApprovalDecision{}, wantErr
+		},
+	})
+	if err == nil || !errors.Is(err, wantErr) {
+		t.Fatalf("expected error wrapping %v, got %v", wantErr, err)
+	}
+}
@@ -0,0 +1,222 @@
+package unifiedllm
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// toolCallBuffer accumulates the raw JSON argument fragments for one
+// in-flight tool call, keyed by ToolCall.ID.
+type toolCallBuffer struct {
+	id    string
+	name  string
+	buf   strings.Builder
+	fired bool
+}
+
+// ToolCallAssembler reassembles streamed tool-call argument fragments
+// (Anthropic's input_json_delta, OpenAI's arguments chunks, Gemini's whole
+// functionCall blocks) into complete, parsed ToolCall values. It fires
+// OnToolCallReady as soon as a buffer parses as well-formed JSON, so
+// consumers can execute tools optimistically rather than waiting for
+// StreamFinish.
+type ToolCallAssembler struct {
+	mu       sync.Mutex
+	buffers  map[string]*toolCallBuffer
+	onReady  func(ToolCall)
+	warnings []Warning
+}
+
+// NewToolCallAssembler creates an empty ToolCallAssembler.
+func NewToolCallAssembler() *ToolCallAssembler {
+	return &ToolCallAssembler{buffers: make(map[string]*toolCallBuffer)}
+}
+
+// OnToolCallReady registers the callback fired whenever a tool call's
+// arguments parse cleanly, either mid-stream or at ToolCallEnd.
+func (a *ToolCallAssembler) OnToolCallReady(fn func(ToolCall)) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.onReady = fn
+}
+
+// Consume processes every event from ch until it closes.
+func (a *ToolCallAssembler) Consume(ch <-chan StreamEvent) {
+	for event := range ch {
+		a.Process(event)
+	}
+}
+
+// Process ingests a single StreamEvent, updating the relevant tool call
+// buffer and firing OnToolCallReady when appropriate.
+func (a *ToolCallAssembler) Process(event StreamEvent) {
+	if event.ToolCall == nil {
+		return
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	switch event.Type {
+	case ToolCallStart:
+		buf := a.bufferFor(event.ToolCall)
+		a.appendFragment(buf, event)
+
+	case ToolCallDelta:
+		buf := a.bufferFor(event.ToolCall)
+		a.appendFragment(buf, event)
+		a.tryFire(buf)
+
+	case ToolCallEnd:
+		buf := a.bufferFor(event.ToolCall)
+		if len(event.ToolCall.Arguments) > 0 {
+			// The provider already handed back complete arguments (e.g. Gemini,
+			// or Anthropic at content_block_stop); trust them over the buffer.
+			buf.buf.Reset()
+			buf.buf.Write(event.ToolCall.Arguments)
+		}
+		a.finish(buf)
+		delete(a.buffers, buf.id)
+	}
+}
+
+// Warnings returns the warnings recorded for tool calls whose arguments
+// could not be assembled into valid JSON, even after repair.
+func (a *ToolCallAssembler) Warnings() []Warning {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	out := make([]Warning, len(a.warnings))
+	copy(out, a.warnings)
+	return out
+}
+
+func (a *ToolCallAssembler) bufferFor(tc *ToolCall) *toolCallBuffer {
+	buf, ok := a.buffers[tc.ID]
+	if !ok {
+		buf = &toolCallBuffer{id: tc.ID, name: tc.Name}
+		a.buffers[tc.ID] = buf
+	}
+	if tc.Name != "" {
+		buf.name = tc.Name
+	}
+	return buf
+}
+
+func (a *ToolCallAssembler) appendFragment(buf *toolCallBuffer, event StreamEvent) {
+	if event.Delta != "" {
+		buf.buf.WriteString(event.Delta)
+	}
+	if len(event.ToolCall.Arguments) > 0 {
+		buf.buf.Write(event.ToolCall.Arguments)
+	}
+}
+
+// tryFire fires OnToolCallReady the first time buf's contents parse as
+// well-formed JSON.
+func (a *ToolCallAssembler) tryFire(buf *toolCallBuffer) {
+	if buf.fired {
+		return
+	}
+	raw, ok := parseCleanJSON(buf.buf.String())
+	if !ok {
+		return
+	}
+	a.fire(buf, raw)
+}
+
+// finish fires OnToolCallReady if buf hasn't already fired, repairing common
+// truncation cases via incremental brace-balancing before recording a
+// Warning and giving up.
+func (a *ToolCallAssembler) finish(buf *toolCallBuffer) {
+	if buf.fired {
+		return
+	}
+	raw, ok := parseCleanJSON(buf.buf.String())
+	if !ok {
+		raw, ok = parseCleanJSON(repairPartialJSON(buf.buf.String()))
+	}
+	if !ok {
+		a.warnings = append(a.warnings, Warning{
+			Message: fmt.Sprintf("tool call %q (%s): could not assemble valid JSON arguments from streamed fragments", buf.id, buf.name),
+			Code:    "tool_call_argument_assembly_failed",
+		})
+		return
+	}
+	a.fire(buf, raw)
+}
+
+func (a *ToolCallAssembler) fire(buf *toolCallBuffer, raw json.RawMessage) {
+	buf.fired = true
+	if a.onReady != nil {
+		a.onReady(ToolCall{ID: buf.id, Name: buf.name, Arguments: raw})
+	}
+}
+
+// parseCleanJSON reports whether s decodes as exactly one well-formed JSON
+// value, using json.Decoder with UseNumber for numeric fidelity.
+func parseCleanJSON(s string) (json.RawMessage, bool) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil, false
+	}
+	dec := json.NewDecoder(strings.NewReader(s))
+	dec.UseNumber()
+	var v interface{}
+	if err := dec.Decode(&v); err != nil {
+		return nil, false
+	}
+	if dec.More() {
+		return nil, false
+	}
+	return json.RawMessage(s), true
+}
+
+// repairPartialJSON attempts to close an unterminated string and balance any
+// unclosed braces/brackets in a truncated JSON fragment, dropping a trailing
+// dangling comma first since that's the most common truncation point for
+// streamed object/array arguments.
+func repairPartialJSON(raw string) string {
+	s := strings.TrimRight(strings.TrimSpace(raw), ", \t\n")
+
+	var stack []byte
+	inString := false
+	escaped := false
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if inString {
+			switch {
+			case escaped:
+				escaped = false
+			case c == '\\':
+				escaped = true
+			case c == '"':
+				inString = false
+			}
+			continue
+		}
+		switch c {
+		case '"':
+			inString = true
+		case '{', '[':
+			stack = append(stack, c)
+		case '}', ']':
+			if len(stack) > 0 {
+				stack = stack[:len(stack)-1]
+			}
+		}
+	}
+
+	if inString {
+		s += `"`
+	}
+	for i := len(stack) - 1; i >= 0; i-- {
+		if stack[i] == '{' {
+			s += "}"
+		} else {
+			s += "]"
+		}
+	}
+	return s
+}
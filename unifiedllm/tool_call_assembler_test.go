@@ -0,0 +1,117 @@
+package unifiedllm
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestToolCallAssemblerFiresOnceArgumentsParseMidStream(t *testing.T) {
+	a := NewToolCallAssembler()
+	var got []ToolCall
+	a.OnToolCallReady(func(tc ToolCall) { got = append(got, tc) })
+
+	a.Process(StreamEvent{Type: ToolCallStart, ToolCall: &ToolCall{ID: "call_1", Name: "get_weather"}})
+	a.Process(StreamEvent{Type: ToolCallDelta, ToolCall: &ToolCall{ID: "call_1"}, Delta: `{"city":`})
+	if len(got) != 0 {
+		t.Fatalf("expected no callback before arguments are well-formed, got %d", len(got))
+	}
+	a.Process(StreamEvent{Type: ToolCallDelta, ToolCall: &ToolCall{ID: "call_1"}, Delta: `"nyc"}`})
+	if len(got) != 1 {
+		t.Fatalf("expected one callback once arguments parse cleanly, got %d", len(got))
+	}
+	if got[0].Name != "get_weather" {
+		t.Errorf("expected name get_weather, got %q", got[0].Name)
+	}
+
+	a.Process(StreamEvent{Type: ToolCallEnd, ToolCall: &ToolCall{ID: "call_1"}})
+	if len(got) != 1 {
+		t.Errorf("expected no duplicate callback at ToolCallEnd, got %d calls", len(got))
+	}
+	if len(a.Warnings()) != 0 {
+		t.Errorf("expected no warnings, got %v", a.Warnings())
+	}
+}
+
+func TestToolCallAssemblerRepairsTruncatedArgumentsAtEnd(t *testing.T) {
+	a := NewToolCallAssembler()
+	var got []ToolCall
+	a.OnToolCallReady(func(tc ToolCall) { got = append(got, tc) })
+
+	a.Process(StreamEvent{Type: ToolCallStart, ToolCall: &ToolCall{ID: "call_1", Name: "search"}})
+	a.Process(StreamEvent{Type: ToolCallDelta, ToolCall: &ToolCall{ID: "call_1"}, Delta: `{"query":"widgets","limit":5,`})
+	a.Process(StreamEvent{Type: ToolCallEnd, ToolCall: &ToolCall{ID: "call_1"}})
+
+	if len(got) != 1 {
+		t.Fatalf("expected the repaired arguments to fire once, got %d", len(got))
+	}
+	var args struct {
+		Query string `json:"query"`
+		Limit int     `json:"limit"`
+	}
+	if err := json.Unmarshal(got[0].Arguments, &args); err != nil {
+		t.Fatalf("expected repaired arguments to unmarshal, got error: %v", err)
+	}
+	if args.Query != "widgets" || args.Limit != 5 {
+		t.Errorf("unexpected repaired arguments: %+v", args)
+	}
+}
+
+func TestToolCallAssemblerRepairsUnterminatedStringWithoutWarning(t *testing.T) {
+	a := NewToolCallAssembler()
+	fired := false
+	a.OnToolCallReady(func(tc ToolCall) { fired = true })
+
+	a.Process(StreamEvent{Type: ToolCallStart, ToolCall: &ToolCall{ID: "call_1", Name: "broken"}})
+	a.Process(StreamEvent{Type: ToolCallDelta, ToolCall: &ToolCall{ID: "call_1"}, Delta: `{"query": "unterminated`})
+	a.Process(StreamEvent{Type: ToolCallEnd, ToolCall: &ToolCall{ID: "call_1"}})
+
+	if !fired {
+		t.Fatal("expected the assembler to repair the unterminated string and still fire")
+	}
+
+	warnings := a.Warnings()
+	if len(warnings) != 0 {
+		t.Errorf("expected no warnings once the repair succeeds, got %v", warnings)
+	}
+}
+
+func TestToolCallAssemblerWarnsOnUnrepairableArguments(t *testing.T) {
+	a := NewToolCallAssembler()
+	fired := false
+	a.OnToolCallReady(func(tc ToolCall) { fired = true })
+
+	a.Process(StreamEvent{Type: ToolCallStart, ToolCall: &ToolCall{ID: "call_1", Name: "broken"}})
+	a.Process(StreamEvent{Type: ToolCallDelta, ToolCall: &ToolCall{ID: "call_1"}, Delta: `not json at all`})
+	a.Process(StreamEvent{Type: ToolCallEnd, ToolCall: &ToolCall{ID: "call_1"}})
+
+	if fired {
+		t.Fatal("expected no callback for arguments that cannot be repaired into valid JSON")
+	}
+	warnings := a.Warnings()
+	if len(warnings) != 1 {
+		t.Fatalf("expected one warning, got %d", len(warnings))
+	}
+	if warnings[0].Code != "tool_call_argument_assembly_failed" {
+		t.Errorf("unexpected warning code: %q", warnings[0].Code)
+	}
+}
+
+func TestToolCallAssemblerTrustsWholeBlockAtEnd(t *testing.T) {
+	a := NewToolCallAssembler()
+	var got []ToolCall
+	a.OnToolCallReady(func(tc ToolCall) { got = append(got, tc) })
+
+	// Gemini-style: no deltas, a single complete block on ToolCallEnd.
+	a.Process(StreamEvent{Type: ToolCallEnd, ToolCall: &ToolCall{
+		ID:        "call_1",
+		Name:      "get_weather",
+		Arguments: json.RawMessage(`{"city":"nyc"}`),
+	}})
+
+	if len(got) != 1 {
+		t.Fatalf("expected one callback for a whole functionCall block, got %d", len(got))
+	}
+	if string(got[0].Arguments) != `{"city":"nyc"}` {
+		t.Errorf("unexpected arguments: %s", got[0].Arguments)
+	}
+}
@@ -0,0 +1,77 @@
+package unifiedllm
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// TracingMiddleware returns a Middleware that opens one OpenTelemetry span
+// per call attempt (so a request retried by RetryPolicyMiddleware or
+// RetryMiddleware produces one span per attempt, not one for the whole
+// call), named "gen_ai.<provider>.complete" and tagged with the gen_ai.*
+// semantic-convention attributes: gen_ai.system, gen_ai.request.model,
+// gen_ai.response.model, gen_ai.response.finish_reasons, and
+// gen_ai.usage.input_tokens/gen_ai.usage.output_tokens. A returned error
+// records the span as errored via span.RecordError/SetStatus.
+func TracingMiddleware(tracer trace.Tracer) Middleware {
+	return func(ctx context.Context, req Request, next func(context.Context, Request) (*Response, error)) (*Response, error) {
+		ctx, span := tracer.Start(ctx, "gen_ai."+providerOrUnknown(req.Provider)+".complete",
+			trace.WithAttributes(
+				attribute.String("gen_ai.system", providerOrUnknown(req.Provider)),
+				attribute.String("gen_ai.request.model", req.Model),
+			),
+		)
+		defer span.End()
+
+		resp, err := next(ctx, req)
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+			return nil, err
+		}
+
+		span.SetAttributes(
+			attribute.String("gen_ai.response.model", resp.Model),
+			attribute.String("gen_ai.response.finish_reasons", resp.FinishReason.Reason),
+			attribute.Int64("gen_ai.usage.input_tokens", int64(resp.Usage.InputTokens)),
+			attribute.Int64("gen_ai.usage.output_tokens", int64(resp.Usage.OutputTokens)),
+		)
+		return resp, nil
+	}
+}
+
+// StreamTracingMiddleware is TracingMiddleware's Stream counterpart. Since a
+// stream's usage totals aren't known until it closes, the span covers only
+// the call that opens the stream (mirroring the provider's own Stream
+// contract); it does not stay open for the lifetime of event delivery.
+func StreamTracingMiddleware(tracer trace.Tracer) StreamMiddleware {
+	return func(ctx context.Context, req Request, next func(context.Context, Request) (<-chan StreamEvent, error)) (<-chan StreamEvent, error) {
+		ctx, span := tracer.Start(ctx, "gen_ai."+providerOrUnknown(req.Provider)+".stream",
+			trace.WithAttributes(
+				attribute.String("gen_ai.system", providerOrUnknown(req.Provider)),
+				attribute.String("gen_ai.request.model", req.Model),
+			),
+		)
+		defer span.End()
+
+		events, err := next(ctx, req)
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		return events, err
+	}
+}
+
+// providerOrUnknown returns provider, or "unknown" if it's empty -- a
+// request can reach middleware before Client.Complete/Stream fills in
+// req.Provider from the resolved adapter.
+func providerOrUnknown(provider string) string {
+	if provider == "" {
+		return "unknown"
+	}
+	return provider
+}
@@ -3,6 +3,7 @@
 package unifiedllm
 
 import (
+	"context"
 	"encoding/json"
 	"strings"
 	"time"
@@ -80,16 +81,26 @@ type ThinkingData struct {
 	Redacted  bool   `json:"redacted"`
 }
 
+// CacheHint requests that a provider cache the content it's attached to, so
+// a later request reusing the same prefix is served from cache rather than
+// reprocessed. Providers that don't support caching treat it as a no-op and
+// report a Warning rather than an error.
+type CacheHint struct {
+	Type string        `json:"type"` // "ephemeral" (the only type today)
+	TTL  time.Duration `json:"ttl,omitempty"`
+}
+
 // ContentPart is a tagged union representing one part of a message.
 type ContentPart struct {
-	Kind       ContentKind     `json:"kind"`
-	Text       string          `json:"text,omitempty"`
-	Image      *ImageData      `json:"image,omitempty"`
-	Audio      *AudioData      `json:"audio,omitempty"`
-	Document   *DocumentData   `json:"document,omitempty"`
-	ToolCall   *ToolCallData   `json:"tool_call,omitempty"`
-	ToolResult *ToolResultData `json:"tool_result,omitempty"`
-	Thinking   *ThinkingData   `json:"thinking,omitempty"`
+	Kind         ContentKind     `json:"kind"`
+	Text         string          `json:"text,omitempty"`
+	Image        *ImageData      `json:"image,omitempty"`
+	Audio        *AudioData      `json:"audio,omitempty"`
+	Document     *DocumentData   `json:"document,omitempty"`
+	ToolCall     *ToolCallData   `json:"tool_call,omitempty"`
+	ToolResult   *ToolResultData `json:"tool_result,omitempty"`
+	Thinking     *ThinkingData   `json:"thinking,omitempty"`
+	CacheControl *CacheHint      `json:"cache_control,omitempty"`
 }
 
 // TextPart creates a text ContentPart.
@@ -142,10 +153,11 @@ func ThinkingPart(text, signature string) ContentPart {
 
 // Message is the fundamental unit of conversation.
 type Message struct {
-	Role       Role          `json:"role"`
-	Content    []ContentPart `json:"content"`
-	Name       string        `json:"name,omitempty"`
-	ToolCallID string        `json:"tool_call_id,omitempty"`
+	Role         Role          `json:"role"`
+	Content      []ContentPart `json:"content"`
+	Name         string        `json:"name,omitempty"`
+	ToolCallID   string        `json:"tool_call_id,omitempty"`
+	CacheControl *CacheHint    `json:"cache_control,omitempty"`
 }
 
 // TextContent returns the concatenation of all text content parts.
@@ -175,6 +187,17 @@ func SystemMessage(text string) Message {
 	return Message{Role: RoleSystem, Content: []ContentPart{TextPart(text)}}
 }
 
+// SystemMessageCached creates a system Message marked for provider-side
+// caching with the given TTL, so repeated requests sharing this prompt
+// prefix skip reprocessing on providers that support it.
+func SystemMessageCached(text string, ttl time.Duration) Message {
+	hint := &CacheHint{Type: "ephemeral", TTL: ttl}
+	msg := SystemMessage(text)
+	msg.CacheControl = hint
+	msg.Content[len(msg.Content)-1].CacheControl = hint
+	return msg
+}
+
 // UserMessage creates a user Message with text content.
 func UserMessage(text string) Message {
 	return Message{Role: RoleUser, Content: []ContentPart{TextPart(text)}}
@@ -203,10 +226,22 @@ type ToolChoice struct {
 
 // Tool defines a tool the model can call.
 type Tool struct {
-	Name        string                                            `json:"name"`
-	Description string                                            `json:"description"`
-	Parameters  map[string]interface{}                            `json:"parameters"` // JSON Schema
-	Execute     func(args json.RawMessage) (interface{}, error)   `json:"-"`          // active tool handler
+	Name        string                                          `json:"name"`
+	Description string                                          `json:"description"`
+	Parameters  map[string]interface{}                          `json:"parameters"` // JSON Schema
+	Execute     func(args json.RawMessage) (interface{}, error) `json:"-"`          // active tool handler
+
+	// ExecuteCtx is an active tool handler that observes ctx cancellation,
+	// for tools (shell, network calls) that should stop promptly when the
+	// parent Generate/StreamGenerate call's context is done or its per-tool
+	// timeout elapses. If set, executeToolsConcurrently calls it instead of
+	// Execute; Execute is kept only for tools with nothing to cancel.
+	ExecuteCtx func(ctx context.Context, args json.RawMessage) (interface{}, error) `json:"-"`
+
+	// Timeout bounds a single call to Execute/ExecuteCtx, overriding
+	// GenerateOptions.Timeout.PerTool for this tool. Zero means "use
+	// GenerateOptions.Timeout.PerTool, if any."
+	Timeout time.Duration `json:"-"`
 }
 
 // ToolCall is extracted from a model response.
@@ -239,13 +274,13 @@ type FinishReason struct {
 
 // Usage tracks token consumption.
 type Usage struct {
-	InputTokens     int                    `json:"input_tokens"`
-	OutputTokens    int                    `json:"output_tokens"`
-	TotalTokens     int                    `json:"total_tokens"`
-	ReasoningTokens *int                   `json:"reasoning_tokens,omitempty"`
-	CacheReadTokens *int                   `json:"cache_read_tokens,omitempty"`
-	CacheWriteTokens *int                  `json:"cache_write_tokens,omitempty"`
-	Raw             map[string]interface{} `json:"raw,omitempty"`
+	InputTokens      int                    `json:"input_tokens"`
+	OutputTokens     int                    `json:"output_tokens"`
+	TotalTokens      int                    `json:"total_tokens"`
+	ReasoningTokens  *int                   `json:"reasoning_tokens,omitempty"`
+	CacheReadTokens  *int                   `json:"cache_read_tokens,omitempty"`
+	CacheWriteTokens *int                   `json:"cache_write_tokens,omitempty"`
+	Raw              map[string]interface{} `json:"raw,omitempty"`
 }
 
 // Add returns a new Usage that is the sum of u and other.
@@ -307,6 +342,7 @@ type Request struct {
 	ReasoningEffort string                 `json:"reasoning_effort,omitempty"`
 	Metadata        map[string]string      `json:"metadata,omitempty"`
 	ProviderOptions map[string]interface{} `json:"provider_options,omitempty"`
+	RetryPolicy     *RetryPolicy           `json:"-"` // overrides the Client's configured retry policy for this request only
 }
 
 // ToolDefinition is the serializable part of a Tool (without execute handler).
@@ -327,6 +363,11 @@ type Response struct {
 	Raw          map[string]interface{} `json:"raw,omitempty"`
 	Warnings     []Warning              `json:"warnings,omitempty"`
 	RateLimit    *RateLimitInfo         `json:"rate_limit,omitempty"`
+
+	// RetryCount is the number of retries RetryMiddleware performed before
+	// this response succeeded. It's 0 for a response that succeeded on the
+	// first attempt, or for adapters not wrapped in RetryMiddleware.
+	RetryCount int `json:"retry_count,omitempty"`
 }
 
 // Text returns the concatenated text from all text parts in the response message.
@@ -368,19 +409,25 @@ func (r Response) Reasoning() string {
 type StreamEventType string
 
 const (
-	StreamStart      StreamEventType = "stream_start"
-	TextStart        StreamEventType = "text_start"
-	TextDelta        StreamEventType = "text_delta"
-	TextEnd          StreamEventType = "text_end"
-	ReasoningStart   StreamEventType = "reasoning_start"
-	ReasoningDelta   StreamEventType = "reasoning_delta"
-	ReasoningEnd     StreamEventType = "reasoning_end"
-	ToolCallStart    StreamEventType = "tool_call_start"
-	ToolCallDelta    StreamEventType = "tool_call_delta"
-	ToolCallEnd      StreamEventType = "tool_call_end"
-	StreamFinish     StreamEventType = "finish"
-	StreamError      StreamEventType = "error"
-	ProviderEvent    StreamEventType = "provider_event"
+	StreamStart    StreamEventType = "stream_start"
+	TextStart      StreamEventType = "text_start"
+	TextDelta      StreamEventType = "text_delta"
+	TextEnd        StreamEventType = "text_end"
+	ReasoningStart StreamEventType = "reasoning_start"
+	ReasoningDelta StreamEventType = "reasoning_delta"
+	ReasoningEnd   StreamEventType = "reasoning_end"
+	ToolCallStart  StreamEventType = "tool_call_start"
+	ToolCallDelta  StreamEventType = "tool_call_delta"
+	ToolCallEnd    StreamEventType = "tool_call_end"
+	StreamFinish   StreamEventType = "finish"
+	StreamError    StreamEventType = "error"
+	ProviderEvent  StreamEventType = "provider_event"
+
+	// RoundBoundary is synthesized by StreamGenerate between tool-calling
+	// rounds, never by a provider adapter. It carries no delta of its own;
+	// consumers use it to know a new Round's events are about to start,
+	// e.g. to group deltas by round or reset a per-round display buffer.
+	RoundBoundary StreamEventType = "round_boundary"
 )
 
 // StreamEvent is a single event from a streaming response.
@@ -395,12 +442,18 @@ type StreamEvent struct {
 	Response       *Response              `json:"response,omitempty"`
 	Error          error                  `json:"-"`
 	Raw            map[string]interface{} `json:"raw,omitempty"`
+
+	// Round is the tool-calling round this event belongs to (0-based).
+	// Only StreamGenerate sets it; a single client.Stream call leaves it
+	// at the zero value.
+	Round int `json:"round,omitempty"`
 }
 
 // TimeoutConfig configures timeout behavior.
 type TimeoutConfig struct {
 	Total   time.Duration `json:"total,omitempty"`
 	PerStep time.Duration `json:"per_step,omitempty"`
+	PerTool time.Duration `json:"per_tool,omitempty"`
 }
 
 // StopCondition is a function that decides whether the tool loop should stop.
@@ -418,6 +471,11 @@ type GenerateResult struct {
 	Steps        []StepResult `json:"steps"`
 	Response     Response     `json:"response"`
 	Output       interface{}  `json:"output,omitempty"` // for generate_object
+
+	// LoopPatternLength is the repeating tool-call pattern length (1-3)
+	// GenerateOptions.LoopDetectionWindow last detected, or 0 if loop
+	// detection is disabled or never triggered.
+	LoopPatternLength int `json:"loop_pattern_length,omitempty"`
 }
 
 // StepResult tracks a single step in a multi-step generation.
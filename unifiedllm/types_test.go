@@ -3,6 +3,7 @@ package unifiedllm
 import (
 	"encoding/json"
 	"testing"
+	"time"
 )
 
 func TestMessageConstructors(t *testing.T) {
@@ -36,6 +37,16 @@ func TestMessageConstructors(t *testing.T) {
 		}
 	})
 
+	t.Run("SystemMessageCached", func(t *testing.T) {
+		msg := SystemMessageCached("You are helpful.", 5*time.Minute)
+		if msg.CacheControl == nil || msg.CacheControl.Type != "ephemeral" || msg.CacheControl.TTL != 5*time.Minute {
+			t.Fatalf("unexpected cache control: %+v", msg.CacheControl)
+		}
+		if len(msg.Content) != 1 || msg.Content[0].CacheControl != msg.CacheControl {
+			t.Fatalf("expected the cache hint to also be attached to the last content part, got %+v", msg.Content)
+		}
+	})
+
 	t.Run("ToolResultMessage", func(t *testing.T) {
 		msg := ToolResultMessage("call_123", "72F and sunny", false)
 		if msg.Role != RoleTool {
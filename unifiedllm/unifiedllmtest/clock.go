@@ -0,0 +1,79 @@
+// Package unifiedllmtest provides fakes for testing code built on unifiedllm,
+// starting with a virtual clock and deterministic random source for
+// asserting exact RetryPolicy backoff sequences without real time.Sleep
+// delays.
+package unifiedllmtest
+
+import (
+	"sync"
+	"time"
+
+	"github.com/martinemde/attractor/unifiedllm"
+)
+
+// FakeClock is an in-memory unifiedllm.Clock whose Now only advances when
+// Advance is called, so a test can assert exact backoff sequences -- or
+// simulate hours of retries -- without sleeping in real time.
+type FakeClock struct {
+	mu      sync.Mutex
+	now     time.Time
+	waiters []fakeWaiter
+}
+
+type fakeWaiter struct {
+	deadline time.Time
+	ch       chan time.Time
+}
+
+// NewFakeClock creates a FakeClock starting at an arbitrary fixed instant.
+// Tests care about elapsed durations, not wall-clock time, so the starting
+// point itself doesn't matter.
+func NewFakeClock() *FakeClock {
+	return &FakeClock{now: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)}
+}
+
+// Now returns the clock's current virtual time.
+func (c *FakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// After returns a channel that fires once virtual time has advanced by at
+// least d (via Advance), or immediately if d is zero or negative.
+func (c *FakeClock) After(d time.Duration) <-chan time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	ch := make(chan time.Time, 1)
+	deadline := c.now.Add(d)
+	if !deadline.After(c.now) {
+		ch <- c.now
+		return ch
+	}
+	c.waiters = append(c.waiters, fakeWaiter{deadline: deadline, ch: ch})
+	return ch
+}
+
+// Sleep blocks until virtual time has advanced by at least d.
+func (c *FakeClock) Sleep(d time.Duration) {
+	<-c.After(d)
+}
+
+// Advance moves virtual time forward by d, firing every pending After
+// channel whose deadline has now elapsed.
+func (c *FakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+	remaining := c.waiters[:0]
+	for _, w := range c.waiters {
+		if w.deadline.After(c.now) {
+			remaining = append(remaining, w)
+			continue
+		}
+		w.ch <- c.now
+	}
+	c.waiters = remaining
+}
+
+var _ unifiedllm.Clock = (*FakeClock)(nil)
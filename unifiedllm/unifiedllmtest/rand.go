@@ -0,0 +1,37 @@
+package unifiedllmtest
+
+import (
+	"sync"
+
+	"github.com/martinemde/attractor/unifiedllm"
+)
+
+// FakeRand is an in-memory unifiedllm.Rand that cycles through a fixed,
+// caller-supplied sequence of Float64 values instead of real randomness, so
+// RetryPolicy jitter can be asserted exactly.
+type FakeRand struct {
+	mu     sync.Mutex
+	values []float64
+	next   int
+}
+
+// NewFakeRand creates a FakeRand whose Float64 calls cycle through values in
+// order, wrapping around once exhausted. With no values, Float64 always
+// returns 0.
+func NewFakeRand(values ...float64) *FakeRand {
+	return &FakeRand{values: values}
+}
+
+// Float64 returns the next value in the sequence passed to NewFakeRand.
+func (r *FakeRand) Float64() float64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.values) == 0 {
+		return 0
+	}
+	v := r.values[r.next%len(r.values)]
+	r.next++
+	return v
+}
+
+var _ unifiedllm.Rand = (*FakeRand)(nil)
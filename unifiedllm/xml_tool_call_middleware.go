@@ -0,0 +1,311 @@
+package unifiedllm
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// xmlFunctionCallStop is the stop sequence appended to every request an
+// XMLToolCallMiddleware/XMLToolCallStreamMiddleware transforms, so a
+// text-only model stops generating as soon as it finishes a function call
+// block instead of hallucinating further text after it.
+const xmlFunctionCallStop = "</function_calls>"
+
+// xmlToolsSystemPrompt renders defs as the <tools> block appended to the
+// system prompt, describing each tool's JSON Schema parameters the way a
+// model trained on Anthropic's legacy XML tool-calling convention expects.
+func xmlToolsSystemPrompt(defs []ToolDefinition) string {
+	var sb strings.Builder
+	sb.WriteString("You have access to the following tools. To call one, respond with " +
+		"a <function_calls> block containing one or more <invoke> elements, then stop:\n\n")
+	sb.WriteString("<function_calls>\n<invoke name=\"$TOOL_NAME\">\n<parameter name=\"$PARAM_NAME\">$PARAM_VALUE</parameter>\n...\n</invoke>\n</function_calls>\n\n")
+	sb.WriteString("<tools>\n")
+	for _, def := range defs {
+		params, _ := json.Marshal(def.Parameters)
+		sb.WriteString("<tool_description>\n")
+		fmt.Fprintf(&sb, "<tool_name>%s</tool_name>\n", def.Name)
+		fmt.Fprintf(&sb, "<description>%s</description>\n", def.Description)
+		fmt.Fprintf(&sb, "<parameters>%s</parameters>\n", params)
+		sb.WriteString("</tool_description>\n")
+	}
+	sb.WriteString("</tools>")
+	return sb.String()
+}
+
+// xmlInvoke is one <invoke> element inside a <function_calls> block.
+type xmlInvoke struct {
+	Name       string `xml:"name,attr"`
+	Parameters []struct {
+		Name  string `xml:"name,attr"`
+		Value string `xml:",chardata"`
+	} `xml:"parameter"`
+}
+
+// xmlFunctionCalls is the <function_calls> block XMLToolCallMiddleware
+// parses out of assistant text.
+type xmlFunctionCalls struct {
+	XMLName xml.Name    `xml:"function_calls"`
+	Invokes []xmlInvoke `xml:"invoke"`
+}
+
+// extractXMLFunctionCalls locates the first <function_calls>...</function_calls>
+// block in text and parses it. It returns ok=false if text contains no such
+// block, leaving the caller free to pass the text through unmodified (a
+// plain, non-tool-calling response).
+func extractXMLFunctionCalls(text string) (block string, calls []ToolCallData, ok bool) {
+	start := strings.Index(text, "<function_calls>")
+	if start == -1 {
+		return "", nil, false
+	}
+	end := strings.Index(text[start:], xmlFunctionCallStop)
+	if end == -1 {
+		return "", nil, false
+	}
+	block = text[start : start+end+len(xmlFunctionCallStop)]
+
+	var parsed xmlFunctionCalls
+	if err := xml.Unmarshal([]byte(block), &parsed); err != nil {
+		return "", nil, false
+	}
+
+	for _, invoke := range parsed.Invokes {
+		args := make(map[string]string, len(invoke.Parameters))
+		for _, p := range invoke.Parameters {
+			args[p.Name] = strings.TrimSpace(p.Value)
+		}
+		raw, err := json.Marshal(args)
+		if err != nil {
+			continue
+		}
+		calls = append(calls, ToolCallData{
+			ID:        "call_" + uuid.New().String()[:8],
+			Name:      invoke.Name,
+			Arguments: raw,
+			Type:      "function",
+		})
+	}
+	return block, calls, len(calls) > 0
+}
+
+// renderXMLFunctionCalls is extractXMLFunctionCalls's inverse: it renders
+// calls back into a <function_calls> block, so a prior assistant turn
+// (already parsed into ToolCallPart content by this middleware) reappears
+// as <function_calls> text when that turn is resubmitted as history on the
+// next request. Parameters are written in map iteration order with %v
+// formatting, so the text need not match what the model produced.
+func renderXMLFunctionCalls(calls []ToolCallData) string {
+	var sb strings.Builder
+	sb.WriteString("<function_calls>\n")
+	for _, call := range calls {
+		fmt.Fprintf(&sb, "<invoke name=\"%s\">\n", call.Name)
+		var args map[string]interface{}
+		if err := json.Unmarshal(call.Arguments, &args); err == nil {
+			for name, value := range args {
+				fmt.Fprintf(&sb, "<parameter name=\"%s\">%v</parameter>\n", name, value)
+			}
+		}
+		sb.WriteString("</invoke>\n")
+	}
+	sb.WriteString("</function_calls>")
+	return sb.String()
+}
+
+// renderXMLFunctionResults renders a tool-result content part as a
+// <function_results> block, the form XMLToolCallMiddleware feeds back to
+// the model as a user turn (text-only models have no native tool-result
+// role to put it in).
+func renderXMLFunctionResults(results []ToolResultData) string {
+	var sb strings.Builder
+	sb.WriteString("<function_results>\n")
+	for _, r := range results {
+		var content string
+		if err := json.Unmarshal(r.Content, &content); err != nil {
+			content = string(r.Content)
+		}
+		sb.WriteString("<result>\n")
+		sb.WriteString(content)
+		sb.WriteString("\n</result>\n")
+	}
+	sb.WriteString("</function_results>")
+	return sb.String()
+}
+
+// xmlEncodeRequest rewrites req for a text-only provider: it moves
+// req.ToolDefs into a <tools> block appended to the system message (creating
+// one if req has none), adds the function-call stop sequence, rewrites any
+// prior assistant ToolCallPart content back into <function_calls> text, and
+// rewrites any ToolResultMessage into a <function_results> user message.
+// Requests with no tools are returned unchanged.
+func xmlEncodeRequest(req Request) Request {
+	if len(req.ToolDefs) == 0 {
+		return req
+	}
+
+	out := req
+	out.ToolDefs = nil
+	out.Tools = nil
+	out.ToolChoice = nil
+	out.StopSequences = append(append([]string{}, req.StopSequences...), xmlFunctionCallStop)
+
+	prompt := xmlToolsSystemPrompt(req.ToolDefs)
+	messages := make([]Message, 0, len(req.Messages)+1)
+	seenSystem := false
+	for _, msg := range req.Messages {
+		switch {
+		case msg.Role == RoleSystem:
+			seenSystem = true
+			msg.Content = append(append([]ContentPart{}, msg.Content...), TextPart("\n\n"+prompt))
+			messages = append(messages, msg)
+		case msg.Role == RoleAssistant && hasToolCallContent(msg):
+			messages = append(messages, Message{Role: RoleAssistant, Content: []ContentPart{TextPart(renderXMLFunctionCalls(msg.ToolCalls()))}})
+		case msg.Role == RoleTool:
+			var results []ToolResultData
+			for _, part := range msg.Content {
+				if part.Kind == ContentToolResult && part.ToolResult != nil {
+					results = append(results, *part.ToolResult)
+				}
+			}
+			messages = append(messages, Message{Role: RoleUser, Content: []ContentPart{TextPart(renderXMLFunctionResults(results))}})
+		default:
+			messages = append(messages, msg)
+		}
+	}
+	if !seenSystem {
+		messages = append([]Message{SystemMessage(prompt)}, messages...)
+	}
+	out.Messages = messages
+	return out
+}
+
+// hasToolCallContent reports whether msg carries any ToolCallPart content.
+func hasToolCallContent(msg Message) bool {
+	for _, part := range msg.Content {
+		if part.Kind == ContentToolCall {
+			return true
+		}
+	}
+	return false
+}
+
+// xmlDecodeResponse parses a <function_calls> block out of resp's text, if
+// any, replacing it with ToolCallPart content (synthetic IDs) and any
+// remaining surrounding text as a TextPart, and reports FinishReason
+// "tool_calls" to match what a native provider adapter would report.
+func xmlDecodeResponse(resp *Response) *Response {
+	if resp == nil {
+		return resp
+	}
+	text := resp.Message.TextContent()
+	block, calls, ok := extractXMLFunctionCalls(text)
+	if !ok {
+		return resp
+	}
+
+	remaining := strings.TrimSpace(strings.Replace(text, block, "", 1))
+	var content []ContentPart
+	if remaining != "" {
+		content = append(content, TextPart(remaining))
+	}
+	for _, call := range calls {
+		call := call
+		content = append(content, ContentPart{Kind: ContentToolCall, ToolCall: &call})
+	}
+
+	resp.Message.Content = content
+	resp.FinishReason = FinishReason{Reason: "tool_calls", Raw: resp.FinishReason.Raw}
+	return resp
+}
+
+// XMLToolCallMiddleware returns a Middleware that lets a text-only model
+// (a local model, an older endpoint, or any provider adapter reporting no
+// native tool support) participate in the Tool/ToolCall protocol: it
+// describes req.ToolDefs as a <tools> block in the system prompt, adds the
+// </function_calls> stop sequence, parses <function_calls>...</invoke>
+// blocks out of the assistant's text response into ToolCallPart content,
+// and round-trips prior tool calls/results back into the XML form on the
+// next request. Requests with no tools pass through untouched, so it's
+// safe to attach to every Client alongside providers that do have native
+// tool support -- it's a no-op for them.
+func XMLToolCallMiddleware() Middleware {
+	return func(ctx context.Context, req Request, next func(context.Context, Request) (*Response, error)) (*Response, error) {
+		resp, err := next(ctx, xmlEncodeRequest(req))
+		if err != nil {
+			return nil, err
+		}
+		return xmlDecodeResponse(resp), nil
+	}
+}
+
+// XMLToolCallStreamMiddleware is XMLToolCallMiddleware for Stream: it
+// encodes the request the same way, then buffers the streamed text and
+// re-emits it as TextDelta/ToolCallStart/ToolCallEnd events once the
+// </function_calls> stop sequence closes the block, since a <function_calls>
+// block can't be meaningfully parsed until it's complete.
+func XMLToolCallStreamMiddleware() StreamMiddleware {
+	return func(ctx context.Context, req Request, next func(context.Context, Request) (<-chan StreamEvent, error)) (<-chan StreamEvent, error) {
+		if len(req.ToolDefs) == 0 {
+			return next(ctx, req)
+		}
+
+		events, err := next(ctx, xmlEncodeRequest(req))
+		if err != nil {
+			return nil, err
+		}
+
+		out := make(chan StreamEvent, 16)
+		go decodeXMLStream(events, out)
+		return out, nil
+	}
+}
+
+// decodeXMLStream drains events, accumulating assistant text until it sees
+// TextEnd or StreamFinish, then runs it through xmlDecodeResponse's parsing
+// logic before re-emitting it as TextDelta/ToolCallStart/ToolCallEnd/TextEnd
+// on out. Non-text events (StreamStart, StreamError, usage) pass through
+// unchanged. It always closes out.
+func decodeXMLStream(events <-chan StreamEvent, out chan<- StreamEvent) {
+	defer close(out)
+
+	var text strings.Builder
+	for event := range events {
+		switch event.Type {
+		case TextDelta:
+			text.WriteString(event.Delta)
+		case TextEnd, StreamFinish:
+			flushXMLText(text.String(), out)
+			text.Reset()
+			out <- event
+		default:
+			out <- event
+		}
+	}
+}
+
+// flushXMLText emits the accumulated assistant text as either a single
+// TextDelta (no function call found) or ToolCallStart/ToolCallEnd pairs for
+// each parsed invoke, optionally preceded by a TextDelta for any leftover
+// surrounding text.
+func flushXMLText(full string, out chan<- StreamEvent) {
+	if full == "" {
+		return
+	}
+	block, calls, ok := extractXMLFunctionCalls(full)
+	if !ok {
+		out <- StreamEvent{Type: TextDelta, Delta: full}
+		return
+	}
+
+	if remaining := strings.TrimSpace(strings.Replace(full, block, "", 1)); remaining != "" {
+		out <- StreamEvent{Type: TextDelta, Delta: remaining}
+	}
+	for _, call := range calls {
+		tc := ToolCall{ID: call.ID, Name: call.Name, Arguments: call.Arguments}
+		out <- StreamEvent{Type: ToolCallStart, ToolCall: &ToolCall{ID: tc.ID, Name: tc.Name}}
+		out <- StreamEvent{Type: ToolCallEnd, ToolCall: &tc}
+	}
+}
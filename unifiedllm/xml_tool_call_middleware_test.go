@@ -0,0 +1,176 @@
+package unifiedllm
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func testWeatherToolDefs() []ToolDefinition {
+	return []ToolDefinition{{
+		Name:        "get_weather",
+		Description: "Get the current weather for a location",
+		Parameters: map[string]interface{}{
+			"type":       "object",
+			"properties": map[string]interface{}{"city": map[string]interface{}{"type": "string"}},
+		},
+	}}
+}
+
+func TestXMLToolCallMiddlewareEncodesToolsAndStopSequence(t *testing.T) {
+	req := Request{
+		Model:    "local-model",
+		Messages: []Message{UserMessage("what's the weather in Denver?")},
+		ToolDefs: testWeatherToolDefs(),
+	}
+
+	var captured Request
+	mw := XMLToolCallMiddleware()
+	_, err := mw(context.Background(), req, func(_ context.Context, r Request) (*Response, error) {
+		captured = r
+		return &Response{Message: AssistantMessage("it's sunny")}, nil
+	})
+	if err != nil {
+		t.Fatalf("middleware: %v", err)
+	}
+
+	if len(captured.ToolDefs) != 0 {
+		t.Errorf("expected ToolDefs to be cleared before calling next, got %+v", captured.ToolDefs)
+	}
+	found := false
+	for _, s := range captured.StopSequences {
+		if s == xmlFunctionCallStop {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("expected %q among stop sequences, got %v", xmlFunctionCallStop, captured.StopSequences)
+	}
+	if captured.Messages[0].Role != RoleSystem || !strings.Contains(captured.Messages[0].TextContent(), "get_weather") {
+		t.Errorf("expected a system message describing get_weather, got %+v", captured.Messages[0])
+	}
+}
+
+func TestXMLToolCallMiddlewareDecodesFunctionCalls(t *testing.T) {
+	req := Request{Model: "local-model", ToolDefs: testWeatherToolDefs(), Messages: []Message{UserMessage("weather in Denver?")}}
+
+	mw := XMLToolCallMiddleware()
+	resp, err := mw(context.Background(), req, func(_ context.Context, r Request) (*Response, error) {
+		text := "<function_calls>\n<invoke name=\"get_weather\">\n<parameter name=\"city\">Denver</parameter>\n</invoke>\n</function_calls>"
+		return &Response{Message: AssistantMessage(text)}, nil
+	})
+	if err != nil {
+		t.Fatalf("middleware: %v", err)
+	}
+
+	calls := resp.Message.ToolCalls()
+	if len(calls) != 1 {
+		t.Fatalf("expected 1 tool call, got %d: %+v", len(calls), calls)
+	}
+	if calls[0].Name != "get_weather" {
+		t.Errorf("expected get_weather, got %q", calls[0].Name)
+	}
+	if !strings.Contains(string(calls[0].Arguments), "Denver") {
+		t.Errorf("expected arguments to contain Denver, got %s", calls[0].Arguments)
+	}
+	if resp.FinishReason.Reason != "tool_calls" {
+		t.Errorf("expected finish reason tool_calls, got %q", resp.FinishReason.Reason)
+	}
+}
+
+func TestXMLToolCallMiddlewareRoundTripsHistory(t *testing.T) {
+	prior := Message{Role: RoleAssistant, Content: []ContentPart{ToolCallPart("call_abc", "get_weather", []byte(`{"city":"Denver"}`))}}
+	toolResult := ToolResultMessage("call_abc", "sunny and 72F", false)
+
+	req := Request{
+		Model:    "local-model",
+		ToolDefs: testWeatherToolDefs(),
+		Messages: []Message{UserMessage("weather in Denver?"), prior, toolResult},
+	}
+
+	var captured Request
+	mw := XMLToolCallMiddleware()
+	_, err := mw(context.Background(), req, func(_ context.Context, r Request) (*Response, error) {
+		captured = r
+		return &Response{Message: AssistantMessage("done")}, nil
+	})
+	if err != nil {
+		t.Fatalf("middleware: %v", err)
+	}
+
+	var sawFunctionCalls, sawFunctionResults bool
+	for _, m := range captured.Messages {
+		text := m.TextContent()
+		if strings.Contains(text, "<function_calls>") && strings.Contains(text, "get_weather") {
+			sawFunctionCalls = true
+		}
+		if strings.Contains(text, "<function_results>") && strings.Contains(text, "sunny and 72F") {
+			sawFunctionResults = true
+			if m.Role != RoleUser {
+				t.Errorf("expected function_results to be rendered as a user turn, got role %q", m.Role)
+			}
+		}
+	}
+	if !sawFunctionCalls {
+		t.Error("expected the prior assistant tool call to round-trip back into <function_calls> text")
+	}
+	if !sawFunctionResults {
+		t.Error("expected the prior tool result to round-trip back into <function_results> text")
+	}
+}
+
+func TestXMLToolCallMiddlewareNoToolsIsNoOp(t *testing.T) {
+	req := Request{Model: "local-model", Messages: []Message{UserMessage("hello")}}
+
+	var captured Request
+	mw := XMLToolCallMiddleware()
+	_, err := mw(context.Background(), req, func(_ context.Context, r Request) (*Response, error) {
+		captured = r
+		return &Response{Message: AssistantMessage("hi")}, nil
+	})
+	if err != nil {
+		t.Fatalf("middleware: %v", err)
+	}
+	if len(captured.Messages) != 1 || captured.Messages[0].Role != RoleUser {
+		t.Errorf("expected request to pass through unmodified, got %+v", captured.Messages)
+	}
+}
+
+func TestXMLToolCallStreamMiddlewareDecodesFunctionCalls(t *testing.T) {
+	req := Request{Model: "local-model", ToolDefs: testWeatherToolDefs(), Messages: []Message{UserMessage("weather in Denver?")}}
+
+	smw := XMLToolCallStreamMiddleware()
+	events, err := smw(context.Background(), req, func(_ context.Context, r Request) (<-chan StreamEvent, error) {
+		ch := make(chan StreamEvent, 8)
+		go func() {
+			defer close(ch)
+			ch <- StreamEvent{Type: StreamStart}
+			ch <- StreamEvent{Type: TextDelta, Delta: "<function_calls>\n<invoke name=\"get_weather\">\n"}
+			ch <- StreamEvent{Type: TextDelta, Delta: "<parameter name=\"city\">Denver</parameter>\n</invoke>\n</function_calls>"}
+			ch <- StreamEvent{Type: TextEnd}
+			ch <- StreamEvent{Type: StreamFinish, FinishReason: &FinishReason{Reason: "stop"}}
+		}()
+		return ch, nil
+	})
+	if err != nil {
+		t.Fatalf("stream middleware: %v", err)
+	}
+
+	var sawStart, sawEnd bool
+	var toolCallName string
+	for evt := range events {
+		switch evt.Type {
+		case ToolCallStart:
+			sawStart = true
+			toolCallName = evt.ToolCall.Name
+		case ToolCallEnd:
+			sawEnd = true
+		}
+	}
+	if !sawStart || !sawEnd {
+		t.Fatalf("expected ToolCallStart and ToolCallEnd, got start=%v end=%v", sawStart, sawEnd)
+	}
+	if toolCallName != "get_weather" {
+		t.Errorf("expected get_weather, got %q", toolCallName)
+	}
+}